@@ -22,6 +22,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // StatefulSetOptions defines custom options for StatefulSets
@@ -41,6 +42,11 @@ type StatefulSetOptions struct {
 	// +optional
 	// +kubebuilder:validation:Enum=OrderedReady;Parallel
 	PodManagementPolicy appsv1.PodManagementPolicyType `json:"podManagementPolicy,omitempty"`
+
+	// Recreate the StatefulSet when the podManagementPolicy is changed, instead of only reporting that a recreate is required.
+	// The StatefulSet is deleted without deleting its pods, which are adopted by the recreated StatefulSet.
+	// +optional
+	RecreateOnPodManagementPolicyChange bool `json:"recreateOnPodManagementPolicyChange,omitempty"`
 }
 
 // DeploymentOptions defines custom options for Deployments
@@ -74,10 +80,26 @@ type PodOptions struct {
 	// +optional
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 
+	// ContainerSecurityContext is the security context for the default container.
+	// This is also used for the init containers, created by the Solr Operator, that use the same image as the default container.
+	// +optional
+	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// InitContainerResources is the resource requirements for the init containers that the Solr Operator creates,
+	// such as the ones that wait for Zookeeper and generate a PKCS12 keystore.
+	// Defaults to small requests and limits, so that the pods can be scheduled in namespaces with enforced LimitRanges or ResourceQuotas.
+	// +optional
+	InitContainerResources *corev1.ResourceRequirements `json:"initContainerResources,omitempty"`
+
 	// Additional environment variables to pass to the default container.
 	// +optional
 	EnvVariables []corev1.EnvVar `json:"envVars,omitempty"`
 
+	// Sources, such as ConfigMaps and Secrets, to populate environment variables in the default container.
+	// Environment variables set by the Solr Operator, or through envVars, take precedence over the variables from these sources.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
 	// Annotations to be added for pods.
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
@@ -94,6 +116,11 @@ type PodOptions struct {
 	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 
+	// Topology spread constraints to be added for the pods.
+	// If a constraint does not specify a labelSelector, it will select all pods managed by this resource.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
 	// Liveness probe parameters
 	// +optional
 	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
@@ -115,7 +142,7 @@ type PodOptions struct {
 	SidecarContainers []corev1.Container `json:"sidecarContainers,omitempty"`
 
 	// Additional init containers to run in the pod.
-	// These will run along with the init container that sets up the "solr.xml".
+	// These will run after the init containers that the Solr Operator creates, such as the one that sets up the "solr.xml".
 	// +optional
 	InitContainers []corev1.Container `json:"initContainers,omitempty"`
 
@@ -130,9 +157,46 @@ type PodOptions struct {
 	// +optional
 	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
 
+	// PreStop is the handler that is called before the default container is terminated.
+	// For SolrClouds, this replaces the preStop hook that the Solr Operator provides, which stops Solr gracefully.
+	// Only exec and httpGet handlers are supported, since Kubernetes does not run tcpSocket lifecycle hooks.
+	// +optional
+	PreStop *corev1.Handler `json:"preStop,omitempty"`
+
+	// PostStart is the handler that is called right after the default container is started, such as to warm caches or register with service discovery.
+	// For SolrClouds, an exec handler is run after the postStart hook that the Solr Operator provides to create the Zookeeper chroot.
+	// Other handlers replace that hook, so they can only be used when zookeeperRef.waitForZookeeper is enabled, which creates the chroot in an initContainer instead.
+	// Only exec and httpGet handlers are supported, since Kubernetes does not run tcpSocket lifecycle hooks.
+	// +optional
+	PostStart *corev1.Handler `json:"postStart,omitempty"`
+
 	// Optional Service Account to run the pod under.
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Static entries to add to the pod's hosts file.
+	// For SolrClouds that advertise their external addresses, these are merged with the host aliases that the operator
+	// manages for the Solr node services. The operator-managed entries take precedence for any hostnames that they share.
+	// +optional
+	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
+
+	// The DNS policy for the pod, such as None to only use the nameservers and search domains given in the dnsConfig.
+	//
+	// Defaults to ClusterFirst.
+	//
+	// +kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	// +optional
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// Custom DNS parameters for the pod, such as additional nameservers and search domains.
+	// These are merged with the DNS configuration generated from the dnsPolicy.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// The name of the RuntimeClass to run the pod with, such as a sandboxed runtime like gVisor.
+	// The RuntimeClass must exist in the cluster, otherwise the pods cannot be created.
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
 }
 
 // ServiceOptions defines custom options for services
@@ -144,6 +208,26 @@ type ServiceOptions struct {
 	// Labels to be added for the Service.
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// The type of the Service.
+	// This overrides the type that the external addressability method would use for the Service.
+	// This option is currently only supported for the common service of a SolrCloud.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// The IP family policy of the Service, used to request a dual-stack Service.
+	// If not provided, the Kubernetes default is used, which is a single-stack Service.
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	// +optional
+	IPFamilyPolicy *corev1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+
+	// The IP families of the Service, in order of preference.
+	// The first family determines the clusterIP of the Service, which is the address that Solr nodes advertise when using the IPs of their node services.
+	// If not provided, the families are defaulted by Kubernetes based on the ipFamilyPolicy and the configuration of the cluster.
+	// +kubebuilder:validation:MaxItems=2
+	// +optional
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
 }
 
 // IngressOptions defines custom options for ingresses
@@ -155,6 +239,33 @@ type IngressOptions struct {
 	// Labels to be added for the Ingress.
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// IngressClassName is the name of the IngressClass cluster resource, which determines the ingress controller that serves the Ingress.
+	// When this is provided, the deprecated "kubernetes.io/ingress.class" annotation will not be added to the Ingress.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+}
+
+// PodDisruptionBudgetOptions defines custom options for PodDisruptionBudgets
+type PodDisruptionBudgetOptions struct {
+	// Do not create a PodDisruptionBudget.
+	// Defaults to false.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// The maximum number of pods that can be unavailable due to voluntary disruptions, such as node drains.
+	// Value can be an absolute number (ex: 5) or a percentage of the desired number of pods (ex: 25%).
+	// Defaults to the maxPodsUnavailable of the managed update strategy.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// Annotations to be added for the PodDisruptionBudget.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels to be added for the PodDisruptionBudget.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // ConfigMapOptions defines custom options for configMaps
@@ -170,6 +281,28 @@ type ConfigMapOptions struct {
 	// Name of a user provided ConfigMap in the same namespace containing a custom solr.xml
 	// +optional
 	ProvidedConfigMap string `json:"providedConfigMap,omitempty"`
+
+	// Name of a user provided ConfigMap in the same namespace containing a custom log4j2.xml.
+	// This takes precedence over a log4j2.xml in the providedConfigMap.
+	// Only used for SolrClouds.
+	// +optional
+	ProvidedLogXmlConfigMap string `json:"providedLogXmlConfigMap,omitempty"`
+
+	// User provided ConfigMaps in the same namespace, to mount into the Solr container.
+	// Changes to the contents of these ConfigMaps trigger a restart of the Solr pods.
+	// Only used for SolrClouds.
+	// +optional
+	AdditionalConfigMaps []AdditionalConfigMap `json:"additionalConfigMaps,omitempty"`
+}
+
+// AdditionalConfigMap is a user provided ConfigMap to mount into the Solr container
+type AdditionalConfigMap struct {
+	// Name of the ConfigMap, in the same namespace as the SolrCloud
+	Name string `json:"name"`
+
+	// Path within the Solr container at which the files of the ConfigMap should be mounted.
+	// Must be an absolute path, and cannot be shared with any other additional ConfigMap.
+	MountPath string `json:"mountPath"`
 }
 
 // AdditionalVolume provides information on additional volumes that should be loaded into pods
@@ -185,6 +318,12 @@ type AdditionalVolume struct {
 	// then this option is not necessary.
 	// +optional
 	DefaultContainerMount *corev1.VolumeMount `json:"defaultContainerMount,omitempty"`
+
+	// Do not restart the Solr pods when the contents of the ConfigMaps or Secrets in this volume change.
+	// By default, SolrCloud pods are restarted when a ConfigMap or Secret that is mounted into the Solr container changes.
+	// Use this option if Solr picks up the changes by itself, or if reloads are managed outside of the Solr Operator.
+	// +optional
+	SkipRestartOnChange bool `json:"skipRestartOnChange,omitempty"`
 }
 
 // ContainerImage defines the fields needed for a Docker repository image. The
@@ -221,7 +360,8 @@ func (c *ContainerImage) ToImageName() (name string) {
 }
 
 func ImageVersion(image string) (version string) {
-	split := strings.Split(image, ":")
+	// Only look for a tag after the last '/', since the registry host of the image may include a port
+	split := strings.Split(image[strings.LastIndex(image, "/")+1:], ":")
 	if len(split) < 2 {
 		return ""
 	} else {
@@ -254,6 +394,10 @@ type ZookeeperConnectionInfo struct {
 	// This ACL should have READ permission in the given chRoot.
 	// +optional
 	ReadOnlyACL *ZookeeperACL `json:"readOnlyAcl,omitempty"`
+
+	// Options to connect to ZK through its secure client port, using TLS.
+	// +optional
+	TLS *ZookeeperTLSOptions `json:"tls,omitempty"`
 }
 
 func (ci *ZookeeperConnectionInfo) withDefaults() (changed bool) {
@@ -285,3 +429,22 @@ type ZookeeperACL struct {
 	// The name of the key in the given secret that contains the ACL password
 	PasswordKey string `json:"passwordKey"`
 }
+
+// ZookeeperTLSOptions defines the keystores to use when connecting to ZK through its secure client port
+type ZookeeperTLSOptions struct {
+	// The TrustStore, in PKCS12 format, that is used to verify the certificates of the ZK servers.
+	TrustStoreSecret corev1.SecretKeySelector `json:"trustStoreSecret"`
+
+	// The password for the TrustStore.
+	// +optional
+	TrustStorePasswordSecret *corev1.SecretKeySelector `json:"trustStorePasswordSecret,omitempty"`
+
+	// The KeyStore, in PKCS12 format, that is used to authenticate with the ZK servers.
+	// Only necessary if ZK requires client authentication.
+	// +optional
+	KeyStoreSecret *corev1.SecretKeySelector `json:"keyStoreSecret,omitempty"`
+
+	// The password for the KeyStore.
+	// +optional
+	KeyStorePasswordSecret *corev1.SecretKeySelector `json:"keyStorePasswordSecret,omitempty"`
+}
@@ -22,6 +22,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"strings"
+	"time"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -31,6 +32,7 @@ const (
 	DefaultAWSCliImageRepo    = "infrastructureascode/aws-cli"
 	DefaultAWSCliImageVersion = "1.16.204"
 	DefaultS3Retries          = 5
+	DefaultMaxRetainedBackups = 5
 )
 
 // SolrBackupSpec defines the desired state of SolrBackup
@@ -42,16 +44,75 @@ type SolrBackupSpec struct {
 	// +optional
 	Collections []string `json:"collections,omitempty"`
 
+	// The maximum number of collections to back up at the same time, to avoid overloading the SolrCloud.
+	// Collections are backed up in the order that they are listed.
+	// If not provided, all collections are backed up at once.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentBackups int32 `json:"maxConcurrentBackups,omitempty"`
+
+	// The name of a backup repository, defined in the SolrCloud's storage options, to store the backup in.
+	// If not provided, the backup is taken to the SolrCloud's backupRestoreOptions volume and persisted using the persistence options.
+	// +optional
+	RepositoryName string `json:"repositoryName,omitempty"`
+
 	// Persistence is the specification on how to persist the backup data.
+	// This is not used when the backup is stored in a backup repository, or taken using volume snapshots.
+	// +optional
 	Persistence PersistenceSource `json:"persistence"`
+
+	// Back up the SolrCloud by taking a CSI VolumeSnapshot of each of its data PVCs, instead of using Solr's backup API.
+	// This requires the SolrCloud to use persistent data storage, and cannot be combined with a backup repository.
+	// +optional
+	VolumeSnapshot *VolumeSnapshotBackupOptions `json:"volumeSnapshot,omitempty"`
+
+	// Schedule for taking recurring backups, in CRON syntax.
+	// If not provided, the backup is only taken once.
+	// Each scheduled backup is persisted separately, with the time that the backup was started appended to the name of the persisted file.
+	//
+	// Multiple CRON syntaxes are supported
+	//   - Standard CRON (e.g. "CRON_TZ=Asia/Seoul 0 6 * * ?")
+	//   - Predefined Schedules (e.g. "@yearly", "@weekly", etc.)
+	//   - Intervals (e.g. "@every 10h30m")
+	//
+	// For more information please check this reference:
+	// https://pkg.go.dev/github.com/robfig/cron/v3?utm_source=godoc#hdr-CRON_Expression_Format
+	//
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// The maximum number of scheduled backups to retain, including the backup that is currently being taken.
+	// When a new backup is started, the oldest backups are deleted from the persistence location until this is met.
+	// Only used when a schedule is provided, defaults to 5.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRetained int32 `json:"maxRetained,omitempty"`
 }
 
 func (spec *SolrBackupSpec) withDefaults(backupName string) (changed bool) {
 	changed = spec.Persistence.withDefaults(backupName) || changed
 
+	if spec.Schedule != "" && spec.MaxRetained == 0 {
+		spec.MaxRetained = DefaultMaxRetainedBackups
+		changed = true
+	}
+
 	return changed
 }
 
+// VolumeSnapshotBackupOptions defines how the data PVCs of a SolrCloud are snapshotted
+type VolumeSnapshotBackupOptions struct {
+	// The name of the VolumeSnapshotClass to take the snapshots with
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName"`
+
+	// Commit the backup's collections before taking the snapshots, so that all indexed documents are flushed to disk.
+	// Documents that are indexed while the snapshots are taken might still be missing, so the snapshots are only consistent on a best-effort basis.
+	// +optional
+	CommitBeforeSnapshot bool `json:"commitBeforeSnapshot,omitempty"`
+}
+
 // PersistenceSource defines the location and method of persisting the backup data.
 // Exactly one member must be specified.
 type PersistenceSource struct {
@@ -193,6 +254,10 @@ type SolrBackupStatus struct {
 	// +optional
 	CollectionBackupStatuses []CollectionBackupStatus `json:"collectionBackupStatuses,omitempty"`
 
+	// The status of each VolumeSnapshot taken, if the backup uses volume snapshots
+	// +optional
+	VolumeSnapshotStatuses []VolumeSnapshotStatus `json:"volumeSnapshotStatuses,omitempty"`
+
 	// Whether the backups are in progress of being persisted
 	PersistenceStatus BackupPersistenceStatus `json:"persistenceStatus"`
 
@@ -206,6 +271,40 @@ type SolrBackupStatus struct {
 
 	// Whether the backup has finished
 	Finished bool `json:"finished,omitempty"`
+
+	// An error that is preventing the backup from being taken, such as a misconfigured backup repository
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Time that the most recent scheduled backup was started at
+	// +optional
+	LastBackupTime *metav1.Time `json:"lastBackupTimestamp,omitempty"`
+
+	// Time that the next scheduled backup will be started at
+	// +optional
+	NextScheduledTime *metav1.Time `json:"nextScheduledTimestamp,omitempty"`
+
+	// The finished scheduled backups that are retained in the persistence location, from oldest to newest
+	// +optional
+	RetainedBackups []RetainedBackupStatus `json:"retainedBackups,omitempty"`
+}
+
+// RetainedBackupStatus describes a finished scheduled backup that is retained in the persistence location
+type RetainedBackupStatus struct {
+	// Name of the backup, including the time that the backup was started
+	Name string `json:"name"`
+
+	// Time that the backup started at
+	// +optional
+	StartTime *metav1.Time `json:"startTimestamp,omitempty"`
+
+	// Time that the backup finished at
+	// +optional
+	FinishTime *metav1.Time `json:"finishTimestamp,omitempty"`
+
+	// Whether the backup was successful
+	// +optional
+	Successful *bool `json:"successful,omitempty"`
 }
 
 // CollectionBackupStatus defines the progress of a Solr Collection's backup
@@ -221,10 +320,19 @@ type CollectionBackupStatus struct {
 	// +optional
 	StartTime *metav1.Time `json:"startTimestamp,omitempty"`
 
-	// The status of the asynchronous backup call to solr
+	// The status of the asynchronous backup call to solr.
+	// This is one of "submitted", "running", "completed" or "failed".
 	// +optional
 	AsyncBackupStatus string `json:"asyncBackupStatus,omitempty"`
 
+	// The id of the asynchronous backup call to solr, which can be used with the REQUESTSTATUS Collections API
+	// +optional
+	AsyncRequestId string `json:"asyncRequestId,omitempty"`
+
+	// The error message given by solr, if the backup failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
 	// Whether the backup has finished
 	Finished bool `json:"finished,omitempty"`
 
@@ -237,6 +345,28 @@ type CollectionBackupStatus struct {
 	Successful *bool `json:"successful,omitempty"`
 }
 
+// VolumeSnapshotStatus defines the progress of a VolumeSnapshot of a Solr data PVC
+type VolumeSnapshotStatus struct {
+	// The name of the Solr data PVC that is snapshotted
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// The name of the VolumeSnapshot
+	VolumeSnapshot string `json:"volumeSnapshot"`
+
+	// Whether the VolumeSnapshot is ready to be used to restore a volume
+	// +optional
+	ReadyToUse bool `json:"readyToUse,omitempty"`
+
+	// The error that occurred while taking the snapshot
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// Finished returns whether the VolumeSnapshot is either ready to use, or has failed
+func (vss *VolumeSnapshotStatus) Finished() bool {
+	return vss.ReadyToUse || vss.Error != ""
+}
+
 // BackupPersistenceStatus defines the status of persisting Solr backup data
 type BackupPersistenceStatus struct {
 	// Whether the collection is being backed up
@@ -276,9 +406,50 @@ func (sb *SolrBackup) SharedLabelsWith(labels map[string]string) map[string]stri
 	return newLabels
 }
 
-// HeadlessServiceName returns the name of the headless service for the cloud
+// IsScheduled returns whether the backup is taken on a recurring schedule
+func (sb *SolrBackup) IsScheduled() bool {
+	return sb.Spec.Schedule != ""
+}
+
+// UsesBackupRepository returns whether the backup is stored in one of the SolrCloud's backup repositories,
+// instead of the backupRestoreOptions volume
+func (sb *SolrBackup) UsesBackupRepository() bool {
+	return sb.Spec.RepositoryName != ""
+}
+
+// UsesVolumeSnapshots returns whether the backup is taken by snapshotting the SolrCloud's data PVCs,
+// instead of using Solr's backup API
+func (sb *SolrBackup) UsesVolumeSnapshots() bool {
+	return sb.Spec.VolumeSnapshot != nil
+}
+
+// CurrentBackupName returns the name of the backup that is currently being taken.
+// Scheduled backups include the time that the backup was started, so that each one is stored separately.
+func (sb *SolrBackup) CurrentBackupName() string {
+	if sb.Status.LastBackupTime != nil {
+		return ScheduledBackupName(sb.GetName(), sb.Status.LastBackupTime.Time)
+	}
+	return sb.GetName()
+}
+
+// ScheduledBackupName returns the name of a scheduled backup that was started at the given time
+func ScheduledBackupName(backupName string, startTime time.Time) string {
+	return fmt.Sprintf("%s-%s", backupName, startTime.UTC().Format("20060102-150405"))
+}
+
+// PersistenceJobName returns the name of the persistence job for the current backup
 func (sb *SolrBackup) PersistenceJobName() string {
-	return fmt.Sprintf("%s-solr-backup-persistence", sb.GetName())
+	return PersistenceJobNameForBackup(sb.CurrentBackupName())
+}
+
+// PersistenceJobNameForBackup returns the name of the persistence job for the given backup name
+func PersistenceJobNameForBackup(backupName string) string {
+	return fmt.Sprintf("%s-solr-backup-persistence", backupName)
+}
+
+// PruneJobName returns the name of the job that deletes the persisted data for a retained scheduled backup
+func (sb *SolrBackup) PruneJobName(backupName string) string {
+	return fmt.Sprintf("%s-solr-backup-prune", backupName)
 }
 
 //+kubebuilder:object:root=true
@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	zk "github.com/pravega/zookeeper-operator/pkg/apis/zookeeper/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,6 +43,10 @@ const (
 	DefaultSolrLogLevel = "INFO"
 	DefaultSolrGCTune   = ""
 
+	DefaultSolrContextPath       = "/solr"
+	DefaultSolrPodPort           = 8983
+	DefaultSolrCommonServicePort = 80
+
 	DefaultBusyBoxImageRepo    = "library/busybox"
 	DefaultBusyBoxImageVersion = "1.28.0-glibc"
 
@@ -51,10 +56,14 @@ const (
 	DefaultZkVersion             = ""
 	DefaultZkVolumeReclaimPolicy = zk.VolumeReclaimPolicyRetain
 
-	SolrTechnologyLabel      = "solr-cloud"
-	ZookeeperTechnologyLabel = "zookeeper"
+	SolrTechnologyLabel             = "solr-cloud"
+	ZookeeperTechnologyLabel        = "zookeeper"
+	ZookeeperCleanupTechnologyLabel = "solr-zookeeper-cleanup"
+	SecurityJsonTechnologyLabel     = "solr-security-json"
 
 	DefaultBasicAuthUsername = "k8s-oper"
+
+	DefaultVersionSkewWarningSeconds = int32(3600)
 )
 
 // SolrCloudSpec defines the desired state of SolrCloud
@@ -77,6 +86,15 @@ type SolrCloudSpec struct {
 	// +optional
 	StorageOptions SolrDataStorageOptions `json:"dataStorage,omitempty"`
 
+	// Restore collections from an existing backup, once all Solr nodes of the SolrCloud are ready.
+	// Each collection is only restored once, and collections that already exist in the SolrCloud are never overwritten.
+	// +optional
+	Restore *SolrCloudRestoreOptions `json:"restore,omitempty"`
+
+	// Drain the collections of the SolrCloud when it is deleted, before its StatefulSets and PVCs are removed.
+	// +optional
+	DeletionOptions *SolrCloudDeletionOptions `json:"deletionOptions,omitempty"`
+
 	// Provide custom options for kubernetes objects created for the Solr Cloud.
 	// +optional
 	CustomSolrKubeOptions CustomSolrKubeOptions `json:"customSolrKubeOptions,omitempty"`
@@ -89,22 +107,70 @@ type SolrCloudSpec struct {
 	// +optional
 	UpdateStrategy SolrUpdateStrategy `json:"updateStrategy,omitempty"`
 
+	// Define how the Solr pods are scaled up and down.
+	// +optional
+	Scaling SolrScalingOptions `json:"scaling,omitempty"`
+
+	// The longest time that a Solr node is expected to take to start, such as to open the cores of large indexes.
+	// The failureThreshold of the startupProbe of the Solr container is computed from this time, so that Solr is not restarted while it is still starting.
+	// A startupProbe is added with the default settings if none is given in the custom pod options, and a failureThreshold given there takes precedence.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxStartupSeconds *int32 `json:"maxStartupSeconds,omitempty"`
+
+	// Additional groups of Solr nodes that run with different resources than the Solr nodes given by replicas,
+	// such as more heap or larger disks. Each node pool is run by its own StatefulSet.
+	// +optional
+	NodePools []SolrNodePool `json:"nodePools,omitempty"`
+
+	// Stop the Solr Operator from reconciling this SolrCloud.
+	// While paused, the StatefulSet, services, Zookeeper resources and PVCs of the SolrCloud are left untouched,
+	// and no managed updates are performed. A paused SolrCloud with a storage finalizer cannot be deleted until it is resumed.
+	// Defaults to false.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Make the collections of the SolrCloud read-only, such as during a maintenance window, through the readOnly property of the Collections API.
+	// Only collections that are made read-only by the Solr Operator are made writable again once this is disabled,
+	// so collections that were already read-only are left as they are.
+	// Defaults to false.
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
 	// +optional
 	BusyBoxImage *ContainerImage `json:"busyBoxImage,omitempty"`
 
+	// Set the heap settings of the Solr JVM through the SOLR_JAVA_MEM environment variable, defaults to "-Xms1g -Xmx2g".
+	// These take precedence over a SOLR_JAVA_MEM env var given in the custom pod options.
+	// +kubebuilder:validation:Pattern=`\S`
 	// +optional
 	SolrJavaMem string `json:"solrJavaMem,omitempty"`
 
 	// You can add common system properties to the SOLR_OPTS environment variable
 	// SolrOpts is the string interface for these optional settings
+	// These take precedence over a SOLR_OPTS env var given in the custom pod options.
 	// +optional
 	SolrOpts string `json:"solrOpts,omitempty"`
 
+	// Additional JVM options to add to the SOLR_OPTS environment variable, one option per entry, e.g. "-DsocketTimeout=300000".
+	// These are added after the options that the Solr Operator manages and after solrOpts,
+	// and they cannot set the system properties that the Solr Operator manages, such as hostPort.
+	// +optional
+	AdditionalJavaOpts []JavaOpt `json:"additionalJavaOpts,omitempty"`
+
 	// Set the Solr Log level, defaults to INFO
 	// +optional
 	SolrLogLevel string `json:"solrLogLevel,omitempty"`
 
+	// A custom log4j2.xml for Solr, given inline.
+	// The Solr Operator stores it in the SolrCloud's generated ConfigMap and points log4j at it through -Dlog4j.configurationFile.
+	// Changes to it trigger a restart of the Solr pods, unless the configuration sets a monitorInterval.
+	// This takes precedence over a log4j2.xml in the providedConfigMap, and cannot be used alongside a providedLogXmlConfigMap.
+	// +optional
+	SolrLogXml string `json:"solrLogXml,omitempty"`
+
 	// Set GC Tuning configuration through GC_TUNE environment variable
+	// These take precedence over a GC_TUNE env var given in the custom pod options.
 	// +optional
 	SolrGCTune string `json:"solrGCTune,omitempty"`
 
@@ -124,7 +190,7 @@ func (spec *SolrCloudSpec) withDefaults() (changed bool) {
 		spec.Replicas = &r
 	}
 
-	if spec.SolrJavaMem == "" && DefaultSolrJavaMem != "" {
+	if strings.TrimSpace(spec.SolrJavaMem) == "" && DefaultSolrJavaMem != "" {
 		changed = true
 		spec.SolrJavaMem = DefaultSolrJavaMem
 	}
@@ -166,6 +232,10 @@ func (spec *SolrCloudSpec) withDefaults() (changed bool) {
 	}
 	changed = spec.BusyBoxImage.withDefaults(DefaultBusyBoxImageRepo, DefaultBusyBoxImageVersion, DefaultPullPolicy) || changed
 
+	if spec.DeletionOptions != nil {
+		changed = spec.DeletionOptions.withDefaults() || changed
+	}
+
 	return changed
 }
 
@@ -198,6 +268,20 @@ type CustomSolrKubeOptions struct {
 	// IngressOptions defines the custom options for the solrCloud Ingress.
 	// +optional
 	IngressOptions *IngressOptions `json:"ingressOptions,omitempty"`
+
+	// PodDisruptionBudgetOptions defines the custom options for the solrCloud PodDisruptionBudget.
+	// +optional
+	PodDisruptionBudgetOptions *PodDisruptionBudgetOptions `json:"podDisruptionBudgetOptions,omitempty"`
+
+	// CommonLabels are added to the resources that the Solr Operator creates for the SolrCloud, including the Solr pods.
+	// Labels that the Solr Operator requires, and labels given in the options of a specific resource, take precedence.
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+
+	// CommonAnnotations are added to the resources that the Solr Operator creates for the SolrCloud, including the Solr pods.
+	// Annotations that the Solr Operator requires, and annotations given in the options of a specific resource, take precedence.
+	// +optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
 }
 
 type SolrDataStorageOptions struct {
@@ -217,9 +301,31 @@ type SolrDataStorageOptions struct {
 	// +optional
 	EphemeralStorage *SolrEphemeralDataStorageOptions `json:"ephemeral,omitempty"`
 
+	// LogStorage is the specification for a separate volume to store the Solr logs in, through the SOLR_LOGS_DIR.
+	//
+	// By default, the Solr logs are stored in the filesystem of the Solr container.
+	//
+	// +optional
+	LogStorage *SolrLogStorageOptions `json:"logs,omitempty"`
+
 	// Options required for backups & restores to be enabled for this solrCloud.
 	// +optional
 	BackupRestoreOptions *SolrBackupRestoreOptions `json:"backupRestoreOptions,omitempty"`
+
+	// BackupRepositories are Solr BackupRepositories that will be configured in the solr.xml of this solrCloud.
+	// SolrBackups can store their data in one of these repositories, instead of the backupRestoreOptions volume.
+	// +optional
+	BackupRepositories []SolrBackupRepository `json:"backupRepositories,omitempty"`
+}
+
+// BackupRepository returns the backup repository with the given name, or nil if none exists
+func (opts *SolrDataStorageOptions) BackupRepository(name string) *SolrBackupRepository {
+	for i, repo := range opts.BackupRepositories {
+		if repo.Name == name {
+			return &opts.BackupRepositories[i]
+		}
+	}
+	return nil
 }
 
 func (opts *SolrDataStorageOptions) withDefaults() (changed bool) {
@@ -235,6 +341,8 @@ type SolrPersistentDataStorageOptions struct {
 	// VolumeReclaimPolicy determines how the Solr Cloud's PVCs will be treated after the cloud is deleted.
 	//   - Retain: This is the default Kubernetes policy, where PVCs created for StatefulSets are not deleted when the StatefulSet is deleted.
 	//   - Delete: The PVCs will be deleted by the Solr Operator after the SolrCloud object is deleted.
+	//   - RetainOrphans: The PVCs are retained, but the Solr Operator labels PVCs that are no longer used by the SolrCloud,
+	//     either after a scale down or after the SolrCloud object is deleted, so that they can be reclaimed manually.
 	// The default value is Retain, so no data will be deleted unless explicitly configured.
 	// +optional
 	VolumeReclaimPolicy VolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
@@ -258,7 +366,7 @@ func (opts *SolrPersistentDataStorageOptions) withDefaults() (changed bool) {
 
 // VolumeReclaimPolicy is a string enumeration type that enumerates
 // all possible ways that a SolrCloud can treat it's PVCs after its death
-// +kubebuilder:validation:Enum=Retain;Delete
+// +kubebuilder:validation:Enum=Retain;Delete;RetainOrphans
 type VolumeReclaimPolicy string
 
 const (
@@ -267,6 +375,9 @@ const (
 
 	// All pod PVCs are deleted after the SolrCloud is deleted.
 	VolumeReclaimPolicyDelete VolumeReclaimPolicy = "Delete"
+
+	// All pod PVCs are retained, and labeled as orphaned once they are no longer used by the SolrCloud.
+	VolumeReclaimPolicyRetainOrphans VolumeReclaimPolicy = "RetainOrphans"
 )
 
 // PersistentVolumeClaimTemplate is used to produce
@@ -325,6 +436,23 @@ type SolrEphemeralDataStorageOptions struct {
 	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
 }
 
+// SolrLogStorageOptions defines a separate volume for the Solr logs, so that their growth can be bounded independently of the Solr data.
+type SolrLogStorageOptions struct {
+	// PersistentVolumeClaimTemplate is the PVC object for each solr node to store its logs.
+	// The default name of the PVC is "logs".
+	// Like the template of the data PVCs, this cannot be added or renamed once the SolrCloud has been created.
+	// If it is, the StatefulSet keeps its current pod template, and the StatefulSetRecreateRequired condition is set, until the StatefulSet is recreated.
+	//
+	// +optional
+	PersistentVolumeClaimTemplate *PersistentVolumeClaimTemplate `json:"pvcTemplate,omitempty"`
+
+	// EmptyDir is the emptyDir volume to store the Solr logs in, when no pvcTemplate is provided.
+	// Use the sizeLimit to bound the disk space that the logs can use.
+	//
+	// +optional
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+}
+
 type SolrBackupRestoreOptions struct {
 	// This is a volumeSource for a volume that will be mounted to all solrNodes to store backups and load restores.
 	// The data within the volume will be namespaces for this instance, so feel free to use the same volume for multiple clouds.
@@ -339,6 +467,160 @@ type SolrBackupRestoreOptions struct {
 	Directory string `json:"directory,omitempty"`
 }
 
+// SolrCloudRestoreOptions defines the backup that collections are restored from when a SolrCloud is brought up
+type SolrCloudRestoreOptions struct {
+	// The name of the backup to restore.
+	// For recurring SolrBackups, this is the name of a single backup that was taken, e.g. "nightly-20200810-201022".
+	BackupName string `json:"backupName"`
+
+	// The name of the backup repository, defined in dataStorage.backupRepositories, that contains the backup.
+	// If not provided, the backup is restored from the backupRestoreOptions volume, so the backupRestoreOptions.directory
+	// must be the same as the one of the SolrCloud that was backed up.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// The backed up collections to restore.
+	// +kubebuilder:validation:MinItems=1
+	Collections []RestoreCollection `json:"collections"`
+}
+
+// RestoreCollection maps a backed up collection to the collection that it is restored as
+type RestoreCollection struct {
+	// The name of the backed up collection
+	Collection string `json:"collection"`
+
+	// The name of the collection to restore the backup as.
+	// Defaults to the name of the backed up collection.
+	// +optional
+	RestoreAs string `json:"restoreAs,omitempty"`
+}
+
+// TargetCollection returns the name of the collection that the backed up collection is restored as
+func (rc *RestoreCollection) TargetCollection() string {
+	if rc.RestoreAs != "" {
+		return rc.RestoreAs
+	}
+	return rc.Collection
+}
+
+// SolrCloudDeletionOptions defines how the collections of a SolrCloud are drained when it is deleted
+type SolrCloudDeletionOptions struct {
+	// How to drain the collections before the SolrCloud is removed.
+	// Commit flushes all indexed documents to disk, which is useful when the PVCs of the SolrCloud are retained.
+	// Backup takes a backup of every collection in the given repository.
+	// +optional
+	Method SolrCloudDrainMethod `json:"method,omitempty"`
+
+	// The name of the backup repository, defined in dataStorage.backupRepositories, to back the collections up to.
+	// Required for the Backup method.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// How long to wait for the drain to finish, starting when the SolrCloud was deleted.
+	// Once the timeout has passed, the SolrCloud is removed whether or not the drain has finished.
+	// Defaults to 600 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// SolrCloudDrainMethod is a string enumeration type that enumerates the ways that the collections of a deleted SolrCloud can be drained.
+// +kubebuilder:validation:Enum=Commit;Backup
+type SolrCloudDrainMethod string
+
+const (
+	DrainMethodCommit SolrCloudDrainMethod = "Commit"
+	DrainMethodBackup SolrCloudDrainMethod = "Backup"
+
+	DefaultDrainMethod         = DrainMethodCommit
+	DefaultDrainTimeoutSeconds = 600
+)
+
+func (opts *SolrCloudDeletionOptions) withDefaults() (changed bool) {
+	if opts.Method == "" {
+		changed = true
+		opts.Method = DefaultDrainMethod
+	}
+
+	if opts.TimeoutSeconds == nil {
+		changed = true
+		t := int32(DefaultDrainTimeoutSeconds)
+		opts.TimeoutSeconds = &t
+	}
+
+	return changed
+}
+
+// SolrBackupRepository defines a Solr BackupRepository that is configured in the solr.xml of the SolrCloud.
+// Exactly one repository type must be specified.
+type SolrBackupRepository struct {
+	// The name of the repository, used to reference it from SolrBackups.
+	// +kubebuilder:validation:Pattern:=[a-zA-Z0-9]([-_a-zA-Z0-9]*[a-zA-Z0-9])?
+	// +kubebuilder:validation:MaxLength=100
+	Name string `json:"name"`
+
+	// A repository that stores backups in an S3 bucket.
+	// Requires Solr 8.10 or above.
+	// +optional
+	S3 *S3BackupRepository `json:"s3,omitempty"`
+
+	// A repository that stores backups in a Google Cloud Storage bucket.
+	// Requires Solr 8.9 or above.
+	// +optional
+	GCS *GCSBackupRepository `json:"gcs,omitempty"`
+}
+
+// S3BackupRepository defines the specs for Solr's "s3" BackupRepository
+type S3BackupRepository struct {
+	// The S3 region to store the backup data in
+	Region string `json:"region"`
+
+	// The S3 bucket to store the backup data in
+	Bucket string `json:"bucket"`
+
+	// The S3 compatible endpoint URL, if not using AWS S3
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// The secrets that contain the credentials for accessing S3.
+	// These are passed to the Solr pods through environment variables, or a mounted credentials file, and are never inlined.
+	// If none are provided, the default AWS credentials provider chain is used (e.g. instance or pod roles).
+	// +optional
+	Credentials *S3Credentials `json:"credentials,omitempty"`
+}
+
+// GCSBackupRepository defines the specs for Solr's "gcs" BackupRepository
+type GCSBackupRepository struct {
+	// The name of the GCS bucket to store the backup data in
+	// +kubebuilder:validation:Pattern:=^[a-z0-9][-_.a-z0-9]*[a-z0-9]$
+	// +kubebuilder:validation:MinLength=3
+	// +kubebuilder:validation:MaxLength=222
+	Bucket string `json:"bucket"`
+
+	// The secret key containing the JSON key of a Google Cloud service account that has access to the bucket.
+	// The key is mounted into the Solr pods and referenced through GOOGLE_APPLICATION_CREDENTIALS, it is never inlined.
+	CredentialsSecret corev1.SecretKeySelector `json:"credentialsSecret"`
+}
+
+// S3Credentials references the secrets that contain the credentials for accessing S3
+type S3Credentials struct {
+	// The secret key containing the Access Key ID
+	// +optional
+	AccessKeyIdSecret *corev1.SecretKeySelector `json:"accessKeyIdSecret,omitempty"`
+
+	// The secret key containing the Secret Access Key
+	// +optional
+	SecretAccessKeySecret *corev1.SecretKeySelector `json:"secretAccessKeySecret,omitempty"`
+
+	// The secret key containing the Session Token
+	// +optional
+	SessionTokenSecret *corev1.SecretKeySelector `json:"sessionTokenSecret,omitempty"`
+
+	// The secret key containing an AWS credentials file
+	// +optional
+	CredentialsFileSecret *corev1.SecretKeySelector `json:"credentialsFileSecret,omitempty"`
+}
+
 type SolrAddressabilityOptions struct {
 	// External defines the way in which this SolrCloud nodes should be made addressable externally, from outside the Kubernetes cluster.
 	// If none is provided, the Solr Cloud will not be made addressable externally.
@@ -347,11 +629,15 @@ type SolrAddressabilityOptions struct {
 
 	// PodPort defines the port to have the Solr Pod listen on.
 	// Defaults to 8983
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
 	PodPort int `json:"podPort,omitempty"`
 
 	// CommonServicePort defines the port to have the common Solr service listen on.
 	// Defaults to 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
 	CommonServicePort int `json:"commonServicePort,omitempty"`
 
@@ -359,6 +645,13 @@ type SolrAddressabilityOptions struct {
 	// Only use this option if the Kubernetes cluster has been setup with a custom domain.
 	// +optional
 	KubeDomain string `json:"kubeDomain,omitempty"`
+
+	// ContextPath defines the context path that Solr is served under, such as "/search".
+	// It is used for the probes of the Solr pods, the Ingress rules and all requests that the Solr Operator sends to Solr.
+	// Defaults to "/solr"
+	// +kubebuilder:validation:Pattern:=^/
+	// +optional
+	ContextPath string `json:"contextPath,omitempty"`
 }
 
 func (opts *SolrAddressabilityOptions) withDefaults() (changed bool) {
@@ -367,11 +660,11 @@ func (opts *SolrAddressabilityOptions) withDefaults() (changed bool) {
 	}
 	if opts.PodPort == 0 {
 		changed = true
-		opts.PodPort = 8983
+		opts.PodPort = DefaultSolrPodPort
 	}
 	if opts.CommonServicePort == 0 {
 		changed = true
-		opts.CommonServicePort = 80
+		opts.CommonServicePort = DefaultSolrCommonServicePort
 	}
 	return changed
 }
@@ -388,6 +681,7 @@ type ExternalAddressability struct {
 	// If true, Solr will startup with the hostname of the external address.
 	//
 	// NOTE: This option cannot be true when hideNodes is set to true. So it will be auto-set to false if that is the case.
+	// It is also unavailable with the NodePort method, since the external address of a Solr Node depends on the Kubernetes node that its pod is scheduled on.
 	//
 	// +optional
 	UseExternalAddress bool `json:"useExternalAddress"`
@@ -426,13 +720,119 @@ type ExternalAddressability struct {
 	// If your ingress controller is not listening on the podPort, then this option is required for solr to be addressable via an Ingress.
 	//
 	// Defaults to 80 if HideNodes=false and method=Ingress, otherwise this is optional.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
 	// +optional
 	NodePortOverride int `json:"nodePortOverride,omitempty"`
+
+	// The nodePort to use for the node service of the Solr pod with ordinal 0, when using the NodePort method.
+	// The node service of the Solr pod with ordinal N will use the nodePort baseNodePort+N.
+	// These ports must be within the nodePort range of the Kubernetes cluster.
+	//
+	// If not provided, Kubernetes will allocate a nodePort for each node service.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	BaseNodePort int `json:"baseNodePort,omitempty"`
+
+	// Add external-dns hostname annotations to the common and individual node services, using their external addresses.
+	// This lets an external-dns deployment create DNS records for the Solr services when using a method other than ExternalDNS.
+	// The ExternalDNS method always annotates the common and headless services, regardless of this option.
+	// This option is not available with the NodePort method.
+	//
+	// Defaults to false.
+	// +optional
+	UseExternalDNSAnnotations bool `json:"useExternalDnsAnnotations,omitempty"`
+
+	// The TTL, in seconds, that external-dns should use for the DNS records of the Solr services.
+	// This is only used when the services are given external-dns hostname annotations.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExternalDNSTTL *int32 `json:"externalDnsTTL,omitempty"`
+
+	// Terminate TLS at the Ingress for the external Solr addresses.
+	// This option is only used with the Ingress method.
+	//
+	// +optional
+	IngressTLSTermination *SolrIngressTLSTermination `json:"ingressTLSTermination,omitempty"`
+
+	// The number of seconds to wait for every individual node service to be assigned an IP address,
+	// when useExternalAddress=true and the node services are used in the hostAliases of the Solr pods.
+	// The StatefulSet will not be created or updated while waiting.
+	// Once the timeout has passed, the StatefulSet will be reconciled with the IP addresses that are available.
+	//
+	// If not provided, the operator will wait indefinitely.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NodeServiceIPTimeoutSeconds *int32 `json:"nodeServiceIPTimeoutSeconds,omitempty"`
+}
+
+// SolrIngressTLSTermination defines how the Ingress for a SolrCloud should terminate TLS.
+type SolrIngressTLSTermination struct {
+	// How the Ingress should connect to Solr after terminating TLS.
+	// This must agree with solrTLS, since Solr can only listen with one scheme.
+	Mode IngressTLSTermination `json:"mode"`
+
+	// The name of the kubernetes.io/tls Secret, in the same namespace, that holds the certificate used by the Ingress.
+	// The certificate should cover the hostnames of the common and individual node addresses exposed through the Ingress.
+	// If an issuerRef is provided, cert-manager will create and renew this secret.
+	TLSSecret string `json:"tlsSecret"`
+
+	// The cert-manager Issuer or ClusterIssuer that should issue the certificate for the Ingress.
+	// This adds the "cert-manager.io/issuer" or "cert-manager.io/cluster-issuer" annotation to the Ingress, depending on the kind.
+	//
+	// +optional
+	IssuerRef *CertManagerIssuerReference `json:"issuerRef,omitempty"`
+}
+
+// CertManagerIssuerReference references a cert-manager Issuer or ClusterIssuer.
+type CertManagerIssuerReference struct {
+	// The name of the Issuer or ClusterIssuer.
+	Name string `json:"name"`
+
+	// The kind of the issuer, either Issuer or ClusterIssuer.
+	Kind CertManagerIssuerKind `json:"kind"`
+
+	// The namespace of the Issuer. cert-manager only uses Issuers in the namespace of the Ingress,
+	// so this must be the namespace of the SolrCloud if it is provided.
+	// This cannot be provided for a ClusterIssuer, since they are not namespaced.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
+// CertManagerIssuerKind is a string enumeration type that enumerates
+// the kinds of cert-manager issuers that can issue certificates.
+// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+type CertManagerIssuerKind string
+
+const (
+	// A namespaced cert-manager Issuer, in the same namespace as the SolrCloud
+	CertManagerIssuerKindIssuer CertManagerIssuerKind = "Issuer"
+
+	// A cluster-wide cert-manager ClusterIssuer
+	CertManagerIssuerKindClusterIssuer CertManagerIssuerKind = "ClusterIssuer"
+)
+
+// IngressTLSTermination is a string enumeration type that enumerates
+// all possible ways that the Ingress for a SolrCloud can connect to Solr after terminating TLS.
+// +kubebuilder:validation:Enum=Edge;Reencrypt
+type IngressTLSTermination string
+
+const (
+	// Terminate TLS at the Ingress, and connect to Solr over HTTP. This cannot be used when solrTLS is configured.
+	IngressTLSTerminationEdge IngressTLSTermination = "Edge"
+
+	// Terminate TLS at the Ingress, and connect to Solr over HTTPS. This requires solrTLS to be configured.
+	IngressTLSTerminationReencrypt IngressTLSTermination = "Reencrypt"
+)
+
 // ExternalAddressability is a string enumeration type that enumerates
 // all possible ways that a SolrCloud can be made addressable external to the kubernetes cluster.
-// +kubebuilder:validation:Enum=Ingress;ExternalDNS
+// +kubebuilder:validation:Enum=Ingress;ExternalDNS;NodePort;LoadBalancer
 type ExternalAddressabilityMethod string
 
 const (
@@ -442,8 +842,10 @@ const (
 	// Use ExternalDNS to make the Solr service(s) externally addressable
 	ExternalDNS ExternalAddressabilityMethod = "ExternalDNS"
 
+	// Make Solr service(s) type:NodePort to make them externally addressable through the IP addresses of the Kubernetes nodes
+	NodePort ExternalAddressabilityMethod = "NodePort"
+
 	// Make Solr service(s) type:LoadBalancer to make them externally addressable
-	// NOTE: This option is not currently supported.
 	LoadBalancer ExternalAddressabilityMethod = "LoadBalancer"
 )
 
@@ -453,6 +855,16 @@ func (opts *ExternalAddressability) withDefaults() (changed bool) {
 		changed = true
 		opts.UseExternalAddress = false
 	}
+	// The external address of a Solr Node is not known before its pod is scheduled when using the NodePort method, so it cannot be advertised
+	if opts.UseExternalAddress && opts.Method == NodePort {
+		changed = true
+		opts.UseExternalAddress = false
+	}
+	// The baseNodePort is only used by the node services of the NodePort method
+	if opts.BaseNodePort > 0 && (opts.Method != NodePort || opts.HideNodes) {
+		changed = true
+		opts.BaseNodePort = 0
+	}
 	// If the Ingress method is used, default the nodePortOverride to 80, since that is the port that most ingress controllers listen on.
 	if !opts.HideNodes && opts.Method == Ingress && opts.NodePortOverride == 0 {
 		changed = true
@@ -467,6 +879,52 @@ func (opts *ExternalAddressability) withDefaults() (changed bool) {
 	return changed
 }
 
+type SolrScalingOptions struct {
+	// Move all Solr replicas off of the pods that will be removed, before scaling down the StatefulSet.
+	// The StatefulSet will keep its current size until the replicas have been moved to the remaining pods.
+	// The StatefulSet of a removed node pool is likewise only deleted once its replicas have been moved to the remaining StatefulSets.
+	// Defaults to false.
+	//
+	// +optional
+	VacatePodsOnScaleDown bool `json:"vacatePodsOnScaleDown,omitempty"`
+}
+
+// SolrNodePool defines a group of Solr nodes in the SolrCloud that run with their own resources.
+// All other options of the Solr nodes in the pool are the same as the options of the SolrCloud.
+type SolrNodePool struct {
+	// The name of the node pool, which is added to the names of the StatefulSet and pods of the pool.
+	// +kubebuilder:validation:Pattern:=^[a-z]([-a-z0-9]*[a-z0-9])?$
+	// +kubebuilder:validation:MaxLength:=20
+	Name string `json:"name"`
+
+	// The number of Solr nodes to run in the pool.
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// Set the heap settings of the Solr JVMs in the pool, overriding solrJavaMem.
+	// +kubebuilder:validation:Pattern=`\S`
+	// +optional
+	SolrJavaMem string `json:"solrJavaMem,omitempty"`
+
+	// Resources for the Solr container of the pods in the pool, overriding customSolrKubeOptions.podOptions.resources.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Node Selector to be added for the pods in the pool, overriding customSolrKubeOptions.podOptions.nodeSelector.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations to be added for the pods in the pool, overriding customSolrKubeOptions.podOptions.tolerations.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// The storage size of the persistent data volumes of the Solr nodes in the pool,
+	// overriding the size given in dataStorage.persistent.pvcTemplate.
+	// This option is ignored if the SolrCloud does not use persistent storage.
+	// +optional
+	StorageCapacity *resource.Quantity `json:"storageCapacity,omitempty"`
+}
+
 type SolrUpdateStrategy struct {
 	// Method defines the way in which SolrClouds should be updated when the podSpec changes.
 	// +optional
@@ -476,6 +934,10 @@ type SolrUpdateStrategy struct {
 	// +optional
 	ManagedUpdateOptions ManagedUpdateOptions `json:"managed,omitempty"`
 
+	// Options for the StatefulSet's own update strategy, only used by the StatefulSet update method.
+	// +optional
+	StatefulSetUpdateOptions *StatefulSetUpdateOptions `json:"statefulSet,omitempty"`
+
 	// Perform a scheduled restart on the given schedule, in CRON format.
 	//
 	// Multiple CRON syntaxes are supported
@@ -488,6 +950,15 @@ type SolrUpdateStrategy struct {
 	//
 	// +optional
 	RestartSchedule string `json:"restartSchedule,omitempty"`
+
+	// The number of seconds that Solr pods may run a version of Solr other than the one requested,
+	// before a warning event is emitted because the upgrade seems to be stuck.
+	//
+	// Defaults to 3600. A value of 0 disables the warning.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	VersionSkewWarningSeconds *int32 `json:"versionSkewWarningSeconds,omitempty"`
 }
 
 // SolrUpdateMethod is a string enumeration type that enumerates
@@ -522,7 +993,8 @@ type ManagedUpdateOptions struct {
 
 	// The maximum number of pods that can be unavailable during the update.
 	// Value can be an absolute number (ex: 5) or a percentage of the desired number of pods (ex: 10%).
-	// Absolute number is calculated from percentage by rounding down.
+	// Absolute number is calculated from percentage by rounding up, and at least 1 pod is always allowed to be unavailable.
+	// Values larger than the desired number of pods are treated as the desired number of pods.
 	// If the provided number is 0 or negative, then all pods will be allowed to be updated in unison.
 	//
 	// Defaults to 25%.
@@ -539,6 +1011,48 @@ type ManagedUpdateOptions struct {
 	//
 	// +optional
 	MaxShardReplicasUnavailable *intstr.IntOrString `json:"maxShardReplicasUnavailable,omitempty"`
+
+	// Do not take down a pod if doing so would leave any shard without an active replica, based on the Solr cluster state.
+	// This is checked in addition to maxShardReplicasUnavailable, and protects shards whose replicas all live on the pods being updated.
+	// Pods hosting the only active replica of a shard, such as of single-replica collections, are updated one at a time
+	// once no other pods can be updated and every replica is active.
+	// Pods hosting active shard leaders are also updated after the pods hosting only follower replicas, where possible.
+	//
+	// Defaults to false.
+	//
+	// +optional
+	RespectShardPlacement bool `json:"respectShardPlacement,omitempty"`
+
+	// Only consider an updated pod available once all of the Solr replicas hosted on it are "active", based on the Solr cluster state.
+	// Without this option, a pod is considered available as soon as Kubernetes marks it as ready, even if its replicas are still recovering.
+	// If the cluster state cannot be fetched, the Kubernetes readiness of the pods is used instead.
+	//
+	// Defaults to false.
+	//
+	// +optional
+	RequireActiveReplicas bool `json:"requireActiveReplicas,omitempty"`
+}
+
+// StatefulSetUpdateOptions defines the update strategy of the StatefulSet, when the StatefulSet update method is used.
+type StatefulSetUpdateOptions struct {
+	// The type of update strategy for the StatefulSet.
+	// RollingUpdate replaces one pod at a time, starting with the highest ordinal.
+	// OnDelete only updates pods once they have been deleted by the user.
+	//
+	// Defaults to RollingUpdate.
+	//
+	// +kubebuilder:validation:Enum=RollingUpdate;OnDelete
+	// +optional
+	Type appsv1.StatefulSetUpdateStrategyType `json:"type,omitempty"`
+
+	// Only pods with an ordinal greater than or equal to the partition are updated by a RollingUpdate.
+	// This can be used to do canary updates, by lowering the partition once the updated pods are healthy.
+	//
+	// Defaults to 0.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
 }
 
 // ZookeeperRef defines the zookeeper ensemble for solr to connect to
@@ -556,6 +1070,17 @@ type ZookeeperRef struct {
 	//   - A zookeeper operator to be running
 	// +optional
 	ProvidedZookeeper *ZookeeperSpec `json:"provided,omitempty"`
+
+	// Add an initContainer to Solr pods that blocks until the Zookeeper ensemble is serving requests.
+	// The initContainer also creates the chroot, if one is used, before Solr starts.
+	// +optional
+	WaitForZookeeper bool `json:"waitForZookeeper,omitempty"`
+
+	// Remove the chroot of the SolrCloud, and all data within it, from Zookeeper when the SolrCloud is deleted.
+	// A chroot other than "/" is required, since the root of the Zookeeper ensemble is never removed.
+	// The chroot is left in place if another SolrCloud uses the same chroot, or a chroot within it.
+	// +optional
+	CleanupZookeeperData bool `json:"cleanupZookeeperData,omitempty"`
 }
 
 func (ref *ZookeeperRef) withDefaults() (changed bool) {
@@ -586,6 +1111,14 @@ func (ref *ZookeeperRef) GetACLs() (allACL *ZookeeperACL, readOnlyACL *Zookeeper
 	return
 }
 
+// GetTLS returns the options to connect to ZK over TLS, which are only available for external ZK ensembles
+func (ref *ZookeeperRef) GetTLS() *ZookeeperTLSOptions {
+	if ref.ConnectionInfo != nil {
+		return ref.ConnectionInfo.TLS
+	}
+	return nil
+}
+
 // ZookeeperSpec defines the internal zookeeper ensemble to run with the given spec
 type ZookeeperSpec struct {
 
@@ -725,6 +1258,10 @@ type SolrCloudStatus struct {
 	// +optional
 	TargetVersion string `json:"targetVersion,omitempty"`
 
+	// The number of Solr pods running each version of solr, sorted by version
+	// +optional
+	Versions []SolrVersionCount `json:"versions,omitempty"`
+
 	// InternalCommonAddress is the internal common http address for all solr nodes
 	InternalCommonAddress string `json:"internalCommonAddress"`
 
@@ -739,6 +1276,223 @@ type SolrCloudStatus struct {
 	// BackupRestoreReady announces whether the solrCloud has the backupRestorePVC mounted to all pods
 	// and therefore is ready for backups and restores.
 	BackupRestoreReady bool `json:"backupRestoreReady"`
+
+	// ScaleDown describes the progress of moving replicas off of the pods that will be removed by a scale down.
+	// This is only populated while a scale down is waiting for replicas to be moved.
+	// +optional
+	ScaleDown *SolrScaleDownStatus `json:"scaleDown,omitempty"`
+
+	// VolumeExpansion describes the progress of expanding the Solr data PVCs, after the requested storage size has been increased.
+	// This is only populated while there are PVCs smaller than the requested size.
+	// +optional
+	VolumeExpansion *SolrVolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+
+	// Restore describes the progress of restoring the collections given in spec.restore.
+	// +optional
+	Restore *SolrCloudRestoreStatus `json:"restore,omitempty"`
+
+	// Drain describes the progress of draining the collections of the SolrCloud, while it is being deleted.
+	// +optional
+	Drain *SolrCloudDrainStatus `json:"drain,omitempty"`
+
+	// ReadOnlyCollections are the collections that have been made read-only by the Solr Operator, because readOnly is enabled.
+	// These are made writable again once readOnly is disabled.
+	// +optional
+	ReadOnlyCollections []string `json:"readOnlyCollections,omitempty"`
+
+	// Conditions describe the current state of the SolrCloud, such as whether it is Ready or being Upgraded.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// The types of conditions that can be found in the SolrCloudStatus
+const (
+	// SolrCloudReady is True when all Solr pods of the SolrCloud are ready
+	SolrCloudReady = "Ready"
+
+	// SolrCloudUpgrading is True when there are Solr pods that are not running the latest pod spec
+	SolrCloudUpgrading = "Upgrading"
+
+	// SolrCloudZookeeperConnected is True when the connection information for the Zookeeper cluster is available
+	SolrCloudZookeeperConnected = "ZookeeperConnected"
+
+	// SolrCloudTLSReady is True when the TLS secrets configured for the SolrCloud are ready to be used.
+	// This condition is only present when TLS is enabled.
+	SolrCloudTLSReady = "TLSReady"
+
+	// SolrCloudTLSHostnamesCovered is True when the TLS certificate of the SolrCloud is valid for all of its internal and external hostnames.
+	// This condition is only present when TLS is enabled, and the TLS secret contains a "tls.crt" that can be checked.
+	SolrCloudTLSHostnamesCovered = "TLSHostnamesCovered"
+
+	// SolrCloudNodeServiceIPsAssigned is True when every individual node service has been assigned an IP address,
+	// and a load balancer address when using the LoadBalancer method.
+	// This condition is only present when the external addresses of the Solr nodes are advertised, and individual node services are used.
+	SolrCloudNodeServiceIPsAssigned = "NodeServiceIPsAssigned"
+
+	// SolrCloudPaused is True when the reconciliation of the SolrCloud has been paused.
+	// This condition is only present while the SolrCloud is paused.
+	SolrCloudPaused = "Paused"
+
+	// SolrCloudVersionSkew is True when there are Solr pods that are not running the requested version of solr
+	SolrCloudVersionSkew = "VersionSkew"
+
+	// SolrCloudProvidedConfigMapFound is True when the providedConfigMap of the SolrCloud exists.
+	// This condition is only present when a providedConfigMap is configured.
+	SolrCloudProvidedConfigMapFound = "ProvidedConfigMapFound"
+
+	// SolrCloudStorageClassesFound is True when the StorageClasses requested by the PVC templates of the SolrCloud exist.
+	// This condition is only present when a PVC template requests a storageClassName.
+	SolrCloudStorageClassesFound = "StorageClassesFound"
+
+	// SolrCloudDryRun is True when the SolrCloud is reconciled as a dry run, and lists the changes that would have been made.
+	// This condition is only present while the dry run annotation is set on the SolrCloud.
+	SolrCloudDryRun = "DryRun"
+
+	// SolrCloudPodsFailing is True when there are Solr pods with containers that are failing to start, such as because their image cannot be pulled.
+	// The message lists the failing pods, with the reason and image of the failing container.
+	SolrCloudPodsFailing = "PodsFailing"
+
+	// SolrCloudReadOnly is True when all collections of the SolrCloud have been made read-only.
+	// This condition is only present while readOnly is enabled, or while collections are being made writable again.
+	SolrCloudReadOnly = "ReadOnly"
+
+	// SolrCloudStatefulSetRecreateRequired is True when a StatefulSet of the SolrCloud must be deleted and recreated by the user for a change to take effect.
+	// This condition is only present while such a change is pending.
+	SolrCloudStatefulSetRecreateRequired = "StatefulSetRecreateRequired"
+
+	// SolrCloudSolrPortAvailable is True when Solr can listen on its podPort without colliding with the ports of the sidecar containers.
+	// This condition is only present when sidecar containers are configured.
+	SolrCloudSolrPortAvailable = "SolrPortAvailable"
+
+	// SolrCloudEnvFromSourcesFound is True when the ConfigMaps and Secrets that the env vars of the Solr container are loaded from exist.
+	// This condition is only present when envFrom sources that are not optional are configured.
+	SolrCloudEnvFromSourcesFound = "EnvFromSourcesFound"
+
+	// SolrCloudZookeeperCleanupPending is True while a deleted SolrCloud is kept until its chroot has been removed from Zookeeper.
+	// This condition is only present for deleted SolrClouds with cleanupZookeeperData enabled.
+	SolrCloudZookeeperCleanupPending = "ZookeeperCleanupPending"
+)
+
+// SolrVersionCount is the number of Solr pods running a version of solr
+type SolrVersionCount struct {
+	// The version of solr
+	Version string `json:"version"`
+
+	// The number of Solr pods running this version
+	Nodes int32 `json:"nodes"`
+}
+
+// SolrScaleDownStatus describes the progress of vacating the pods that will be removed by a scale down
+type SolrScaleDownStatus struct {
+	// The number of pods that the StatefulSet will be scaled down to, once all replicas have been moved off of the removed pods.
+	TargetReplicas int32 `json:"targetReplicas"`
+
+	// The number of Solr replicas that still live on the pods that will be removed.
+	RemainingReplicas int32 `json:"remainingReplicas"`
+
+	// The Solr nodes that will be removed and still host replicas.
+	// +optional
+	VacatingNodes []string `json:"vacatingNodes,omitempty"`
+}
+
+// SolrVolumeExpansionStatus describes the progress of expanding the Solr data PVCs
+type SolrVolumeExpansionStatus struct {
+	// The storage size that the Solr data PVCs are being expanded to.
+	RequestedSize string `json:"requestedSize"`
+
+	// The PVCs that have not yet been expanded to the requested size.
+	// +optional
+	PendingPVCs []string `json:"pendingPVCs,omitempty"`
+
+	// An error that is preventing the PVCs from being expanded, such as a StorageClass that does not allow volume expansion.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// SolrCloudRestoreStatus describes the progress of restoring collections from a backup
+type SolrCloudRestoreStatus struct {
+	// The name of the backup that the collections are restored from
+	BackupName string `json:"backupName"`
+
+	// Whether all collections have finished restoring, successfully or not
+	// +optional
+	Finished bool `json:"finished,omitempty"`
+
+	// The statuses of the restores of each collection
+	// +optional
+	Collections []CollectionRestoreStatus `json:"collections,omitempty"`
+}
+
+// CollectionRestoreStatus describes the progress of restoring a single collection
+type CollectionRestoreStatus struct {
+	// The name of the collection that the backup is restored as
+	Collection string `json:"collection"`
+
+	// The status of the asynchronous restore call to Solr
+	// +optional
+	AsyncRestoreStatus string `json:"asyncRestoreStatus,omitempty"`
+
+	// Whether the restore has finished
+	// +optional
+	Finished bool `json:"finished,omitempty"`
+
+	// The number of times that Solr refused to start the restore.
+	// The restore is given up on once this reaches the maximum number of attempts.
+	// +optional
+	FailedAttempts int32 `json:"failedAttempts,omitempty"`
+
+	// Whether the restore was successful.
+	// A collection that already existed before its restore was started is not overwritten, and is counted as unsuccessful.
+	// +optional
+	Successful *bool `json:"successful,omitempty"`
+
+	// Why the restore was unsuccessful
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SolrCloudDrainStatus describes the progress of draining the collections of a deleted SolrCloud
+type SolrCloudDrainStatus struct {
+	// How the collections are drained
+	Method SolrCloudDrainMethod `json:"method"`
+
+	// The name of the backup that the collections are backed up to, when using the Backup method
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// Whether all collections have been drained, successfully or not
+	// +optional
+	Finished bool `json:"finished,omitempty"`
+
+	// The statuses of the drain of each collection
+	// +optional
+	Collections []CollectionDrainStatus `json:"collections,omitempty"`
+}
+
+// CollectionDrainStatus describes the progress of draining a single collection
+type CollectionDrainStatus struct {
+	// The name of the collection
+	Collection string `json:"collection"`
+
+	// The status of the asynchronous backup call to Solr, when using the Backup method
+	// +optional
+	AsyncBackupStatus string `json:"asyncBackupStatus,omitempty"`
+
+	// Whether the drain has finished
+	// +optional
+	Finished bool `json:"finished,omitempty"`
+
+	// Whether the drain was successful
+	// +optional
+	Successful *bool `json:"successful,omitempty"`
+
+	// Why the drain was unsuccessful
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // SolrNodeStatus is the status of a solrNode in the cloud, with readiness status
@@ -766,6 +1520,10 @@ type SolrNodeStatus struct {
 
 	// This Solr Node pod is using the latest version of solrcloud pod spec.
 	SpecUpToDate bool `json:"specUpToDate"`
+
+	// The node pool that the Solr Node belongs to, if it is not one of the replicas of the SolrCloud.
+	// +optional
+	NodePool string `json:"nodePool,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -797,6 +1555,7 @@ func (sc *SolrCloud) WithDefaults() bool {
 	return sc.Spec.withDefaults()
 }
 
+// GetAllSolrNodeNames returns the names of the pods of all Solr nodes in the cloud, including the nodes of its node pools
 func (sc *SolrCloud) GetAllSolrNodeNames() []string {
 	replicas := 1
 	if sc.Spec.Replicas != nil {
@@ -807,9 +1566,41 @@ func (sc *SolrCloud) GetAllSolrNodeNames() []string {
 	for i := range nodeNames {
 		nodeNames[i] = fmt.Sprintf("%s-%d", statefulSetName, i)
 	}
+	for _, pool := range sc.Spec.NodePools {
+		poolStatefulSetName := sc.NodePoolStatefulSetName(pool.Name)
+		for i := 0; i < int(pool.Replicas); i++ {
+			nodeNames = append(nodeNames, fmt.Sprintf("%s-%d", poolStatefulSetName, i))
+		}
+	}
 	return nodeNames
 }
 
+// TotalReplicas returns the number of Solr nodes in the cloud, including the nodes of its node pools
+func (sc *SolrCloud) TotalReplicas() int32 {
+	replicas := int32(0)
+	if sc.Spec.Replicas != nil {
+		replicas = *sc.Spec.Replicas
+	}
+	for _, pool := range sc.Spec.NodePools {
+		replicas += pool.Replicas
+	}
+	return replicas
+}
+
+// NodePoolOfNode returns the name of the node pool that the given Solr node belongs to, or an empty string if it is not part of a node pool
+func (sc *SolrCloud) NodePoolOfNode(nodeName string) string {
+	statefulSetName := nodeName
+	if index := strings.LastIndex(nodeName, "-"); index > 0 {
+		statefulSetName = nodeName[:index]
+	}
+	for _, pool := range sc.Spec.NodePools {
+		if statefulSetName == sc.NodePoolStatefulSetName(pool.Name) {
+			return pool.Name
+		}
+	}
+	return ""
+}
+
 func (sc *SolrCloud) BasicAuthSecretName() string {
 	if sc.Spec.SolrSecurity != nil && sc.Spec.SolrSecurity.BasicAuthSecret != "" {
 		return sc.Spec.SolrSecurity.BasicAuthSecret
@@ -822,16 +1613,62 @@ func (sc *SolrCloud) SecurityBootstrapSecretName() string {
 	return fmt.Sprintf("%s-solrcloud-security-bootstrap", sc.Name)
 }
 
+// SecurityJsonSecretName returns the name of the secret holding the merged security.json that the security.json Job writes to Zookeeper
+func (sc *SolrCloud) SecurityJsonSecretName() string {
+	return fmt.Sprintf("%s-solrcloud-security-json", sc.Name)
+}
+
+// SecurityJsonJobName returns the name of the Job that writes the merged security.json of the cloud to Zookeeper
+func (sc *SolrCloud) SecurityJsonJobName() string {
+	return fmt.Sprintf("%s-solrcloud-security-json", sc.GetName())
+}
+
 // ConfigMapName returns the name of the cloud config-map
 func (sc *SolrCloud) ConfigMapName() string {
 	return fmt.Sprintf("%s-solrcloud-configmap", sc.GetName())
 }
 
+// ProvidedConfigMapNames returns the names of all user provided ConfigMaps that the cloud uses
+func (sc *SolrCloud) ProvidedConfigMapNames() (names []string) {
+	configMapOptions := sc.Spec.CustomSolrKubeOptions.ConfigMapOptions
+	if configMapOptions == nil {
+		return nil
+	}
+	found := map[string]bool{"": true}
+	addName := func(name string) {
+		if !found[name] {
+			found[name] = true
+			names = append(names, name)
+		}
+	}
+	addName(configMapOptions.ProvidedConfigMap)
+	addName(configMapOptions.ProvidedLogXmlConfigMap)
+	for _, additionalConfigMap := range configMapOptions.AdditionalConfigMaps {
+		addName(additionalConfigMap.Name)
+	}
+	return names
+}
+
 // StatefulSetName returns the name of the statefulset for the cloud
 func (sc *SolrCloud) StatefulSetName() string {
 	return fmt.Sprintf("%s-solrcloud", sc.GetName())
 }
 
+// ZookeeperCleanupJobName returns the name of the Job that removes the chroot of the deleted SolrCloud from Zookeeper
+func (sc *SolrCloud) ZookeeperCleanupJobName() string {
+	return fmt.Sprintf("%s-solrcloud-zk-cleanup", sc.GetName())
+}
+
+// NodePoolStatefulSetName returns the name of the statefulset for the given node pool of the cloud
+func (sc *SolrCloud) NodePoolStatefulSetName(poolName string) string {
+	return fmt.Sprintf("%s-%s", sc.StatefulSetName(), poolName)
+}
+
+// PodDisruptionBudgetName returns the name of the PodDisruptionBudget for the cloud
+func (sc *SolrCloud) PodDisruptionBudgetName() string {
+	return sc.StatefulSetName()
+}
+
 // CommonServiceName returns the name of the common service for the cloud
 func (sc *SolrCloud) CommonServiceName() string {
 	return fmt.Sprintf("%s-solrcloud-common", sc.GetName())
@@ -895,8 +1732,8 @@ func (sc *SolrCloud) UsesIndividualNodeServices() bool {
 }
 
 func (extOpts *ExternalAddressability) UsesIndividualNodeServices() bool {
-	// LoadBalancer and Ingress will not work with headless services if each pod needs to be exposed externally.
-	return extOpts != nil && !extOpts.HideNodes && (extOpts.Method == Ingress || extOpts.Method == LoadBalancer)
+	// LoadBalancer, NodePort and Ingress will not work with headless services if each pod needs to be exposed externally.
+	return extOpts != nil && !extOpts.HideNodes && (extOpts.Method == Ingress || extOpts.Method == LoadBalancer || extOpts.Method == NodePort)
 }
 
 func (sc *SolrCloud) CommonExternalPrefix() string {
@@ -990,12 +1827,12 @@ func (sc *SolrCloud) InternalCommonUrl(withPort bool) (url string) {
 }
 
 func (sc *SolrCloud) ExternalNodeUrl(nodeName string, domainName string, withPort bool) (url string) {
-	if sc.Spec.SolrAddressability.External.Method == Ingress {
+	// The LoadBalancer method requires DNS routing to the load balancers through the same url template as the Ingress method
+	if sc.Spec.SolrAddressability.External.Method == Ingress || sc.Spec.SolrAddressability.External.Method == LoadBalancer {
 		url = fmt.Sprintf("%s.%s", sc.NodeIngressPrefix(nodeName), domainName)
 	} else if sc.Spec.SolrAddressability.External.Method == ExternalDNS {
 		url = fmt.Sprintf("%s.%s", nodeName, sc.ExternalDnsDomain(domainName))
 	}
-	// TODO: Add LoadBalancer stuff here
 	if withPort {
 		url += sc.NodePortSuffix()
 	}
@@ -1003,7 +1840,7 @@ func (sc *SolrCloud) ExternalNodeUrl(nodeName string, domainName string, withPor
 }
 
 func (sc *SolrCloud) ExternalCommonUrl(domainName string, withPort bool) (url string) {
-	if sc.Spec.SolrAddressability.External.Method == Ingress {
+	if sc.Spec.SolrAddressability.External.Method == Ingress || sc.Spec.SolrAddressability.External.Method == LoadBalancer {
 		url = fmt.Sprintf("%s.%s", sc.CommonExternalPrefix(), domainName)
 	} else if sc.Spec.SolrAddressability.External.Method == ExternalDNS {
 		url = fmt.Sprintf("%s.%s", sc.CommonServiceName(), sc.ExternalDnsDomain(domainName))
@@ -1014,6 +1851,14 @@ func (sc *SolrCloud) ExternalCommonUrl(domainName string, withPort bool) (url st
 	return url
 }
 
+// SolrContextPath returns the context path that Solr is served under, "/solr" unless a custom contextPath is given
+func (sc *SolrCloud) SolrContextPath() string {
+	if sc.Spec.SolrAddressability.ContextPath != "" {
+		return sc.Spec.SolrAddressability.ContextPath
+	}
+	return DefaultSolrContextPath
+}
+
 func (sc *SolrCloud) UrlScheme() string {
 	urlScheme := "http"
 	if sc.Spec.SolrTLS != nil {
@@ -1031,6 +1876,55 @@ func (sc *SolrCloud) AdvertisedNodeHost(nodeName string) string {
 	}
 }
 
+// TLSDNSNames returns the DNS names that the TLS certificate of the SolrCloud must be valid for.
+// This includes the internal hostnames of the common service and of every Solr node,
+// the external hostnames of the common endpoint and the nodes for every external domain, and any additionalDNSNames.
+func (sc *SolrCloud) TLSDNSNames() (dnsNames []string) {
+	addDNSName := func(dnsName string) {
+		for _, existing := range dnsNames {
+			if existing == dnsName {
+				return
+			}
+		}
+		if dnsName != "" {
+			dnsNames = append(dnsNames, dnsName)
+		}
+	}
+
+	addDNSName(sc.InternalCommonUrl(false))
+	if external := sc.Spec.SolrAddressability.External; external != nil && !external.HideCommon {
+		for _, domainName := range append([]string{external.DomainName}, external.AdditionalDomainNames...) {
+			addDNSName(sc.ExternalCommonUrl(domainName, false))
+		}
+	}
+	for _, nodeName := range sc.GetAllSolrNodeNames() {
+		for _, dnsName := range sc.NodeTLSDNSNames(nodeName) {
+			addDNSName(dnsName)
+		}
+	}
+	if sc.Spec.SolrTLS != nil {
+		for _, dnsName := range sc.Spec.SolrTLS.AdditionalDNSNames {
+			addDNSName(dnsName)
+		}
+	}
+	return dnsNames
+}
+
+// NodeTLSDNSNames returns the internal and external hostnames of the given Solr node, that the TLS certificate of the SolrCloud must be valid for
+func (sc *SolrCloud) NodeTLSDNSNames(nodeName string) (dnsNames []string) {
+	if internal := sc.InternalNodeUrl(nodeName, false); internal != "" {
+		dnsNames = append(dnsNames, internal)
+	}
+	if external := sc.Spec.SolrAddressability.External; external != nil && !external.HideNodes {
+		for _, domainName := range append([]string{external.DomainName}, external.AdditionalDomainNames...) {
+			if externalNodeUrl := sc.ExternalNodeUrl(nodeName, domainName, false); externalNodeUrl != "" {
+				dnsNames = append(dnsNames, externalNodeUrl)
+			}
+		}
+	}
+	return dnsNames
+}
+
 func (sc *SolrCloud) UsesPersistentStorage() bool {
 	return sc.Spec.StorageOptions.PersistentStorage != nil
 }
@@ -1078,7 +1972,9 @@ type SolrTLSOptions struct {
 	// TLS Secret containing a pkcs12 keystore
 	PKCS12Secret *corev1.SecretKeySelector `json:"pkcs12Secret"`
 
-	// Secret containing the key store password; this field is required as most JVMs do not support pkcs12 keystores without a password
+	// Secret containing the key store password; this field is required as most JVMs do not support pkcs12 keystores without a password.
+	// The Solr Operator never generates this password, so it can be managed outside of Kubernetes and synced into an existing secret.
+	// The PKCS12 keystore generated by the initContainer, when the TLS secret has none, is protected by this password as well.
 	KeyStorePasswordSecret *corev1.SecretKeySelector `json:"keyStorePasswordSecret"`
 
 	// TLS Secret containing a pkcs12 truststore; if not provided, then the keystore and password are used for the truststore
@@ -1095,6 +1991,13 @@ type SolrTLSOptions struct {
 	// +kubebuilder:default=None
 	ClientAuth ClientAuthType `json:"clientAuth,omitempty"`
 
+	// Name of a kubernetes.io/tls Secret containing the client certificate (tls.crt) and key (tls.key) that the operator
+	// presents when calling the Solr API, required if clientAuth is Need, unless the operator has its own client certificate from -tls-client-cert-path.
+	// If the secret contains a ca.crt, it is used to verify the Solr server certificate, otherwise the server is verified
+	// according to the operator's -tls-skip-verify-server and -tls-ca-cert-path options. If not provided, the operator calls Solr with its own TLS settings.
+	// +optional
+	ClientCertSecret string `json:"clientCertSecret,omitempty"`
+
 	// Verify client's hostname during SSL handshake
 	// +optional
 	VerifyClientHostname bool `json:"verifyClientHostname,omitempty"`
@@ -1106,8 +2009,74 @@ type SolrTLSOptions struct {
 	// Opt-in flag to restart Solr pods after TLS secret updates, such as if the cert is renewed; default is false.
 	// +optional
 	RestartOnTLSSecretUpdate bool `json:"restartOnTLSSecretUpdate,omitempty"`
+
+	// Whether the operator sets the "urlScheme" cluster property in ZooKeeper to "https"; default is true.
+	// Set this to false if the cluster properties are managed outside of the operator, the keystore and TLS settings
+	// of the Solr pods are still configured. Solr will not use https addresses unless "urlScheme" is set some other way.
+	// +kubebuilder:default=true
+	// +optional
+	ManageUrlScheme *bool `json:"manageUrlScheme,omitempty"`
+
+	// Additional DNS names that the TLS certificate must be valid for, such as the hostname of a proxy in front of the SolrCloud.
+	// These are checked along with the internal and external hostnames of the SolrCloud, when the TLS secret contains a PEM encoded certificate.
+	// +optional
+	AdditionalDNSNames []string `json:"additionalDNSNames,omitempty"`
+
+	// The keys of the PEM encoded files in the TLS secret, if they differ from the kubernetes.io/tls secret layout.
+	// These are used to generate the PKCS12 keystore when the TLS secret does not contain one.
+	// +optional
+	PEMKeys *SolrTLSPEMKeys `json:"pemKeys,omitempty"`
+}
+
+// SolrTLSPEMKeys defines the keys of the PEM encoded certificate, private key and CA bundle in a TLS secret.
+type SolrTLSPEMKeys struct {
+	// The key of the PEM encoded certificate; defaults to "tls.crt".
+	// +optional
+	CertKey string `json:"certKey,omitempty"`
+
+	// The key of the PEM encoded private key; defaults to "tls.key".
+	// +optional
+	KeyKey string `json:"keyKey,omitempty"`
+
+	// The key of the PEM encoded CA bundle; defaults to "ca.crt".
+	// The CA bundle is optional, it is only added to the generated keystore and truststore if the TLS secret contains it.
+	// +optional
+	CAKey string `json:"caKey,omitempty"`
+}
+
+// ManagesUrlScheme returns whether the operator sets the "urlScheme" cluster property, which it does unless disabled.
+func (opts *SolrTLSOptions) ManagesUrlScheme() bool {
+	return opts.ManageUrlScheme == nil || *opts.ManageUrlScheme
+}
+
+// PEMCertKey returns the key of the PEM encoded certificate in the TLS secret
+func (opts *SolrTLSOptions) PEMCertKey() string {
+	if opts.PEMKeys != nil && opts.PEMKeys.CertKey != "" {
+		return opts.PEMKeys.CertKey
+	}
+	return corev1.TLSCertKey
+}
+
+// PEMPrivateKeyKey returns the key of the PEM encoded private key in the TLS secret
+func (opts *SolrTLSOptions) PEMPrivateKeyKey() string {
+	if opts.PEMKeys != nil && opts.PEMKeys.KeyKey != "" {
+		return opts.PEMKeys.KeyKey
+	}
+	return corev1.TLSPrivateKeyKey
+}
+
+// PEMCAKey returns the key of the PEM encoded CA bundle in the TLS secret
+func (opts *SolrTLSOptions) PEMCAKey() string {
+	if opts.PEMKeys != nil && opts.PEMKeys.CAKey != "" {
+		return opts.PEMKeys.CAKey
+	}
+	return "ca.crt"
 }
 
+// JavaOpt is a single JVM option, such as a system property "-Dname=value"
+// +kubebuilder:validation:Pattern=`^-\S+$`
+type JavaOpt string
+
 // +kubebuilder:validation:Enum=Basic
 type AuthenticationType string
 
@@ -1135,6 +2104,21 @@ type SolrSecurityOptions struct {
 	// +optional
 	BasicAuthSecret string `json:"basicAuthSecret,omitempty"`
 
+	// A user-provided security.json, in a secret, to bootstrap in ZooKeeper instead of the default security.json
+	// generated by the operator. The security.json can configure the BasicAuthPlugin and RuleBasedAuthorizationPlugin.
+	// The 'basicAuthSecret' must also be provided, with the credentials of a user in this security.json that the
+	// operator can use for API requests.
+	// +optional
+	BootstrapSecurityJson *corev1.SecretKeySelector `json:"bootstrapSecurityJson,omitempty"`
+
+	// Flag to keep the users, roles and permissions of the 'bootstrapSecurityJson' in sync with the security.json in ZooKeeper,
+	// instead of only bootstrapping it when ZooKeeper does not have a security.json; defaults to false. If set to true,
+	// the operator restores the users, user-roles and permissions (matched by name) of the 'bootstrapSecurityJson' whenever
+	// they have drifted, merging them into the security.json in ZooKeeper. Any other users, roles and permissions, such as
+	// the ones added through the Solr Security API, are kept.
+	// +optional
+	ReconcileSecurityJson bool `json:"reconcileSecurityJson,omitempty"`
+
 	// Flag to indicate if the configured HTTP endpoint(s) used for the probes require authentication; defaults
 	// to false. If you set to true, then probes will use a local command on the main container to hit the secured
 	// endpoints with credentials sourced from an env var instead of HTTP directly.
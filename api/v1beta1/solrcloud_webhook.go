@@ -0,0 +1,39 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager registers the defaulting webhook for SolrClouds with the manager
+func (sc *SolrCloud) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(sc).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-solr-apache-org-v1beta1-solrcloud,mutating=true,failurePolicy=fail,groups=solr.apache.org,resources=solrclouds,verbs=create;update,versions=v1beta1,name=msolrcloud.solr.apache.org
+
+var _ webhook.Defaulter = &SolrCloud{}
+
+// Default sets the default values of the SolrCloud before it is stored, using the same defaults as WithDefaults.
+func (sc *SolrCloud) Default() {
+	sc.WithDefaults()
+}
@@ -0,0 +1,207 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DefaultCollectionNumShards         = 1
+	DefaultCollectionReplicationFactor = 1
+
+	// SolrCollectionFinalizer is added to SolrCollections that delete their Solr collection when they are deleted
+	SolrCollectionFinalizer = "solr.apache.org/delete-collection"
+)
+
+// SolrCollectionSpec defines the desired state of SolrCollection
+type SolrCollectionSpec struct {
+	// A reference to the SolrCloud to create the collection in
+	SolrCloud string `json:"solrCloud"`
+
+	// The name of the collection in Solr.
+	// If not provided, the name of the SolrCollection resource is used.
+	// +optional
+	CollectionName string `json:"collectionName,omitempty"`
+
+	// The name of the configset, already uploaded to the SolrCloud, to use for the collection.
+	// If not provided, Solr will use its default configset when creating the collection.
+	// +optional
+	ConfigName string `json:"config,omitempty"`
+
+	// The number of shards to create the collection with.
+	// This is only used when the collection is created, and it cannot be used with the implicit router.
+	// Defaults to 1 for the compositeId router.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumShards *int32 `json:"numShards,omitempty"`
+
+	// The number of replicas to create for each shard.
+	// Changing this for an existing collection only updates the replicationFactor of the collection,
+	// it does not add or remove replicas.
+	// Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+
+	// The router to use for the collection.
+	// This is only used when the collection is created.
+	// +optional
+	Router SolrCollectionRouterOptions `json:"router,omitempty"`
+
+	// Determines what happens to the Solr collection when the SolrCollection resource is deleted.
+	// Defaults to "Retain", which keeps the collection in Solr.
+	// +optional
+	DeletionPolicy CollectionDeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+func (spec *SolrCollectionSpec) withDefaults() (changed bool) {
+	if spec.Router.Name == "" {
+		spec.Router.Name = CompositeIdCollectionRouter
+		changed = true
+	}
+
+	if spec.NumShards == nil && spec.Router.Name == CompositeIdCollectionRouter {
+		numShards := int32(DefaultCollectionNumShards)
+		spec.NumShards = &numShards
+		changed = true
+	}
+
+	if spec.ReplicationFactor == nil {
+		replicationFactor := int32(DefaultCollectionReplicationFactor)
+		spec.ReplicationFactor = &replicationFactor
+		changed = true
+	}
+
+	if spec.DeletionPolicy == "" {
+		spec.DeletionPolicy = CollectionDeletionPolicyRetain
+		changed = true
+	}
+
+	return changed
+}
+
+// SolrCollectionRouterOptions defines how documents are routed to the shards of a collection
+type SolrCollectionRouterOptions struct {
+	// The name of the router.
+	// Defaults to "compositeId".
+	// +optional
+	Name CollectionRouterName `json:"name,omitempty"`
+
+	// The names of the shards to create, required for the implicit router.
+	// +optional
+	Shards []string `json:"shards,omitempty"`
+
+	// The field in each document to route on, instead of the uniqueKey field.
+	// +optional
+	Field string `json:"field,omitempty"`
+}
+
+// CollectionRouterName is a string enumeration type that enumerates the ways that documents can be routed for a collection.
+// +kubebuilder:validation:Enum=compositeId;implicit
+type CollectionRouterName string
+
+const (
+	// Route documents based on a hash of their uniqueKey, or the router field
+	CompositeIdCollectionRouter CollectionRouterName = "compositeId"
+
+	// Documents are routed to the shard named in the router field
+	ImplicitCollectionRouter CollectionRouterName = "implicit"
+)
+
+// CollectionDeletionPolicy is a string enumeration type that enumerates what happens to a collection when its SolrCollection is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type CollectionDeletionPolicy string
+
+const (
+	// Keep the collection in Solr when the SolrCollection is deleted
+	CollectionDeletionPolicyRetain CollectionDeletionPolicy = "Retain"
+
+	// Delete the collection in Solr when the SolrCollection is deleted
+	CollectionDeletionPolicyDelete CollectionDeletionPolicy = "Delete"
+)
+
+// SolrCollectionStatus defines the observed state of SolrCollection
+type SolrCollectionStatus struct {
+	// Whether the collection exists in Solr
+	Created bool `json:"created"`
+
+	// The name of the configset that the collection uses
+	// +optional
+	ConfigName string `json:"configName,omitempty"`
+
+	// The number of shards in the collection
+	Shards int32 `json:"shards"`
+
+	// The number of replicas, across all shards, in the collection
+	Replicas int32 `json:"replicas"`
+
+	// The number of replicas, across all shards, that are active
+	ActiveReplicas int32 `json:"activeReplicas"`
+
+	// An error that occurred while managing the collection, such as the SolrCloud not existing
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// CollectionName returns the name of the collection in Solr
+func (sc *SolrCollection) CollectionName() string {
+	if sc.Spec.CollectionName != "" {
+		return sc.Spec.CollectionName
+	}
+	return sc.Name
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:storageversion
+//+kubebuilder:categories=all
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".spec.solrCloud",description="Solr Cloud"
+//+kubebuilder:printcolumn:name="Created",type="boolean",JSONPath=".status.created",description="Whether the collection exists in Solr"
+//+kubebuilder:printcolumn:name="Shards",type="integer",JSONPath=".status.shards",description="Number of shards"
+//+kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas",description="Number of replicas"
+//+kubebuilder:printcolumn:name="ActiveReplicas",type="integer",JSONPath=".status.activeReplicas",description="Number of active replicas"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrCollection is the Schema for the solrcollections API
+type SolrCollection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SolrCollectionSpec   `json:"spec,omitempty"`
+	Status SolrCollectionStatus `json:"status,omitempty"`
+}
+
+// WithDefaults set default values when not defined in the spec.
+func (sc *SolrCollection) WithDefaults() bool {
+	return sc.Spec.withDefaults()
+}
+
+//+kubebuilder:object:root=true
+
+// SolrCollectionList contains a list of SolrCollection
+type SolrCollectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrCollection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrCollection{}, &SolrCollectionList{})
+}
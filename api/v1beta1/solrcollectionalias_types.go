@@ -0,0 +1,157 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SolrCollectionAliasFinalizer is added to SolrCollectionAliases, so that the alias can be deleted in Solr when they are deleted
+	SolrCollectionAliasFinalizer = "solr.apache.org/delete-alias"
+)
+
+// SolrCollectionAliasSpec defines the desired state of SolrCollectionAlias
+type SolrCollectionAliasSpec struct {
+	// A reference to the SolrCloud to create the alias in
+	SolrCloud string `json:"solrCloud"`
+
+	// The name of the alias in Solr.
+	// If not provided, the name of the SolrCollectionAlias resource is used.
+	// +optional
+	AliasName string `json:"aliasName,omitempty"`
+
+	// The collections that a standard alias points to.
+	// Updates sent to the alias are sent to the first collection in the list.
+	// Either collections or routed must be provided, but not both.
+	// +optional
+	Collections []string `json:"collections,omitempty"`
+
+	// Options to create a routed alias, whose collections are created by Solr.
+	// These options are only used when the alias is created.
+	// Either collections or routed must be provided, but not both.
+	// +optional
+	Routed *RoutedAliasOptions `json:"routed,omitempty"`
+
+	// Properties to set on the alias, through the ALIASPROP action of the Collections API.
+	// Properties that are set on the alias, but not listed here, are removed.
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// RoutedAliasOptions defines how a routed alias creates collections and routes documents to them
+type RoutedAliasOptions struct {
+	// The type of routed alias
+	RouterName RoutedAliasRouterName `json:"routerName"`
+
+	// The field in each document that determines which collection it is routed to
+	Field string `json:"field"`
+
+	// The start of the first time range, for time routed aliases, e.g. "NOW/DAY" or "2021-01-01T00:00:00Z"
+	// +optional
+	Start string `json:"start,omitempty"`
+
+	// The length of each time range, for time routed aliases, in Solr date math, e.g. "+1DAY"
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// The maximum number of collections that a category routed alias can create
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCardinality *int32 `json:"maxCardinality,omitempty"`
+
+	// The name of the configset, already uploaded to the SolrCloud, to use for the collections that are created for the alias
+	// +optional
+	ConfigName string `json:"config,omitempty"`
+
+	// The number of shards for each collection that is created for the alias
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NumShards *int32 `json:"numShards,omitempty"`
+
+	// The number of replicas for each shard of the collections that are created for the alias
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ReplicationFactor *int32 `json:"replicationFactor,omitempty"`
+}
+
+// RoutedAliasRouterName is a string enumeration type that enumerates the types of routed aliases.
+// +kubebuilder:validation:Enum=time;category
+type RoutedAliasRouterName string
+
+const (
+	// Documents are routed to collections by a date field, with a collection for each time range
+	TimeRoutedAlias RoutedAliasRouterName = "time"
+
+	// Documents are routed to collections by the value of a field, with a collection for each value
+	CategoryRoutedAlias RoutedAliasRouterName = "category"
+)
+
+// SolrCollectionAliasStatus defines the observed state of SolrCollectionAlias
+type SolrCollectionAliasStatus struct {
+	// Whether the alias exists in Solr
+	Created bool `json:"created"`
+
+	// The collections that the alias currently resolves to
+	// +optional
+	Collections []string `json:"collections,omitempty"`
+
+	// An error that occurred while managing the alias, such as the SolrCloud not existing
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// AliasName returns the name of the alias in Solr
+func (sca *SolrCollectionAlias) AliasName() string {
+	if sca.Spec.AliasName != "" {
+		return sca.Spec.AliasName
+	}
+	return sca.Name
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:storageversion
+//+kubebuilder:categories=all
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".spec.solrCloud",description="Solr Cloud"
+//+kubebuilder:printcolumn:name="Created",type="boolean",JSONPath=".status.created",description="Whether the alias exists in Solr"
+//+kubebuilder:printcolumn:name="Collections",type="string",JSONPath=".status.collections",description="The collections that the alias resolves to"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SolrCollectionAlias is the Schema for the solrcollectionaliases API
+type SolrCollectionAlias struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SolrCollectionAliasSpec   `json:"spec,omitempty"`
+	Status SolrCollectionAliasStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SolrCollectionAliasList contains a list of SolrCollectionAlias
+type SolrCollectionAliasList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SolrCollectionAlias `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SolrCollectionAlias{}, &SolrCollectionAliasList{})
+}
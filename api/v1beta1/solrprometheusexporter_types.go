@@ -24,6 +24,8 @@ import (
 
 const (
 	SolrPrometheusExporterTechnologyLabel = "solr-prometheus-exporter"
+
+	DefaultPrometheusExporterReplicas = int32(1)
 )
 
 // SolrPrometheusExporterSpec defines the desired state of SolrPrometheusExporter
@@ -35,6 +37,12 @@ type SolrPrometheusExporterSpec struct {
 	// +optional
 	Image *ContainerImage `json:"image,omitempty"`
 
+	// The number of exporter pods to run.
+	// Defaults to 1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// Provide custom options for kubernetes objects created for the SolrPrometheusExporter.
 	// +optional
 	CustomKubeOptions CustomExporterKubeOptions `json:"customKubeOptions,omitempty"`
@@ -56,6 +64,31 @@ type SolrPrometheusExporterSpec struct {
 	// The xml config for the metrics
 	// +optional
 	Config string `json:"metricsConfig,omitempty"`
+
+	// Generate a Prometheus Operator ServiceMonitor that scrapes the exporter's metrics Service.
+	// This is skipped when the ServiceMonitor CRD is not installed in the Kubernetes cluster.
+	// +optional
+	ServiceMonitor *ServiceMonitorOptions `json:"serviceMonitor,omitempty"`
+}
+
+// ServiceMonitorOptions defines the ServiceMonitor generated for the SolrPrometheusExporter
+type ServiceMonitorOptions struct {
+	// How often Prometheus should scrape the exporter, e.g. "30s".
+	// Defaults to the global scrape interval of the Prometheus instance.
+	// +optional
+	Interval string `json:"interval,omitempty"`
+
+	// How long Prometheus should wait for a scrape of the exporter to complete, e.g. "10s".
+	// +optional
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+
+	// Annotations to be added for the ServiceMonitor.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels to be added for the ServiceMonitor, such as the labels that the Prometheus instance selects ServiceMonitors by.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 func (ps *SolrPrometheusExporterSpec) withDefaults(namespace string) (changed bool) {
@@ -66,6 +99,12 @@ func (ps *SolrPrometheusExporterSpec) withDefaults(namespace string) (changed bo
 	}
 	changed = ps.Image.withDefaults(DefaultSolrRepo, DefaultSolrVersion, DefaultPullPolicy) || changed
 
+	if ps.Replicas == nil {
+		changed = true
+		r := DefaultPrometheusExporterReplicas
+		ps.Replicas = &r
+	}
+
 	if ps.NumThreads == 0 {
 		ps.NumThreads = 1
 		changed = true
@@ -85,7 +124,14 @@ type SolrReference struct {
 	// +optional
 	Standalone *StandaloneSolrReference `json:"standalone,omitempty"`
 
-	// Settings to configure the SolrJ client used to request metrics from TLS enabled Solr pods
+	// References of additional solrClouds to scrape, alongside the cloud above, such as all solrClouds that a team runs.
+	// The exporter can only connect to a single solrCloud, so every additional cloud is scraped by its own exporter container in the exporter pods.
+	// The additional clouds are scraped with the same solrTLS and basicAuthSecret settings as the cloud above.
+	// +optional
+	AdditionalClouds []SolrCloudReference `json:"additionalClouds,omitempty"`
+
+	// Settings to configure the SolrJ client used to request metrics from TLS enabled Solr pods.
+	// If not provided, the TLS settings of the referenced SolrCloud are used, when it is in the same namespace as the exporter.
 	// +optional
 	SolrTLS *SolrTLSOptions `json:"solrTLS,omitempty"`
 
@@ -102,6 +148,9 @@ func (sr *SolrReference) withDefaults(namespace string) (changed bool) {
 	if sr.Cloud != nil {
 		changed = sr.Cloud.withDefaults(namespace) || changed
 	}
+	for i := range sr.AdditionalClouds {
+		changed = sr.AdditionalClouds[i].withDefaults(namespace) || changed
+	}
 	return changed
 }
 
@@ -172,6 +221,18 @@ type SolrPrometheusExporterStatus struct {
 
 	// Is the prometheus exporter up and running
 	Ready bool `json:"ready"`
+
+	// The number of exporter pods created by the Deployment
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// The number of exporter pods that are ready to be scraped
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// The address, within the Kube cluster, at which Prometheus can scrape the metrics of the exporter
+	// +optional
+	ScrapeTarget string `json:"scrapeTarget,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -180,6 +241,7 @@ type SolrPrometheusExporterStatus struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:storageversion
 //+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready",description="Whether the prometheus exporter is ready"
+//+kubebuilder:printcolumn:name="ReadyReplicas",type="integer",JSONPath=".status.readyReplicas",description="Number of exporter pods that are ready"
 //+kubebuilder:printcolumn:name="Scrape Interval",type="integer",JSONPath=".spec.scrapeInterval",description="Scrape interval for metrics (in ms)"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
@@ -229,6 +291,16 @@ func (sc *SolrPrometheusExporter) MetricsServiceName() string {
 	return fmt.Sprintf("%s-solr-metrics", sc.GetName())
 }
 
+// MetricsServiceMonitorName returns the name of the ServiceMonitor for the exporter
+func (sc *SolrPrometheusExporter) MetricsServiceMonitorName() string {
+	return fmt.Sprintf("%s-solr-metrics", sc.GetName())
+}
+
+// MetricsScrapeTarget returns the address, within the Kube cluster, at which the metrics of the exporter can be scraped
+func (sc *SolrPrometheusExporter) MetricsScrapeTarget(port int) string {
+	return fmt.Sprintf("http://%s.%s:%d/metrics", sc.MetricsServiceName(), sc.Namespace, port)
+}
+
 func (sc *SolrPrometheusExporter) MetricsIngressPrefix() string {
 	return fmt.Sprintf("%s-%s-solr-metrics", sc.Namespace, sc.Name)
 }
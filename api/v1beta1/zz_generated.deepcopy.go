@@ -24,10 +24,27 @@ package v1beta1
 import (
 	zookeeperv1beta1 "github.com/pravega/zookeeper-operator/pkg/apis/zookeeper/v1beta1"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalConfigMap) DeepCopyInto(out *AdditionalConfigMap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalConfigMap.
+func (in *AdditionalConfigMap) DeepCopy() *AdditionalConfigMap {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalConfigMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdditionalVolume) DeepCopyInto(out *AdditionalVolume) {
 	*out = *in
@@ -105,6 +122,21 @@ func (in *CollectionBackupStatus) DeepCopy() *CollectionBackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerReference) DeepCopyInto(out *CertManagerIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerReference.
+func (in *CertManagerIssuerReference) DeepCopy() *CertManagerIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigMapOptions) DeepCopyInto(out *ConfigMapOptions) {
 	*out = *in
@@ -122,6 +154,11 @@ func (in *ConfigMapOptions) DeepCopyInto(out *ConfigMapOptions) {
 			(*out)[key] = val
 		}
 	}
+	if in.AdditionalConfigMaps != nil {
+		in, out := &in.AdditionalConfigMaps, &out.AdditionalConfigMaps
+		*out = make([]AdditionalConfigMap, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapOptions.
@@ -149,6 +186,46 @@ func (in *ContainerImage) DeepCopy() *ContainerImage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectionDrainStatus) DeepCopyInto(out *CollectionDrainStatus) {
+	*out = *in
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectionDrainStatus.
+func (in *CollectionDrainStatus) DeepCopy() *CollectionDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectionDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectionRestoreStatus) DeepCopyInto(out *CollectionRestoreStatus) {
+	*out = *in
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectionRestoreStatus.
+func (in *CollectionRestoreStatus) DeepCopy() *CollectionRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectionRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomExporterKubeOptions) DeepCopyInto(out *CustomExporterKubeOptions) {
 	*out = *in
@@ -222,6 +299,25 @@ func (in *CustomSolrKubeOptions) DeepCopyInto(out *CustomSolrKubeOptions) {
 		*out = new(IngressOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PodDisruptionBudgetOptions != nil {
+		in, out := &in.PodDisruptionBudgetOptions, &out.PodDisruptionBudgetOptions
+		*out = new(PodDisruptionBudgetOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomSolrKubeOptions.
@@ -271,6 +367,21 @@ func (in *ExternalAddressability) DeepCopyInto(out *ExternalAddressability) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExternalDNSTTL != nil {
+		in, out := &in.ExternalDNSTTL, &out.ExternalDNSTTL
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IngressTLSTermination != nil {
+		in, out := &in.IngressTLSTermination, &out.IngressTLSTermination
+		*out = new(SolrIngressTLSTermination)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeServiceIPTimeoutSeconds != nil {
+		in, out := &in.NodeServiceIPTimeoutSeconds, &out.NodeServiceIPTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAddressability.
@@ -283,6 +394,22 @@ func (in *ExternalAddressability) DeepCopy() *ExternalAddressability {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GCSBackupRepository) DeepCopyInto(out *GCSBackupRepository) {
+	*out = *in
+	in.CredentialsSecret.DeepCopyInto(&out.CredentialsSecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSBackupRepository.
+func (in *GCSBackupRepository) DeepCopy() *GCSBackupRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(GCSBackupRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressOptions) DeepCopyInto(out *IngressOptions) {
 	*out = *in
@@ -300,6 +427,11 @@ func (in *IngressOptions) DeepCopyInto(out *IngressOptions) {
 			(*out)[key] = val
 		}
 	}
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressOptions.
@@ -379,6 +511,40 @@ func (in *PersistentVolumeClaimTemplate) DeepCopy() *PersistentVolumeClaimTempla
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetOptions) DeepCopyInto(out *PodDisruptionBudgetOptions) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetOptions.
+func (in *PodDisruptionBudgetOptions) DeepCopy() *PodDisruptionBudgetOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodOptions) DeepCopyInto(out *PodOptions) {
 	*out = *in
@@ -400,6 +566,16 @@ func (in *PodOptions) DeepCopyInto(out *PodOptions) {
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InitContainerResources != nil {
+		in, out := &in.InitContainerResources, &out.InitContainerResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.EnvVariables != nil {
 		in, out := &in.EnvVariables, &out.EnvVariables
 		*out = make([]v1.EnvVar, len(*in))
@@ -407,6 +583,13 @@ func (in *PodOptions) DeepCopyInto(out *PodOptions) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Annotations != nil {
 		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
@@ -435,6 +618,13 @@ func (in *PodOptions) DeepCopyInto(out *PodOptions) {
 			(*out)[key] = val
 		}
 	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.LivenessProbe != nil {
 		in, out := &in.LivenessProbe, &out.LivenessProbe
 		*out = new(v1.Probe)
@@ -474,6 +664,33 @@ func (in *PodOptions) DeepCopyInto(out *PodOptions) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.PreStop != nil {
+		in, out := &in.PreStop, &out.PreStop
+		*out = new(v1.Handler)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostStart != nil {
+		in, out := &in.PostStart, &out.PostStart
+		*out = new(v1.Handler)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]v1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(v1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodOptions.
@@ -487,148 +704,340 @@ func (in *PodOptions) DeepCopy() *PodOptions {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *S3PersistenceSource) DeepCopyInto(out *S3PersistenceSource) {
+func (in *RestoreCollection) DeepCopyInto(out *RestoreCollection) {
 	*out = *in
-	if in.Retries != nil {
-		in, out := &in.Retries, &out.Retries
-		*out = new(int32)
-		**out = **in
-	}
-	out.Secrets = in.Secrets
-	out.AWSCliImage = in.AWSCliImage
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3PersistenceSource.
-func (in *S3PersistenceSource) DeepCopy() *S3PersistenceSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreCollection.
+func (in *RestoreCollection) DeepCopy() *RestoreCollection {
 	if in == nil {
 		return nil
 	}
-	out := new(S3PersistenceSource)
+	out := new(RestoreCollection)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *S3Secrets) DeepCopyInto(out *S3Secrets) {
+func (in *RetainedBackupStatus) DeepCopyInto(out *RetainedBackupStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.FinishTime != nil {
+		in, out := &in.FinishTime, &out.FinishTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Secrets.
-func (in *S3Secrets) DeepCopy() *S3Secrets {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainedBackupStatus.
+func (in *RetainedBackupStatus) DeepCopy() *RetainedBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(S3Secrets)
+	out := new(RetainedBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceOptions) DeepCopyInto(out *ServiceOptions) {
+func (in *S3BackupRepository) DeepCopyInto(out *S3BackupRepository) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(S3Credentials)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceOptions.
-func (in *ServiceOptions) DeepCopy() *ServiceOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupRepository.
+func (in *S3BackupRepository) DeepCopy() *S3BackupRepository {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceOptions)
+	out := new(S3BackupRepository)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrAddressabilityOptions) DeepCopyInto(out *SolrAddressabilityOptions) {
+func (in *S3Credentials) DeepCopyInto(out *S3Credentials) {
 	*out = *in
-	if in.External != nil {
-		in, out := &in.External, &out.External
-		*out = new(ExternalAddressability)
+	if in.AccessKeyIdSecret != nil {
+		in, out := &in.AccessKeyIdSecret, &out.AccessKeyIdSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretAccessKeySecret != nil {
+		in, out := &in.SecretAccessKeySecret, &out.SecretAccessKeySecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SessionTokenSecret != nil {
+		in, out := &in.SessionTokenSecret, &out.SessionTokenSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialsFileSecret != nil {
+		in, out := &in.CredentialsFileSecret, &out.CredentialsFileSecret
+		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrAddressabilityOptions.
-func (in *SolrAddressabilityOptions) DeepCopy() *SolrAddressabilityOptions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Credentials.
+func (in *S3Credentials) DeepCopy() *S3Credentials {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrAddressabilityOptions)
+	out := new(S3Credentials)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrBackup) DeepCopyInto(out *SolrBackup) {
+func (in *S3PersistenceSource) DeepCopyInto(out *S3PersistenceSource) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int32)
+		**out = **in
+	}
+	out.Secrets = in.Secrets
+	out.AWSCliImage = in.AWSCliImage
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackup.
-func (in *SolrBackup) DeepCopy() *SolrBackup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3PersistenceSource.
+func (in *S3PersistenceSource) DeepCopy() *S3PersistenceSource {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrBackup)
+	out := new(S3PersistenceSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SolrBackup) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrBackupList) DeepCopyInto(out *SolrBackupList) {
+func (in *S3Secrets) DeepCopyInto(out *S3Secrets) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]SolrBackup, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackupList.
-func (in *SolrBackupList) DeepCopy() *SolrBackupList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Secrets.
+func (in *S3Secrets) DeepCopy() *S3Secrets {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrBackupList)
+	out := new(S3Secrets)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SolrBackupList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutedAliasOptions) DeepCopyInto(out *RoutedAliasOptions) {
+	*out = *in
+	if in.MaxCardinality != nil {
+		in, out := &in.MaxCardinality, &out.MaxCardinality
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NumShards != nil {
+		in, out := &in.NumShards, &out.NumShards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicationFactor != nil {
+		in, out := &in.ReplicationFactor, &out.ReplicationFactor
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutedAliasOptions.
+func (in *RoutedAliasOptions) DeepCopy() *RoutedAliasOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutedAliasOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceMonitorOptions) DeepCopyInto(out *ServiceMonitorOptions) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMonitorOptions.
+func (in *ServiceMonitorOptions) DeepCopy() *ServiceMonitorOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceMonitorOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceOptions) DeepCopyInto(out *ServiceOptions) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(v1.IPFamilyPolicyType)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]v1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceOptions.
+func (in *ServiceOptions) DeepCopy() *ServiceOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrAddressabilityOptions) DeepCopyInto(out *SolrAddressabilityOptions) {
+	*out = *in
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalAddressability)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrAddressabilityOptions.
+func (in *SolrAddressabilityOptions) DeepCopy() *SolrAddressabilityOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrAddressabilityOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrBackup) DeepCopyInto(out *SolrBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackup.
+func (in *SolrBackup) DeepCopy() *SolrBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrBackupList) DeepCopyInto(out *SolrBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackupList.
+func (in *SolrBackupList) DeepCopy() *SolrBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrBackupRepository) DeepCopyInto(out *SolrBackupRepository) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackupRepository)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GCSBackupRepository)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackupRepository.
+func (in *SolrBackupRepository) DeepCopy() *SolrBackupRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrBackupRepository)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -656,6 +1065,11 @@ func (in *SolrBackupSpec) DeepCopyInto(out *SolrBackupSpec) {
 		copy(*out, *in)
 	}
 	in.Persistence.DeepCopyInto(&out.Persistence)
+	if in.VolumeSnapshot != nil {
+		in, out := &in.VolumeSnapshot, &out.VolumeSnapshot
+		*out = new(VolumeSnapshotBackupOptions)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackupSpec.
@@ -678,6 +1092,11 @@ func (in *SolrBackupStatus) DeepCopyInto(out *SolrBackupStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VolumeSnapshotStatuses != nil {
+		in, out := &in.VolumeSnapshotStatuses, &out.VolumeSnapshotStatuses
+		*out = make([]VolumeSnapshotStatus, len(*in))
+		copy(*out, *in)
+	}
 	in.PersistenceStatus.DeepCopyInto(&out.PersistenceStatus)
 	if in.FinishTime != nil {
 		in, out := &in.FinishTime, &out.FinishTime
@@ -688,6 +1107,21 @@ func (in *SolrBackupStatus) DeepCopyInto(out *SolrBackupStatus) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduledTime != nil {
+		in, out := &in.NextScheduledTime, &out.NextScheduledTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RetainedBackups != nil {
+		in, out := &in.RetainedBackups, &out.RetainedBackups
+		*out = make([]RetainedBackupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrBackupStatus.
@@ -727,6 +1161,48 @@ func (in *SolrCloud) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCloudDeletionOptions) DeepCopyInto(out *SolrCloudDeletionOptions) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudDeletionOptions.
+func (in *SolrCloudDeletionOptions) DeepCopy() *SolrCloudDeletionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudDeletionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCloudDrainStatus) DeepCopyInto(out *SolrCloudDrainStatus) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]CollectionDrainStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudDrainStatus.
+func (in *SolrCloudDrainStatus) DeepCopy() *SolrCloudDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrCloudList) DeepCopyInto(out *SolrCloudList) {
 	*out = *in
@@ -779,6 +1255,48 @@ func (in *SolrCloudReference) DeepCopy() *SolrCloudReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCloudRestoreOptions) DeepCopyInto(out *SolrCloudRestoreOptions) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]RestoreCollection, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudRestoreOptions.
+func (in *SolrCloudRestoreOptions) DeepCopy() *SolrCloudRestoreOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudRestoreOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCloudRestoreStatus) DeepCopyInto(out *SolrCloudRestoreStatus) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]CollectionRestoreStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudRestoreStatus.
+func (in *SolrCloudRestoreStatus) DeepCopy() *SolrCloudRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrCloudSpec) DeepCopyInto(out *SolrCloudSpec) {
 	*out = *in
@@ -798,58 +1316,354 @@ func (in *SolrCloudSpec) DeepCopyInto(out *SolrCloudSpec) {
 		**out = **in
 	}
 	in.StorageOptions.DeepCopyInto(&out.StorageOptions)
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(SolrCloudRestoreOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeletionOptions != nil {
+		in, out := &in.DeletionOptions, &out.DeletionOptions
+		*out = new(SolrCloudDeletionOptions)
+		(*in).DeepCopyInto(*out)
+	}
 	in.CustomSolrKubeOptions.DeepCopyInto(&out.CustomSolrKubeOptions)
 	in.SolrAddressability.DeepCopyInto(&out.SolrAddressability)
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
+	out.Scaling = in.Scaling
+	if in.MaxStartupSeconds != nil {
+		in, out := &in.MaxStartupSeconds, &out.MaxStartupSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]SolrNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.BusyBoxImage != nil {
 		in, out := &in.BusyBoxImage, &out.BusyBoxImage
 		*out = new(ContainerImage)
 		**out = **in
 	}
+	if in.AdditionalJavaOpts != nil {
+		in, out := &in.AdditionalJavaOpts, &out.AdditionalJavaOpts
+		*out = make([]JavaOpt, len(*in))
+		copy(*out, *in)
+	}
 	if in.SolrTLS != nil {
 		in, out := &in.SolrTLS, &out.SolrTLS
 		*out = new(SolrTLSOptions)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.SolrSecurity != nil {
-		in, out := &in.SolrSecurity, &out.SolrSecurity
-		*out = new(SolrSecurityOptions)
-		**out = **in
+	if in.SolrSecurity != nil {
+		in, out := &in.SolrSecurity, &out.SolrSecurity
+		*out = new(SolrSecurityOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudSpec.
+func (in *SolrCloudSpec) DeepCopy() *SolrCloudSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCloudStatus) DeepCopyInto(out *SolrCloudStatus) {
+	*out = *in
+	if in.SolrNodes != nil {
+		in, out := &in.SolrNodes, &out.SolrNodes
+		*out = make([]SolrNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]SolrVersionCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalCommonAddress != nil {
+		in, out := &in.ExternalCommonAddress, &out.ExternalCommonAddress
+		*out = new(string)
+		**out = **in
+	}
+	in.ZookeeperConnectionInfo.DeepCopyInto(&out.ZookeeperConnectionInfo)
+	if in.ScaleDown != nil {
+		in, out := &in.ScaleDown, &out.ScaleDown
+		*out = new(SolrScaleDownStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeExpansion != nil {
+		in, out := &in.VolumeExpansion, &out.VolumeExpansion
+		*out = new(SolrVolumeExpansionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(SolrCloudRestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(SolrCloudDrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadOnlyCollections != nil {
+		in, out := &in.ReadOnlyCollections, &out.ReadOnlyCollections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudStatus.
+func (in *SolrCloudStatus) DeepCopy() *SolrCloudStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCloudStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollection) DeepCopyInto(out *SolrCollection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollection.
+func (in *SolrCollection) DeepCopy() *SolrCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrCollection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionAlias) DeepCopyInto(out *SolrCollectionAlias) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionAlias.
+func (in *SolrCollectionAlias) DeepCopy() *SolrCollectionAlias {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollectionAlias)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrCollectionAlias) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionAliasList) DeepCopyInto(out *SolrCollectionAliasList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrCollectionAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionAliasList.
+func (in *SolrCollectionAliasList) DeepCopy() *SolrCollectionAliasList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollectionAliasList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrCollectionAliasList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionAliasSpec) DeepCopyInto(out *SolrCollectionAliasSpec) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Routed != nil {
+		in, out := &in.Routed, &out.Routed
+		*out = new(RoutedAliasOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionAliasSpec.
+func (in *SolrCollectionAliasSpec) DeepCopy() *SolrCollectionAliasSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollectionAliasSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionAliasStatus) DeepCopyInto(out *SolrCollectionAliasStatus) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionAliasStatus.
+func (in *SolrCollectionAliasStatus) DeepCopy() *SolrCollectionAliasStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollectionAliasStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionList) DeepCopyInto(out *SolrCollectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SolrCollection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionList.
+func (in *SolrCollectionList) DeepCopy() *SolrCollectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SolrCollectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionRouterOptions) DeepCopyInto(out *SolrCollectionRouterOptions) {
+	*out = *in
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudSpec.
-func (in *SolrCloudSpec) DeepCopy() *SolrCloudSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionRouterOptions.
+func (in *SolrCollectionRouterOptions) DeepCopy() *SolrCollectionRouterOptions {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrCloudSpec)
+	out := new(SolrCollectionRouterOptions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SolrCloudStatus) DeepCopyInto(out *SolrCloudStatus) {
+func (in *SolrCollectionSpec) DeepCopyInto(out *SolrCollectionSpec) {
 	*out = *in
-	if in.SolrNodes != nil {
-		in, out := &in.SolrNodes, &out.SolrNodes
-		*out = make([]SolrNodeStatus, len(*in))
-		copy(*out, *in)
+	if in.NumShards != nil {
+		in, out := &in.NumShards, &out.NumShards
+		*out = new(int32)
+		**out = **in
 	}
-	if in.ExternalCommonAddress != nil {
-		in, out := &in.ExternalCommonAddress, &out.ExternalCommonAddress
-		*out = new(string)
+	if in.ReplicationFactor != nil {
+		in, out := &in.ReplicationFactor, &out.ReplicationFactor
+		*out = new(int32)
 		**out = **in
 	}
-	in.ZookeeperConnectionInfo.DeepCopyInto(&out.ZookeeperConnectionInfo)
+	in.Router.DeepCopyInto(&out.Router)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCloudStatus.
-func (in *SolrCloudStatus) DeepCopy() *SolrCloudStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionSpec.
+func (in *SolrCollectionSpec) DeepCopy() *SolrCollectionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(SolrCloudStatus)
+	out := new(SolrCollectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrCollectionStatus) DeepCopyInto(out *SolrCollectionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrCollectionStatus.
+func (in *SolrCollectionStatus) DeepCopy() *SolrCollectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrCollectionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -867,11 +1681,23 @@ func (in *SolrDataStorageOptions) DeepCopyInto(out *SolrDataStorageOptions) {
 		*out = new(SolrEphemeralDataStorageOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LogStorage != nil {
+		in, out := &in.LogStorage, &out.LogStorage
+		*out = new(SolrLogStorageOptions)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BackupRestoreOptions != nil {
 		in, out := &in.BackupRestoreOptions, &out.BackupRestoreOptions
 		*out = new(SolrBackupRestoreOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BackupRepositories != nil {
+		in, out := &in.BackupRepositories, &out.BackupRepositories
+		*out = make([]SolrBackupRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrDataStorageOptions.
@@ -909,6 +1735,86 @@ func (in *SolrEphemeralDataStorageOptions) DeepCopy() *SolrEphemeralDataStorageO
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrIngressTLSTermination) DeepCopyInto(out *SolrIngressTLSTermination) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(CertManagerIssuerReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrIngressTLSTermination.
+func (in *SolrIngressTLSTermination) DeepCopy() *SolrIngressTLSTermination {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrIngressTLSTermination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrLogStorageOptions) DeepCopyInto(out *SolrLogStorageOptions) {
+	*out = *in
+	if in.PersistentVolumeClaimTemplate != nil {
+		in, out := &in.PersistentVolumeClaimTemplate, &out.PersistentVolumeClaimTemplate
+		*out = new(PersistentVolumeClaimTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EmptyDir != nil {
+		in, out := &in.EmptyDir, &out.EmptyDir
+		*out = new(v1.EmptyDirVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrLogStorageOptions.
+func (in *SolrLogStorageOptions) DeepCopy() *SolrLogStorageOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrLogStorageOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrNodePool) DeepCopyInto(out *SolrNodePool) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageCapacity != nil {
+		in, out := &in.StorageCapacity, &out.StorageCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrNodePool.
+func (in *SolrNodePool) DeepCopy() *SolrNodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrNodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrNodeStatus) DeepCopyInto(out *SolrNodeStatus) {
 	*out = *in
@@ -1008,7 +1914,17 @@ func (in *SolrPrometheusExporterSpec) DeepCopyInto(out *SolrPrometheusExporterSp
 		*out = new(ContainerImage)
 		**out = **in
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	in.CustomKubeOptions.DeepCopyInto(&out.CustomKubeOptions)
+	if in.ServiceMonitor != nil {
+		in, out := &in.ServiceMonitor, &out.ServiceMonitor
+		*out = new(ServiceMonitorOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrPrometheusExporterSpec.
@@ -1049,6 +1965,13 @@ func (in *SolrReference) DeepCopyInto(out *SolrReference) {
 		*out = new(StandaloneSolrReference)
 		**out = **in
 	}
+	if in.AdditionalClouds != nil {
+		in, out := &in.AdditionalClouds, &out.AdditionalClouds
+		*out = make([]SolrCloudReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.SolrTLS != nil {
 		in, out := &in.SolrTLS, &out.SolrTLS
 		*out = new(SolrTLSOptions)
@@ -1066,9 +1989,49 @@ func (in *SolrReference) DeepCopy() *SolrReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrScaleDownStatus) DeepCopyInto(out *SolrScaleDownStatus) {
+	*out = *in
+	if in.VacatingNodes != nil {
+		in, out := &in.VacatingNodes, &out.VacatingNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrScaleDownStatus.
+func (in *SolrScaleDownStatus) DeepCopy() *SolrScaleDownStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrScaleDownStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrScalingOptions) DeepCopyInto(out *SolrScalingOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrScalingOptions.
+func (in *SolrScalingOptions) DeepCopy() *SolrScalingOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrScalingOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrSecurityOptions) DeepCopyInto(out *SolrSecurityOptions) {
 	*out = *in
+	if in.BootstrapSecurityJson != nil {
+		in, out := &in.BootstrapSecurityJson, &out.BootstrapSecurityJson
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrSecurityOptions.
@@ -1104,6 +2067,21 @@ func (in *SolrTLSOptions) DeepCopyInto(out *SolrTLSOptions) {
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ManageUrlScheme != nil {
+		in, out := &in.ManageUrlScheme, &out.ManageUrlScheme
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AdditionalDNSNames != nil {
+		in, out := &in.AdditionalDNSNames, &out.AdditionalDNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PEMKeys != nil {
+		in, out := &in.PEMKeys, &out.PEMKeys
+		*out = new(SolrTLSPEMKeys)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrTLSOptions.
@@ -1116,10 +2094,35 @@ func (in *SolrTLSOptions) DeepCopy() *SolrTLSOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrTLSPEMKeys) DeepCopyInto(out *SolrTLSPEMKeys) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrTLSPEMKeys.
+func (in *SolrTLSPEMKeys) DeepCopy() *SolrTLSPEMKeys {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrTLSPEMKeys)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SolrUpdateStrategy) DeepCopyInto(out *SolrUpdateStrategy) {
 	*out = *in
 	in.ManagedUpdateOptions.DeepCopyInto(&out.ManagedUpdateOptions)
+	if in.StatefulSetUpdateOptions != nil {
+		in, out := &in.StatefulSetUpdateOptions, &out.StatefulSetUpdateOptions
+		*out = new(StatefulSetUpdateOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VersionSkewWarningSeconds != nil {
+		in, out := &in.VersionSkewWarningSeconds, &out.VersionSkewWarningSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrUpdateStrategy.
@@ -1132,6 +2135,41 @@ func (in *SolrUpdateStrategy) DeepCopy() *SolrUpdateStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrVersionCount) DeepCopyInto(out *SolrVersionCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrVersionCount.
+func (in *SolrVersionCount) DeepCopy() *SolrVersionCount {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrVersionCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SolrVolumeExpansionStatus) DeepCopyInto(out *SolrVolumeExpansionStatus) {
+	*out = *in
+	if in.PendingPVCs != nil {
+		in, out := &in.PendingPVCs, &out.PendingPVCs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SolrVolumeExpansionStatus.
+func (in *SolrVolumeExpansionStatus) DeepCopy() *SolrVolumeExpansionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SolrVolumeExpansionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StandaloneSolrReference) DeepCopyInto(out *StandaloneSolrReference) {
 	*out = *in
@@ -1176,6 +2214,26 @@ func (in *StatefulSetOptions) DeepCopy() *StatefulSetOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetUpdateOptions) DeepCopyInto(out *StatefulSetUpdateOptions) {
+	*out = *in
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetUpdateOptions.
+func (in *StatefulSetUpdateOptions) DeepCopy() *StatefulSetUpdateOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetUpdateOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TemplateMeta) DeepCopyInto(out *TemplateMeta) {
 	*out = *in
@@ -1222,6 +2280,36 @@ func (in *VolumePersistenceSource) DeepCopy() *VolumePersistenceSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotBackupOptions) DeepCopyInto(out *VolumeSnapshotBackupOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotBackupOptions.
+func (in *VolumeSnapshotBackupOptions) DeepCopy() *VolumeSnapshotBackupOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotBackupOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSnapshotStatus) DeepCopyInto(out *VolumeSnapshotStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSnapshotStatus.
+func (in *VolumeSnapshotStatus) DeepCopy() *VolumeSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZookeeperACL) DeepCopyInto(out *ZookeeperACL) {
 	*out = *in
@@ -1255,6 +2343,11 @@ func (in *ZookeeperConnectionInfo) DeepCopyInto(out *ZookeeperConnectionInfo) {
 		*out = new(ZookeeperACL)
 		**out = **in
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ZookeeperTLSOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperConnectionInfo.
@@ -1379,3 +2472,34 @@ func (in *ZookeeperSpec) DeepCopy() *ZookeeperSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZookeeperTLSOptions) DeepCopyInto(out *ZookeeperTLSOptions) {
+	*out = *in
+	in.TrustStoreSecret.DeepCopyInto(&out.TrustStoreSecret)
+	if in.TrustStorePasswordSecret != nil {
+		in, out := &in.TrustStorePasswordSecret, &out.TrustStorePasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyStoreSecret != nil {
+		in, out := &in.KeyStoreSecret, &out.KeyStoreSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeyStorePasswordSecret != nil {
+		in, out := &in.KeyStorePasswordSecret, &out.KeyStorePasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperTLSOptions.
+func (in *ZookeeperTLSOptions) DeepCopy() *ZookeeperTLSOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ZookeeperTLSOptions)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -18,14 +18,21 @@
 package controllers
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	b64 "encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"github.com/apache/solr-operator/controllers/util"
 	zkv1beta1 "github.com/pravega/zookeeper-operator/pkg/apis/zookeeper/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/onsi/gomega"
@@ -214,6 +221,21 @@ func createTLSOptions(tlsSecretName string, keystorePassKey string, restartOnTLS
 	}
 }
 
+// createMockTLSCertificate returns a PEM encoded, self-signed certificate that is valid for the given DNS names
+func createMockTLSCertificate(t *testing.T, dnsNames ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})
+}
+
 func createMockTLSSecret(ctx context.Context, apiClient client.Client, secretName string, secretKey string, ns string, keystorePasswordKey string) (corev1.Secret, error) {
 	secretData := map[string][]byte{}
 	secretData[secretKey] = []byte(b64.StdEncoding.EncodeToString([]byte("mock keystore")))
@@ -301,9 +323,15 @@ func expectTLSConfigOnPodTemplate(t *testing.T, tls *solr.SolrTLSOptions, podTem
 				break
 			}
 		}
-		expCmd := "openssl pkcs12 -export -in /var/solr/tls/tls.crt -in /var/solr/tls/ca.crt -inkey /var/solr/tls/tls.key -out /var/solr/tls/pkcs12/keystore.p12 -passout pass:${SOLR_SSL_KEY_STORE_PASSWORD}"
+		expCmd := "openssl pkcs12 -export -in /var/solr/tls/tls.crt $([ -f /var/solr/tls/ca.crt ] && echo -certfile /var/solr/tls/ca.crt) -inkey /var/solr/tls/tls.key -out /var/solr/tls/pkcs12/keystore.p12 -passout pass:${SOLR_SSL_KEY_STORE_PASSWORD}"
 		assert.NotNil(t, expInitContainer, "Didn't find the gen-pkcs12-keystore InitContainer in the sts!")
-		assert.Equal(t, expCmd, expInitContainer.Command[2])
+		assert.True(t, strings.HasPrefix(expInitContainer.Command[2], expCmd), "initContainer should generate the pkcs12 keystore")
+		if tls.TrustStoreSecret == nil {
+			expResetCmd := "rm -f /var/solr/tls/pkcs12/truststore.p12 /var/solr/tls/pkcs12/ca-*.crt"
+			assert.True(t, strings.Contains(expInitContainer.Command[2], expResetCmd), "initContainer should remove the truststore of a previous run")
+			expTrustCmd := "for caCert in /var/solr/tls/pkcs12/ca-*.crt; do keytool -importcert -noprompt -alias $(basename ${caCert} .crt) -file ${caCert} -keystore /var/solr/tls/pkcs12/truststore.p12"
+			assert.True(t, strings.Contains(expInitContainer.Command[2], expTrustCmd), "initContainer should generate the pkcs12 truststore from every cert of the CA bundle")
+		}
 	}
 
 	if tls.ClientAuth == solr.Need {
@@ -335,7 +363,11 @@ func expectTLSEnvVars(t *testing.T, envVars []corev1.EnvVar, expectedKeystorePas
 	}
 
 	if expectedTruststorePath == "" {
-		expectedTruststorePath = expectedKeystorePath
+		if needsPkcs12InitContainer {
+			expectedTruststorePath = util.DefaultWritableKeyStorePath + "/truststore.p12"
+		} else {
+			expectedTruststorePath = expectedKeystorePath
+		}
 	}
 
 	for _, envVar := range envVars {
@@ -658,12 +690,14 @@ var (
 		},
 	}
 	testPriorityClass              = "p4"
+	testRuntimeClassName           = "gvisor"
 	testImagePullSecretName        = "MAIN_SECRET"
 	testAdditionalImagePullSecrets = []corev1.LocalObjectReference{
 		{Name: "ADDITIONAL_SECRET_1"},
 		{Name: "ADDITIONAL_SECRET_2"},
 	}
 	testTerminationGracePeriodSeconds = int64(50)
+	testStatefulSetPartition          = int32(2)
 	extraVars                         = []corev1.EnvVar{
 		{
 			Name:  "VAR_1",
@@ -679,6 +713,16 @@ var (
 			},
 		},
 	}
+	testDNSConfig = &corev1.PodDNSConfig{
+		Nameservers: []string{"10.0.0.10"},
+		Searches:    []string{"internal.example.com"},
+	}
+	testEnvFrom = []corev1.EnvFromSource{
+		{
+			Prefix:       "PLUGIN_",
+			ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "plugin-settings"}},
+		},
+	}
 	one                = int64(1)
 	two                = int64(2)
 	four               = int32(4)
@@ -687,6 +731,12 @@ var (
 		RunAsUser:  &one,
 		RunAsGroup: &two,
 	}
+	containerSecurityContext = corev1.SecurityContext{
+		RunAsUser: &one,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
 	extraVolumes = []solr.AdditionalVolume{
 		{
 			Name: "vol1",
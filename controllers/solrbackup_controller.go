@@ -19,6 +19,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	"reflect"
 	"time"
@@ -28,6 +29,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
@@ -51,6 +53,8 @@ type SolrBackupReconciler struct {
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete;deletecollection
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrbackups,verbs=get;list;watch;create;update;patch;delete
@@ -83,6 +87,26 @@ func (r *SolrBackupReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// Start a new backup if one is scheduled, and determine when to check on the schedule next
+	var scheduleWait *time.Duration
+	if backup.IsScheduled() {
+		if scheduleWait, err = reconcileBackupSchedule(r, backup); err != nil {
+			r.Log.Error(err, "Error while scheduling SolrCloud backup", "namespace", backup.Namespace, "name", backup.Name, "schedule", backup.Spec.Schedule)
+			return reconcile.Result{}, err
+		}
+		// The retained backups that could not be pruned stay in the status, and are tried again later.
+		// This must not hold up the scheduled backups, or the status update below.
+		if pruneErr := pruneRetainedBackups(r, backup); pruneErr != nil {
+			r.Log.Error(pruneErr, "Error while pruning retained backups, will retry", "namespace", backup.Namespace, "name", backup.Name)
+			pruneRetryWait := time.Second * 30
+			if *scheduleWait > pruneRetryWait {
+				scheduleWait = &pruneRetryWait
+			}
+		}
+	} else if backup.Status.NextScheduledTime != nil {
+		backup.Status.NextScheduledTime = nil
+	}
+
 	// When working with the collection backups, auto-requeue after 5 seconds
 	// to check on the status of the async solr backup calls
 	requeueOrNot := reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}
@@ -102,11 +126,19 @@ func (r *SolrBackupReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		// and the collection backups are all complete (not necessarily successful)
 		// Do not do this right after the collectionsBackup have been complete, wait till the next cycle
 		if allCollectionsComplete && !backup.Status.Finished {
-			// We will count on the Job updates to be notifified
 			requeueOrNot = reconcile.Result{}
-			err = persistSolrCloudBackups(r, backup, solrCloud)
-			if err != nil {
-				r.Log.Error(err, "Error while persisting SolrCloud backup")
+			if backup.UsesBackupRepository() {
+				// Solr stores the backup in the repository directly, so there is nothing to persist
+				finishBackupWithoutPersistence(backup, util.AllCollectionBackupsSuccessful(backup))
+			} else if backup.UsesVolumeSnapshots() {
+				// The snapshots are stored by the CSI driver, so there is nothing to persist
+				finishBackupWithoutPersistence(backup, util.AllVolumeSnapshotsReady(backup))
+			} else {
+				// We will count on the Job updates to be notifified
+				err = persistSolrCloudBackups(r, backup, solrCloud)
+				if err != nil {
+					r.Log.Error(err, "Error while persisting SolrCloud backup")
+				}
 			}
 		}
 	}
@@ -114,7 +146,9 @@ func (r *SolrBackupReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 	if backup.Status.Finished && backup.Status.FinishTime == nil {
 		now := metav1.Now()
 		backup.Status.FinishTime = &now
-		backup.Status.Successful = backup.Status.PersistenceStatus.Successful
+		// The backup is only successful if every collection was backed up, even if the successful backups were persisted
+		successful := backup.Status.PersistenceStatus.Successful != nil && *backup.Status.PersistenceStatus.Successful && util.AllCollectionBackupsSuccessful(backup)
+		backup.Status.Successful = &successful
 	}
 
 	if !reflect.DeepEqual(oldStatus, backup.Status) {
@@ -126,9 +160,195 @@ func (r *SolrBackupReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		requeueOrNot = reconcile.Result{}
 	}
 
+	if scheduleWait != nil {
+		updateRequeueAfter(&requeueOrNot, *scheduleWait)
+	}
+
 	return requeueOrNot, err
 }
 
+// reconcileBackupSchedule starts a new backup when the next scheduled backup is due.
+// The previous backup is recorded in the list of retained backups before a new one is started, to be pruned by pruneRetainedBackups.
+func reconcileBackupSchedule(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup) (scheduleWait *time.Duration, err error) {
+	startBackup, nextScheduledTime, err := util.ScheduleNextBackup(backup)
+	if err != nil {
+		return nil, err
+	}
+	if backup.Status.NextScheduledTime == nil || !backup.Status.NextScheduledTime.Time.Equal(nextScheduledTime) {
+		if !startBackup && backup.Status.NextScheduledTime != nil && util.IsBackupInProgress(backup) {
+			r.Log.Info("Skipping scheduled backup, the previous backup is still in progress", "namespace", backup.Namespace, "name", backup.Name, "backup", backup.CurrentBackupName())
+		}
+		next := metav1.NewTime(nextScheduledTime)
+		backup.Status.NextScheduledTime = &next
+	}
+	wait := time.Until(nextScheduledTime)
+	scheduleWait = &wait
+
+	if !startBackup {
+		return scheduleWait, nil
+	}
+
+	// Record the previous backup, if one was taken, as a retained backup
+	if backup.Status.Finished || len(backup.Status.CollectionBackupStatuses) > 0 || len(backup.Status.VolumeSnapshotStatuses) > 0 {
+		startTime := backup.Status.LastBackupTime
+		if startTime == nil && len(backup.Status.CollectionBackupStatuses) > 0 {
+			startTime = backup.Status.CollectionBackupStatuses[0].StartTime
+		}
+		backup.Status.RetainedBackups = append(backup.Status.RetainedBackups, solrv1beta1.RetainedBackupStatus{
+			Name:       backup.CurrentBackupName(),
+			StartTime:  startTime,
+			FinishTime: backup.Status.FinishTime,
+			Successful: backup.Status.Successful,
+		})
+	}
+
+	// Reset the status for the new backup
+	now := metav1.Now()
+	backup.Status.SolrVersion = ""
+	backup.Status.CollectionBackupStatuses = nil
+	backup.Status.VolumeSnapshotStatuses = nil
+	backup.Status.PersistenceStatus = solrv1beta1.BackupPersistenceStatus{}
+	backup.Status.FinishTime = nil
+	backup.Status.Successful = nil
+	backup.Status.Finished = false
+	backup.Status.LastBackupTime = &now
+	r.Log.Info("Starting scheduled backup", "namespace", backup.Namespace, "name", backup.Name, "backup", backup.CurrentBackupName())
+
+	return scheduleWait, nil
+}
+
+// pruneRetainedBackups deletes the persisted data of the oldest retained backups, until no more than maxRetained backups
+// remain, including the current backup. See util.RetainedBackupsToPrune.
+func pruneRetainedBackups(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup) error {
+	if len(util.RetainedBackupsToPrune(backup)) == 0 {
+		return nil
+	}
+
+	if backup.UsesVolumeSnapshots() {
+		return pruneRetainedVolumeSnapshots(r, backup)
+	}
+
+	solrCloud := &solrv1beta1.SolrCloud{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Namespace: backup.Namespace, Name: backup.Spec.SolrCloud}, solrCloud); err != nil {
+		return err
+	}
+
+	if backup.UsesBackupRepository() {
+		return pruneRetainedRepositoryBackups(r, backup, solrCloud)
+	}
+
+	for _, prunedBackup := range util.RetainedBackupsToPrune(backup) {
+
+		pruneJob := util.GenerateBackupPruneJobForCloud(backup, solrCloud, prunedBackup.Name)
+		if err := controllerutil.SetControllerReference(backup, pruneJob, r.scheme); err != nil {
+			return err
+		}
+		r.Log.Info("Creating Prune Job for retained backup", "namespace", pruneJob.Namespace, "name", pruneJob.Name, "backup", prunedBackup.Name)
+		if err := r.Create(context.TODO(), pruneJob); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+
+		// The persistence job for the pruned backup is no longer needed
+		persistenceJob := &batchv1.Job{}
+		err := r.Get(context.TODO(), types.NamespacedName{Namespace: backup.Namespace, Name: solrv1beta1.PersistenceJobNameForBackup(prunedBackup.Name)}, persistenceJob)
+		if err == nil {
+			err = r.Delete(context.TODO(), persistenceJob, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		backup.Status.RetainedBackups = backup.Status.RetainedBackups[1:]
+	}
+	return nil
+}
+
+// pruneRetainedVolumeSnapshots deletes the VolumeSnapshots of the oldest retained backups
+func pruneRetainedVolumeSnapshots(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup) error {
+	for _, prunedBackup := range util.RetainedBackupsToPrune(backup) {
+
+		r.Log.Info("Deleting VolumeSnapshots of retained backup", "namespace", backup.Namespace, "name", backup.Name, "backup", prunedBackup.Name)
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(util.VolumeSnapshotGVK)
+		err := r.DeleteAllOf(context.TODO(), snapshot, client.InNamespace(backup.Namespace), client.MatchingLabels(backup.SharedLabelsWith(map[string]string{util.VolumeSnapshotBackupNameLabel: prunedBackup.Name})))
+		if err != nil {
+			return err
+		}
+
+		backup.Status.RetainedBackups = backup.Status.RetainedBackups[1:]
+	}
+	return nil
+}
+
+// pruneRetainedRepositoryBackups deletes the oldest retained backups from the backup repository, using the Solr Collections API
+func pruneRetainedRepositoryBackups(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud) error {
+	httpHeaders, err := getBasicAuthHeaders(r, solrCloud)
+	if err != nil {
+		return err
+	}
+
+	for _, prunedBackup := range util.RetainedBackupsToPrune(backup) {
+
+		r.Log.Info("Deleting retained backup from backup repository", "namespace", backup.Namespace, "name", backup.Name, "backup", prunedBackup.Name, "repository", backup.Spec.RepositoryName)
+		for _, collection := range backup.Spec.Collections {
+			if err = util.DeleteRepositoryBackupForCollection(solrCloud, collection, prunedBackup.Name, backup.Spec.RepositoryName, httpHeaders); err != nil {
+				return err
+			}
+		}
+
+		backup.Status.RetainedBackups = backup.Status.RetainedBackups[1:]
+	}
+	return nil
+}
+
+// validateBackupRepository makes sure that the SolrCloud has the backup's repository, and that the secrets containing its credentials exist
+func validateBackupRepository(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud) error {
+	repo := solrCloud.Spec.StorageOptions.BackupRepository(backup.Spec.RepositoryName)
+	if repo == nil {
+		return fmt.Errorf("SolrCloud %s does not have a backup repository named %s", solrCloud.Name, backup.Spec.RepositoryName)
+	}
+
+	var credentialSecrets []*corev1.SecretKeySelector
+	if repo.GCS != nil {
+		credentialSecrets = append(credentialSecrets, &repo.GCS.CredentialsSecret)
+	}
+	if repo.S3 != nil && repo.S3.Credentials != nil {
+		credentialSecrets = append(credentialSecrets,
+			repo.S3.Credentials.AccessKeyIdSecret,
+			repo.S3.Credentials.SecretAccessKeySecret,
+			repo.S3.Credentials.SessionTokenSecret,
+			repo.S3.Credentials.CredentialsFileSecret)
+	}
+	for _, secretKey := range credentialSecrets {
+		if secretKey == nil || (secretKey.Optional != nil && *secretKey.Optional) {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: secretKey.Name, Namespace: solrCloud.Namespace}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("the credentials secret %s for backup repository %s does not exist", secretKey.Name, repo.Name)
+			}
+			return err
+		}
+		if _, hasKey := secret.Data[secretKey.Key]; !hasKey {
+			return fmt.Errorf("the credentials secret %s for backup repository %s does not contain the key %s", secretKey.Name, repo.Name, secretKey.Key)
+		}
+	}
+	return nil
+}
+
+// getBasicAuthHeaders returns the headers needed to call the Solr API of the given SolrCloud, if it uses basic auth
+func getBasicAuthHeaders(r client.Reader, solrCloud *solrv1beta1.SolrCloud) (httpHeaders map[string]string, err error) {
+	if solrCloud.Spec.SolrSecurity != nil {
+		basicAuthSecret := &corev1.Secret{}
+		if err = r.Get(context.TODO(), types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+			return nil, err
+		}
+		httpHeaders = map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}
+	}
+	return httpHeaders, nil
+}
+
 func reconcileSolrCloudBackup(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup) (solrCloud *solrv1beta1.SolrCloud, collectionBackupsFinished bool, actionTaken bool, err error) {
 	// Get the solrCloud that this backup is for.
 	solrCloud = &solrv1beta1.SolrCloud{}
@@ -141,17 +361,37 @@ func reconcileSolrCloudBackup(r *SolrBackupReconciler, backup *solrv1beta1.SolrB
 		return nil, collectionBackupsFinished, actionTaken, err
 	}
 
-	var httpHeaders map[string]string
-	if solrCloud.Spec.SolrSecurity != nil {
-		basicAuthSecret := &corev1.Secret{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: solrCloud.BasicAuthSecretName(), Namespace: solrCloud.Namespace}, basicAuthSecret); err != nil {
+	// Make sure that the backup repository can be used, and surface the reason in the status if it cannot
+	if backup.UsesBackupRepository() {
+		if err = validateBackupRepository(r, backup, solrCloud); err != nil {
+			backup.Status.Error = err.Error()
+			r.Log.Error(err, "Cannot use backup repository", "namespace", backup.Namespace, "backupName", backup.Name, "solrCloudName", solrCloud.Name, "repository", backup.Spec.RepositoryName)
 			return nil, collectionBackupsFinished, actionTaken, err
 		}
-		httpHeaders = map[string]string{"Authorization": util.BasicAuthHeader(basicAuthSecret)}
+		backup.Status.Error = ""
 	}
 
-	// First check if the collection backups have been completed
-	collectionBackupsFinished = util.CheckStatusOfCollectionBackups(backup)
+	// Make sure that the SolrCloud's data can be snapshotted, and surface the reason in the status if it cannot
+	if backup.UsesVolumeSnapshots() {
+		if err = util.ValidateVolumeSnapshotBackup(backup, solrCloud); err != nil {
+			backup.Status.Error = err.Error()
+			r.Log.Error(err, "Cannot use volume snapshots", "namespace", backup.Namespace, "backupName", backup.Name, "solrCloudName", solrCloud.Name)
+			return nil, collectionBackupsFinished, actionTaken, err
+		}
+		backup.Status.Error = ""
+	}
+
+	httpHeaders, err := getBasicAuthHeaders(r, solrCloud)
+	if err != nil {
+		return nil, collectionBackupsFinished, actionTaken, err
+	}
+
+	// First check if the collection backups, or volume snapshots, have been completed
+	if backup.UsesVolumeSnapshots() {
+		collectionBackupsFinished = util.CheckStatusOfVolumeSnapshots(backup)
+	} else {
+		collectionBackupsFinished = util.CheckStatusOfCollectionBackups(backup)
+	}
 
 	// If the collectionBackups are complete, then nothing else has to be done here
 	if collectionBackupsFinished {
@@ -162,14 +402,17 @@ func reconcileSolrCloudBackup(r *SolrBackupReconciler, backup *solrv1beta1.SolrB
 
 	// This should only occur before the backup processes have been started
 	if backup.Status.SolrVersion == "" {
-		// Prep the backup directory in the persistentVolume
-		err := util.EnsureDirectoryForBackup(solrCloud, backup.Name, r.config)
-		if err != nil {
-			return solrCloud, collectionBackupsFinished, actionTaken, err
+		// Backup repositories are configured in the solr.xml, and snapshots are taken of the data volumes, so there is no shared volume to prepare
+		if !backup.UsesBackupRepository() && !backup.UsesVolumeSnapshots() {
+			// Prep the backup directory in the persistentVolume
+			err := util.EnsureDirectoryForBackup(solrCloud, backup.CurrentBackupName(), r.config)
+			if err != nil {
+				return solrCloud, collectionBackupsFinished, actionTaken, err
+			}
 		}
 
-		// Make sure that all solr nodes are active and have the backupRestore shared volume mounted
-		cloudReady := solrCloud.Status.BackupRestoreReady && (solrCloud.Status.Replicas == solrCloud.Status.ReadyReplicas)
+		// Make sure that all solr nodes are active and have the backupRestore shared volume mounted, if it is used
+		cloudReady := (backup.UsesBackupRepository() || backup.UsesVolumeSnapshots() || solrCloud.Status.BackupRestoreReady) && (solrCloud.Status.Replicas == solrCloud.Status.ReadyReplicas)
 		if !cloudReady {
 			r.Log.Info("Cloud not ready for backup backup", "namespace", backup.Namespace, "cloud", solrCloud.Name, "backup", backup.Name)
 			return solrCloud, collectionBackupsFinished, actionTaken, errors.NewServiceUnavailable("Cloud is not ready for backups or restores")
@@ -179,9 +422,34 @@ func reconcileSolrCloudBackup(r *SolrBackupReconciler, backup *solrv1beta1.SolrB
 		backup.Status.SolrVersion = solrCloud.Status.Version
 	}
 
+	if backup.UsesVolumeSnapshots() {
+		err = reconcileVolumeSnapshots(r, backup, solrCloud, httpHeaders)
+		collectionBackupsFinished = util.CheckStatusOfVolumeSnapshots(backup)
+		return solrCloud, collectionBackupsFinished, actionTaken, err
+	}
+
 	// Go through each collection specified and reconcile the backup.
+	// New backups are only started while fewer than maxConcurrentBackups are in progress,
+	// and an error for one collection does not stop the backups of the others.
+	inProgress := 0
+	for _, collectionStatus := range backup.Status.CollectionBackupStatuses {
+		if collectionStatus.InProgress {
+			inProgress += 1
+		}
+	}
 	for _, collection := range backup.Spec.Collections {
-		_, err = reconcileSolrCollectionBackup(backup, solrCloud, collection, httpHeaders)
+		wasInProgress, isInProgress, collectionErr := reconcileSolrCollectionBackup(backup, solrCloud, collection, util.CanStartCollectionBackup(backup, inProgress), httpHeaders)
+		if wasInProgress && !isInProgress {
+			inProgress -= 1
+		} else if !wasInProgress && isInProgress {
+			inProgress += 1
+		}
+		if collectionErr != nil {
+			r.Log.Error(collectionErr, "Error while backing up collection", "namespace", backup.Namespace, "backup", backup.Name, "collection", collection)
+			if err == nil {
+				err = collectionErr
+			}
+		}
 	}
 
 	// First check if the collection backups have been completed
@@ -190,7 +458,77 @@ func reconcileSolrCloudBackup(r *SolrBackupReconciler, backup *solrv1beta1.SolrB
 	return solrCloud, collectionBackupsFinished, actionTaken, err
 }
 
-func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, collection string, httpHeaders map[string]string) (finished bool, err error) {
+// reconcileVolumeSnapshots takes a VolumeSnapshot of each of the SolrCloud's data PVCs, after committing the backup's collections if requested,
+// and then checks on the readiness of the snapshots until they are all finished.
+func reconcileVolumeSnapshots(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, httpHeaders map[string]string) error {
+	// The snapshots are only taken once for each backup
+	if len(backup.Status.VolumeSnapshotStatuses) == 0 {
+		if backup.Spec.VolumeSnapshot.CommitBeforeSnapshot {
+			for _, collection := range backup.Spec.Collections {
+				if err := util.CommitCollection(solrCloud, collection, httpHeaders); err != nil {
+					return err
+				}
+			}
+		}
+
+		pvcList := &corev1.PersistentVolumeClaimList{}
+		err := r.List(context.TODO(), pvcList, client.InNamespace(solrCloud.Namespace), client.MatchingLabels{
+			util.SolrPVCTechnologyLabel: util.SolrCloudPVCTechnology,
+			util.SolrPVCStorageLabel:    util.SolrCloudPVCDataStorage,
+			util.SolrPVCInstanceLabel:   solrCloud.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		var snapshotStatuses []solrv1beta1.VolumeSnapshotStatus
+		for _, pvc := range pvcList.Items {
+			// PVCs of removed Solr nodes do not contain any live data
+			if pvc.Labels[util.SolrPVCOrphanedLabel] == "true" {
+				continue
+			}
+			snapshot := util.GenerateVolumeSnapshot(backup, pvc.Name)
+			if err = controllerutil.SetControllerReference(backup, snapshot, r.scheme); err != nil {
+				return err
+			}
+			r.Log.Info("Creating VolumeSnapshot", "namespace", snapshot.GetNamespace(), "name", snapshot.GetName(), "pvc", pvc.Name)
+			if err = r.Create(context.TODO(), snapshot); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			}
+			snapshotStatuses = append(snapshotStatuses, solrv1beta1.VolumeSnapshotStatus{
+				PersistentVolumeClaim: pvc.Name,
+				VolumeSnapshot:        snapshot.GetName(),
+			})
+		}
+		if len(snapshotStatuses) == 0 {
+			return fmt.Errorf("SolrCloud %s does not have any data PVCs to snapshot", solrCloud.Name)
+		}
+		backup.Status.VolumeSnapshotStatuses = snapshotStatuses
+		return nil
+	}
+
+	for i := range backup.Status.VolumeSnapshotStatuses {
+		snapshotStatus := &backup.Status.VolumeSnapshotStatuses[i]
+		if snapshotStatus.Finished() {
+			continue
+		}
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(util.VolumeSnapshotGVK)
+		err := r.Get(context.TODO(), types.NamespacedName{Namespace: backup.Namespace, Name: snapshotStatus.VolumeSnapshot}, snapshot)
+		if errors.IsNotFound(err) {
+			snapshotStatus.Error = "The VolumeSnapshot no longer exists"
+		} else if err != nil {
+			return err
+		} else {
+			snapshotStatus.ReadyToUse, snapshotStatus.Error = util.VolumeSnapshotReadiness(snapshot)
+		}
+	}
+	return nil
+}
+
+// reconcileSolrCollectionBackup starts the backup of a collection, if it has not been started and canStart is true, or checks on the backup if it is in progress.
+// Whether the backup was in progress before and after the call is returned, so that the number of concurrent backups can be tracked.
+func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud, collection string, canStart bool, httpHeaders map[string]string) (wasInProgress bool, isInProgress bool, err error) {
 	now := metav1.Now()
 	collectionBackupStatus := solrv1beta1.CollectionBackupStatus{}
 	collectionBackupStatus.Collection = collection
@@ -203,23 +541,27 @@ func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *so
 		}
 	}
 
+	wasInProgress = collectionBackupStatus.InProgress
+
 	// If the collection backup hasn't started, start it
-	if !collectionBackupStatus.InProgress && !collectionBackupStatus.Finished {
+	if !collectionBackupStatus.InProgress && !collectionBackupStatus.Finished && canStart {
 
 		// Start the backup by calling solr
-		started, err := util.StartBackupForCollection(solrCloud, collection, backup.Name, httpHeaders)
-		if err != nil {
-			return true, err
-		}
+		var started bool
+		started, err = util.StartBackupForCollection(solrCloud, collection, backup.CurrentBackupName(), backup.Spec.RepositoryName, httpHeaders)
 		collectionBackupStatus.InProgress = started
-		if started && collectionBackupStatus.StartTime == nil {
-			collectionBackupStatus.StartTime = &now
+		if started {
+			collectionBackupStatus.AsyncBackupStatus = "submitted"
+			collectionBackupStatus.AsyncRequestId = util.AsyncIdForCollectionBackup(collection, backup.CurrentBackupName())
+			if collectionBackupStatus.StartTime == nil {
+				collectionBackupStatus.StartTime = &now
+			}
 		}
 	} else if collectionBackupStatus.InProgress {
 		// Check the state of the backup, when it is in progress, and update the state accordingly
-		finished, successful, asyncStatus, error := util.CheckBackupForCollection(solrCloud, collection, backup.Name, httpHeaders)
+		finished, successful, asyncStatus, message, error := util.CheckBackupForCollection(solrCloud, collection, backup.CurrentBackupName(), httpHeaders)
 		if error != nil {
-			return false, error
+			return wasInProgress, collectionBackupStatus.InProgress, error
 		}
 		collectionBackupStatus.Finished = finished
 		if finished {
@@ -227,13 +569,17 @@ func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *so
 			if collectionBackupStatus.Successful == nil {
 				collectionBackupStatus.Successful = &successful
 			}
-			collectionBackupStatus.AsyncBackupStatus = ""
+			// Keep the final state of the backup, and the reason that it failed, since the async info is removed from solr
+			collectionBackupStatus.AsyncBackupStatus = asyncStatus
+			if !successful {
+				collectionBackupStatus.Message = message
+			}
 			if collectionBackupStatus.FinishTime == nil {
 				collectionBackupStatus.FinishTime = &now
 			}
 
-			err = util.DeleteAsyncInfoForBackup(solrCloud, collection, backup.Name, httpHeaders)
-		} else {
+			err = util.DeleteAsyncInfoForBackup(solrCloud, collection, backup.CurrentBackupName(), httpHeaders)
+		} else if asyncStatus != "" {
 			collectionBackupStatus.AsyncBackupStatus = asyncStatus
 		}
 	}
@@ -244,7 +590,18 @@ func reconcileSolrCollectionBackup(backup *solrv1beta1.SolrBackup, solrCloud *so
 		backup.Status.CollectionBackupStatuses[backupIndex] = collectionBackupStatus
 	}
 
-	return collectionBackupStatus.Finished, err
+	return wasInProgress, collectionBackupStatus.InProgress, err
+}
+
+// finishBackupWithoutPersistence marks the persistence of a backup as finished, for backups that are already stored outside of the SolrCloud,
+// such as in a backup repository or as VolumeSnapshots.
+func finishBackupWithoutPersistence(backup *solrv1beta1.SolrBackup, successful bool) {
+	now := metav1.Now()
+	backup.Status.PersistenceStatus.Successful = &successful
+	backup.Status.PersistenceStatus.Finished = true
+	backup.Status.PersistenceStatus.FinishTime = &now
+	backup.Status.Finished = true
+	backup.Status.Successful = &successful
 }
 
 func persistSolrCloudBackups(r *SolrBackupReconciler, backup *solrv1beta1.SolrBackup, solrCloud *solrv1beta1.SolrCloud) (err error) {
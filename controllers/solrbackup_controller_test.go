@@ -19,7 +19,9 @@ package controllers
 
 import (
 	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
 	"github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -72,3 +74,50 @@ func TestBackupReconcile(t *testing.T) {
 	defer testClient.Delete(context.TODO(), instance)
 	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedBackupRequest)))
 }
+
+func TestReconcileNotStartedCollectionBackup(t *testing.T) {
+	tru := true
+	solrCloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-back", Namespace: "default"},
+		Spec: solr.SolrBackupSpec{
+			SolrCloud:            "foo",
+			Collections:          []string{"col1", "col2", "col3"},
+			MaxConcurrentBackups: 1,
+		},
+		Status: solr.SolrBackupStatus{
+			CollectionBackupStatuses: []solr.CollectionBackupStatus{
+				{Collection: "col1", Finished: true, Successful: &tru},
+				{Collection: "col2", InProgress: true, AsyncBackupStatus: "running"},
+			},
+		},
+	}
+	assert.False(t, util.CanStartCollectionBackup(backup, 1), "No collection backup can be started while the maximum number are in progress")
+
+	// A collection backup that cannot be started yet should be tracked without calling Solr
+	wasInProgress, isInProgress, err := reconcileSolrCollectionBackup(backup, solrCloud, "col3", false, nil)
+	assert.NoError(t, err, "Solr should not be called for a collection backup that cannot be started")
+	assert.False(t, wasInProgress, "A collection backup that has not been started was not in progress")
+	assert.False(t, isInProgress, "A collection backup that cannot be started should not be in progress")
+	assert.Equal(t, []solr.CollectionBackupStatus{
+		{Collection: "col1", Finished: true, Successful: &tru},
+		{Collection: "col2", InProgress: true, AsyncBackupStatus: "running"},
+		{Collection: "col3"},
+	}, backup.Status.CollectionBackupStatuses, "The collection backup that has not been started should be added to the status")
+	assert.False(t, util.CheckStatusOfCollectionBackups(backup), "The backup should not be finished while a collection backup has not been started")
+	assert.False(t, backup.Status.Finished, "The backup should not be finished while a collection backup has not been started")
+
+	// Waiting again should not add another status for the same collection
+	wasInProgress, isInProgress, err = reconcileSolrCollectionBackup(backup, solrCloud, "col3", false, nil)
+	assert.NoError(t, err, "Solr should not be called for a collection backup that cannot be started")
+	assert.False(t, wasInProgress, "A collection backup that has not been started was not in progress")
+	assert.False(t, isInProgress, "A collection backup that cannot be started should not be in progress")
+	assert.Len(t, backup.Status.CollectionBackupStatuses, 3, "Each collection should only have a single backup status")
+
+	// A finished collection backup should never be started again, even when there is room for it
+	wasInProgress, isInProgress, err = reconcileSolrCollectionBackup(backup, solrCloud, "col1", true, nil)
+	assert.NoError(t, err, "Solr should not be called for a finished collection backup")
+	assert.False(t, wasInProgress, "A finished collection backup was not in progress")
+	assert.False(t, isInProgress, "A finished collection backup should not be started again")
+	assert.Equal(t, solr.CollectionBackupStatus{Collection: "col1", Finished: true, Successful: &tru}, backup.Status.CollectionBackupStatuses[0], "The status of a finished collection backup should not change")
+}
@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	solr "github.com/apache/solr-operator/api/v1beta1"
@@ -31,17 +32,24 @@ import (
 	"github.com/go-logr/logr"
 	zk "github.com/pravega/zookeeper-operator/pkg/apis/zookeeper/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -52,14 +60,56 @@ import (
 // SolrCloudReconciler reconciles a SolrCloud object
 type SolrCloudReconciler struct {
 	client.Client
-	scheme *runtime.Scheme
-	Log    logr.Logger
+	scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
+
+	// UseZkCRD is set when the Zookeeper Operator is available, so that ZookeeperClusters can be created for SolrClouds with a provided Zookeeper
+	UseZkCRD bool
+
+	// UseDefaultingWebhook is set when SolrClouds are defaulted by the mutating admission webhook when they are stored.
+	// Otherwise the defaults are written to the SolrCloud by the reconciler, which requires another reconcile.
+	UseDefaultingWebhook bool
+
+	// MaxConcurrentReconciles is how many SolrClouds may be reconciled in parallel, 1 if unset.
+	// A single SolrCloud is never reconciled by more than one worker at a time.
+	MaxConcurrentReconciles int
+
+	// RequeueIntervals are how long to wait before reconciling a SolrCloud again, when it is waiting on something.
+	// The DefaultRequeueIntervals are used for the intervals that are not set.
+	RequeueIntervals RequeueIntervals
+
+	// transientConditionBackoff tracks the backoff of the transient conditions of each SolrCloud, it is created from the RequeueIntervals when first used
+	transientConditionBackoff     workqueue.RateLimiter
+	transientConditionBackoffLock sync.Mutex
+
+	// dryRun is set when the writes of the reconciler are only being planned, so changes outside of Kubernetes must be skipped as well
+	dryRun bool
 }
 
-var useZkCRD bool
+// RequeueIntervals are how long the SolrCloudReconciler waits before reconciling a SolrCloud again,
+// when it is waiting on something that does not trigger a reconcile by itself.
+type RequeueIntervals struct {
+	// Poll is used to check on operations that are expected to finish soon, such as moving replicas before a scale down or restoring collections.
+	// It is also the first wait of the exponential backoff used for transient conditions.
+	Poll time.Duration
+
+	// Retry is used to retry actions that are blocked, such as managed updates waiting on replicas to recover, or the cleanup and expansion of PVCs.
+	Retry time.Duration
+
+	// MissingDependency is used to check for user-provided resources that do not exist yet, such as a providedConfigMap or a StorageClass.
+	MissingDependency time.Duration
 
-func UseZkCRD(useCRD bool) {
-	useZkCRD = useCRD
+	// MaxBackoff caps the exponential backoff used while waiting on transient conditions, such as Zookeeper or the TLS secrets becoming available.
+	MaxBackoff time.Duration
+}
+
+// DefaultRequeueIntervals are used for the RequeueIntervals that are not set on the SolrCloudReconciler
+var DefaultRequeueIntervals = RequeueIntervals{
+	Poll:              time.Second * 5,
+	Retry:             time.Second * 15,
+	MissingDependency: time.Second * 30,
+	MaxBackoff:        time.Minute * 5,
 }
 
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
@@ -70,9 +120,14 @@ func UseZkCRD(useCRD bool) {
 // +kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=zookeeper.pravega.io,resources=zookeeperclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=zookeeper.pravega.io,resources=zookeeperclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
@@ -80,25 +135,58 @@ func UseZkCRD(useCRD bool) {
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/finalizers,verbs=update
 
-func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
 	_ = context.Background()
 
 	logger := r.Log.WithValues("namespace", req.Namespace, "solrCloud", req.Name)
 	// Fetch the SolrCloud instance
 	instance := &solr.SolrCloud{}
-	err := r.Get(context.TODO(), req.NamespacedName, instance)
+	err = r.Get(context.TODO(), req.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			deleteSolrCloudMetrics(req.NamespacedName)
+			r.forgetBackoffsForCloud(req.NamespacedName)
+			util.RemoveMTLSHttpClientForCloud(&solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace}})
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the req.
+		observeSolrCloudReconcile(req.NamespacedName, time.Now(), err)
 		return reconcile.Result{}, err
 	}
+	defer func(start time.Time) {
+		observeSolrCloudReconcile(req.NamespacedName, start, err)
+	}(time.Now())
+
+	// Leave the SolrCloud and everything it manages untouched while it is paused, only report that it is paused
+	if instance.Spec.Paused {
+		logger.Info("Skipping reconciliation, the SolrCloud is paused")
+		pausedStatus := solr.SolrCloudStatus{
+			Conditions: instance.Status.DeepCopy().Conditions,
+		}
+		setSolrCloudCondition(instance, &pausedStatus, solr.SolrCloudPaused, true, "Paused", "Reconciliation of the SolrCloud is paused")
+		r.updateStatusConditions(instance, pausedStatus.Conditions, logger)
+		return reconcile.Result{}, nil
+	}
+
+	// Only plan the changes to the SolrCloud's resources, and report them, while the dry run annotation is set
+	if instance.Annotations[util.SolrDryRunAnnotation] == "true" {
+		return r.dryRunReconcile(instance, logger)
+	}
+
+	return r.reconcileCloud(instance, logger)
+}
 
+// reconcileCloud reconciles all of the resources managed for the SolrCloud, and the SolrCloud's status
+func (r *SolrCloudReconciler) reconcileCloud(instance *solr.SolrCloud, logger logr.Logger) (ctrl.Result, error) {
+	var err error
 	changed := instance.WithDefaults()
-	if changed {
+	if changed && (r.UseDefaultingWebhook || r.dryRun) {
+		// The SolrCloud was stored before the webhook was enabled, or before the current defaults existed.
+		// The defaults will be stored by the webhook on the next update, so there is no need to write them here.
+		logger.Info("Using default settings that are not yet stored for SolrCloud")
+	} else if changed {
 		logger.Info("Setting default settings for SolrCloud")
 		if err := r.Update(context.TODO(), instance); err != nil {
 			return reconcile.Result{}, err
@@ -109,7 +197,12 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	// When working with the clouds, some actions outside of kube may need to be retried after a few seconds
 	requeueOrNot := reconcile.Result{}
 
-	newStatus := solr.SolrCloudStatus{}
+	// Conditions are carried over from the previous status, so that their transition times are kept when they do not change
+	newStatus := solr.SolrCloudStatus{
+		Conditions: instance.Status.DeepCopy().Conditions,
+	}
+	meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudPaused)
+	meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudDryRun)
 
 	blockReconciliationOfStatefulSet := false
 	if err := reconcileZk(r, logger, instance, &newStatus); err != nil {
@@ -129,9 +222,11 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			err = r.Create(context.TODO(), commonService)
 		}
 	} else if err == nil {
-		var needsUpdate bool
-		needsUpdate, err = util.OvertakeControllerRef(instance, foundCommonService, r.scheme)
-		needsUpdate = util.CopyServiceFields(commonService, foundCommonService, commonServiceLogger) || needsUpdate
+		var recreating, needsUpdate bool
+		if recreating, err = deleteServiceToRecreate(r, commonService, foundCommonService, commonServiceLogger); !recreating {
+			needsUpdate, err = util.OvertakeControllerRef(instance, foundCommonService, r.scheme)
+			needsUpdate = util.CopyServiceFields(commonService, foundCommonService, commonServiceLogger) || needsUpdate
+		}
 
 		// Update the found Service and write the result back if there are any changes
 		if needsUpdate && err == nil {
@@ -146,24 +241,42 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	solrNodeNames := instance.GetAllSolrNodeNames()
 
 	hostNameIpMap := make(map[string]string)
+	// The node service IP Addresses only need to be used in the hostname map if the SolrCloud is advertising the external address.
+	usesNodeServiceIPs := instance.UsesIndividualNodeServices() && instance.Spec.SolrAddressability.External.UseExternalAddress
+	// Solr Nodes should not advertise addresses that route to load balancers which have not yet been provisioned
+	waitForLoadBalancers := usesNodeServiceIPs && instance.Spec.SolrAddressability.External.Method == solr.LoadBalancer
+	var nodesWithoutServiceIPs []string
 	// Generate a service for every Node
 	if instance.UsesIndividualNodeServices() {
 		for _, nodeName := range solrNodeNames {
-			err, ip := reconcileNodeService(r, logger, instance, nodeName)
+			err, ip, loadBalancerAddress := reconcileNodeService(r, logger, instance, nodeName)
 			if err != nil {
 				return requeueOrNot, err
 			}
-			// This IP Address only needs to be used in the hostname map if the SolrCloud is advertising the external address.
-			if instance.Spec.SolrAddressability.External.UseExternalAddress {
-				if ip == "" {
-					// If we are using this IP in the hostAliases of the statefulSet, it needs to be set for every service before trying to update the statefulSet
-					blockReconciliationOfStatefulSet = true
-				} else {
+			if usesNodeServiceIPs {
+				if ip != "" {
+					// The IP is re-read every reconcile, so the hostAliases of the statefulSet will be updated if a service's IP changes
 					hostNameIpMap[instance.AdvertisedNodeHost(nodeName)] = ip
 				}
+				if ip == "" || (waitForLoadBalancers && loadBalancerAddress == "") {
+					nodesWithoutServiceIPs = append(nodesWithoutServiceIPs, nodeName)
+				}
 			}
 		}
 	}
+	if !usesNodeServiceIPs {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudNodeServiceIPsAssigned)
+	} else if len(nodesWithoutServiceIPs) == 0 {
+		setSolrCloudCondition(instance, &newStatus, solr.SolrCloudNodeServiceIPsAssigned, true, "NodeServiceIPsAssigned", "All node services have been assigned addresses")
+	} else {
+		// If we are using these IPs in the hostAliases of the statefulSet, they need to be set for every service before trying to update the statefulSet.
+		// Unless the configured timeout has passed, then the statefulSet is reconciled with the IPs that are available.
+		var waitDuration *time.Duration
+		blockReconciliationOfStatefulSet, waitDuration = waitForNodeServiceIPs(r, instance, &newStatus, nodesWithoutServiceIPs)
+		if waitDuration != nil {
+			updateRequeueAfter(&requeueOrNot, *waitDuration)
+		}
+	}
 
 	// Generate HeadlessService
 	if instance.UsesHeadlessService() {
@@ -179,9 +292,11 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 				err = r.Create(context.TODO(), headless)
 			}
 		} else if err == nil {
-			var needsUpdate bool
-			needsUpdate, err = util.OvertakeControllerRef(instance, foundHeadless, r.scheme)
-			needsUpdate = util.CopyServiceFields(headless, foundHeadless, headlessServiceLogger) || needsUpdate
+			var recreating, needsUpdate bool
+			if recreating, err = deleteServiceToRecreate(r, headless, foundHeadless, headlessServiceLogger); !recreating {
+				needsUpdate, err = util.OvertakeControllerRef(instance, foundHeadless, r.scheme)
+				needsUpdate = util.CopyServiceFields(headless, foundHeadless, headlessServiceLogger) || needsUpdate
+			}
 
 			// Update the found HeadlessService and write the result back if there are any changes
 			if needsUpdate && err == nil {
@@ -198,17 +313,120 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	// needed for creating the STS and supporting objects (secrets, config maps, and so on)
 	reconcileConfigInfo := make(map[string]string)
 
+	// Make sure that Solr can be served under the context path, before generating the probes and Ingress rules
+	if err = util.ValidateContextPath(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the backup repositories can be configured, before generating the solr.xml
+	if err = util.ValidateBackupRepositories(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that TLS can be terminated at the Ingress, before generating any resources
+	if err = util.ValidateIngressTLSTermination(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the custom sidecar and init containers do not collide with the containers the operator manages
+	if err = util.ValidateCustomContainers(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that Solr can listen on its port, alongside the sidecar containers
+	if err = util.ValidateSolrPodPort(instance); err != nil {
+		if solrCloudConditionChanged(instance, solr.SolrCloudSolrPortAvailable, false, "SidecarPortConflict", err.Error()) {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "SidecarPortConflict", "The Solr pods cannot be created: %s", err)
+		}
+		setSolrCloudCondition(instance, &newStatus, solr.SolrCloudSolrPortAvailable, false, "SidecarPortConflict", err.Error())
+		r.updateStatusConditions(instance, newStatus.Conditions, logger)
+		return requeueOrNot, err
+	} else if instance.Spec.CustomSolrKubeOptions.PodOptions != nil && len(instance.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers) > 0 {
+		setSolrCloudCondition(instance, &newStatus, solr.SolrCloudSolrPortAvailable, true, "NoPortConflicts", "The sidecar containers do not use the port of Solr")
+	} else {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudSolrPortAvailable)
+	}
+
+	// Make sure that the custom lifecycle hooks of the Solr container can be run
+	if err = util.ValidateSolrLifecycleHooks(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the custom volumes do not collide with the volumes the operator manages
+	if err = util.ValidateCustomVolumes(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the Solr logs and data are stored in different volumes
+	if err = util.ValidateLogStorage(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the additional Java options do not override the options the operator manages
+	if err = util.ValidateAdditionalJavaOpts(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that each node pool can be given its own StatefulSet
+	if err = util.ValidateNodePools(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the additional user-provided ConfigMaps can be mounted
+	if err = util.ValidateAdditionalConfigMaps(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that only one custom log4j2.xml is provided
+	if err = util.ValidateSolrLogXml(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the Solr and BusyBox images can be pulled
+	if err = util.ValidateSolrCloudImages(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the backup to restore collections from can be found
+	if err = util.ValidateRestoreOptions(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that the collections can be drained as requested when the SolrCloud is deleted
+	if err = util.ValidateDeletionOptions(instance); err != nil {
+		return requeueOrNot, err
+	}
+
+	// Make sure that only a chroot will be removed from Zookeeper when the SolrCloud is deleted
+	if err = util.ValidateZookeeperCleanup(instance); err != nil {
+		return requeueOrNot, err
+	}
+
 	// Generate ConfigMap unless the user supplied a custom ConfigMap for solr.xml
+	providedConfigMapMissing := false
 	if instance.Spec.CustomSolrKubeOptions.ConfigMapOptions != nil && instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap != "" {
 		providedConfigMapName := instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap
 		foundConfigMap := &corev1.ConfigMap{}
 		nn := types.NamespacedName{Name: providedConfigMapName, Namespace: instance.Namespace}
 		err = r.Get(context.TODO(), nn, foundConfigMap)
-		if err != nil {
-			return requeueOrNot, err // if they passed a providedConfigMap name, then it must exist
+		if err != nil && !errors.IsNotFound(err) {
+			return requeueOrNot, err
 		}
 
-		if foundConfigMap.Data != nil {
+		if err != nil {
+			// Keep the existing StatefulSet as it is, the ConfigMap watch will trigger a reconcile once it is recreated
+			providedConfigMapMissing = true
+			blockReconciliationOfStatefulSet = true
+			message := fmt.Sprintf("providedConfigMap %s not found", providedConfigMapName)
+			logger.Info("Not reconciling the StatefulSet, the providedConfigMap does not exist", "configMap", providedConfigMapName)
+			if solrCloudConditionChanged(instance, solr.SolrCloudProvidedConfigMapFound, false, "ProvidedConfigMapNotFound", message) {
+				r.Recorder.Event(instance, corev1.EventTypeWarning, "ProvidedConfigMapNotFound", message+", the StatefulSet will not be updated until it exists")
+			}
+			setSolrCloudCondition(instance, &newStatus, solr.SolrCloudProvidedConfigMapFound, false, "ProvidedConfigMapNotFound", message)
+			updateRequeueAfter(&requeueOrNot, r.requeueIntervals().MissingDependency)
+		} else if foundConfigMap.Data != nil {
+			setSolrCloudCondition(instance, &newStatus, solr.SolrCloudProvidedConfigMapFound, true, "ProvidedConfigMapFound", fmt.Sprintf("providedConfigMap %s found", providedConfigMapName))
+
 			logXml, hasLogXml := foundConfigMap.Data[util.LogXmlFile]
 			solrXml, hasSolrXml := foundConfigMap.Data[util.SolrXmlFile]
 
@@ -221,10 +439,8 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 			if hasSolrXml {
 				// make sure the user-provided solr.xml is valid
-				if !strings.Contains(solrXml, "${hostPort:") {
-					return requeueOrNot,
-						fmt.Errorf("Custom solr.xml in ConfigMap %s must contain a placeholder for the 'hostPort' variable, such as <int name=\"hostPort\">${hostPort:80}</int>",
-							providedConfigMapName)
+				if err = util.ValidateProvidedSolrXml(instance, providedConfigMapName, solrXml); err != nil {
+					return requeueOrNot, err
 				}
 				// stored in the pod spec annotations on the statefulset so that we get a restart when solr.xml changes
 				reconcileConfigInfo[util.SolrXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(solrXml)))
@@ -242,14 +458,91 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		} else {
 			return requeueOrNot, fmt.Errorf("Provided ConfigMap %s has no data", providedConfigMapName)
 		}
+	} else {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudProvidedConfigMapFound)
+	}
+
+	// A separately provided log4j2.xml takes precedence over one in the providedConfigMap
+	if instance.Spec.CustomSolrKubeOptions.ConfigMapOptions != nil && instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedLogXmlConfigMap != "" {
+		providedConfigMapName := instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedLogXmlConfigMap
+		foundConfigMap := &corev1.ConfigMap{}
+		err = r.Get(context.TODO(), types.NamespacedName{Name: providedConfigMapName, Namespace: instance.Namespace}, foundConfigMap)
+		if err != nil {
+			return requeueOrNot, err // if they passed a providedLogXmlConfigMap name, then it must exist
+		}
+
+		logXml, hasLogXml := foundConfigMap.Data[util.LogXmlFile]
+		if !hasLogXml {
+			return requeueOrNot, fmt.Errorf("User provided ConfigMap %s must have a 'log4j2.xml'", providedConfigMapName)
+		}
+		delete(reconcileConfigInfo, util.LogXmlMd5Annotation)
+		if !strings.Contains(logXml, "monitorInterval=") {
+			// stored in the pod spec annotations on the statefulset so that we get a restart when the log config changes
+			reconcileConfigInfo[util.LogXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(logXml)))
+		} // else log4j will automatically refresh for us, so no restart needed
+		reconcileConfigInfo[util.LogXmlFile] = foundConfigMap.Name
+	}
+
+	// Additional provided ConfigMaps and custom volumes are mounted as-is, so any change to their contents requires a restart
+	var mountedConfigMaps []*corev1.ConfigMap
+	var mountedSecrets []*corev1.Secret
+	if instance.Spec.CustomSolrKubeOptions.ConfigMapOptions != nil {
+		for _, additionalConfigMap := range instance.Spec.CustomSolrKubeOptions.ConfigMapOptions.AdditionalConfigMaps {
+			foundConfigMap := &corev1.ConfigMap{}
+			err = r.Get(context.TODO(), types.NamespacedName{Name: additionalConfigMap.Name, Namespace: instance.Namespace}, foundConfigMap)
+			if err != nil {
+				return requeueOrNot, err // additional ConfigMaps must exist before they can be mounted
+			}
+			mountedConfigMaps = append(mountedConfigMaps, foundConfigMap)
+		}
+	}
+	// A missing ConfigMap or Secret in a custom volume either keeps the pods from starting or is optional, so it is not tracked
+	volumeConfigMaps, volumeSecrets := util.MountedVolumeConfigMapsAndSecrets(instance)
+	for _, configMapName := range volumeConfigMaps {
+		foundConfigMap := &corev1.ConfigMap{}
+		if getErr := r.Get(context.TODO(), types.NamespacedName{Name: configMapName, Namespace: instance.Namespace}, foundConfigMap); getErr == nil {
+			mountedConfigMaps = append(mountedConfigMaps, foundConfigMap)
+		} else if !errors.IsNotFound(getErr) {
+			return requeueOrNot, getErr
+		}
+	}
+	for _, secretName := range volumeSecrets {
+		foundSecret := &corev1.Secret{}
+		if getErr := r.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: instance.Namespace}, foundSecret); getErr == nil {
+			mountedSecrets = append(mountedSecrets, foundSecret)
+		} else if !errors.IsNotFound(getErr) {
+			return requeueOrNot, getErr
+		}
+	}
+	if len(mountedConfigMaps)+len(mountedSecrets) > 0 {
+		reconcileConfigInfo[util.AdditionalConfigMd5Annotation] = util.MountedConfigMd5(mountedConfigMaps, mountedSecrets)
+	}
+
+	// The Solr pods cannot start without the ConfigMaps and Secrets that their env vars are loaded from, so report any that are missing
+	if err = r.reportMissingEnvFromSources(instance, &newStatus); err != nil {
+		return requeueOrNot, err
 	}
 
-	if reconcileConfigInfo[util.SolrXmlFile] == "" {
-		// no user provided solr.xml, so create the default
+	// Generate the ConfigMap when there is no user provided solr.xml, or when it must hold an inline log4j2.xml
+	// A missing providedConfigMap must not be replaced by the default solr.xml
+	if !providedConfigMapMissing && (reconcileConfigInfo[util.SolrXmlFile] == "" || instance.Spec.SolrLogXml != "") {
 		configMap := util.GenerateConfigMap(instance)
 
-		reconcileConfigInfo[util.SolrXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(configMap.Data[util.SolrXmlFile])))
-		reconcileConfigInfo[util.SolrXmlFile] = configMap.Name
+		if reconcileConfigInfo[util.SolrXmlFile] == "" {
+			// no user provided solr.xml, so use the default
+			reconcileConfigInfo[util.SolrXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(configMap.Data[util.SolrXmlFile])))
+			reconcileConfigInfo[util.SolrXmlFile] = configMap.Name
+		}
+
+		// an inline log4j2.xml takes precedence over one in the providedConfigMap
+		if logXml, hasLogXml := configMap.Data[util.LogXmlFile]; hasLogXml {
+			delete(reconcileConfigInfo, util.LogXmlMd5Annotation)
+			if !strings.Contains(logXml, "monitorInterval=") {
+				// stored in the pod spec annotations on the statefulset so that we get a restart when the log config changes
+				reconcileConfigInfo[util.LogXmlMd5Annotation] = fmt.Sprintf("%x", md5.Sum([]byte(logXml)))
+			} // else log4j will automatically refresh for us, so no restart needed
+			reconcileConfigInfo[util.LogXmlFile] = configMap.Name
+		}
 
 		// Check if the ConfigMap already exists
 		configMapLogger := logger.WithValues("configMap", configMap.Name)
@@ -296,6 +589,11 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			}
 		}
 
+		// the operator needs its own credentials for a user-provided security.json
+		if sec.BootstrapSecurityJson != nil && sec.BasicAuthSecret == "" {
+			return requeueOrNot, fmt.Errorf("'solrSecurity.basicAuthSecret' must be provided when using 'solrSecurity.bootstrapSecurityJson'")
+		}
+
 		ctx := context.TODO()
 		basicAuthSecret := &corev1.Secret{}
 
@@ -310,6 +608,18 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 				return requeueOrNot, err
 			}
 
+			// stash the user-provided security.json, so that the setup-zk initContainer can bootstrap it in ZK
+			if sec.BootstrapSecurityJson != nil {
+				securityJsonSecret := &corev1.Secret{}
+				if err := r.Get(ctx, types.NamespacedName{Name: sec.BootstrapSecurityJson.Name, Namespace: instance.Namespace}, securityJsonSecret); err != nil {
+					return requeueOrNot, err
+				}
+				securityJson, hasSecurityJson := securityJsonSecret.Data[sec.BootstrapSecurityJson.Key]
+				if !hasSecurityJson || len(securityJson) == 0 {
+					return requeueOrNot, fmt.Errorf("%s key not found in the bootstrap security.json secret %s", sec.BootstrapSecurityJson.Key, securityJsonSecret.Name)
+				}
+				reconcileConfigInfo[util.SecurityJsonFile] = string(securityJson)
+			}
 		} else {
 			// We're supplying a secret with random passwords and a default security.json
 			// since we randomly generate the passwords, we need to lookup the secret first and only create if not exist
@@ -362,32 +672,54 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	// Only create stateful set if zkConnectionString can be found (must contain host and port)
 	if !strings.Contains(newStatus.ZkConnectionString(), ":") {
 		blockReconciliationOfStatefulSet = true
+		message := "The Zookeeper connection string is not yet available"
+		if solrCloudConditionChanged(instance, solr.SolrCloudZookeeperConnected, false, "WaitingForZookeeper", message) {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "WaitingForZookeeper", "Waiting for the Zookeeper connection string to be available before creating the StatefulSet")
+		}
+		setSolrCloudCondition(instance, &newStatus, solr.SolrCloudZookeeperConnected, false, "WaitingForZookeeper", message)
+		// The ZookeeperCluster triggers a reconcile when it changes, this is only a fallback for changes that are missed
+		updateRequeueAfter(&requeueOrNot, r.backoffForCondition(instance, solr.SolrCloudZookeeperConnected))
+	} else {
+		r.resetBackoffForCondition(instance, solr.SolrCloudZookeeperConnected)
+		setSolrCloudCondition(instance, &newStatus, solr.SolrCloudZookeeperConnected, true, "ZookeeperAvailable", "Connecting to Zookeeper at "+newStatus.ZkConnectionString())
+	}
+	if instance.Spec.SolrTLS == nil {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudTLSReady)
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudTLSHostnamesCovered)
 	}
 
 	tlsCertMd5 := ""
 	needsPkcs12InitContainer := false // flag if the StatefulSet needs an additional initCont to create PKCS12 keystore
+	var uncoveredTLSNodes map[string]bool
 	// don't start reconciling TLS until we have ZK connectivity, avoids TLS code having to check for ZK
 	if !blockReconciliationOfStatefulSet && instance.Spec.SolrTLS != nil {
-		foundTLSSecret, err := r.verifyTLSSecretConfig(instance.Spec.SolrTLS.PKCS12Secret.Name, instance.Namespace, instance.Spec.SolrTLS.KeyStorePasswordSecret)
+		foundTLSSecret, foundPasswordSecret, err := r.verifyTLSSecretConfig(instance.Spec.SolrTLS.PKCS12Secret.Name, instance.Namespace, instance.Spec.SolrTLS.KeyStorePasswordSecret)
+		if err == nil {
+			// If the keystore is not in the TLS secret, then it is generated from the PEM encoded certificate and key using an initContainer
+			needsPkcs12InitContainer, err = util.NeedsPkcs12InitContainer(instance.Spec.SolrTLS, foundTLSSecret)
+		}
 		if err != nil {
-			return requeueOrNot, err
+			if solrCloudConditionChanged(instance, solr.SolrCloudTLSReady, false, "TLSSecretNotReady", err.Error()) {
+				r.Recorder.Eventf(instance, corev1.EventTypeWarning, "TLSSecretNotReady", "TLS secret %s is not ready: %s", instance.Spec.SolrTLS.PKCS12Secret.Name, err)
+			}
+			setSolrCloudCondition(instance, &newStatus, solr.SolrCloudTLSReady, false, "TLSSecretNotReady", err.Error())
+			r.updateStatusConditions(instance, newStatus.Conditions, logger)
+			// The secret may still be being issued, such as by cert-manager, so check on it again with a growing backoff instead of failing
+			logger.Info("Waiting for the TLS secret to be ready", "secret", instance.Spec.SolrTLS.PKCS12Secret.Name, "reason", err.Error())
+			updateRequeueAfter(&requeueOrNot, r.backoffForCondition(instance, solr.SolrCloudTLSReady))
+			return requeueOrNot, nil
 		} else {
 			// We have a watch on secrets, so will get notified when the secret changes (such as after cert renewal)
 			// capture the hash of the secret and stash in an annotation so that pods get restarted if the cert changes
 			if instance.Spec.SolrTLS.RestartOnTLSSecretUpdate {
-				if tlsCertBytes, ok := foundTLSSecret.Data[util.TLSCertKey]; ok {
-					tlsCertMd5 = fmt.Sprintf("%x", md5.Sum(tlsCertBytes))
-				} else {
-					return requeueOrNot, fmt.Errorf("%s key not found in TLS secret %s, cannot watch for updates to"+
-						" the cert without this data but 'solrTLS.restartOnTLSSecretUpdate' is enabled!",
-						util.TLSCertKey, foundTLSSecret.Name)
-				}
+				// The keystore password is only read when Solr starts, so track the version of the user-provided password secret as well.
+				// The version is used, rather than a hash of the password, so that nothing derived from the password ends up in the pod spec.
+				reconcileConfigInfo[util.SolrTlsPasswordVersionAnnotation] = foundPasswordSecret.ResourceVersion
+				tlsCertMd5 = util.TLSSecretMd5(instance.Spec.SolrTLS, foundTLSSecret)
 			}
 
-			if _, ok := foundTLSSecret.Data[instance.Spec.SolrTLS.PKCS12Secret.Key]; !ok {
-				// the keystore.p12 key is not in the TLS secret, indicating we need to create it using an initContainer
-				needsPkcs12InitContainer = true
-			}
+			// Connections to any hostname of the SolrCloud that the certificate is not valid for will fail, so report them
+			uncoveredTLSNodes = r.reconcileTLSHostnames(instance, &newStatus, foundTLSSecret)
 		}
 
 		if instance.Spec.SolrTLS.TrustStoreSecret != nil {
@@ -396,102 +728,209 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			if passwordSecret == nil {
 				passwordSecret = instance.Spec.SolrTLS.KeyStorePasswordSecret
 			}
-			_, err := r.verifyTLSSecretConfig(instance.Spec.SolrTLS.TrustStoreSecret.Name, instance.Namespace, passwordSecret)
+			foundTrustStoreSecret, _, err := r.verifyTLSSecretConfig(instance.Spec.SolrTLS.TrustStoreSecret.Name, instance.Namespace, passwordSecret)
+			if err != nil {
+				if solrCloudConditionChanged(instance, solr.SolrCloudTLSReady, false, "TrustStoreSecretNotReady", err.Error()) {
+					r.Recorder.Eventf(instance, corev1.EventTypeWarning, "TLSSecretNotReady", "TrustStore secret %s is not ready: %s", instance.Spec.SolrTLS.TrustStoreSecret.Name, err)
+				}
+				setSolrCloudCondition(instance, &newStatus, solr.SolrCloudTLSReady, false, "TrustStoreSecretNotReady", err.Error())
+				r.updateStatusConditions(instance, newStatus.Conditions, logger)
+				logger.Info("Waiting for the TrustStore secret to be ready", "secret", instance.Spec.SolrTLS.TrustStoreSecret.Name, "reason", err.Error())
+				updateRequeueAfter(&requeueOrNot, r.backoffForCondition(instance, solr.SolrCloudTLSReady))
+				return requeueOrNot, nil
+			}
+
+			// capture the hash of the truststore as well, so that pods get restarted if the truststore changes
+			if instance.Spec.SolrTLS.RestartOnTLSSecretUpdate {
+				if trustStoreBytes, ok := foundTrustStoreSecret.Data[instance.Spec.SolrTLS.TrustStoreSecret.Key]; ok {
+					reconcileConfigInfo[util.SolrTlsTrustStoreMd5Annotation] = fmt.Sprintf("%x", md5.Sum(trustStoreBytes))
+				} else {
+					return requeueOrNot, fmt.Errorf("%s key not found in truststore secret %s",
+						instance.Spec.SolrTLS.TrustStoreSecret.Key, foundTrustStoreSecret.Name)
+				}
+			}
+		}
+
+		if instance.Spec.SolrTLS.ClientCertSecret != "" {
+			// the operator must present a client cert when calling the Solr API (mTLS).
+			// The client itself is resolved whenever Solr is called, this makes sure that the secret can be used.
+			clientCertSecret := &corev1.Secret{}
+			err := r.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.SolrTLS.ClientCertSecret, Namespace: instance.Namespace}, clientCertSecret)
 			if err != nil {
 				return requeueOrNot, err
 			}
+			if _, err = util.MTLSHttpClientForCloud(instance, clientCertSecret); err != nil {
+				return requeueOrNot, err
+			}
+		} else {
+			util.RemoveMTLSHttpClientForCloud(instance)
+		}
+		r.resetBackoffForCondition(instance, solr.SolrCloudTLSReady)
+		setSolrCloudCondition(instance, &newStatus, solr.SolrCloudTLSReady, true, "TLSSecretsReady", "The TLS secrets are ready to be used")
+	} else if instance.Spec.SolrTLS == nil {
+		util.RemoveMTLSHttpClientForCloud(instance)
+	}
+
+	// PVCs stay pending while their StorageClass does not exist, so report any missing StorageClasses and check them again later
+	if missingStorageClasses, err := r.reconcileStorageClasses(instance, &newStatus); err != nil {
+		return requeueOrNot, err
+	} else if missingStorageClasses {
+		updateRequeueAfter(&requeueOrNot, r.requeueIntervals().MissingDependency)
+	}
+
+	// The collections of a deleted SolrCloud are drained before its PVCs are cleaned up, and before the SolrCloud can be removed
+	draining, err := r.reconcileDrainFinalizer(instance, &newStatus, basicAuthHeader, logger)
+	if err != nil {
+		return requeueOrNot, err
+	} else if draining {
+		updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Poll)
+	}
+
+	// The Zookeeper data of a deleted SolrCloud is only removed once its collections have been drained
+	if !draining {
+		if cleaningUpZookeeper, err := r.reconcileZookeeperCleanupFinalizer(instance, &newStatus, logger); err != nil {
+			return requeueOrNot, err
+		} else if cleaningUpZookeeper {
+			updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Poll)
+		}
+		// The Solr pods are stopped before the chroot is removed, so that they cannot recreate it. Keep them stopped until the SolrCloud is gone.
+		if !instance.ObjectMeta.DeletionTimestamp.IsZero() && instance.Spec.ZookeeperRef != nil && instance.Spec.ZookeeperRef.CleanupZookeeperData {
+			blockReconciliationOfStatefulSet = true
 		}
 	}
 
 	pvcLabelSelector := make(map[string]string, 0)
-	var statefulSetStatus appsv1.StatefulSetStatus
+	statefulSetStatuses := map[string]appsv1.StatefulSetStatus{}
 
 	if !blockReconciliationOfStatefulSet {
-		// Generate StatefulSet
-		statefulSet := util.GenerateStatefulSet(instance, &newStatus, hostNameIpMap, reconcileConfigInfo, needsPkcs12InitContainer, tlsCertMd5)
-
-		// Check if the StatefulSet already exists
-		statefulSetLogger := logger.WithValues("statefulSet", statefulSet.Name)
-		foundStatefulSet := &appsv1.StatefulSet{}
-		err = r.Get(context.TODO(), types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace}, foundStatefulSet)
+		// Generate the StatefulSet of the SolrCloud, and one StatefulSet for each of its node pools
+		statefulSets := []*appsv1.StatefulSet{util.GenerateStatefulSet(instance, &newStatus, hostNameIpMap, reconcileConfigInfo, needsPkcs12InitContainer, tlsCertMd5)}
+		for _, nodePool := range instance.Spec.NodePools {
+			statefulSets = append(statefulSets, util.GenerateNodePoolStatefulSet(instance, nodePool, &newStatus, hostNameIpMap, reconcileConfigInfo, needsPkcs12InitContainer, tlsCertMd5))
+		}
 
-		// Set the annotation for a scheduled restart, if necessary.
-		if nextRestartAnnotation, reconcileWaitDuration, err := util.ScheduleNextRestart(instance.Spec.UpdateStrategy.RestartSchedule, foundStatefulSet.Spec.Template.Annotations); err != nil {
-			logger.Error(err, "Cannot parse restartSchedule cron: %s", instance.Spec.UpdateStrategy.RestartSchedule)
-		} else {
-			if nextRestartAnnotation != "" {
-				// Set the new restart time annotation
-				statefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation] = nextRestartAnnotation
-				// TODO: Create event for the CRD.
-			} else if existingRestartAnnotation, exists := foundStatefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation]; exists {
-				// Keep the existing nextRestart annotation if it exists and we aren't setting a new one.
-				statefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation] = existingRestartAnnotation
+		var recreateRequired []string
+		remainingStatefulSets := make(map[string]int, len(statefulSets))
+		for i, statefulSet := range statefulSets {
+			foundStatus, statefulSetPVCLabels, recreateReason, err := r.reconcileStatefulSet(instance, statefulSet, &newStatus, &requeueOrNot, basicAuthHeader, uncoveredTLSNodes, logger)
+			if err != nil {
+				return requeueOrNot, err
 			}
-			if reconcileWaitDuration != nil {
-				// Set the requeueAfter if it has not been set, or is greater than the time we need to wait to restart again
-				updateRequeueAfter(&requeueOrNot, *reconcileWaitDuration)
+			if recreateReason != "" {
+				recreateRequired = append(recreateRequired, recreateReason)
 			}
-		}
-
-		// Update or Create the StatefulSet
-		if err != nil && errors.IsNotFound(err) {
-			statefulSetLogger.Info("Creating StatefulSet")
-			if err = controllerutil.SetControllerReference(instance, statefulSet, r.scheme); err == nil {
-				err = r.Create(context.TODO(), statefulSet)
+			if foundStatus != nil {
+				statefulSetStatuses[statefulSet.Name] = *foundStatus
 			}
-			// Find which labels the PVCs will be using, to use for the finalizer
-			pvcLabelSelector = statefulSet.Spec.Selector.MatchLabels
-		} else if err == nil {
-			statefulSetStatus = foundStatefulSet.Status
-			// Find which labels the PVCs will be using, to use for the finalizer
-			pvcLabelSelector = foundStatefulSet.Spec.Selector.MatchLabels
-
-			// Check to see if the StatefulSet needs an update
-			var needsUpdate bool
-			needsUpdate, err = util.OvertakeControllerRef(instance, foundStatefulSet, r.scheme)
-			needsUpdate = util.CopyStatefulSetFields(statefulSet, foundStatefulSet, statefulSetLogger) || needsUpdate
+			remainingStatefulSets[statefulSet.Name] = int(*statefulSet.Spec.Replicas)
+			// The PVC labels of the SolrCloud's StatefulSet also match the PVCs of its node pools
+			if i == 0 {
+				pvcLabelSelector = statefulSetPVCLabels
+			}
+		}
 
-			// Update the found StatefulSet and write the result back if there are any changes
-			if needsUpdate && err == nil {
-				statefulSetLogger.Info("Updating StatefulSet")
-				err = r.Update(context.TODO(), foundStatefulSet)
+		// Only emit events for StatefulSets that newly need to be recreated, the others are kept in the condition while they persist
+		if len(recreateRequired) > 0 {
+			previousCondition := meta.FindStatusCondition(instance.Status.Conditions, solr.SolrCloudStatefulSetRecreateRequired)
+			for _, recreateReason := range recreateRequired {
+				if previousCondition == nil || previousCondition.Status != metav1.ConditionTrue || !strings.Contains(previousCondition.Message, recreateReason) {
+					r.Recorder.Event(instance, corev1.EventTypeWarning, "StatefulSetRecreateRequired", recreateReason)
+				}
 			}
+			setSolrCloudCondition(instance, &newStatus, solr.SolrCloudStatefulSetRecreateRequired, true, "ImmutableFieldsChanged", strings.Join(recreateRequired, "; "))
+		} else {
+			meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudStatefulSetRecreateRequired)
 		}
-		if err != nil {
+
+		if vacatingNodePools, err := r.cleanupRemovedNodePools(instance, &newStatus, remainingStatefulSets, basicAuthHeader, logger); err != nil {
 			return requeueOrNot, err
+		} else if vacatingNodePools {
+			updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Poll)
 		}
 	} else {
-		// If we are blocking the reconciliation of the statefulSet, we still want to find information about it.
-		foundStatefulSet := &appsv1.StatefulSet{}
-		err = r.Get(context.TODO(), types.NamespacedName{Name: instance.StatefulSetName(), Namespace: instance.Namespace}, foundStatefulSet)
-		if err == nil {
-			// Find the status
-			statefulSetStatus = foundStatefulSet.Status
-			// Find which labels the PVCs will be using, to use for the finalizer
-			pvcLabelSelector = foundStatefulSet.Spec.Selector.MatchLabels
-		} else if !errors.IsNotFound(err) {
-			return requeueOrNot, err
+		// If we are blocking the reconciliation of the statefulSets, we still want to find information about them.
+		statefulSetNames := []string{instance.StatefulSetName()}
+		for _, nodePool := range instance.Spec.NodePools {
+			statefulSetNames = append(statefulSetNames, instance.NodePoolStatefulSetName(nodePool.Name))
+		}
+		for i, statefulSetName := range statefulSetNames {
+			foundStatefulSet := &appsv1.StatefulSet{}
+			err = r.Get(context.TODO(), types.NamespacedName{Name: statefulSetName, Namespace: instance.Namespace}, foundStatefulSet)
+			if err == nil {
+				// Find the status
+				statefulSetStatuses[statefulSetName] = foundStatefulSet.Status
+				// Find which labels the PVCs will be using, to use for the finalizer
+				if i == 0 {
+					pvcLabelSelector = foundStatefulSet.Spec.Selector.MatchLabels
+				}
+			} else if !errors.IsNotFound(err) {
+				return requeueOrNot, err
+			}
 		}
 	}
 
 	// Do not reconcile the storage finalizer unless we have PVC Labels that we know the Solr data PVCs are using.
 	// Otherwise it will delete all PVCs possibly
-	if len(pvcLabelSelector) > 0 {
-		if err := r.reconcileStorageFinalizer(instance, pvcLabelSelector, logger); err != nil {
-			logger.Error(err, "Cannot delete PVCs while garbage collecting after deletion.")
-			updateRequeueAfter(&requeueOrNot, time.Second*15)
+	// The PVCs are also left alone while the collections of a deleted SolrCloud are being drained
+	if len(pvcLabelSelector) > 0 && !draining {
+		if needsStorageFinalizerReconcile(instance) {
+			if err := r.reconcileStorageFinalizer(instance, pvcLabelSelector, logger); err != nil {
+				logger.Error(err, "Cannot delete PVCs while garbage collecting after deletion.")
+				updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Retry)
+			}
+		}
+
+		// Expand the existing PVCs if the requested storage size has been increased, since the StatefulSet's volumeClaimTemplates cannot be changed
+		if instance.UsesPersistentStorage() && instance.ObjectMeta.DeletionTimestamp.IsZero() {
+			volumeExpansionStatus, err := r.reconcileStorageExpansion(instance, pvcLabelSelector, logger)
+			if err != nil {
+				logger.Error(err, "Cannot expand PVCs for the requested storage size.")
+			}
+			if volumeExpansionStatus != nil {
+				updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Retry)
+				// Only emit an event when the expansion is newly blocked, the error is kept in the status while it persists
+				if volumeExpansionStatus.Error != "" && (instance.Status.VolumeExpansion == nil || instance.Status.VolumeExpansion.Error != volumeExpansionStatus.Error) {
+					r.Recorder.Event(instance, corev1.EventTypeWarning, "VolumeExpansionBlocked", volumeExpansionStatus.Error)
+				}
+			}
+			newStatus.VolumeExpansion = volumeExpansionStatus
 		}
 	}
 
+	// If authn enabled on Solr, we need to pass the basic auth header
+	var authHeader map[string]string
+	if basicAuthHeader != "" {
+		authHeader = map[string]string{"Authorization": basicAuthHeader}
+	}
+
 	var outOfDatePods, outOfDatePodsNotStarted []corev1.Pod
 	var availableUpdatedPodCount int
-	outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err = reconcileCloudStatus(r, instance, logger, &newStatus, statefulSetStatus)
+	outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err = reconcileCloudStatus(r, instance, logger, &newStatus, statefulSetStatuses, authHeader)
 	if err != nil {
 		return requeueOrNot, err
 	}
+	observeSolrCloudPodsUpdated(types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, int(newStatus.UpToDateNodes), len(outOfDatePods)+len(outOfDatePodsNotStarted))
+	if waitDuration := warnOnPersistentVersionSkew(r, instance, &newStatus); waitDuration != nil {
+		updateRequeueAfter(&requeueOrNot, *waitDuration)
+	}
+
+	// Make the collections read-only while readOnly is enabled, and writable again once it is disabled
+	if r.reconcileReadOnly(instance, &newStatus, authHeader, logger) {
+		updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Retry)
+	}
+
+	// Restore the operator-owned parts of a user-provided security.json, which can be changed through the Solr Security API at any time
+	if sec := instance.Spec.SolrSecurity; sec != nil && sec.ReconcileSecurityJson && sec.BootstrapSecurityJson != nil {
+		if r.reconcileSecurityJson(instance, &newStatus, []byte(reconcileConfigInfo[util.SecurityJsonFile]), authHeader, logger) {
+			updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Poll)
+		} else {
+			// Changes made through the Security API do not trigger a reconcile, so check for drift regularly
+			updateRequeueAfter(&requeueOrNot, r.requeueIntervals().MaxBackoff)
+		}
+	}
 
 	// Manage the updating of out-of-spec pods, if the Managed UpdateStrategy has been specified.
-	totalPodCount := int(*instance.Spec.Replicas)
+	totalPodCount := int(instance.TotalReplicas())
 	if instance.Spec.UpdateStrategy.Method == solr.ManagedUpdate && len(outOfDatePods)+len(outOfDatePodsNotStarted) > 0 {
 		updateLogger := logger.WithName("ManagedUpdateSelector")
 
@@ -502,29 +941,77 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			logger.Info("Pod killed for update.", "pod", pod.Name, "reason", "The solr container in the pod has not yet started, thus it is safe to update.")
 		}
 
-		// If authn enabled on Solr, we need to pass the basic auth header
-		var authHeader map[string]string
-		if basicAuthHeader != "" {
-			authHeader = map[string]string{"Authorization": basicAuthHeader}
-		}
-
 		// Pick which pods should be deleted for an update.
 		// Don't exit on an error, which would only occur because of an HTTP Exception. Requeue later instead.
 		additionalPodsToUpdate, retryLater := util.DeterminePodsSafeToUpdate(instance, outOfDatePods, totalPodCount, int(newStatus.ReadyReplicas), availableUpdatedPodCount, len(outOfDatePodsNotStarted), updateLogger, authHeader)
 		podsToUpdate = append(podsToUpdate, additionalPodsToUpdate...)
 
+		// Out of date pods may have been created with a different grace period, so give them the currently configured one.
+		gracePeriod := client.GracePeriodSeconds(util.SolrTerminationGracePeriodSeconds(instance))
 		for _, pod := range podsToUpdate {
 			err = r.Delete(context.Background(), &pod, client.Preconditions{
 				UID: &pod.UID,
-			})
+			}, gracePeriod)
 			if err != nil {
 				updateLogger.Error(err, "Error while killing solr pod for update", "pod", pod.Name)
+				r.Recorder.Eventf(instance, corev1.EventTypeWarning, "PodUpdateFailed", "Error while killing pod %s for update: %s", pod.Name, err)
+			} else {
+				r.Recorder.Eventf(instance, corev1.EventTypeNormal, "PodKilledForUpdate", "Killed pod %s to update it to the latest spec", pod.Name)
+				if !r.dryRun {
+					observeSolrCloudPodKilledForUpdate(types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace})
+				}
 			}
-			// TODO: Create event for the CRD.
 		}
 		if err != nil || retryLater {
-			updateRequeueAfter(&requeueOrNot, time.Second*15)
+			updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Retry)
+		}
+	}
+
+	// Restore the requested collections from a backup, once all Solr nodes are ready
+	if instance.Spec.Restore != nil {
+		newStatus.Restore = util.RestoreStatusForCloud(instance)
+		readyForRestore := newStatus.Replicas > 0 && newStatus.ReadyReplicas == newStatus.Replicas &&
+			(instance.Spec.Restore.Repository != "" || newStatus.BackupRestoreReady)
+		if readyForRestore && !newStatus.Restore.Finished && !r.dryRun {
+			if err = r.reconcileRestore(instance, newStatus.Restore, authHeader, logger); err != nil {
+				return requeueOrNot, err
+			}
+		}
+		if !newStatus.Restore.Finished {
+			updateRequeueAfter(&requeueOrNot, r.requeueIntervals().Poll)
+		}
+	}
+
+	// Generate the PodDisruptionBudget, or remove it if it has been disabled
+	pdb := util.GeneratePodDisruptionBudget(instance)
+	pdbLogger := logger.WithValues("podDisruptionBudget", pdb.Name)
+	foundPDB := &policyv1beta1.PodDisruptionBudget{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: pdb.Name, Namespace: pdb.Namespace}, foundPDB)
+	if pdbOpts := instance.Spec.CustomSolrKubeOptions.PodDisruptionBudgetOptions; pdbOpts != nil && pdbOpts.Disabled {
+		if err == nil && metav1.IsControlledBy(foundPDB, instance) {
+			pdbLogger.Info("Deleting PodDisruptionBudget, since it has been disabled")
+			err = r.Delete(context.TODO(), foundPDB)
+		} else if errors.IsNotFound(err) {
+			err = nil
+		}
+	} else if err != nil && errors.IsNotFound(err) {
+		pdbLogger.Info("Creating PodDisruptionBudget")
+		if err = controllerutil.SetControllerReference(instance, pdb, r.scheme); err == nil {
+			err = r.Create(context.TODO(), pdb)
 		}
+	} else if err == nil {
+		var needsUpdate bool
+		needsUpdate, err = util.OvertakeControllerRef(instance, foundPDB, r.scheme)
+		needsUpdate = util.CopyPodDisruptionBudgetFields(pdb, foundPDB, pdbLogger) || needsUpdate
+
+		// Update the found PodDisruptionBudget and write the result back if there are any changes
+		if needsUpdate && err == nil {
+			pdbLogger.Info("Updating PodDisruptionBudget")
+			err = r.Update(context.TODO(), foundPDB)
+		}
+	}
+	if err != nil {
+		return requeueOrNot, err
 	}
 
 	extAddressabilityOpts := instance.Spec.SolrAddressability.External
@@ -569,48 +1056,489 @@ func (r *SolrCloudReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	return requeueOrNot, nil
 }
 
-func reconcileCloudStatus(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, logger logr.Logger, newStatus *solr.SolrCloudStatus, statefulSetStatus appsv1.StatefulSetStatus) (outOfDatePods []corev1.Pod, outOfDatePodsNotStarted []corev1.Pod, availableUpdatedPodCount int, err error) {
-	foundPods := &corev1.PodList{}
-	selectorLabels := solrCloud.SharedLabels()
-	selectorLabels["technology"] = solr.SolrTechnologyLabel
-
-	labelSelector := labels.SelectorFromSet(selectorLabels)
-	listOps := &client.ListOptions{
-		Namespace:     solrCloud.Namespace,
-		LabelSelector: labelSelector,
+// reconcileReadOnly makes the collections of the SolrCloud read-only while readOnly is enabled, and makes them writable again once it is disabled.
+// The collections that are made read-only are kept in the status, so that only those are made writable again.
+// The returned retry flag is true when the readOnly property of some collections could not be changed, and should be tried again.
+func (r *SolrCloudReconciler) reconcileReadOnly(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, httpHeaders map[string]string, logger logr.Logger) (retry bool) {
+	newStatus.ReadOnlyCollections = cloud.Status.ReadOnlyCollections
+	if !cloud.Spec.ReadOnly && len(newStatus.ReadOnlyCollections) == 0 {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudReadOnly)
+		return false
+	}
+	// The collections can only be changed once Solr is available, which triggers another reconcile
+	if r.dryRun || newStatus.ReadyReplicas == 0 {
+		return false
 	}
 
-	err = r.List(context.TODO(), foundPods, listOps)
+	states, err := util.GetCollectionStates(cloud, httpHeaders)
 	if err != nil {
-		return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
+		logger.Error(err, "Cannot fetch the collections to change their readOnly property, retrying later")
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudReadOnly, false, "SolrUnavailable", "Cannot fetch the collections from Solr: "+err.Error())
+		return true
+	}
+	makeReadOnly, makeWritable, readOnlyCollections := util.ReadOnlyCollectionChanges(cloud.Spec.ReadOnly, states, newStatus.ReadOnlyCollections)
+
+	madeReadOnly, madeWritable := 0, 0
+	var failures []string
+	for _, collection := range makeReadOnly {
+		if err = util.ModifyCollection(cloud, collection, map[string]string{"readOnly": "true"}, httpHeaders); err == nil {
+			readOnlyCollections = append(readOnlyCollections, collection)
+			madeReadOnly += 1
+		} else {
+			logger.Error(err, "Cannot make the collection read-only, retrying later", "collection", collection)
+			failures = append(failures, fmt.Sprintf("%s: %s", collection, err))
+		}
+	}
+	for _, collection := range makeWritable {
+		if err = util.ModifyCollection(cloud, collection, map[string]string{"readOnly": "false"}, httpHeaders); err != nil {
+			logger.Error(err, "Cannot make the collection writable again, retrying later", "collection", collection)
+			failures = append(failures, fmt.Sprintf("%s: %s", collection, err))
+			readOnlyCollections = append(readOnlyCollections, collection)
+		} else {
+			madeWritable += 1
+		}
 	}
+	sort.Strings(readOnlyCollections)
+	newStatus.ReadOnlyCollections = readOnlyCollections
 
-	var otherVersions []string
-	nodeNames := make([]string, len(foundPods.Items))
-	nodeStatusMap := map[string]solr.SolrNodeStatus{}
-	backupRestoreReadyPods := 0
+	if madeReadOnly > 0 {
+		r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "CollectionsMadeReadOnly", "Made %d collections read-only", madeReadOnly)
+	}
+	if madeWritable > 0 {
+		r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "CollectionsMadeWritable", "Made %d collections writable again", madeWritable)
+	}
+	failed := len(failures)
+	if failed > 0 {
+		r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "ReadOnlyUpdateFailed", "Could not change the readOnly property of %d collections, retrying later: %s", failed, strings.Join(failures, "; "))
+	}
 
-	updateRevision := statefulSetStatus.UpdateRevision
+	if cloud.Spec.ReadOnly && failed == 0 {
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudReadOnly, true, "CollectionsReadOnly", fmt.Sprintf("%d collections have been made read-only by the Solr Operator", len(readOnlyCollections)))
+	} else if cloud.Spec.ReadOnly {
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudReadOnly, false, "MakingCollectionsReadOnly", fmt.Sprintf("%d collections could not be made read-only yet: %s", failed, strings.Join(failures, "; ")))
+	} else if failed > 0 {
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudReadOnly, false, "MakingCollectionsWritable", fmt.Sprintf("%d collections have not been made writable again yet: %s", len(readOnlyCollections), strings.Join(failures, "; ")))
+	} else if len(readOnlyCollections) > 0 {
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudReadOnly, false, "MakingCollectionsWritable", fmt.Sprintf("%d collections have not been made writable again yet", len(readOnlyCollections)))
+	} else {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudReadOnly)
+	}
+	return failed > 0
+}
 
-	newStatus.Replicas = statefulSetStatus.Replicas
-	newStatus.UpToDateNodes = int32(0)
-	newStatus.ReadyReplicas = int32(0)
-	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
-		MatchLabels: selectorLabels,
-	})
-	if err != nil {
-		logger.Error(err, "Error getting SolrCloud PodSelector labels")
-		return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
+// reconcileSecurityJson merges the users, user-roles and permissions of the user-provided security.json into the security.json that Solr uses, whenever they have drifted.
+// Solr's Security API cannot set hashed credentials, so the merged security.json is written to Zookeeper by a Job instead, the same way that the setup-zk initContainer bootstraps it.
+// The returned retry flag is true while the merged security.json is being written, or when the security.json could not be checked.
+func (r *SolrCloudReconciler) reconcileSecurityJson(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, securityJson []byte, httpHeaders map[string]string, logger logr.Logger) (retry bool) {
+	// The security.json can only be checked once Solr is available, which triggers another reconcile
+	if r.dryRun || newStatus.ReadyReplicas == 0 || len(securityJson) == 0 {
+		return false
 	}
-	newStatus.PodSelector = selector.String()
-	for idx, p := range foundPods.Items {
-		nodeNames[idx] = p.Name
-		nodeStatus := solr.SolrNodeStatus{}
-		nodeStatus.Name = p.Name
-		nodeStatus.NodeName = p.Spec.NodeName
+
+	// Wait for the previous Job to finish, it is removed afterwards so that the security.json can be checked again
+	foundJob := &batchv1.Job{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: cloud.SecurityJsonJobName(), Namespace: cloud.Namespace}, foundJob)
+	if err == nil {
+		failed := false
+		for _, condition := range foundJob.Status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+				failed = true
+			}
+		}
+		if foundJob.Status.Succeeded == 0 && !failed {
+			return true
+		}
+		if foundJob.DeletionTimestamp.IsZero() {
+			if failed {
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "SecurityJsonUpdateFailed", "The Job %s could not write the merged security.json to Zookeeper, retrying", foundJob.Name)
+			}
+			if err = r.Delete(context.TODO(), foundJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "Cannot delete the finished security.json Job", "job", foundJob.Name)
+			}
+		}
+		return true
+	} else if !errors.IsNotFound(err) {
+		logger.Error(err, "Cannot fetch the security.json Job, retrying later")
+		return true
+	}
+
+	current, err := util.GetSecurityJson(cloud, httpHeaders)
+	if err != nil {
+		logger.Error(err, "Cannot fetch the security.json from Solr, retrying later")
+		return true
+	}
+	merged, changed, err := util.MergeSecurityJson(current, securityJson)
+	if err != nil {
+		logger.Error(err, "Cannot merge the bootstrapSecurityJson into the security.json of the SolrCloud")
+		return false
+	} else if !changed {
+		return false
+	}
+
+	securityJsonSecret := util.GenerateSecurityJsonSecret(cloud, merged)
+	if err = controllerutil.SetControllerReference(cloud, securityJsonSecret, r.scheme); err != nil {
+		logger.Error(err, "Cannot own the merged security.json secret")
+		return true
+	}
+	foundSecret := &corev1.Secret{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: securityJsonSecret.Name, Namespace: securityJsonSecret.Namespace}, foundSecret)
+	if err != nil && errors.IsNotFound(err) {
+		err = r.Create(context.TODO(), securityJsonSecret)
+	} else if err == nil {
+		foundSecret.Data = securityJsonSecret.Data
+		err = r.Update(context.TODO(), foundSecret)
+	}
+	if err != nil {
+		logger.Error(err, "Cannot store the merged security.json, retrying later")
+		return true
+	}
+
+	job := util.GenerateSecurityJsonJob(cloud)
+	if err = controllerutil.SetControllerReference(cloud, job, r.scheme); err == nil {
+		err = r.Create(context.TODO(), job)
+	}
+	if err != nil {
+		logger.Error(err, "Cannot create the Job to write the merged security.json to Zookeeper, retrying later")
+		return true
+	}
+	logger.Info("Restoring the users, roles and permissions of the bootstrapSecurityJson that have drifted", "job", job.Name)
+	r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "UpdatingSecurityJson", "Restoring the users, roles and permissions of the bootstrapSecurityJson, which have drifted in the security.json of the SolrCloud")
+	return true
+}
+
+// reconcileRestore starts the restores of the collections given in the SolrCloud's restore options, and checks on the ones in progress.
+// Collections that already exist are never overwritten, so a restore is only ever started once for each collection.
+func (r *SolrCloudReconciler) reconcileRestore(cloud *solr.SolrCloud, restoreStatus *solr.SolrCloudRestoreStatus, httpHeaders map[string]string, logger logr.Logger) error {
+	restore := cloud.Spec.Restore
+	restoreLogger := logger.WithValues("backup", restore.BackupName)
+	tru := true
+	fals := false
+
+	restoreStatus.Finished = true
+	for i, restoreCollection := range restore.Collections {
+		collectionStatus := &restoreStatus.Collections[i]
+		if collectionStatus.Finished {
+			continue
+		}
+
+		// Always check on the restore first, since it might have been started without the status being saved
+		finished, successful, asyncStatus, message, err := util.CheckRestoreForCollection(cloud, collectionStatus.Collection, restore.BackupName, httpHeaders)
+		if err != nil {
+			return err
+		}
+		if asyncStatus == "notfound" {
+			if collectionStatus.AsyncRestoreStatus != "" {
+				// The restore was started, but Solr no longer knows about it
+				collectionStatus.Finished = true
+				collectionStatus.Successful = &fals
+				collectionStatus.Message = "The restore request could not be found in Solr"
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "CollectionRestoreFailed", "Restore of collection %s from backup %s could not be found in Solr", collectionStatus.Collection, restore.BackupName)
+				continue
+			}
+
+			_, exists, err := util.GetCollectionState(cloud, collectionStatus.Collection, httpHeaders)
+			if err != nil {
+				return err
+			}
+			if exists {
+				restoreLogger.Info("Not restoring collection, since it already exists", "collection", collectionStatus.Collection)
+				collectionStatus.Finished = true
+				collectionStatus.Successful = &fals
+				collectionStatus.Message = "The collection already exists, so it was not restored"
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "CollectionRestoreSkipped", "Collection %s already exists, so it was not restored from backup %s", collectionStatus.Collection, restore.BackupName)
+				continue
+			}
+
+			started, err := util.StartRestoreForCollection(cloud, restoreCollection, restore.BackupName, restore.Repository, httpHeaders)
+			if err != nil {
+				return err
+			}
+			if started {
+				collectionStatus.AsyncRestoreStatus = "submitted"
+				collectionStatus.Message = ""
+				r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "CollectionRestoreStarted", "Started restoring collection %s from backup %s", collectionStatus.Collection, restore.BackupName)
+			} else if util.RecordFailedRestoreAttempt(collectionStatus, "Solr refused to start the restore") {
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "CollectionRestoreFailed", "Solr refused to start the restore of collection %s from backup %s %d times, giving up", collectionStatus.Collection, restore.BackupName, collectionStatus.FailedAttempts)
+				continue
+			} else {
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "CollectionRestoreNotStarted", "Solr refused to start the restore of collection %s from backup %s, attempt %d of %d", collectionStatus.Collection, restore.BackupName, collectionStatus.FailedAttempts, util.MaxRestoreAttempts)
+			}
+			restoreStatus.Finished = false
+			continue
+		}
+
+		if asyncStatus != "" {
+			collectionStatus.AsyncRestoreStatus = asyncStatus
+		}
+		if finished {
+			collectionStatus.Finished = true
+			if successful {
+				collectionStatus.Successful = &tru
+				r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "CollectionRestored", "Restored collection %s from backup %s", collectionStatus.Collection, restore.BackupName)
+			} else {
+				collectionStatus.Successful = &fals
+				collectionStatus.Message = message
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "CollectionRestoreFailed", "Restore of collection %s from backup %s failed: %s", collectionStatus.Collection, restore.BackupName, message)
+			}
+		} else {
+			restoreStatus.Finished = false
+		}
+	}
+	return nil
+}
+
+// reconcileStatefulSet creates or updates the given StatefulSet of the SolrCloud.
+// The status of the StatefulSet, if it already existed, and the labels that its PVCs use are returned.
+// If the StatefulSet has to be recreated by the user for a change to take effect, the reason is returned as well.
+func (r *SolrCloudReconciler) reconcileStatefulSet(instance *solr.SolrCloud, statefulSet *appsv1.StatefulSet, newStatus *solr.SolrCloudStatus, requeueOrNot *reconcile.Result, basicAuthHeader string, uncoveredTLSNodes map[string]bool, logger logr.Logger) (statefulSetStatus *appsv1.StatefulSetStatus, pvcLabelSelector map[string]string, recreateRequired string, err error) {
+	// Check if the StatefulSet already exists
+	statefulSetLogger := logger.WithValues("statefulSet", statefulSet.Name)
+	foundStatefulSet := &appsv1.StatefulSet{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace}, foundStatefulSet)
+
+	// Set the annotation for a scheduled restart, if necessary.
+	if nextRestartAnnotation, reconcileWaitDuration, err := util.ScheduleNextRestart(instance.Spec.UpdateStrategy.RestartSchedule, foundStatefulSet.Spec.Template.Annotations); err != nil {
+		logger.Error(err, "Cannot parse restartSchedule cron: %s", instance.Spec.UpdateStrategy.RestartSchedule)
+	} else {
+		if nextRestartAnnotation != "" {
+			// Set the new restart time annotation
+			statefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation] = nextRestartAnnotation
+			// TODO: Create event for the CRD.
+		} else if existingRestartAnnotation, exists := foundStatefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation]; exists {
+			// Keep the existing nextRestart annotation if it exists and we aren't setting a new one.
+			statefulSet.Spec.Template.Annotations[util.SolrScheduledRestartAnnotation] = existingRestartAnnotation
+		}
+		if reconcileWaitDuration != nil {
+			// Set the requeueAfter if it has not been set, or is greater than the time we need to wait to restart again
+			updateRequeueAfter(requeueOrNot, *reconcileWaitDuration)
+		}
+	}
+
+	// Hold the StatefulSet at its current size until all replicas have been moved off of the pods that will be removed
+	// Replicas are not moved during a dry run, so the scale down is planned as if they already were
+	if err == nil && !r.dryRun && instance.Spec.Scaling.VacatePodsOnScaleDown && foundStatefulSet.Spec.Replicas != nil && *foundStatefulSet.Spec.Replicas > *statefulSet.Spec.Replicas {
+		var authHeader map[string]string
+		if basicAuthHeader != "" {
+			authHeader = map[string]string{"Authorization": basicAuthHeader}
+		}
+		vacated, scaleDownStatus, vacateErr := util.VacatePodsForScaleDown(instance, statefulSet.Name, int(*statefulSet.Spec.Replicas), int(*foundStatefulSet.Spec.Replicas), authHeader, statefulSetLogger)
+		if vacateErr != nil {
+			// Do not scale down if the replicas cannot be confirmed to have moved, retry later instead
+			statefulSetLogger.Error(vacateErr, "Error moving replicas off of the pods that will be removed by a scale down, delaying the scale down")
+		}
+		if !vacated {
+			statefulSetLogger.Info("Delaying scale down until all replicas have been moved off of the pods that will be removed", "currentReplicas", *foundStatefulSet.Spec.Replicas, "desiredReplicas", *statefulSet.Spec.Replicas)
+			// Only one scale down is reported at a time, the others will be reported once it is finished
+			if newStatus.ScaleDown == nil {
+				newStatus.ScaleDown = scaleDownStatus
+			}
+			statefulSet.Spec.Replicas = foundStatefulSet.Spec.Replicas
+			updateRequeueAfter(requeueOrNot, r.requeueIntervals().Poll)
+		}
+	}
+
+	// Hold a scale up before the first new pod that the TLS certificate is not valid for, since it could not talk to the other Solr nodes.
+	// The TLS secret is watched, so the scale up continues once the certificate has been re-issued with the hostnames of the new pods.
+	if err == nil && foundStatefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas > *foundStatefulSet.Spec.Replicas {
+		for ordinal := *foundStatefulSet.Spec.Replicas; ordinal < *statefulSet.Spec.Replicas; ordinal++ {
+			if nodeName := fmt.Sprintf("%s-%d", statefulSet.Name, ordinal); uncoveredTLSNodes[nodeName] {
+				statefulSetLogger.Info("Delaying scale up until the TLS certificate is valid for the new pods", "currentReplicas", *foundStatefulSet.Spec.Replicas, "desiredReplicas", *statefulSet.Spec.Replicas, "pod", nodeName)
+				// The uncovered hostnames are reported by the TLSHostnamesCovered condition, so only report the held scale up when it changes
+				if hostnamesCondition := meta.FindStatusCondition(newStatus.Conditions, solr.SolrCloudTLSHostnamesCovered); hostnamesCondition != nil &&
+					solrCloudConditionChanged(instance, solr.SolrCloudTLSHostnamesCovered, false, hostnamesCondition.Reason, hostnamesCondition.Message) {
+					r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ScaleUpWaitingForTLSCertificate",
+						"Scaling StatefulSet %s to %d pods, instead of %d, until the TLS certificate is valid for the hostnames of pod %s", statefulSet.Name, ordinal, *statefulSet.Spec.Replicas, nodeName)
+				}
+				heldReplicas := ordinal
+				statefulSet.Spec.Replicas = &heldReplicas
+				updateRequeueAfter(requeueOrNot, r.requeueIntervals().MissingDependency)
+				break
+			}
+		}
+	}
+
+	// Update or Create the StatefulSet
+	if err != nil && errors.IsNotFound(err) {
+		statefulSetLogger.Info("Creating StatefulSet")
+		if err = controllerutil.SetControllerReference(instance, statefulSet, r.scheme); err == nil {
+			err = r.Create(context.TODO(), statefulSet)
+		}
+		if err == nil {
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "CreatedStatefulSet", "Created StatefulSet %s", statefulSet.Name)
+		}
+		// Find which labels the PVCs will be using, to use for the finalizer
+		pvcLabelSelector = statefulSet.Spec.Selector.MatchLabels
+	} else if err == nil {
+		statefulSetStatus = &foundStatefulSet.Status
+		// Find which labels the PVCs will be using, to use for the finalizer
+		pvcLabelSelector = foundStatefulSet.Spec.Selector.MatchLabels
+
+		// The PodManagementPolicy cannot be updated, so the StatefulSet must be recreated for a change to take effect.
+		// Only explicitly requested policies are enforced, so that StatefulSets created with an older default are left alone.
+		if stsOpts := instance.Spec.CustomSolrKubeOptions.StatefulSetOptions; stsOpts != nil && stsOpts.PodManagementPolicy != "" &&
+			foundStatefulSet.Spec.PodManagementPolicy != statefulSet.Spec.PodManagementPolicy {
+			if !stsOpts.RecreateOnPodManagementPolicyChange {
+				statefulSetLogger.Info("The StatefulSet must be recreated to change its PodManagementPolicy", "from", foundStatefulSet.Spec.PodManagementPolicy, "to", statefulSet.Spec.PodManagementPolicy)
+				recreateRequired = fmt.Sprintf("StatefulSet %s must be deleted and recreated to change its podManagementPolicy from %s to %s, or statefulSetOptions.recreateOnPodManagementPolicyChange must be set",
+					foundStatefulSet.Name, foundStatefulSet.Spec.PodManagementPolicy, statefulSet.Spec.PodManagementPolicy)
+			} else {
+				// The StatefulSet will be recreated once it has been deleted
+				updateRequeueAfter(requeueOrNot, r.requeueIntervals().Poll)
+				if foundStatefulSet.DeletionTimestamp.IsZero() {
+					statefulSetLogger.Info("Deleting StatefulSet, without its pods, to recreate it with a new PodManagementPolicy", "from", foundStatefulSet.Spec.PodManagementPolicy, "to", statefulSet.Spec.PodManagementPolicy)
+					err = r.Delete(context.TODO(), foundStatefulSet, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+					if err == nil {
+						r.Recorder.Eventf(instance, corev1.EventTypeNormal, "RecreatingStatefulSet", "Recreating StatefulSet %s to change its podManagementPolicy to %s", foundStatefulSet.Name, statefulSet.Spec.PodManagementPolicy)
+					}
+				}
+				return statefulSetStatus, pvcLabelSelector, "", err
+			}
+		}
+
+		// The volumeClaimTemplates cannot be updated either, such as when separate log storage is added to an existing SolrCloud.
+		// The pod template would then mount volumes that do not exist, so the current pod template is kept until the StatefulSet is recreated.
+		if foundNames, newNames := util.VolumeClaimTemplateNames(foundStatefulSet), util.VolumeClaimTemplateNames(statefulSet); !reflect.DeepEqual(foundNames, newNames) {
+			statefulSetLogger.Info("The StatefulSet must be recreated to change its volumeClaimTemplates, keeping the current pod template", "from", foundNames, "to", newNames)
+			vctRecreateRequired := fmt.Sprintf("StatefulSet %s must be deleted and recreated to change its volumeClaimTemplates from [%s] to [%s]",
+				foundStatefulSet.Name, strings.Join(foundNames, ", "), strings.Join(newNames, ", "))
+			if recreateRequired == "" {
+				recreateRequired = vctRecreateRequired
+			} else {
+				recreateRequired += "; " + vctRecreateRequired
+			}
+			statefulSet.Spec.Template = *foundStatefulSet.Spec.Template.DeepCopy()
+		}
+
+		// Check to see if the StatefulSet needs an update
+		var needsUpdate bool
+		needsUpdate, err = util.OvertakeControllerRef(instance, foundStatefulSet, r.scheme)
+		needsUpdate = util.CopyStatefulSetFields(statefulSet, foundStatefulSet, statefulSetLogger) || needsUpdate
+
+		// Update the found StatefulSet and write the result back if there are any changes
+		if needsUpdate && err == nil {
+			statefulSetLogger.Info("Updating StatefulSet")
+			err = r.Update(context.TODO(), foundStatefulSet)
+			if err == nil {
+				r.Recorder.Eventf(instance, corev1.EventTypeNormal, "UpdatedStatefulSet", "Updated StatefulSet %s", foundStatefulSet.Name)
+			}
+		}
+	}
+	return statefulSetStatus, pvcLabelSelector, recreateRequired, err
+}
+
+// cleanupRemovedNodePools deletes the StatefulSets of node pools that have been removed from the SolrCloud.
+// If vacatePodsOnScaleDown is enabled, the replicas on the pods of a removed node pool are first moved to the remainingStatefulSets,
+// and the StatefulSet is kept until the pods have been vacated. Otherwise the replicas are lost along with the pods.
+func (r *SolrCloudReconciler) cleanupRemovedNodePools(instance *solr.SolrCloud, newStatus *solr.SolrCloudStatus, remainingStatefulSets map[string]int, basicAuthHeader string, logger logr.Logger) (vacating bool, err error) {
+	nodePools := map[string]bool{}
+	for _, nodePool := range instance.Spec.NodePools {
+		nodePools[nodePool.Name] = true
+	}
+
+	foundStatefulSets := &appsv1.StatefulSetList{}
+	if err = r.List(context.TODO(), foundStatefulSets, client.InNamespace(instance.Namespace), client.MatchingLabels(instance.SharedLabels())); err != nil {
+		return false, err
+	}
+	for _, statefulSet := range foundStatefulSets.Items {
+		poolName, isNodePool := statefulSet.Labels[util.SolrNodePoolLabel]
+		if !isNodePool || nodePools[poolName] || !metav1.IsControlledBy(&statefulSet, instance) {
+			continue
+		}
+		statefulSetLogger := logger.WithValues("statefulSet", statefulSet.Name, "nodePool", poolName)
+
+		// Replicas are not moved during a dry run, so the deletion is planned as if they already were
+		if !r.dryRun && instance.Spec.Scaling.VacatePodsOnScaleDown && statefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas > 0 {
+			var authHeader map[string]string
+			if basicAuthHeader != "" {
+				authHeader = map[string]string{"Authorization": basicAuthHeader}
+			}
+			vacated, scaleDownStatus, vacateErr := util.VacatePodsOfRemovedNodePool(instance, statefulSet.Name, int(*statefulSet.Spec.Replicas), remainingStatefulSets, authHeader, statefulSetLogger)
+			if vacateErr != nil {
+				// Do not delete the StatefulSet if the replicas cannot be confirmed to have moved, retry later instead
+				statefulSetLogger.Error(vacateErr, "Error moving replicas off of the pods of the removed node pool, delaying the deletion of its StatefulSet")
+			}
+			if !vacated {
+				statefulSetLogger.Info("Delaying the deletion of the StatefulSet of the removed node pool until all replicas have been moved off of its pods")
+				// Only one scale down is reported at a time, the others will be reported once it is finished
+				if newStatus.ScaleDown == nil {
+					newStatus.ScaleDown = scaleDownStatus
+				}
+				vacating = true
+				continue
+			}
+		}
+
+		statefulSetLogger.Info("Deleting StatefulSet of removed node pool")
+		if err = r.Delete(context.TODO(), &statefulSet); err != nil && !errors.IsNotFound(err) {
+			return vacating, err
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "DeletedStatefulSet", "Deleted StatefulSet %s of removed node pool %s", statefulSet.Name, poolName)
+	}
+	return vacating, nil
+}
+
+func reconcileCloudStatus(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, logger logr.Logger, newStatus *solr.SolrCloudStatus, statefulSetStatuses map[string]appsv1.StatefulSetStatus, httpHeaders map[string]string) (outOfDatePods []corev1.Pod, outOfDatePodsNotStarted []corev1.Pod, availableUpdatedPodCount int, err error) {
+	foundPods := &corev1.PodList{}
+	selectorLabels := solrCloud.SharedLabels()
+	selectorLabels["technology"] = solr.SolrTechnologyLabel
+
+	labelSelector := labels.SelectorFromSet(selectorLabels)
+	listOps := &client.ListOptions{
+		Namespace:     solrCloud.Namespace,
+		LabelSelector: labelSelector,
+	}
+
+	err = r.List(context.TODO(), foundPods, listOps)
+	if err != nil {
+		return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
+	}
+
+	var otherVersions, failingPods []string
+	nodeNames := make([]string, len(foundPods.Items))
+	nodeStatusMap := map[string]solr.SolrNodeStatus{}
+	backupRestoreReadyPods := 0
+
+	newStatus.Replicas = int32(0)
+	readyStatefulSetReplicas := int32(0)
+	for _, statefulSetStatus := range statefulSetStatuses {
+		newStatus.Replicas += statefulSetStatus.Replicas
+		readyStatefulSetReplicas += statefulSetStatus.ReadyReplicas
+	}
+	newStatus.UpToDateNodes = int32(0)
+	newStatus.ReadyReplicas = int32(0)
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels: selectorLabels,
+	})
+	if err != nil {
+		logger.Error(err, "Error getting SolrCloud PodSelector labels")
+		return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, err
+	}
+	newStatus.PodSelector = selector.String()
+
+	// Optionally only consider updated pods available when all of their Solr replicas are active.
+	// If the cluster state cannot be fetched, fall back to the Kubernetes readiness of the pods.
+	var nodesWithInactiveReplicas map[string]bool
+	if solrCloud.Spec.UpdateStrategy.Method == solr.ManagedUpdate && solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.RequireActiveReplicas && readyStatefulSetReplicas > 0 {
+		if nodesWithInactiveReplicas, err = util.FindSolrNodesWithInactiveReplicas(solrCloud, httpHeaders); err != nil {
+			logger.Error(err, "Error retrieving cluster status, using the Kubernetes readiness of pods to determine their availability")
+			err = nil
+		}
+	}
+	for idx, p := range foundPods.Items {
+		nodeNames[idx] = p.Name
+		nodeStatus := solr.SolrNodeStatus{}
+		nodeStatus.Name = p.Name
+		nodeStatus.NodeName = p.Spec.NodeName
+		nodeStatus.NodePool = p.Labels[util.SolrNodePoolLabel]
 		nodeStatus.InternalAddress = solrCloud.UrlScheme() + "://" + solrCloud.InternalNodeUrl(nodeStatus.Name, true)
-		if solrCloud.Spec.SolrAddressability.External != nil && !solrCloud.Spec.SolrAddressability.External.HideNodes {
-			nodeStatus.ExternalAddress = solrCloud.UrlScheme() + "://" + solrCloud.ExternalNodeUrl(nodeStatus.Name, solrCloud.Spec.SolrAddressability.External.DomainName, true)
+		if extOpts := solrCloud.Spec.SolrAddressability.External; extOpts != nil && !extOpts.HideNodes {
+			if extOpts.Method == solr.NodePort {
+				nodeStatus.ExternalAddress = externalNodePortAddress(r, solrCloud, p)
+			} else if extOpts.Method == solr.LoadBalancer {
+				nodeStatus.ExternalAddress = externalLoadBalancerAddress(r, solrCloud, p.Name, solrCloud.NodePort())
+			} else {
+				nodeStatus.ExternalAddress = solrCloud.UrlScheme() + "://" + solrCloud.ExternalNodeUrl(nodeStatus.Name, extOpts.DomainName, true)
+			}
 		}
 		if len(p.Status.ContainerStatuses) > 0 {
 			// The first container should always be running solr
@@ -629,6 +1557,9 @@ func reconcileCloudStatus(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, log
 		}
 		if nodeStatus.Ready {
 			newStatus.ReadyReplicas += 1
+		} else if failure := util.FailingPodContainer(&p); failure != "" {
+			// Otherwise a bad image or a crashing container only shows up as a pod that is not ready, or as a pod that is replaced over and over by managed updates
+			failingPods = append(failingPods, failure)
 		}
 
 		// Get Volumes for backup/restore
@@ -640,11 +1571,15 @@ func reconcileCloudStatus(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, log
 			}
 		}
 
-		// A pod is out of date if it's revision label is not equal to the statefulSetStatus' updateRevision.
+		// A pod is out of date if it's revision label is not equal to the updateRevision of the StatefulSet that owns it.
+		updateRevision := ""
+		if owner := metav1.GetControllerOf(&p); owner != nil {
+			updateRevision = statefulSetStatuses[owner.Name].UpdateRevision
+		}
 		nodeStatus.SpecUpToDate = p.Labels["controller-revision-hash"] == updateRevision
 		if nodeStatus.SpecUpToDate {
 			newStatus.UpToDateNodes += 1
-			if nodeStatus.Ready {
+			if nodeStatus.Ready && !nodesWithInactiveReplicas[util.SolrNodeName(solrCloud, p)] {
 				// If the pod is up-to-date and is available, increase the counter
 				availableUpdatedPodCount += 1
 			}
@@ -677,7 +1612,7 @@ func reconcileCloudStatus(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, log
 		newStatus.SolrNodes[idx] = nodeStatusMap[nodeName]
 	}
 
-	if backupRestoreReadyPods == int(*solrCloud.Spec.Replicas) && backupRestoreReadyPods > 0 {
+	if backupRestoreReadyPods == int(solrCloud.TotalReplicas()) && backupRestoreReadyPods > 0 {
 		newStatus.BackupRestoreReady = true
 	}
 
@@ -689,17 +1624,208 @@ func reconcileCloudStatus(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, log
 		newStatus.TargetVersion = ""
 		newStatus.Version = solrCloud.Spec.SolrImage.Tag
 	}
+	newStatus.Versions = util.SolrVersionCounts(newStatus.SolrNodes)
 
 	newStatus.InternalCommonAddress = solrCloud.UrlScheme() + "://" + solrCloud.InternalCommonUrl(true)
-	if solrCloud.Spec.SolrAddressability.External != nil && !solrCloud.Spec.SolrAddressability.External.HideCommon {
+	// A common service of type LoadBalancer is addressed through its load balancer, once it has been provisioned.
+	// The NodePort method exposes the common service on every Kubernetes node, so there is no single external common address.
+	if util.CommonServiceType(solrCloud) == corev1.ServiceTypeLoadBalancer {
+		if extAddress := externalLoadBalancerAddress(r, solrCloud, solrCloud.CommonServiceName(), solrCloud.Spec.SolrAddressability.CommonServicePort); extAddress != "" {
+			newStatus.ExternalCommonAddress = &extAddress
+		}
+	} else if solrCloud.Spec.SolrAddressability.External != nil && !solrCloud.Spec.SolrAddressability.External.HideCommon && solrCloud.Spec.SolrAddressability.External.Method != solr.NodePort {
 		extAddress := solrCloud.UrlScheme() + "://" + solrCloud.ExternalCommonUrl(solrCloud.Spec.SolrAddressability.External.DomainName, true)
 		newStatus.ExternalCommonAddress = &extAddress
 	}
 
+	if newStatus.ReadyReplicas >= solrCloud.TotalReplicas() {
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudReady, true, "PodsReady", fmt.Sprintf("%d of %d Solr pods are ready", newStatus.ReadyReplicas, solrCloud.TotalReplicas()))
+	} else {
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudReady, false, "PodsNotReady", fmt.Sprintf("%d of %d Solr pods are ready", newStatus.ReadyReplicas, solrCloud.TotalReplicas()))
+	}
+	if outOfDatePodCount := len(outOfDatePods) + len(outOfDatePodsNotStarted); outOfDatePodCount > 0 {
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudUpgrading, true, "PodsOutOfDate", fmt.Sprintf("%d Solr pods are not running the latest pod spec", outOfDatePodCount))
+	} else {
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudUpgrading, false, "PodsUpToDate", "All Solr pods are running the latest pod spec")
+	}
+	if len(otherVersions) > 0 {
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudVersionSkew, true, "VersionsDiffer", fmt.Sprintf("%d Solr pods are not running version %s", len(otherVersions), solrCloud.Spec.SolrImage.Tag))
+	} else {
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudVersionSkew, false, "VersionsMatch", fmt.Sprintf("All Solr pods are running version %s", solrCloud.Spec.SolrImage.Tag))
+	}
+	if len(failingPods) > 0 {
+		sort.Strings(failingPods)
+		// Only emit events for newly failing pods, the failures are kept in the condition while they persist
+		previousCondition := meta.FindStatusCondition(solrCloud.Status.Conditions, solr.SolrCloudPodsFailing)
+		for _, failure := range failingPods {
+			if previousCondition == nil || previousCondition.Status != metav1.ConditionTrue || !strings.Contains(previousCondition.Message, failure) {
+				r.Recorder.Event(solrCloud, corev1.EventTypeWarning, "PodFailing", failure)
+			}
+		}
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudPodsFailing, true, "ContainersFailing", strings.Join(failingPods, "; "))
+	} else {
+		setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudPodsFailing, false, "NoContainersFailing", "No Solr pods have containers that are failing to start")
+	}
+
 	return outOfDatePods, outOfDatePodsNotStarted, availableUpdatedPodCount, nil
 }
 
-func reconcileNodeService(r *SolrCloudReconciler, logger logr.Logger, instance *solr.SolrCloud, nodeName string) (err error, ip string) {
+// warnOnPersistentVersionSkew emits a warning event when Solr pods have been running a version of solr other than the requested one
+// for longer than the versionSkewWarningSeconds, which usually means that the upgrade of the SolrCloud is stuck.
+// The VersionSkew condition then gets the VersionSkewPersisting reason, so that the event is only emitted once while the skew lasts.
+// If the skew has not yet lasted that long, the time left until the warning is returned so that the SolrCloud can be requeued.
+func warnOnPersistentVersionSkew(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus) (waitDuration *time.Duration) {
+	warningSeconds := solr.DefaultVersionSkewWarningSeconds
+	if solrCloud.Spec.UpdateStrategy.VersionSkewWarningSeconds != nil {
+		warningSeconds = *solrCloud.Spec.UpdateStrategy.VersionSkewWarningSeconds
+	}
+	condition := meta.FindStatusCondition(newStatus.Conditions, solr.SolrCloudVersionSkew)
+	if warningSeconds <= 0 || condition == nil || condition.Status != metav1.ConditionTrue {
+		return nil
+	}
+	warningAfter := time.Duration(warningSeconds) * time.Second
+	remaining := time.Until(condition.LastTransitionTime.Add(warningAfter))
+	if remaining > 0 {
+		return &remaining
+	}
+	if previous := meta.FindStatusCondition(solrCloud.Status.Conditions, solr.SolrCloudVersionSkew); previous == nil || previous.Status != metav1.ConditionTrue || previous.Reason != "VersionSkewPersisting" {
+		r.Recorder.Eventf(solrCloud, corev1.EventTypeWarning, "VersionSkewPersisting", "Solr pods have been running different versions of solr for more than %s, the upgrade may be stuck: %s", warningAfter, condition.Message)
+	}
+	setSolrCloudCondition(solrCloud, newStatus, solr.SolrCloudVersionSkew, true, "VersionSkewPersisting", condition.Message)
+	return nil
+}
+
+// reportMissingEnvFromSources reports the required envFrom ConfigMaps and Secrets of the SolrCloud that do not exist, through a condition and a warning event.
+// The StatefulSet is still reconciled, the Solr pods will start once the sources are created.
+func (r *SolrCloudReconciler) reportMissingEnvFromSources(instance *solr.SolrCloud, newStatus *solr.SolrCloudStatus) error {
+	envFromConfigMaps, envFromSecrets := util.RequiredEnvFromConfigMapsAndSecrets(instance)
+	if len(envFromConfigMaps)+len(envFromSecrets) == 0 {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudEnvFromSourcesFound)
+		return nil
+	}
+	var missingSources []string
+	for _, configMapName := range envFromConfigMaps {
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: configMapName, Namespace: instance.Namespace}, &corev1.ConfigMap{}); errors.IsNotFound(err) {
+			missingSources = append(missingSources, "ConfigMap "+configMapName)
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, secretName := range envFromSecrets {
+		if err := r.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: instance.Namespace}, &corev1.Secret{}); errors.IsNotFound(err) {
+			missingSources = append(missingSources, "Secret "+secretName)
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(missingSources) > 0 {
+		message := "envFrom sources not found: " + strings.Join(missingSources, ", ")
+		if solrCloudConditionChanged(instance, solr.SolrCloudEnvFromSourcesFound, false, "EnvFromSourceNotFound", message) {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "EnvFromSourceNotFound", "The envFrom sources of the Solr container do not exist, Solr pods cannot start until they are created: %s", strings.Join(missingSources, ", "))
+		}
+		setSolrCloudCondition(instance, newStatus, solr.SolrCloudEnvFromSourcesFound, false, "EnvFromSourceNotFound", message)
+		return nil
+	}
+	setSolrCloudCondition(instance, newStatus, solr.SolrCloudEnvFromSourcesFound, true, "EnvFromSourcesFound", "All envFrom sources of the Solr container exist")
+	return nil
+}
+
+// setSolrCloudCondition sets the given condition in the new status of the SolrCloud.
+// The transition time of the condition is only changed when its status changes.
+func setSolrCloudCondition(solrCloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, conditionType string, status bool, reason string, message string) {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&newStatus.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: solrCloud.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// solrCloudConditionChanged returns whether the given condition differs from the one in the current status of the SolrCloud.
+// Events for ongoing states are only emitted when this is the case, since the state itself is kept in the condition.
+func solrCloudConditionChanged(solrCloud *solr.SolrCloud, conditionType string, status bool, reason string, message string) bool {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+	previousCondition := meta.FindStatusCondition(solrCloud.Status.Conditions, conditionType)
+	return previousCondition == nil || previousCondition.Status != conditionStatus || previousCondition.Reason != reason || previousCondition.Message != message
+}
+
+// updateStatusConditions persists the given conditions to the status of the SolrCloud, without changing the rest of the status.
+// This is used when reconciliation cannot continue, and therefore the full status cannot be computed.
+func (r *SolrCloudReconciler) updateStatusConditions(solrCloud *solr.SolrCloud, conditions []metav1.Condition, logger logr.Logger) {
+	if reflect.DeepEqual(solrCloud.Status.Conditions, conditions) {
+		return
+	}
+	solrCloud.Status.Conditions = conditions
+	if err := r.Status().Update(context.TODO(), solrCloud); err != nil {
+		logger.Error(err, "Error updating SolrCloud Status conditions")
+	}
+}
+
+// maxDryRunChangesInCondition limits the number of planned changes listed in the DryRun condition, all of them are still emitted as events.
+const maxDryRunChangesInCondition = 20
+
+// dryRunReconcile plans the reconciliation of the SolrCloud with a client that records its writes instead of making them.
+// The planned changes are reported through events and the DryRun condition, which are the only things written for the SolrCloud.
+func (r *SolrCloudReconciler) dryRunReconcile(instance *solr.SolrCloud, logger logr.Logger) (ctrl.Result, error) {
+	logger.Info("Planning the reconciliation of the SolrCloud, the dry run annotation is set")
+	plan := &util.DryRunPlan{}
+	planner := &SolrCloudReconciler{
+		Client:   util.NewDryRunClient(r.Client, plan),
+		scheme:   r.scheme,
+		Log:      r.Log,
+		UseZkCRD: r.UseZkCRD,
+		// The planned reconcile waits on the same intervals and backoffs as the real one
+		RequeueIntervals:          r.RequeueIntervals,
+		transientConditionBackoff: r.conditionBackoff(),
+		// The events of the planned reconcile would announce changes that are never made, the planned changes are reported below instead
+		Recorder: &record.FakeRecorder{},
+		dryRun:   true,
+	}
+	requeueOrNot, err := planner.reconcileCloud(instance.DeepCopy(), util.NewDryRunLogger(logger, plan))
+	if err != nil {
+		// The changes that were planned before the error are still reported
+		logger.Error(err, "Error while planning the reconciliation of the SolrCloud")
+	}
+
+	reason := "NoChangesPlanned"
+	message := "No changes would be made to the resources of the SolrCloud"
+	if len(plan.Changes) > 0 {
+		reason = "ChangesPlanned"
+		descriptions := make([]string, 0, maxDryRunChangesInCondition)
+		for i, change := range plan.Changes {
+			if i == maxDryRunChangesInCondition {
+				descriptions = append(descriptions, fmt.Sprintf("and %d more", len(plan.Changes)-i))
+				break
+			}
+			descriptions = append(descriptions, change.String())
+		}
+		message = fmt.Sprintf("%d changes would be made: %s", len(plan.Changes), strings.Join(descriptions, "; "))
+	}
+
+	// The plan is made again every reconcile, so only emit the events when it changes
+	if previous := meta.FindStatusCondition(instance.Status.Conditions, solr.SolrCloudDryRun); previous == nil || previous.Message != message {
+		for _, change := range plan.Changes {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "DryRunChange", change.String())
+		}
+	}
+
+	dryRunStatus := solr.SolrCloudStatus{
+		Conditions: instance.Status.DeepCopy().Conditions,
+	}
+	setSolrCloudCondition(instance, &dryRunStatus, solr.SolrCloudDryRun, true, reason, message)
+	r.updateStatusConditions(instance, dryRunStatus.Conditions, logger)
+	return requeueOrNot, err
+}
+
+func reconcileNodeService(r *SolrCloudReconciler, logger logr.Logger, instance *solr.SolrCloud, nodeName string) (err error, ip string, loadBalancerAddress string) {
 	// Generate Node Service
 	service := util.GenerateNodeService(instance, nodeName)
 
@@ -713,10 +1839,15 @@ func reconcileNodeService(r *SolrCloudReconciler, logger logr.Logger, instance *
 			err = r.Create(context.TODO(), service)
 		}
 	} else if err == nil {
+		// The IP of a Service that is being recreated is about to change, so it cannot be used
+		var recreating, needsUpdate bool
+		if recreating, err = deleteServiceToRecreate(r, service, foundService, nodeServiceLogger); recreating {
+			return err, "", ""
+		}
 		ip = foundService.Spec.ClusterIP
+		loadBalancerAddress = util.LoadBalancerAddress(foundService)
 
 		// Check to see if the Service needs an update
-		var needsUpdate bool
 		needsUpdate, err = util.OvertakeControllerRef(instance, foundService, r.scheme)
 		needsUpdate = util.CopyServiceFields(service, foundService, nodeServiceLogger) || needsUpdate
 
@@ -727,10 +1858,92 @@ func reconcileNodeService(r *SolrCloudReconciler, logger logr.Logger, instance *
 		}
 	}
 	if err != nil {
-		return err, ip
+		return err, ip, loadBalancerAddress
+	}
+
+	return nil, ip, loadBalancerAddress
+}
+
+// deleteServiceToRecreate deletes the existing Service if it cannot be updated in place to match the generated Service, see util.ServiceRecreateReason.
+// The Services are owned by the reconciled resources, so their deletion triggers a reconcile that creates them again.
+func deleteServiceToRecreate(c client.Client, service *corev1.Service, foundService *corev1.Service, logger logr.Logger) (recreating bool, err error) {
+	reason := util.ServiceRecreateReason(service, foundService)
+	if reason == "" {
+		return false, nil
+	}
+	if foundService.DeletionTimestamp.IsZero() {
+		logger.Info("Deleting Service to recreate it, since it cannot be updated in place", "reason", reason)
+		err = c.Delete(context.TODO(), foundService)
+	}
+	return true, err
+}
+
+// externalNodePortAddress returns the external address of the given Solr pod when using the NodePort method.
+// This uses the IP of the Kubernetes node that the pod is scheduled on, and the nodePort allocated to the pod's node service.
+// An empty address is returned if either is not yet available.
+func externalNodePortAddress(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, pod corev1.Pod) string {
+	if pod.Spec.NodeName == "" {
+		return ""
 	}
+	service := &corev1.Service{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, service); err != nil || len(service.Spec.Ports) == 0 || service.Spec.Ports[0].NodePort == 0 {
+		return ""
+	}
+	node := &corev1.Node{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		return ""
+	}
+	address := util.ExternalNodePortAddress(node, service.Spec.Ports[0].NodePort)
+	if address == "" {
+		return ""
+	}
+	return solrCloud.UrlScheme() + "://" + address
+}
+
+// externalLoadBalancerAddress returns the external address of the load balancer provisioned for the given service when using the LoadBalancer method.
+// An empty address is returned if the load balancer is not yet available.
+func externalLoadBalancerAddress(r *SolrCloudReconciler, solrCloud *solr.SolrCloud, serviceName string, port int) string {
+	service := &corev1.Service{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: serviceName, Namespace: solrCloud.Namespace}, service); err != nil {
+		return ""
+	}
+	address := util.LoadBalancerAddress(service)
+	if address == "" {
+		return ""
+	}
+	return solrCloud.UrlScheme() + "://" + util.UrlHost(address) + solrCloud.PortToSuffix(port)
+}
 
-	return nil, ip
+// waitForNodeServiceIPs reports that the given Solr nodes do not yet have an IP address, or load balancer address, for their node service,
+// and determines whether the reconciliation of the StatefulSet should wait for them.
+// The StatefulSet is blocked until the nodeServiceIPTimeoutSeconds have passed since the SolrCloud started waiting, or indefinitely if no timeout is given.
+// If the timeout has not yet passed, the time left to wait is returned so that the SolrCloud can be requeued.
+func waitForNodeServiceIPs(r *SolrCloudReconciler, instance *solr.SolrCloud, newStatus *solr.SolrCloudStatus, nodesWithoutServiceIPs []string) (block bool, waitDuration *time.Duration) {
+	message := fmt.Sprintf("Waiting for the node services of %s to be assigned addresses", strings.Join(nodesWithoutServiceIPs, ", "))
+
+	timeoutSeconds := instance.Spec.SolrAddressability.External.NodeServiceIPTimeoutSeconds
+	if timeoutSeconds != nil {
+		waitingSince := time.Now()
+		if condition := meta.FindStatusCondition(newStatus.Conditions, solr.SolrCloudNodeServiceIPsAssigned); condition != nil && condition.Status == metav1.ConditionFalse {
+			waitingSince = condition.LastTransitionTime.Time
+		}
+		remaining := time.Until(waitingSince.Add(time.Duration(*timeoutSeconds) * time.Second))
+		if remaining <= 0 {
+			timeoutMessage := message + ", the StatefulSet is no longer waiting for them"
+			if solrCloudConditionChanged(instance, solr.SolrCloudNodeServiceIPsAssigned, false, "NodeServiceIPTimeout", timeoutMessage) {
+				r.Recorder.Eventf(instance, corev1.EventTypeWarning, "NodeServiceIPTimeout", "Timed out after %ds waiting for node service addresses, reconciling the StatefulSet without waiting for: %s", *timeoutSeconds, strings.Join(nodesWithoutServiceIPs, ", "))
+			}
+			setSolrCloudCondition(instance, newStatus, solr.SolrCloudNodeServiceIPsAssigned, false, "NodeServiceIPTimeout", timeoutMessage)
+			return false, nil
+		}
+		waitDuration = &remaining
+	}
+
+	if solrCloudConditionChanged(instance, solr.SolrCloudNodeServiceIPsAssigned, false, "WaitingForNodeServiceIPs", message) {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "WaitingForNodeServiceIPs", message+" before reconciling the StatefulSet")
+	}
+	setSolrCloudCondition(instance, newStatus, solr.SolrCloudNodeServiceIPsAssigned, false, "WaitingForNodeServiceIPs", message)
+	return true, waitDuration
 }
 
 func reconcileZk(r *SolrCloudReconciler, logger logr.Logger, instance *solr.SolrCloud, newStatus *solr.SolrCloudStatus) error {
@@ -741,7 +1954,7 @@ func reconcileZk(r *SolrCloudReconciler, logger logr.Logger, instance *solr.Solr
 	} else if zkRef.ProvidedZookeeper != nil {
 		pzk := zkRef.ProvidedZookeeper
 		// Generate ZookeeperCluster
-		if !useZkCRD {
+		if !r.UseZkCRD {
 			return errors.NewBadRequest("Cannot create a Zookeeper Cluster, as the Solr Operator is not configured to use the Zookeeper CRD")
 		}
 		zkCluster := util.GenerateZookeeperCluster(instance, pzk)
@@ -787,14 +2000,305 @@ func reconcileZk(r *SolrCloudReconciler, logger logr.Logger, instance *solr.Solr
 	return nil
 }
 
+// reconcileDrainFinalizer adds the drain finalizer to SolrClouds that have deletionOptions, and removes it from those that do not.
+// Once the SolrCloud is deleted, its collections are drained before the finalizer is removed.
+// The finalizer is also removed when the drain times out, or when the force delete annotation is set on the SolrCloud.
+// The returned draining flag is true while the SolrCloud is kept around to finish its drain.
+func (r *SolrCloudReconciler) reconcileDrainFinalizer(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, basicAuthHeader string, logger logr.Logger) (draining bool, err error) {
+	hasFinalizer := util.ContainsString(cloud.ObjectMeta.Finalizers, util.SolrDrainFinalizer)
+	if cloud.ObjectMeta.DeletionTimestamp.IsZero() {
+		if cloud.Spec.DeletionOptions != nil && !hasFinalizer {
+			cloud.ObjectMeta.Finalizers = append(cloud.ObjectMeta.Finalizers, util.SolrDrainFinalizer)
+			err = r.Update(context.Background(), cloud)
+		} else if cloud.Spec.DeletionOptions == nil && hasFinalizer {
+			logger.Info("Removing drain finalizer for SolrCloud")
+			cloud.ObjectMeta.Finalizers = util.RemoveString(cloud.ObjectMeta.Finalizers, util.SolrDrainFinalizer)
+			err = r.Update(context.Background(), cloud)
+		}
+		return false, err
+	} else if !hasFinalizer {
+		return false, nil
+	}
+
+	opts := cloud.Spec.DeletionOptions
+	newStatus.Drain = cloud.Status.Drain.DeepCopy()
+	if opts == nil {
+		logger.Info("Not draining the collections of the deleted SolrCloud, since its deletionOptions have been removed")
+	} else if cloud.Annotations[util.SolrForceDeleteAnnotation] == "true" {
+		logger.Info("Not draining the collections of the deleted SolrCloud, since it is being force deleted")
+		r.Recorder.Event(cloud, corev1.EventTypeWarning, "DrainSkipped", "The collections were not drained, since the SolrCloud is being force deleted")
+	} else if time.Now().After(cloud.DeletionTimestamp.Add(time.Duration(*opts.TimeoutSeconds) * time.Second)) {
+		logger.Info("Giving up on draining the collections of the deleted SolrCloud, since the drain has timed out", "timeoutSeconds", *opts.TimeoutSeconds)
+		r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "DrainTimedOut", "The collections were not drained within %d seconds, the SolrCloud is deleted anyway", *opts.TimeoutSeconds)
+	} else if r.dryRun {
+		// Solr is not called during a dry run, so the collections are reported as still draining
+		logger.Info("Not draining the collections of the deleted SolrCloud during a dry run")
+		return true, nil
+	} else {
+		var httpHeaders map[string]string
+		if basicAuthHeader != "" {
+			httpHeaders = map[string]string{"Authorization": basicAuthHeader}
+		}
+		drained, drainErr := r.drainCollections(cloud, newStatus, httpHeaders, logger)
+		if drainErr != nil {
+			// Solr may not be available right now, so keep retrying until the drain times out
+			logger.Error(drainErr, "Error while draining the collections of the deleted SolrCloud, retrying later")
+		}
+		if !drained {
+			return true, nil
+		}
+		r.Recorder.Event(cloud, corev1.EventTypeNormal, "Drained", "All collections have been drained, the SolrCloud can be deleted")
+	}
+
+	// remove our finalizer from the list and update it.
+	cloud.ObjectMeta.Finalizers = util.RemoveString(cloud.ObjectMeta.Finalizers, util.SolrDrainFinalizer)
+	return false, r.Update(context.Background(), cloud)
+}
+
+// reconcileZookeeperCleanupFinalizer adds the Zookeeper cleanup finalizer to SolrClouds that have cleanupZookeeperData enabled, and removes it from those that do not.
+// Once the SolrCloud is deleted, a Job removes its chroot from Zookeeper before the finalizer is removed.
+// The chroot is left in place when another SolrCloud uses the same chroot, or a chroot within it, and when the skip or force delete annotation is set on the SolrCloud.
+// The Solr pods are stopped before the Job is created, since live Solr nodes would recreate znodes in the chroot while, or after, it is removed.
+// The returned cleaningUp flag is true while the SolrCloud is kept around to finish the cleanup.
+func (r *SolrCloudReconciler) reconcileZookeeperCleanupFinalizer(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, logger logr.Logger) (cleaningUp bool, err error) {
+	hasFinalizer := util.ContainsString(cloud.ObjectMeta.Finalizers, util.SolrZookeeperCleanupFinalizer)
+	cleanupEnabled := cloud.Spec.ZookeeperRef != nil && cloud.Spec.ZookeeperRef.CleanupZookeeperData
+	if cloud.ObjectMeta.DeletionTimestamp.IsZero() {
+		if cleanupEnabled && !hasFinalizer {
+			cloud.ObjectMeta.Finalizers = append(cloud.ObjectMeta.Finalizers, util.SolrZookeeperCleanupFinalizer)
+			err = r.Update(context.Background(), cloud)
+		} else if !cleanupEnabled && hasFinalizer {
+			logger.Info("Removing Zookeeper cleanup finalizer for SolrCloud")
+			cloud.ObjectMeta.Finalizers = util.RemoveString(cloud.ObjectMeta.Finalizers, util.SolrZookeeperCleanupFinalizer)
+			err = r.Update(context.Background(), cloud)
+		}
+		return false, err
+	} else if !hasFinalizer {
+		return false, nil
+	}
+
+	zkInfo := cloud.Status.ZookeeperConnectionInfo
+	if !cleanupEnabled {
+		logger.Info("Not removing the Zookeeper data of the deleted SolrCloud, since cleanupZookeeperData has been disabled")
+	} else if cloud.Annotations[util.SolrSkipZookeeperCleanupAnnotation] == "true" || cloud.Annotations[util.SolrForceDeleteAnnotation] == "true" {
+		logger.Info("Not removing the Zookeeper data of the deleted SolrCloud, since the cleanup is skipped")
+		r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "ZookeeperCleanupSkipped", "The chroot %s was not removed from Zookeeper, since the cleanup is skipped", zkInfo.ChRoot)
+	} else if zkInfo.InternalConnectionString == "" || strings.Trim(zkInfo.ChRoot, "/") == "" {
+		logger.Info("Not removing the Zookeeper data of the deleted SolrCloud, since it never connected to a Zookeeper chroot")
+	} else if sharingClouds, listErr := r.solrCloudsSharingZookeeperChroot(cloud); listErr != nil {
+		return true, listErr
+	} else if len(sharingClouds) > 0 {
+		logger.Info("Not removing the Zookeeper data of the deleted SolrCloud, since other SolrClouds use its chroot", "chroot", zkInfo.ChRoot, "solrClouds", sharingClouds)
+		r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "ZookeeperCleanupSkipped", "The chroot %s was not removed from Zookeeper, since it is also used by the SolrClouds %s", zkInfo.ChRoot, strings.Join(sharingClouds, ", "))
+	} else if stopped, stopErr := r.stopSolrPodsForZookeeperCleanup(cloud, newStatus, logger); stopErr != nil || !stopped {
+		return true, stopErr
+	} else {
+		cleanedUp, cleanupErr := r.runZookeeperCleanupJob(cloud, newStatus, logger)
+		if cleanupErr != nil || !cleanedUp {
+			return true, cleanupErr
+		}
+		r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "ZookeeperCleanedUp", "The chroot %s has been removed from Zookeeper, the SolrCloud can be deleted", zkInfo.ChRoot)
+	}
+
+	// remove our finalizer from the list and update it.
+	cloud.ObjectMeta.Finalizers = util.RemoveString(cloud.ObjectMeta.Finalizers, util.SolrZookeeperCleanupFinalizer)
+	return false, r.Update(context.Background(), cloud)
+}
+
+// solrCloudsSharingZookeeperChroot returns the namespaced names of the other SolrClouds that would lose data if the chroot of the given SolrCloud was removed.
+// Only the SolrClouds that the Solr Operator watches can be found.
+func (r *SolrCloudReconciler) solrCloudsSharingZookeeperChroot(cloud *solr.SolrCloud) (sharingClouds []string, err error) {
+	solrClouds := &solr.SolrCloudList{}
+	if err = r.List(context.TODO(), solrClouds); err != nil {
+		return nil, err
+	}
+	for _, other := range solrClouds.Items {
+		if other.UID == cloud.UID || other.Status.ZookeeperConnectionInfo.InternalConnectionString == "" {
+			continue
+		}
+		if util.SharesZookeeperChroot(cloud.Status.ZookeeperConnectionInfo, other.Status.ZookeeperConnectionInfo) {
+			sharingClouds = append(sharingClouds, other.Namespace+"/"+other.Name)
+		}
+	}
+	return sharingClouds, nil
+}
+
+// stopSolrPodsForZookeeperCleanup scales the StatefulSets of the deleted SolrCloud down to 0 pods, and reports whether all of its Solr pods are gone.
+func (r *SolrCloudReconciler) stopSolrPodsForZookeeperCleanup(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, logger logr.Logger) (stopped bool, err error) {
+	foundStatefulSets := &appsv1.StatefulSetList{}
+	if err = r.List(context.TODO(), foundStatefulSets, client.InNamespace(cloud.Namespace), client.MatchingLabels(cloud.SharedLabels())); err != nil {
+		return false, err
+	}
+	for _, statefulSet := range foundStatefulSets.Items {
+		if !metav1.IsControlledBy(&statefulSet, cloud) || (statefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas == 0) {
+			continue
+		}
+		logger.Info("Scaling down StatefulSet before removing the Zookeeper data of the deleted SolrCloud", "statefulSet", statefulSet.Name)
+		noReplicas := int32(0)
+		statefulSet.Spec.Replicas = &noReplicas
+		if err = r.Update(context.TODO(), &statefulSet); err != nil {
+			return false, err
+		}
+	}
+
+	foundPods := &corev1.PodList{}
+	selectorLabels := cloud.SharedLabels()
+	selectorLabels["technology"] = solr.SolrTechnologyLabel
+	if err = r.List(context.TODO(), foundPods, client.InNamespace(cloud.Namespace), client.MatchingLabels(selectorLabels)); err != nil {
+		return false, err
+	}
+	if len(foundPods.Items) > 0 {
+		logger.Info("Waiting for the Solr pods to stop before removing the Zookeeper data of the deleted SolrCloud", "pods", len(foundPods.Items))
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudZookeeperCleanupPending, true, "StoppingSolrPods", fmt.Sprintf("Waiting for %d Solr pods to stop before removing the chroot %s", len(foundPods.Items), cloud.Status.ZookeeperConnectionInfo.ChRoot))
+		return false, nil
+	}
+	return true, nil
+}
+
+// runZookeeperCleanupJob creates the Job that removes the chroot of the deleted SolrCloud from Zookeeper, and reports whether it has succeeded.
+// A failed Job is reported, but not retried, so that the skip annotation can be used to delete the SolrCloud without removing its chroot.
+func (r *SolrCloudReconciler) runZookeeperCleanupJob(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, logger logr.Logger) (cleanedUp bool, err error) {
+	job := util.GenerateZookeeperCleanupJob(cloud)
+	if err = controllerutil.SetControllerReference(cloud, job, r.scheme); err != nil {
+		return false, err
+	}
+
+	foundJob := &batchv1.Job{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, foundJob)
+	runningMessage := fmt.Sprintf("The Job %s is removing the chroot %s", job.Name, cloud.Status.ZookeeperConnectionInfo.ChRoot)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating Job to remove the Zookeeper data of the deleted SolrCloud", "job", job.Name, "chroot", cloud.Status.ZookeeperConnectionInfo.ChRoot)
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudZookeeperCleanupPending, true, "CleanupJobRunning", runningMessage)
+		return false, r.Create(context.TODO(), job)
+	} else if err != nil {
+		return false, err
+	}
+
+	if foundJob.Status.Succeeded > 0 {
+		return true, nil
+	}
+	for _, condition := range foundJob.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			// The failed Job is kept, so only report it when the condition changes
+			failedMessage := fmt.Sprintf("The Job %s could not remove the chroot %s, set the %s annotation to \"true\" to delete the SolrCloud without removing its chroot", foundJob.Name, cloud.Status.ZookeeperConnectionInfo.ChRoot, util.SolrSkipZookeeperCleanupAnnotation)
+			if solrCloudConditionChanged(cloud, solr.SolrCloudZookeeperCleanupPending, true, "CleanupJobFailed", failedMessage) {
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "ZookeeperCleanupFailed", "The Job %s could not remove the chroot from Zookeeper, set the %s annotation to \"true\" to delete the SolrCloud without removing its chroot", foundJob.Name, util.SolrSkipZookeeperCleanupAnnotation)
+			}
+			setSolrCloudCondition(cloud, newStatus, solr.SolrCloudZookeeperCleanupPending, true, "CleanupJobFailed", failedMessage)
+			return false, nil
+		}
+	}
+	setSolrCloudCondition(cloud, newStatus, solr.SolrCloudZookeeperCleanupPending, true, "CleanupJobRunning", runningMessage)
+	return false, nil
+}
+
+// drainCollections commits or backs up all collections of the deleted SolrCloud, depending on the drain method, and records the progress in the drain status.
+// The collections to drain are listed once, when the drain starts. A drain is only complete when every collection was drained successfully.
+func (r *SolrCloudReconciler) drainCollections(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, httpHeaders map[string]string, logger logr.Logger) (drained bool, err error) {
+	opts := cloud.Spec.DeletionOptions
+	tru := true
+	fals := false
+
+	// The collections can only be drained through Solr, which is never called during a dry run
+	if r.dryRun {
+		return false, nil
+	}
+
+	if newStatus.Drain == nil || newStatus.Drain.Method != opts.Method {
+		collections, err := util.ListCollections(cloud, httpHeaders)
+		if err != nil {
+			return false, err
+		}
+		newStatus.Drain = &solr.SolrCloudDrainStatus{
+			Method:      opts.Method,
+			Collections: make([]solr.CollectionDrainStatus, len(collections)),
+		}
+		if opts.Method == solr.DrainMethodBackup {
+			newStatus.Drain.BackupName = util.DrainBackupName(cloud)
+		}
+		for i, collection := range collections {
+			newStatus.Drain.Collections[i] = solr.CollectionDrainStatus{Collection: collection}
+		}
+		r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "DrainStarted", "Draining %d collections with the %s method, before deleting the SolrCloud", len(collections), opts.Method)
+	}
+	drainStatus := newStatus.Drain
+	drainLogger := logger.WithValues("drainMethod", drainStatus.Method, "backup", drainStatus.BackupName)
+
+	drainStatus.Finished = true
+	drained = true
+	for i := range drainStatus.Collections {
+		collectionStatus := &drainStatus.Collections[i]
+		if collectionStatus.Finished {
+			drained = drained && collectionStatus.Successful != nil && *collectionStatus.Successful
+			continue
+		}
+
+		if drainStatus.Method == solr.DrainMethodCommit {
+			// A failed commit is retried, until the drain times out
+			if err = util.CommitCollection(cloud, collectionStatus.Collection, httpHeaders); err != nil {
+				collectionStatus.Message = err.Error()
+				drainStatus.Finished = false
+				drained = false
+				continue
+			}
+			drainLogger.Info("Committed collection", "collection", collectionStatus.Collection)
+			collectionStatus.Finished = true
+			collectionStatus.Successful = &tru
+			collectionStatus.Message = ""
+			continue
+		}
+
+		// Always check on the backup first, since it might have been started without the status being saved
+		finished, successful, asyncStatus, message, err := util.CheckBackupForCollection(cloud, collectionStatus.Collection, drainStatus.BackupName, httpHeaders)
+		if err != nil {
+			return false, err
+		}
+		if asyncStatus == "notfound" && collectionStatus.AsyncBackupStatus == "" {
+			started, err := util.StartBackupForCollection(cloud, collectionStatus.Collection, drainStatus.BackupName, opts.Repository, httpHeaders)
+			if err != nil {
+				return false, err
+			}
+			if started {
+				collectionStatus.AsyncBackupStatus = "submitted"
+				drainLogger.Info("Started collection backup", "collection", collectionStatus.Collection)
+			}
+		} else if asyncStatus == "notfound" {
+			// The backup was started, but Solr no longer knows about it
+			finished, successful, message = true, false, "The backup request could not be found in Solr"
+		} else if asyncStatus != "" {
+			collectionStatus.AsyncBackupStatus = asyncStatus
+		}
+		if finished {
+			collectionStatus.Finished = true
+			if successful {
+				collectionStatus.Successful = &tru
+			} else {
+				collectionStatus.Successful = &fals
+				collectionStatus.Message = message
+				r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "CollectionDrainFailed", "Backup of collection %s to %s failed, the SolrCloud is kept until the drain times out or it is force deleted: %s", collectionStatus.Collection, drainStatus.BackupName, message)
+			}
+		} else {
+			drainStatus.Finished = false
+		}
+		drained = drained && finished && successful
+	}
+	return drained, nil
+}
+
+// needsStorageFinalizerReconcile returns whether the storage finalizer of the SolrCloud has to be reconciled.
+// Ephemeral storage has no PVCs to clean up, so the finalizer is skipped unless it was left behind by persistent storage.
+func needsStorageFinalizerReconcile(cloud *solr.SolrCloud) bool {
+	return cloud.UsesPersistentStorage() || util.ContainsString(cloud.ObjectMeta.Finalizers, util.SolrStorageFinalizer)
+}
+
 // Logic derived from:
 // - https://book.kubebuilder.io/reference/using-finalizers.html
 // - https://github.com/pravega/zookeeper-operator/blob/v0.2.9/pkg/controller/zookeepercluster/zookeepercluster_controller.go#L629
 func (r *SolrCloudReconciler) reconcileStorageFinalizer(cloud *solr.SolrCloud, pvcLabelSelector map[string]string, logger logr.Logger) error {
-	// If persistentStorage is being used by the cloud, and the reclaim policy is set to "Delete",
-	// then set a finalizer for the storage on the cloud, and delete the PVCs if the solrcloud has been deleted.
+	// If persistentStorage is being used by the cloud, and the reclaim policy is set to "Delete" or "RetainOrphans",
+	// then set a finalizer for the storage on the cloud, and delete (or label) the PVCs if the solrcloud has been deleted.
 
-	if cloud.Spec.StorageOptions.PersistentStorage != nil && cloud.Spec.StorageOptions.PersistentStorage.VolumeReclaimPolicy == solr.VolumeReclaimPolicyDelete {
+	if cloud.Spec.StorageOptions.PersistentStorage != nil && (cloud.Spec.StorageOptions.PersistentStorage.VolumeReclaimPolicy == solr.VolumeReclaimPolicyDelete || cloud.Spec.StorageOptions.PersistentStorage.VolumeReclaimPolicy == solr.VolumeReclaimPolicyRetainOrphans) {
 		if cloud.ObjectMeta.DeletionTimestamp.IsZero() {
 			// The object is not being deleted, so if it does not have our finalizer,
 			// then lets add the finalizer and update the object
@@ -807,13 +2311,23 @@ func (r *SolrCloudReconciler) reconcileStorageFinalizer(cloud *solr.SolrCloud, p
 			return r.cleanupOrphanPVCs(cloud, pvcLabelSelector, logger)
 		} else if util.ContainsString(cloud.ObjectMeta.Finalizers, util.SolrStorageFinalizer) {
 			// The object is being deleted
-			logger.Info("Deleting PVCs for SolrCloud")
+			if cloud.Spec.StorageOptions.PersistentStorage.VolumeReclaimPolicy == solr.VolumeReclaimPolicyRetainOrphans {
+				logger.Info("Labeling PVCs as orphaned for SolrCloud")
 
-			// Our finalizer is present, so let's delete all existing PVCs
-			if err := r.cleanUpAllPVCs(cloud, pvcLabelSelector, logger); err != nil {
-				return err
+				// Our finalizer is present, so let's label all existing PVCs as orphaned, since the cloud will no longer use them
+				if err := r.labelAllPVCsOrphaned(cloud, pvcLabelSelector, logger); err != nil {
+					return err
+				}
+				logger.Info("Labeled PVCs as orphaned for SolrCloud")
+			} else {
+				logger.Info("Deleting PVCs for SolrCloud")
+
+				// Our finalizer is present, so let's delete all existing PVCs
+				if err := r.cleanUpAllPVCs(cloud, pvcLabelSelector, logger); err != nil {
+					return err
+				}
+				logger.Info("Deleted PVCs for SolrCloud")
 			}
-			logger.Info("Deleted PVCs for SolrCloud")
 
 			// remove our finalizer from the list and update it.
 			cloud.ObjectMeta.Finalizers = util.RemoveString(cloud.ObjectMeta.Finalizers, util.SolrStorageFinalizer)
@@ -832,6 +2346,109 @@ func (r *SolrCloudReconciler) reconcileStorageFinalizer(cloud *solr.SolrCloud, p
 	return nil
 }
 
+// reconcileStorageClasses sets the StorageClassesFound condition of the SolrCloud, based on whether the StorageClasses that its PVC templates request exist.
+// A warning event is emitted for missing StorageClasses, since the PVCs that use them will stay pending until they are created.
+func (r *SolrCloudReconciler) reconcileStorageClasses(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus) (missing bool, err error) {
+	storageClassNames := util.RequestedStorageClassNames(cloud)
+	if len(storageClassNames) == 0 {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudStorageClassesFound)
+		return false, nil
+	}
+	var missingStorageClasses []string
+	for _, storageClassName := range storageClassNames {
+		if err = r.Get(context.TODO(), types.NamespacedName{Name: storageClassName}, &storagev1.StorageClass{}); errors.IsNotFound(err) {
+			missingStorageClasses = append(missingStorageClasses, storageClassName)
+		} else if err != nil {
+			return false, err
+		}
+	}
+	if len(missingStorageClasses) > 0 {
+		message := fmt.Sprintf("StorageClasses not found: %s", strings.Join(missingStorageClasses, ", "))
+		if solrCloudConditionChanged(cloud, solr.SolrCloudStorageClassesFound, false, "StorageClassNotFound", message) {
+			r.Recorder.Event(cloud, corev1.EventTypeWarning, "StorageClassNotFound", message+", the PVCs that request them will stay pending until they are created")
+		}
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudStorageClassesFound, false, "StorageClassNotFound", message)
+		return true, nil
+	}
+	setSolrCloudCondition(cloud, newStatus, solr.SolrCloudStorageClassesFound, true, "StorageClassesFound", "All StorageClasses requested by the PVC templates exist")
+	return false, nil
+}
+
+// reconcileStorageExpansion expands the Solr data PVCs when the requested storage size of the SolrCloud has been increased.
+// PVCs can only be expanded if their StorageClass allows volume expansion, otherwise the error is surfaced in the status.
+func (r *SolrCloudReconciler) reconcileStorageExpansion(cloud *solr.SolrCloud, pvcLabelSelector map[string]string, logger logr.Logger) (expansionStatus *solr.SolrVolumeExpansionStatus, err error) {
+	requestedSize, hasRequestedSize := cloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !hasRequestedSize {
+		return nil, nil
+	}
+	pvcList, err := r.getPVCList(cloud, pvcLabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	storageClasses := map[string]*storagev1.StorageClass{}
+	for _, pvcItem := range pvcList.Items {
+		// The requested storage size only applies to the PVCs that store the Solr data, not the Solr logs
+		if storageType, hasStorageType := pvcItem.Labels[util.SolrPVCStorageLabel]; hasStorageType && storageType != util.SolrCloudPVCDataStorage {
+			continue
+		}
+		// The PVCs of node pools can request their own storage size
+		pvcRequestedSize := requestedSize
+		if poolName, inPool := pvcItem.Labels[util.SolrNodePoolLabel]; inPool {
+			for _, nodePool := range cloud.Spec.NodePools {
+				if nodePool.Name == poolName && nodePool.StorageCapacity != nil {
+					pvcRequestedSize = *nodePool.StorageCapacity
+				}
+			}
+		}
+		needsExpansion, needsResizeRequest := util.PVCNeedsExpansion(&pvcItem, pvcRequestedSize)
+		if !needsExpansion {
+			continue
+		}
+		if expansionStatus == nil {
+			expansionStatus = &solr.SolrVolumeExpansionStatus{RequestedSize: pvcRequestedSize.String()}
+		}
+		expansionStatus.PendingPVCs = append(expansionStatus.PendingPVCs, pvcItem.Name)
+		// The expansion has already been requested, Kubernetes will finish resizing the volume
+		if !needsResizeRequest {
+			continue
+		}
+
+		storageClassName := ""
+		if pvcItem.Spec.StorageClassName != nil {
+			storageClassName = *pvcItem.Spec.StorageClassName
+		}
+		if storageClassName == "" {
+			expansionStatus.Error = fmt.Sprintf("PVC %s does not use a StorageClass, so it cannot be expanded", pvcItem.Name)
+			continue
+		}
+		storageClass, found := storageClasses[storageClassName]
+		if !found {
+			storageClass = &storagev1.StorageClass{}
+			if err = r.Get(context.TODO(), types.NamespacedName{Name: storageClassName}, storageClass); err != nil {
+				expansionStatus.Error = fmt.Sprintf("Cannot fetch StorageClass %s to determine whether PVC %s can be expanded: %s", storageClassName, pvcItem.Name, err)
+				return expansionStatus, err
+			}
+			storageClasses[storageClassName] = storageClass
+		}
+		if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+			expansionStatus.Error = fmt.Sprintf("StorageClass %s does not allow volume expansion, so PVC %s cannot be expanded", storageClassName, pvcItem.Name)
+			continue
+		}
+
+		currentRequest := pvcItem.Spec.Resources.Requests[corev1.ResourceStorage]
+		logger.Info("Expanding PVC for SolrCloud", "PVC", pvcItem.Name, "from", currentRequest.String(), "to", pvcRequestedSize.String())
+		if pvcItem.Spec.Resources.Requests == nil {
+			pvcItem.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvcItem.Spec.Resources.Requests[corev1.ResourceStorage] = pvcRequestedSize
+		if err = r.Update(context.TODO(), &pvcItem); err != nil {
+			expansionStatus.Error = fmt.Sprintf("Cannot expand PVC %s: %s", pvcItem.Name, err)
+			return expansionStatus, err
+		}
+	}
+	return expansionStatus, nil
+}
+
 func (r *SolrCloudReconciler) getPVCCount(cloud *solr.SolrCloud, pvcLabelSelector map[string]string) (pvcCount int, err error) {
 	pvcList, err := r.getPVCList(cloud, pvcLabelSelector)
 	if err != nil {
@@ -848,11 +2465,18 @@ func (r *SolrCloudReconciler) cleanupOrphanPVCs(cloud *solr.SolrCloud, pvcLabelS
 		if err != nil {
 			return err
 		}
-		if len(pvcList.Items) > int(*cloud.Spec.Replicas) {
+		if cloud.Spec.StorageOptions.PersistentStorage.VolumeReclaimPolicy == solr.VolumeReclaimPolicyRetainOrphans {
+			// label Orphan PVCs, and remove the label from PVCs that are in use again after a scale up
+			for _, pvcItem := range pvcList.Items {
+				if err = r.setPVCOrphanedLabel(cloud, pvcItem, util.IsSolrCloudPVCOrphan(cloud, pvcItem), logger); err != nil {
+					return err
+				}
+			}
+		} else if len(pvcList.Items) > int(cloud.TotalReplicas()) {
 			for _, pvcItem := range pvcList.Items {
 				// delete only Orphan PVCs
-				if util.IsPVCOrphan(pvcItem.Name, *cloud.Spec.Replicas) {
-					r.deletePVC(pvcItem, logger)
+				if util.IsSolrCloudPVCOrphan(cloud, pvcItem) {
+					r.deletePVC(cloud, pvcItem, logger)
 				}
 			}
 		}
@@ -860,6 +2484,50 @@ func (r *SolrCloudReconciler) cleanupOrphanPVCs(cloud *solr.SolrCloud, pvcLabelS
 	return nil
 }
 
+func (r *SolrCloudReconciler) labelAllPVCsOrphaned(cloud *solr.SolrCloud, pvcLabelSelector map[string]string, logger logr.Logger) (err error) {
+	pvcList, err := r.getPVCList(cloud, pvcLabelSelector)
+	if err != nil {
+		return err
+	}
+	for _, pvcItem := range pvcList.Items {
+		if err = r.setPVCOrphanedLabel(cloud, pvcItem, true, logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setPVCOrphanedLabel labels a PVC as orphaned, along with the time it was orphaned, so that it can be reclaimed manually.
+// If the PVC is no longer orphaned, because it is in use again, then the label is removed.
+func (r *SolrCloudReconciler) setPVCOrphanedLabel(cloud *solr.SolrCloud, pvcItem corev1.PersistentVolumeClaim, orphaned bool, logger logr.Logger) error {
+	_, isLabeled := pvcItem.Labels[util.SolrPVCOrphanedLabel]
+	if orphaned == isLabeled {
+		return nil
+	}
+	if orphaned {
+		logger.Info("Labeling orphaned PVC for SolrCloud", "PVC", pvcItem.Name)
+		if pvcItem.Labels == nil {
+			pvcItem.Labels = map[string]string{}
+		}
+		pvcItem.Labels[util.SolrPVCOrphanedLabel] = "true"
+		if pvcItem.Annotations == nil {
+			pvcItem.Annotations = map[string]string{}
+		}
+		pvcItem.Annotations[util.SolrPVCOrphanedTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	} else {
+		logger.Info("Removing orphaned label from PVC for SolrCloud, since it is in use again", "PVC", pvcItem.Name)
+		delete(pvcItem.Labels, util.SolrPVCOrphanedLabel)
+		delete(pvcItem.Annotations, util.SolrPVCOrphanedTimeAnnotation)
+	}
+	err := r.Client.Update(context.TODO(), &pvcItem)
+	if err != nil {
+		logger.Error(err, "Error labeling PVC for SolrCloud", "PVC", pvcItem.Name)
+	} else if orphaned {
+		r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "LabeledOrphanedPVC", "Labeled PVC %s as orphaned", pvcItem.Name)
+	}
+	return err
+}
+
 func (r *SolrCloudReconciler) getPVCList(cloud *solr.SolrCloud, pvcLabelSelector map[string]string) (pvList corev1.PersistentVolumeClaimList, err error) {
 	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
 		MatchLabels: pvcLabelSelector,
@@ -879,12 +2547,12 @@ func (r *SolrCloudReconciler) cleanUpAllPVCs(cloud *solr.SolrCloud, pvcLabelSele
 		return err
 	}
 	for _, pvcItem := range pvcList.Items {
-		r.deletePVC(pvcItem, logger)
+		r.deletePVC(cloud, pvcItem, logger)
 	}
 	return nil
 }
 
-func (r *SolrCloudReconciler) deletePVC(pvcItem corev1.PersistentVolumeClaim, logger logr.Logger) {
+func (r *SolrCloudReconciler) deletePVC(cloud *solr.SolrCloud, pvcItem corev1.PersistentVolumeClaim, logger logr.Logger) {
 	pvcDelete := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pvcItem.Name,
@@ -895,23 +2563,44 @@ func (r *SolrCloudReconciler) deletePVC(pvcItem corev1.PersistentVolumeClaim, lo
 	err := r.Client.Delete(context.TODO(), pvcDelete)
 	if err != nil {
 		logger.Error(err, "Error deleting PVC for SolrCloud", "PVC", pvcDelete.Name)
+		r.Recorder.Eventf(cloud, corev1.EventTypeWarning, "PVCDeletionFailed", "Error deleting PVC %s: %s", pvcDelete.Name, err)
+	} else {
+		r.Recorder.Eventf(cloud, corev1.EventTypeNormal, "DeletedPVC", "Deleted PVC %s", pvcDelete.Name)
 	}
 }
 
+// maxConcurrentReconciles returns how many SolrClouds may be reconciled in parallel, defaulting to 1
+func (r *SolrCloudReconciler) maxConcurrentReconciles() (int, error) {
+	if r.MaxConcurrentReconciles < 0 {
+		return 0, fmt.Errorf("the maximum number of concurrent reconciles must be at least 1, got %d", r.MaxConcurrentReconciles)
+	}
+	if r.MaxConcurrentReconciles == 0 {
+		return 1, nil
+	}
+	return r.MaxConcurrentReconciles, nil
+}
+
 func (r *SolrCloudReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return r.SetupWithManagerAndReconciler(mgr, r)
 }
 
 func (r *SolrCloudReconciler) SetupWithManagerAndReconciler(mgr ctrl.Manager, reconciler reconcile.Reconciler) error {
+	maxConcurrentReconciles, err := r.maxConcurrentReconciles()
+	if err != nil {
+		return err
+	}
+
 	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
 		For(&solr.SolrCloud{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}). /* for authentication */
-		Owns(&netv1.Ingress{})
+		Owns(&netv1.Ingress{}).
+		Owns(&policyv1beta1.PodDisruptionBudget{}).
+		Owns(&batchv1.Job{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: maxConcurrentReconciles})
 
-	var err error
 	ctrlBuilder, err = r.indexAndWatchForProvidedConfigMaps(mgr, ctrlBuilder)
 	if err != nil {
 		return err
@@ -922,26 +2611,33 @@ func (r *SolrCloudReconciler) SetupWithManagerAndReconciler(mgr ctrl.Manager, re
 		return err
 	}
 
-	if useZkCRD {
+	ctrlBuilder, err = r.indexAndWatchForMountedSecrets(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	ctrlBuilder, err = r.indexAndWatchForSecurityJsonSecret(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
+	if r.UseZkCRD {
 		ctrlBuilder = ctrlBuilder.Owns(&zk.ZookeeperCluster{})
 	}
 
 	r.scheme = mgr.GetScheme()
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("solrcloud-controller")
+	}
 	return ctrlBuilder.Complete(reconciler)
 }
 
 func (r *SolrCloudReconciler) indexAndWatchForProvidedConfigMaps(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
 	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &solr.SolrCloud{}, ".spec.customSolrKubeOptions.configMapOptions.providedConfigMap", func(rawObj runtime.Object) []string {
-		// grab the SolrCloud object, extract the used configMap...
+		// grab the SolrCloud object, and extract all of the user-provided configMaps it uses
 		solrCloud := rawObj.(*solr.SolrCloud)
-		if solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions == nil {
-			return nil
-		}
-		if solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap == "" {
-			return nil
-		}
-		// ...and if so, return it
-		return []string{solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.ProvidedConfigMap}
+		volumeConfigMaps, _ := util.MountedVolumeConfigMapsAndSecrets(solrCloud)
+		return append(solrCloud.ProvidedConfigMapNames(), volumeConfigMaps...)
 	}); err != nil {
 		return ctrlBuilder, err
 	}
@@ -982,8 +2678,21 @@ func (r *SolrCloudReconciler) indexAndWatchForTLSSecret(mgr ctrl.Manager, ctrlBu
 		if solrCloud.Spec.SolrTLS == nil {
 			return nil
 		}
-		// ...and if so, return it
-		return []string{solrCloud.Spec.SolrTLS.PKCS12Secret.Name}
+		// ...and if so, return it, along with the truststore, the password secrets and the client cert secret used for mTLS calls to the Solr API
+		secrets := []string{solrCloud.Spec.SolrTLS.PKCS12Secret.Name}
+		if solrCloud.Spec.SolrTLS.TrustStoreSecret != nil && !util.ContainsString(secrets, solrCloud.Spec.SolrTLS.TrustStoreSecret.Name) {
+			secrets = append(secrets, solrCloud.Spec.SolrTLS.TrustStoreSecret.Name)
+		}
+		if solrCloud.Spec.SolrTLS.KeyStorePasswordSecret != nil && !util.ContainsString(secrets, solrCloud.Spec.SolrTLS.KeyStorePasswordSecret.Name) {
+			secrets = append(secrets, solrCloud.Spec.SolrTLS.KeyStorePasswordSecret.Name)
+		}
+		if solrCloud.Spec.SolrTLS.TrustStorePasswordSecret != nil && !util.ContainsString(secrets, solrCloud.Spec.SolrTLS.TrustStorePasswordSecret.Name) {
+			secrets = append(secrets, solrCloud.Spec.SolrTLS.TrustStorePasswordSecret.Name)
+		}
+		if solrCloud.Spec.SolrTLS.ClientCertSecret != "" {
+			secrets = append(secrets, solrCloud.Spec.SolrTLS.ClientCertSecret)
+		}
+		return secrets
 	}); err != nil {
 		return ctrlBuilder, err
 	}
@@ -1017,28 +2726,109 @@ func (r *SolrCloudReconciler) indexAndWatchForTLSSecret(mgr ctrl.Manager, ctrlBu
 		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
 }
 
-func (r *SolrCloudReconciler) verifyTLSSecretConfig(secretName string, secretNamespace string, passwordSecret *corev1.SecretKeySelector) (*corev1.Secret, error) {
+func (r *SolrCloudReconciler) indexAndWatchForMountedSecrets(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	mountedSecretsField := ".spec.customSolrKubeOptions.podOptions.volumes.secrets"
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &solr.SolrCloud{}, mountedSecretsField, func(rawObj runtime.Object) []string {
+		// grab the SolrCloud object, and extract the secrets mounted into the Solr container that should trigger restarts
+		solrCloud := rawObj.(*solr.SolrCloud)
+		_, volumeSecrets := util.MountedVolumeConfigMapsAndSecrets(solrCloud)
+		return volumeSecrets
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				foundClouds := &solr.SolrCloudList{}
+				listOps := &client.ListOptions{
+					FieldSelector: fields.OneTermEqualSelector(mountedSecretsField, a.Meta.GetName()),
+					Namespace:     a.Meta.GetNamespace(),
+				}
+				err := r.List(context.TODO(), foundClouds, listOps)
+				if err != nil {
+					return []reconcile.Request{}
+				}
+
+				requests := make([]reconcile.Request, len(foundClouds.Items))
+				for i, item := range foundClouds.Items {
+					requests[i] = reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      item.GetName(),
+							Namespace: item.GetNamespace(),
+						},
+					}
+				}
+				return requests
+			}),
+		},
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+func (r *SolrCloudReconciler) verifyTLSSecretConfig(secretName string, secretNamespace string, passwordSecret *corev1.SecretKeySelector) (*corev1.Secret, *corev1.Secret, error) {
 	ctx := context.TODO()
 
 	foundTLSSecret := &corev1.Secret{}
+	keyStorePasswordSecret := &corev1.Secret{}
 	lookupErr := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: secretNamespace}, foundTLSSecret)
 	if lookupErr != nil {
-		return nil, lookupErr
+		return nil, nil, lookupErr
 	} else {
 		// Make sure the secret containing the keystore password exists as well
-		keyStorePasswordSecret := &corev1.Secret{}
 		err := r.Get(ctx, types.NamespacedName{Name: passwordSecret.Name, Namespace: foundTLSSecret.Namespace}, keyStorePasswordSecret)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// we found the keystore secret, but does it have the key we expect?
 		if _, ok := keyStorePasswordSecret.Data[passwordSecret.Key]; !ok {
-			return nil, fmt.Errorf("%s key not found in keystore password secret %s", passwordSecret.Key, keyStorePasswordSecret.Name)
+			return nil, nil, fmt.Errorf("%s key not found in keystore password secret %s", passwordSecret.Key, keyStorePasswordSecret.Name)
 		}
 	}
 
-	return foundTLSSecret, nil
+	return foundTLSSecret, keyStorePasswordSecret, nil
+}
+
+// reconcileTLSHostnames checks that the certificate in the TLS secret is valid for all hostnames that the SolrCloud is addressed by.
+// The certificate can only be checked when the TLS secret contains a PEM encoded certificate, keystores are not inspected.
+// When Solr verifies the hostnames of its peers (checkPeerName), the Solr nodes whose advertised hostname the certificate is not valid for are returned,
+// since the other Solr nodes will not be able to talk to them.
+func (r *SolrCloudReconciler) reconcileTLSHostnames(cloud *solr.SolrCloud, newStatus *solr.SolrCloudStatus, tlsSecret *corev1.Secret) (uncoveredNodes map[string]bool) {
+	tlsCertBytes, hasTLSCert := tlsSecret.Data[cloud.Spec.SolrTLS.PEMCertKey()]
+	if !hasTLSCert {
+		meta.RemoveStatusCondition(&newStatus.Conditions, solr.SolrCloudTLSHostnamesCovered)
+		return nil
+	}
+	cert, err := util.ParseTLSCertificate(tlsCertBytes)
+	if err != nil {
+		message := fmt.Sprintf("Unable to parse the %s in TLS secret %s: %s", cloud.Spec.SolrTLS.PEMCertKey(), tlsSecret.Name, err)
+		if solrCloudConditionChanged(cloud, solr.SolrCloudTLSHostnamesCovered, false, "CertificateInvalid", message) {
+			r.Recorder.Event(cloud, corev1.EventTypeWarning, "TLSCertificateInvalid", message)
+		}
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudTLSHostnamesCovered, false, "CertificateInvalid", message)
+		return nil
+	}
+	missingDNSNames := util.MissingTLSDNSNames(cert, cloud.TLSDNSNames())
+	if len(missingDNSNames) > 0 {
+		message := fmt.Sprintf("The TLS certificate is not valid for: %s", strings.Join(missingDNSNames, ", "))
+		if solrCloudConditionChanged(cloud, solr.SolrCloudTLSHostnamesCovered, false, "HostnamesNotCovered", message) {
+			r.Recorder.Event(cloud, corev1.EventTypeWarning, "TLSHostnamesNotCovered", message+", TLS connections to these hosts will fail hostname verification")
+		}
+		setSolrCloudCondition(cloud, newStatus, solr.SolrCloudTLSHostnamesCovered, false, "HostnamesNotCovered", message)
+		if cloud.Spec.SolrTLS.CheckPeerName {
+			uncoveredNodes = make(map[string]bool)
+			for _, nodeName := range cloud.GetAllSolrNodeNames() {
+				// The other Solr nodes connect to the advertised hostname of the node
+				if cert.VerifyHostname(cloud.AdvertisedNodeHost(nodeName)) != nil {
+					uncoveredNodes[nodeName] = true
+				}
+			}
+		}
+		return uncoveredNodes
+	}
+	setSolrCloudCondition(cloud, newStatus, solr.SolrCloudTLSHostnamesCovered, true, "HostnamesCovered", "The TLS certificate is valid for all hostnames of the SolrCloud")
+	return nil
 }
 
 // Set the requeueAfter if it has not been set, or is greater than the new time to requeue at
@@ -1047,3 +2837,96 @@ func updateRequeueAfter(requeueOrNot *reconcile.Result, newWait time.Duration) {
 		requeueOrNot.RequeueAfter = newWait
 	}
 }
+
+// requeueIntervals returns the RequeueIntervals of the reconciler, using the DefaultRequeueIntervals for the intervals that are not set.
+// Longer intervals reduce the load on the Kubernetes API Server and Solr when managing many SolrClouds, at the cost of reacting slower.
+func (r *SolrCloudReconciler) requeueIntervals() RequeueIntervals {
+	intervals := r.RequeueIntervals
+	if intervals.Poll <= 0 {
+		intervals.Poll = DefaultRequeueIntervals.Poll
+	}
+	if intervals.Retry <= 0 {
+		intervals.Retry = DefaultRequeueIntervals.Retry
+	}
+	if intervals.MissingDependency <= 0 {
+		intervals.MissingDependency = DefaultRequeueIntervals.MissingDependency
+	}
+	if intervals.MaxBackoff <= 0 {
+		intervals.MaxBackoff = DefaultRequeueIntervals.MaxBackoff
+	}
+	return intervals
+}
+
+// conditionBackoff returns the backoff of the transient conditions, which is shared by all SolrClouds of the reconciler
+func (r *SolrCloudReconciler) conditionBackoff() workqueue.RateLimiter {
+	r.transientConditionBackoffLock.Lock()
+	defer r.transientConditionBackoffLock.Unlock()
+	if r.transientConditionBackoff == nil {
+		intervals := r.requeueIntervals()
+		r.transientConditionBackoff = workqueue.NewItemExponentialFailureRateLimiter(intervals.Poll, intervals.MaxBackoff)
+	}
+	return r.transientConditionBackoff
+}
+
+// backoffForCondition returns how long to wait before checking on the given condition of the SolrCloud again, while it is not yet met.
+// The wait doubles every time, starting at the poll interval, until the condition is met and resetBackoffForCondition is called.
+func (r *SolrCloudReconciler) backoffForCondition(solrCloud *solr.SolrCloud, conditionType string) time.Duration {
+	return r.conditionBackoff().When(solrCloud.Namespace + "/" + solrCloud.Name + "/" + conditionType)
+}
+
+// resetBackoffForCondition starts the backoff for the given condition of the SolrCloud over, once the condition is met
+func (r *SolrCloudReconciler) resetBackoffForCondition(solrCloud *solr.SolrCloud, conditionType string) {
+	r.conditionBackoff().Forget(solrCloud.Namespace + "/" + solrCloud.Name + "/" + conditionType)
+}
+
+// The conditions that are waited on with backoffForCondition
+var transientConditions = []string{solr.SolrCloudZookeeperConnected, solr.SolrCloudTLSReady}
+
+// forgetBackoffsForCloud removes the backoffs of all transient conditions of a SolrCloud, once it has been deleted
+func (r *SolrCloudReconciler) forgetBackoffsForCloud(cloud types.NamespacedName) {
+	for _, conditionType := range transientConditions {
+		r.conditionBackoff().Forget(cloud.Namespace + "/" + cloud.Name + "/" + conditionType)
+	}
+}
+
+func (r *SolrCloudReconciler) indexAndWatchForSecurityJsonSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &solr.SolrCloud{}, ".spec.solrSecurity.bootstrapSecurityJson", func(rawObj runtime.Object) []string {
+		// grab the SolrCloud object, extract the user-provided security.json secret...
+		solrCloud := rawObj.(*solr.SolrCloud)
+		if solrCloud.Spec.SolrSecurity == nil || solrCloud.Spec.SolrSecurity.BootstrapSecurityJson == nil {
+			return nil
+		}
+		// ...and if so, return it
+		return []string{solrCloud.Spec.SolrSecurity.BootstrapSecurityJson.Name}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				foundClouds := &solr.SolrCloudList{}
+				listOps := &client.ListOptions{
+					FieldSelector: fields.OneTermEqualSelector(".spec.solrSecurity.bootstrapSecurityJson", a.Meta.GetName()),
+					Namespace:     a.Meta.GetNamespace(),
+				}
+				err := r.List(context.TODO(), foundClouds, listOps)
+				if err != nil {
+					return []reconcile.Request{}
+				}
+
+				requests := make([]reconcile.Request, len(foundClouds.Items))
+				for i, item := range foundClouds.Items {
+					requests[i] = reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      item.GetName(),
+							Namespace: item.GetNamespace(),
+						},
+					}
+				}
+				return requests
+			}),
+		},
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
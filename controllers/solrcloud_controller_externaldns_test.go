@@ -36,7 +36,6 @@ import (
 var _ reconcile.Reconciler = &SolrCloudReconciler{}
 
 func TestEDSCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -76,8 +75,9 @@ func TestEDSCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -154,7 +154,6 @@ func TestEDSCloudReconcile(t *testing.T) {
 }
 
 func TestEDSNoNodesCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -195,8 +194,9 @@ func TestEDSNoNodesCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -270,7 +270,6 @@ func TestEDSNoNodesCloudReconcile(t *testing.T) {
 }
 
 func TestEDSNoCommonCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -311,8 +310,9 @@ func TestEDSNoCommonCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -385,7 +385,6 @@ func TestEDSNoCommonCloudReconcile(t *testing.T) {
 }
 
 func TestEDSUseInternalAddressCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -426,8 +425,9 @@ func TestEDSUseInternalAddressCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -504,7 +504,6 @@ func TestEDSUseInternalAddressCloudReconcile(t *testing.T) {
 }
 
 func TestEDSExtraDomainsCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -545,8 +544,9 @@ func TestEDSExtraDomainsCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -628,7 +628,6 @@ func TestEDSExtraDomainsCloudReconcile(t *testing.T) {
 }
 
 func TestEDSKubeDomainCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -670,8 +669,9 @@ func TestEDSKubeDomainCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -38,7 +38,6 @@ import (
 var _ reconcile.Reconciler = &SolrCloudReconciler{}
 
 func TestIngressCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	replicas := int32(4)
@@ -86,8 +85,9 @@ func TestIngressCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -171,7 +171,6 @@ func TestIngressCloudReconcile(t *testing.T) {
 }
 
 func TestIngressNoNodesCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	replicas := int32(4)
@@ -220,8 +219,9 @@ func TestIngressNoNodesCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -299,7 +299,6 @@ func TestIngressNoNodesCloudReconcile(t *testing.T) {
 }
 
 func TestIngressNoCommonCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	replicas := int32(4)
@@ -348,8 +347,9 @@ func TestIngressNoCommonCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -432,7 +432,6 @@ func TestIngressNoCommonCloudReconcile(t *testing.T) {
 }
 
 func TestIngressUseInternalAddressCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	replicas := int32(4)
@@ -480,8 +479,9 @@ func TestIngressUseInternalAddressCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -560,7 +560,6 @@ func TestIngressUseInternalAddressCloudReconcile(t *testing.T) {
 }
 
 func TestIngressExtraDomainsCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	replicas := int32(4)
@@ -609,8 +608,9 @@ func TestIngressExtraDomainsCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -695,7 +695,6 @@ func TestIngressExtraDomainsCloudReconcile(t *testing.T) {
 }
 
 func TestIngressKubeDomainCloudReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	replicas := int32(4)
@@ -738,8 +737,9 @@ func TestIngressKubeDomainCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -861,3 +861,83 @@ func testIngressRules(t *testing.T, ingress *extv1.Ingress, withCommon bool, wit
 		}
 	}
 }
+
+func TestIngressDefaultsWithDefaultingWebhook(t *testing.T) {
+	testIngressDefaultsReconcile(t, true)
+}
+
+func TestIngressDefaultsWithoutDefaultingWebhook(t *testing.T) {
+	testIngressDefaultsReconcile(t, false)
+}
+
+func testIngressDefaultsReconcile(t *testing.T, useWebhook bool) {
+	g := gomega.NewGomegaWithT(t)
+
+	replicas := int32(2)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:     solr.Ingress,
+					DomainName: testDomain,
+				},
+			},
+		},
+	}
+	if useWebhook {
+		// The defaulting webhook is not served by the test environment, so default the SolrCloud as it would before it is stored
+		instance.Default()
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:               testClient,
+		Log:                  ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD:             false,
+		UseDefaultingWebhook: useWebhook,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object and expect the Reconcile and StatefulSet to be created
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	expectStatefulSet(t, g, requests, expectedCloudRequest, cloudSsKey)
+
+	// The Ingress should use the default ports, whether the defaults were set by the webhook or the reconciler
+	ingress := expectIngress(g, requests, expectedCloudRequest, cloudIKey)
+	testIngressRules(t, ingress, true, int(replicas), []string{testDomain}, 80, 80)
+
+	g.Eventually(func() *string {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		return instance.Status.ExternalCommonAddress
+	}, timeout).ShouldNot(gomega.BeNil(), "External common address in Status should not be nil.")
+	assert.Equal(t, 80, instance.Spec.SolrAddressability.External.NodePortOverride, "Bad Default - instance.Spec.SolrAddressability.External.NodePortOverride")
+	assert.Equal(t, 80, instance.Spec.SolrAddressability.CommonServicePort, "Bad Default - instance.Spec.SolrAddressability.CommonServicePort")
+	assert.EqualValues(t, "http://"+instance.Namespace+"-"+instance.Name+"-solrcloud"+"."+testDomain, *instance.Status.ExternalCommonAddress, "Wrong external common address in status")
+}
@@ -18,9 +18,12 @@
 package controllers
 
 import (
+	"strings"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/apache/solr-operator/controllers/util"
@@ -38,9 +41,9 @@ import (
 var _ reconcile.Reconciler = &SolrCloudReconciler{}
 
 func TestPersistentStorageVolumesRetain(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
+	missingStorageClass := "missing-storage-class"
 	instance := &solr.SolrCloud{
 		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
 		Spec: solr.SolrCloudSpec{
@@ -60,6 +63,9 @@ func TestPersistentStorageVolumesRetain(t *testing.T) {
 							Name:   "other-data-1",
 							Labels: map[string]string{"base": "here"},
 						},
+						Spec: corev1.PersistentVolumeClaimSpec{
+							StorageClassName: &missingStorageClass,
+						},
 					},
 				},
 			},
@@ -82,8 +88,9 @@ func TestPersistentStorageVolumesRetain(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -111,6 +118,12 @@ func TestPersistentStorageVolumesRetain(t *testing.T) {
 		return testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, foundInstance)
 	}, timeout).Should(gomega.Succeed())
 	assert.Equal(t, 0, len(foundInstance.GetFinalizers()), "The solrcloud should have no finalizers when the reclaim policy is Retain")
+	g.Eventually(func() bool {
+		if err := testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, foundInstance); err != nil {
+			return false
+		}
+		return meta.IsStatusConditionFalse(foundInstance.Status.Conditions, solr.SolrCloudStorageClassesFound)
+	}, timeout).Should(gomega.BeTrue(), "The solrcloud should report that the requested StorageClass does not exist")
 
 	// Check the statefulSet
 	statefulSet := expectStatefulSet(t, g, requests, expectedCloudRequest, cloudSsKey)
@@ -124,7 +137,6 @@ func TestPersistentStorageVolumesRetain(t *testing.T) {
 }
 
 func TestPersistentStorageVolumesDelete(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -169,8 +181,9 @@ func TestPersistentStorageVolumesDelete(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -221,8 +234,76 @@ func TestPersistentStorageVolumesDelete(t *testing.T) {
 	g.Expect(err).To(gomega.HaveOccurred(), "Cloud has not been deleted, thus the finalizers have not been removed from the object.")
 }
 
+func TestPersistentStorageVolumesRetainOrphans(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			StorageOptions: solr.SolrDataStorageOptions{
+				PersistentStorage: &solr.SolrPersistentDataStorageOptions{
+					VolumeReclaimPolicy: solr.VolumeReclaimPolicyRetainOrphans,
+				},
+			},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object and expect the Reconcile and StatefulSet to be created
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	// Fetch new value of instance to check finalizers
+	foundInstance := &solr.SolrCloud{}
+	g.Eventually(func() error {
+		return testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, foundInstance)
+	}, timeout).Should(gomega.Succeed())
+	assert.Equal(t, 1, len(foundInstance.GetFinalizers()), "The solrcloud should have 1 storage finalizer when persistent storage reclaim policy is set to RetainOrphans")
+	assert.Equal(t, util.SolrStorageFinalizer, foundInstance.GetFinalizers()[0], "Incorrect finalizer set for labeling orphaned persistent storage.")
+
+	// Explicitly delete, make sure that finalizers are removed from the object so that kubernetes can delete it.
+	testClient.Delete(context.TODO(), instance)
+	for i := 0; i < 5; i++ {
+		g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)), "Cloud has not been deleted, thus the finalizers have not been removed from the object.")
+		err = testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)
+		if err != nil {
+			break
+		}
+	}
+	g.Expect(err).To(gomega.HaveOccurred(), "Cloud has not been deleted, thus the finalizers have not been removed from the object.")
+}
+
 func TestDefaultEphemeralStorage(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -255,7 +336,6 @@ func TestDefaultEphemeralStorage(t *testing.T) {
 }
 
 func TestDefaultEphemeralStorageWhenNilEmptyDir(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -290,7 +370,6 @@ func TestDefaultEphemeralStorageWhenNilEmptyDir(t *testing.T) {
 }
 
 func TestEphemeralStorageWithEmptyDirSpecs(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -331,8 +410,9 @@ func TestEphemeralStorageWithEmptyDirSpecs(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -373,7 +453,6 @@ func TestEphemeralStorageWithEmptyDirSpecs(t *testing.T) {
 }
 
 func TestEphemeralStorageWithHostPathSpecs(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	hostPathType := corev1.HostPathDirectoryOrCreate
@@ -412,7 +491,6 @@ func TestEphemeralStorageWithHostPathSpecs(t *testing.T) {
 }
 
 func TestEphemeralStorageWithHostPathAndEmptyDirSpecs(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	hostPathType := corev1.HostPathDirectoryOrCreate
@@ -463,8 +541,9 @@ func testHostPathSpecs(t *testing.T, g *gomega.GomegaWithT, instance *solr.SolrC
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -512,8 +591,9 @@ func testForDefaultEmptyDirSpecs(t *testing.T, g *gomega.GomegaWithT, instance *
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -550,3 +630,199 @@ func testForDefaultEmptyDirSpecs(t *testing.T, g *gomega.GomegaWithT, instance *
 	dataVolume := statefulSet.Spec.Template.Spec.Volumes[1]
 	assert.NotNil(t, dataVolume.EmptyDir, "The data volume should be an empty-dir.")
 }
+
+func TestS3BackupRepository(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			SolrImage: &solr.ContainerImage{
+				Tag: "8.10.1",
+			},
+			StorageOptions: solr.SolrDataStorageOptions{
+				BackupRepositories: []solr.SolrBackupRepository{
+					{
+						Name: "s3-repo",
+						S3: &solr.S3BackupRepository{
+							Region:   "us-west-2",
+							Bucket:   "solr-backups",
+							Endpoint: "http://localhost:4566",
+							Credentials: &solr.S3Credentials{
+								AccessKeyIdSecret: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "aws-secret"},
+									Key:                  "access-key-id",
+								},
+								SecretAccessKeySecret: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "aws-secret"},
+									Key:                  "secret-access-key",
+								},
+								CredentialsFileSecret: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "aws-secret"},
+									Key:                  "credentials",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object and expect the Reconcile and StatefulSet to be created
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	// Check the solr.xml
+	configMap := &corev1.ConfigMap{}
+	g.Eventually(func() error { return testClient.Get(context.TODO(), cloudCMKey, configMap) }, timeout).Should(gomega.Succeed())
+	solrXml := configMap.Data[util.SolrXmlFile]
+	assert.Contains(t, solrXml, "<str name=\"sharedLib\">${solr.sharedLib:/opt/solr/contrib/s3-repository/lib,/opt/solr/dist}</str>", "The S3 repository libraries should be added to the sharedLib")
+	assert.Contains(t, solrXml, "<repository name=\"s3-repo\" class=\""+util.S3BackupRepositoryClass+"\" default=\"false\">", "The S3 repository should be defined in the solr.xml")
+	assert.Contains(t, solrXml, "<str name=\"s3.bucket.name\">solr-backups</str>", "The S3 repository bucket is wrong")
+	assert.Contains(t, solrXml, "<str name=\"s3.region\">us-west-2</str>", "The S3 repository region is wrong")
+	assert.Contains(t, solrXml, "<str name=\"s3.endpoint\">http://localhost:4566</str>", "The S3 repository endpoint is wrong")
+
+	// Check the statefulSet
+	statefulSet := expectStatefulSet(t, g, requests, expectedCloudRequest, cloudSsKey)
+
+	mainContainer := statefulSet.Spec.Template.Spec.Containers[0]
+	s3EnvVars := filterVarsByName(mainContainer.Env, func(n string) bool {
+		return strings.HasPrefix(n, "AWS_") || n == "SOLR_MODULES"
+	})
+	assert.Equal(t, 4, len(s3EnvVars), "Wrong number of S3 env vars")
+	for _, envVar := range s3EnvVars {
+		switch envVar.Name {
+		case "SOLR_MODULES":
+			assert.Equal(t, util.S3BackupRepositoryModule, envVar.Value, "The S3 repository module should be enabled")
+		case "AWS_ACCESS_KEY_ID":
+			assert.Equal(t, instance.Spec.StorageOptions.BackupRepositories[0].S3.Credentials.AccessKeyIdSecret, envVar.ValueFrom.SecretKeyRef, "The Access Key ID should be read from the secret")
+		case "AWS_SECRET_ACCESS_KEY":
+			assert.Equal(t, instance.Spec.StorageOptions.BackupRepositories[0].S3.Credentials.SecretAccessKeySecret, envVar.ValueFrom.SecretKeyRef, "The Secret Access Key should be read from the secret")
+		case "AWS_SHARED_CREDENTIALS_FILE":
+			assert.Equal(t, util.AWSSecretDir+"/"+util.S3CredentialsFile, envVar.Value, "The credentials file should be read from the mounted secret")
+		}
+	}
+
+	var credentialsVolume *corev1.Volume
+	for _, vol := range statefulSet.Spec.Template.Spec.Volumes {
+		if vol.Name == util.S3CredentialsVolume {
+			credentialsVolume = &vol
+			break
+		}
+	}
+	assert.NotNil(t, credentialsVolume, "The S3 credentials file volume should exist")
+	assert.Equal(t, "aws-secret", credentialsVolume.Secret.SecretName, "The S3 credentials file volume should reference the secret")
+}
+
+func TestAddLogStoragePVCToExistingCloud(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object without separate log storage
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	statefulSet := &appsv1.StatefulSet{}
+	g.Eventually(func() error { return testClient.Get(context.TODO(), cloudSsKey, statefulSet) }, timeout).Should(gomega.Succeed())
+	assert.Empty(t, statefulSet.Spec.VolumeClaimTemplates, "The StatefulSet should not have any volumeClaimTemplates with ephemeral storage")
+	originalTemplate := statefulSet.Spec.Template.DeepCopy()
+
+	// Add a log storage PVC, which cannot be added to the existing StatefulSet
+	g.Eventually(func() error {
+		if err := testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance); err != nil {
+			return err
+		}
+		instance.Spec.StorageOptions.LogStorage = &solr.SolrLogStorageOptions{
+			PersistentVolumeClaimTemplate: &solr.PersistentVolumeClaimTemplate{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+					},
+				},
+			},
+		}
+		return testClient.Update(context.TODO(), instance)
+	}, timeout).Should(gomega.Succeed())
+
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		return meta.IsStatusConditionTrue(instance.Status.Conditions, solr.SolrCloudStatefulSetRecreateRequired)
+	}, timeout).Should(gomega.BeTrue(), "Adding a log storage PVC to an existing SolrCloud should be reported in a condition")
+	assert.Contains(t, meta.FindStatusCondition(instance.Status.Conditions, solr.SolrCloudStatefulSetRecreateRequired).Message, "volumeClaimTemplates", "The condition should explain which change requires the StatefulSet to be recreated")
+
+	g.Expect(testClient.Get(context.TODO(), cloudSsKey, statefulSet)).To(gomega.Succeed())
+	assert.Empty(t, statefulSet.Spec.VolumeClaimTemplates, "The volumeClaimTemplates of an existing StatefulSet cannot be changed")
+	assert.Equal(t, originalTemplate.Spec.Containers[0].VolumeMounts, statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts, "The pod template should not mount a volume that the StatefulSet does not provide")
+	for _, volumeMount := range statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts {
+		assert.NotEqual(t, util.SolrLogsDir, volumeMount.MountPath, "The log storage should not be mounted until the StatefulSet is recreated")
+	}
+}
@@ -30,10 +30,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"strings"
@@ -53,7 +58,6 @@ var (
 )
 
 func TestCloudReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -69,13 +73,14 @@ func TestCloudReconcile(t *testing.T) {
 			SolrLogLevel: "DEBUG",
 			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
 				PodOptions: &solr.PodOptions{
-					EnvVariables:       extraVars,
-					PodSecurityContext: &podSecurityContext,
-					Volumes:            extraVolumes,
-					Affinity:           affinity,
-					Resources:          resources,
-					SidecarContainers:  extraContainers1,
-					InitContainers:     extraContainers2,
+					EnvVariables:             extraVars,
+					PodSecurityContext:       &podSecurityContext,
+					ContainerSecurityContext: &containerSecurityContext,
+					Volumes:                  extraVolumes,
+					Affinity:                 affinity,
+					Resources:                resources,
+					SidecarContainers:        extraContainers1,
+					InitContainers:           extraContainers2,
 				},
 			},
 		},
@@ -88,8 +93,9 @@ func TestCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -149,6 +155,8 @@ func TestCloudReconcile(t *testing.T) {
 
 	// Other Pod Options Checks
 	assert.Equal(t, podSecurityContext, *statefulSet.Spec.Template.Spec.SecurityContext, "PodSecurityContext is not the same as the one provided in podOptions")
+	assert.Equal(t, containerSecurityContext, *statefulSet.Spec.Template.Spec.Containers[0].SecurityContext, "ContainerSecurityContext is not the same as the one provided in podOptions")
+	assert.Nil(t, statefulSet.Spec.Template.Spec.Containers[1].SecurityContext, "ContainerSecurityContext should not be used for sidecar containers")
 	assert.Equal(t, affinity, statefulSet.Spec.Template.Spec.Affinity, "Affinity is not the same as the one provided in podOptions")
 	assert.Equal(t, resources.Limits, statefulSet.Spec.Template.Spec.Containers[0].Resources.Limits, "Resources.Limits is not the same as the one provided in podOptions")
 	assert.Equal(t, resources.Requests, statefulSet.Spec.Template.Spec.Containers[0].Resources.Requests, "Resources.Requests is not the same as the one provided in podOptions")
@@ -172,7 +180,6 @@ func TestCloudReconcile(t *testing.T) {
 }
 
 func TestCustomKubeOptionsCloudReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 	replicas := int32(4)
 
@@ -195,6 +202,9 @@ func TestCustomKubeOptionsCloudReconcile(t *testing.T) {
 			UpdateStrategy: solr.SolrUpdateStrategy{
 				Method:          solr.StatefulSetUpdate,
 				RestartSchedule: "@every 30m",
+				StatefulSetUpdateOptions: &solr.StatefulSetUpdateOptions{
+					Partition: &testStatefulSetPartition,
+				},
 			},
 			SolrGCTune: "gc Options",
 			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
@@ -207,9 +217,13 @@ func TestCustomKubeOptionsCloudReconcile(t *testing.T) {
 					ReadinessProbe:                testProbeReadinessNonDefaults,
 					StartupProbe:                  testProbeStartup,
 					PriorityClassName:             testPriorityClass,
+					RuntimeClassName:              &testRuntimeClassName,
 					ImagePullSecrets:              testAdditionalImagePullSecrets,
 					TerminationGracePeriodSeconds: &testTerminationGracePeriodSeconds,
 					ServiceAccountName:            testServiceAccountName,
+					DNSPolicy:                     corev1.DNSNone,
+					DNSConfig:                     testDNSConfig,
+					EnvFrom:                       testEnvFrom,
 				},
 				StatefulSetOptions: &solr.StatefulSetOptions{
 					Annotations:         testSSAnnotations,
@@ -239,8 +253,9 @@ func TestCustomKubeOptionsCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -299,12 +314,17 @@ func TestCustomKubeOptionsCloudReconcile(t *testing.T) {
 	assert.Equal(t, []string{"solr", "stop", "-p", "8983"}, statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command, "Incorrect pre-stop command")
 	testPodTolerations(t, testTolerations, statefulSet.Spec.Template.Spec.Tolerations)
 	assert.EqualValues(t, testPriorityClass, statefulSet.Spec.Template.Spec.PriorityClassName, "Incorrect Priority class name for Pod Spec")
+	assert.EqualValues(t, &testRuntimeClassName, statefulSet.Spec.Template.Spec.RuntimeClassName, "Incorrect Runtime class name for Pod Spec")
 	assert.ElementsMatch(t, append(testAdditionalImagePullSecrets, corev1.LocalObjectReference{Name: testImagePullSecretName}), statefulSet.Spec.Template.Spec.ImagePullSecrets, "Incorrect imagePullSecrets")
 	assert.EqualValues(t, &testTerminationGracePeriodSeconds, statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, "Incorrect terminationGracePeriodSeconds")
 	assert.EqualValues(t, testServiceAccountName, statefulSet.Spec.Template.Spec.ServiceAccountName, "Incorrect serviceAccountName")
+	assert.EqualValues(t, corev1.DNSNone, statefulSet.Spec.Template.Spec.DNSPolicy, "Incorrect dnsPolicy")
+	assert.EqualValues(t, testDNSConfig, statefulSet.Spec.Template.Spec.DNSConfig, "Incorrect dnsConfig")
+	assert.EqualValues(t, testEnvFrom, statefulSet.Spec.Template.Spec.Containers[0].EnvFrom, "Incorrect envFrom")
 
 	// Check the update strategy
 	assert.EqualValues(t, appsv1.RollingUpdateStatefulSetStrategyType, statefulSet.Spec.UpdateStrategy.Type, "Incorrect statefulset update strategy")
+	assert.EqualValues(t, &testStatefulSetPartition, statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition, "Incorrect statefulset rolling update partition")
 	assert.EqualValues(t, appsv1.OrderedReadyPodManagement, statefulSet.Spec.PodManagementPolicy, "Incorrect statefulset pod management policy")
 
 	// Check the client Service
@@ -321,7 +341,6 @@ func TestCustomKubeOptionsCloudReconcile(t *testing.T) {
 }
 
 func TestCloudWithExternalZookeeperChroot(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 	connString := "host:7271,host2:7271"
 	instance := &solr.SolrCloud{
@@ -343,8 +362,9 @@ func TestCloudWithExternalZookeeperChroot(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -397,7 +417,6 @@ func TestCloudWithExternalZookeeperChroot(t *testing.T) {
 }
 
 func TestDefaults(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 	instance := &solr.SolrCloud{
 		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
@@ -417,8 +436,9 @@ func TestDefaults(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -488,7 +508,6 @@ func TestDefaults(t *testing.T) {
 }
 
 func TestExternalKubeDomainCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	instance := &solr.SolrCloud{
@@ -524,8 +543,9 @@ func TestExternalKubeDomainCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -593,7 +613,6 @@ func TestExternalKubeDomainCloudReconcile(t *testing.T) {
 }
 
 func TestCloudWithCustomSolrXmlConfigMapReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	testCustomSolrXmlConfigMap := "my-custom-solr-xml"
@@ -616,8 +635,9 @@ func TestCloudWithCustomSolrXmlConfigMapReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -636,8 +656,16 @@ func TestCloudWithCustomSolrXmlConfigMapReconcile(t *testing.T) {
 	g.Expect(err).NotTo(gomega.HaveOccurred())
 	defer testClient.Delete(context.TODO(), instance)
 
-	// reconcile will have failed b/c the provided ConfigMap doesn't exist ...
+	// reconcile will not create the StatefulSet b/c the provided ConfigMap doesn't exist ...
 	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	g.Eventually(func() bool {
+		if err := testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance); err != nil {
+			return false
+		}
+		return meta.IsStatusConditionFalse(instance.Status.Conditions, solr.SolrCloudProvidedConfigMapFound)
+	}, timeout).Should(gomega.BeTrue(), "The SolrCloud should report that the providedConfigMap is missing")
+	err = testClient.Get(context.TODO(), cloudSsKey, &appsv1.StatefulSet{})
+	g.Expect(err).To(gomega.HaveOccurred(), "The StatefulSet should not be created without the providedConfigMap")
 
 	// start with an invalid provided ConfigMap
 	invalidConfigMap := &corev1.ConfigMap{
@@ -714,7 +742,6 @@ func TestCloudWithCustomSolrXmlConfigMapReconcile(t *testing.T) {
 }
 
 func TestCloudWithUserProvidedLogConfigMapReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	testCustomLogXmlConfigMap := "my-custom-log4j2-xml"
@@ -737,8 +764,9 @@ func TestCloudWithUserProvidedLogConfigMapReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -853,7 +881,6 @@ func TestCloudWithUserProvidedLogConfigMapReconcile(t *testing.T) {
 }
 
 func TestCloudWithUserProvidedSolrXmlAndLogConfigReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 
 	testCustomConfigMap := "my-custom-config-xml"
@@ -876,8 +903,9 @@ func TestCloudWithUserProvidedSolrXmlAndLogConfigReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -962,3 +990,902 @@ func TestCloudWithUserProvidedSolrXmlAndLogConfigReconcile(t *testing.T) {
 	expectedEnvVars := map[string]string{"LOG4J_PROPS": fmt.Sprintf("%s/%s", expectedMountPath, util.LogXmlFile)}
 	testPodEnvVariables(t, expectedEnvVars, stateful.Spec.Template.Spec.Containers[0].Env)
 }
+
+func TestCloudWithSeparateProvidedConfigMapsReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	testCustomSolrXmlConfigMap := "my-custom-solr-xml"
+	testCustomLogXmlConfigMap := "my-custom-log4j2-xml"
+	testAdditionalConfigMap := "my-additional-config"
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				ConfigMapOptions: &solr.ConfigMapOptions{
+					ProvidedConfigMap:       testCustomSolrXmlConfigMap,
+					ProvidedLogXmlConfigMap: testCustomLogXmlConfigMap,
+					AdditionalConfigMaps: []solr.AdditionalConfigMap{
+						{Name: testAdditionalConfigMap, MountPath: "/opt/custom-config"},
+					},
+				},
+			},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the user-provided ConfigMaps first to streamline reconcile
+	solrXmlConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testCustomSolrXmlConfigMap, Namespace: instance.Namespace},
+		Data: map[string]string{
+			util.SolrXmlFile: "<solr> ${hostPort: </solr>", // the controller checks for ${hostPort: in the solr.xml
+			util.LogXmlFile:  "<Configuration name=\"overridden\"/>",
+		},
+	}
+	logXmlConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testCustomLogXmlConfigMap, Namespace: instance.Namespace},
+		Data:       map[string]string{util.LogXmlFile: "<Configuration/>"},
+	}
+	additionalConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testAdditionalConfigMap, Namespace: instance.Namespace},
+		Data:       map[string]string{"custom.properties": "a=b"},
+	}
+	for _, configMap := range []*corev1.ConfigMap{solrXmlConfigMap, logXmlConfigMap, additionalConfigMap} {
+		g.Expect(testClient.Create(context.TODO(), configMap)).To(gomega.Succeed())
+		defer testClient.Delete(context.TODO(), configMap)
+	}
+
+	// Create the SolrCloud object and expect the Reconcile and StatefulSet to be created
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	stateful := &appsv1.StatefulSet{}
+	g.Eventually(func() error { return testClient.Get(context.TODO(), cloudSsKey, stateful) }, timeout).Should(gomega.Succeed())
+
+	expectedLogXmlMd5 := fmt.Sprintf("%x", md5.Sum([]byte(logXmlConfigMap.Data[util.LogXmlFile])))
+	assert.Equal(t, expectedLogXmlMd5, stateful.Spec.Template.Annotations[util.LogXmlMd5Annotation], "The log4j2.xml of the providedLogXmlConfigMap should take precedence")
+	expectedLogXmlPath := fmt.Sprintf("/var/solr/%s/%s", testCustomLogXmlConfigMap, util.LogXmlFile)
+	testPodEnvVariables(t, map[string]string{"LOG4J_PROPS": expectedLogXmlPath}, stateful.Spec.Template.Spec.Containers[0].Env)
+
+	var additionalConfigVol *corev1.Volume
+	for i, vol := range stateful.Spec.Template.Spec.Volumes {
+		if vol.ConfigMap != nil && vol.ConfigMap.Name == testAdditionalConfigMap {
+			additionalConfigVol = &stateful.Spec.Template.Spec.Volumes[i]
+		}
+	}
+	g.Expect(additionalConfigVol).NotTo(gomega.BeNil(), "Didn't find the additional ConfigMap volume")
+	assert.Contains(t, stateful.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: additionalConfigVol.Name, MountPath: "/opt/custom-config", ReadOnly: true}, "The additional ConfigMap should be mounted into the Solr container")
+	initialConfigMd5 := stateful.Spec.Template.Annotations[util.AdditionalConfigMd5Annotation]
+	assert.NotEmpty(t, initialConfigMd5, "The MD5 of the additional ConfigMaps should be set on the pod template")
+
+	// Changing an additional ConfigMap should change the pod template, which restarts the pods
+	foundConfigMap := &corev1.ConfigMap{}
+	g.Expect(testClient.Get(context.TODO(), types.NamespacedName{Name: testAdditionalConfigMap, Namespace: instance.Namespace}, foundConfigMap)).To(gomega.Succeed())
+	foundConfigMap.Data["custom.properties"] = "a=c"
+	g.Expect(testClient.Update(context.TODO(), foundConfigMap)).To(gomega.Succeed())
+	g.Eventually(func() string {
+		if err := testClient.Get(context.TODO(), cloudSsKey, stateful); err != nil {
+			return ""
+		}
+		return stateful.Spec.Template.Annotations[util.AdditionalConfigMd5Annotation]
+	}, timeout).ShouldNot(gomega.Or(gomega.BeEmpty(), gomega.Equal(initialConfigMd5)), "Changing an additional ConfigMap should update the pod template")
+}
+
+func TestCloudWithInlineLogXmlReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			SolrLogXml: "<Configuration/>",
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object and expect the Reconcile and StatefulSet to be created
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	stateful := &appsv1.StatefulSet{}
+	g.Eventually(func() error { return testClient.Get(context.TODO(), cloudSsKey, stateful) }, timeout).Should(gomega.Succeed())
+
+	foundConfigMap := &corev1.ConfigMap{}
+	g.Expect(testClient.Get(context.TODO(), types.NamespacedName{Name: instance.ConfigMapName(), Namespace: instance.Namespace}, foundConfigMap)).To(gomega.Succeed())
+	assert.Equal(t, instance.Spec.SolrLogXml, foundConfigMap.Data[util.LogXmlFile], "The inline log4j2.xml should be stored in the generated ConfigMap")
+
+	expectedLogXmlMd5 := fmt.Sprintf("%x", md5.Sum([]byte(instance.Spec.SolrLogXml)))
+	assert.Equal(t, expectedLogXmlMd5, stateful.Spec.Template.Annotations[util.LogXmlMd5Annotation], "The MD5 of the inline log4j2.xml should be set on the pod template")
+	expectedLogXmlPath := fmt.Sprintf("/var/solr/%s/%s", instance.ConfigMapName(), util.LogXmlFile)
+	testPodEnvVariables(t, map[string]string{"LOG4J_PROPS": expectedLogXmlPath}, stateful.Spec.Template.Spec.Containers[0].Env)
+
+	// Changing the inline log4j2.xml should change the pod template, which restarts the pods
+	updatedLogXml := "<Configuration name=\"json\"/>"
+	g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+	instance.Spec.SolrLogXml = updatedLogXml
+	g.Expect(testClient.Update(context.TODO(), instance)).To(gomega.Succeed())
+	g.Eventually(func() string {
+		if err := testClient.Get(context.TODO(), cloudSsKey, stateful); err != nil {
+			return ""
+		}
+		return stateful.Spec.Template.Annotations[util.LogXmlMd5Annotation]
+	}, timeout).Should(gomega.Equal(fmt.Sprintf("%x", md5.Sum([]byte(updatedLogXml)))), "Changing the inline log4j2.xml should update the pod template")
+}
+
+func TestPausedCloudReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			Paused: true,
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the paused SolrCloud object and expect nothing to be created for it
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		return meta.IsStatusConditionTrue(instance.Status.Conditions, solr.SolrCloudPaused)
+	}, timeout).Should(gomega.BeTrue(), "A paused SolrCloud should have a Paused condition")
+	expectNoStatefulSet(g, cloudSsKey)
+	expectNoService(g, cloudCsKey, "The common service should not be created for a paused SolrCloud")
+
+	// Resume the SolrCloud and expect it to be reconciled normally
+	instance.Spec.Paused = false
+	g.Expect(testClient.Update(context.TODO(), instance)).To(gomega.Succeed())
+	expectStatefulSet(t, g, requests, expectedCloudRequest, cloudSsKey)
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		return meta.FindStatusCondition(instance.Status.Conditions, solr.SolrCloudPaused) == nil
+	}, timeout).Should(gomega.BeTrue(), "The Paused condition should be removed once the SolrCloud is resumed")
+}
+
+func TestCloudWithNodePoolsReconcile(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	replicas := int32(1)
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			NodePools: []solr.SolrNodePool{
+				{
+					Name:         "large",
+					Replicas:     2,
+					SolrJavaMem:  "-Xms8g -Xmx8g",
+					NodeSelector: map[string]string{"instance-type": "large"},
+				},
+			},
+		},
+	}
+	poolSsKey := types.NamespacedName{Name: cloudSsKey.Name + "-large", Namespace: cloudSsKey.Namespace}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object and expect a StatefulSet for the SolrCloud and for its node pool
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	statefulSet := expectStatefulSet(t, g, requests, expectedCloudRequest, cloudSsKey)
+	assert.EqualValues(t, replicas, *statefulSet.Spec.Replicas, "Solr StatefulSet has incorrect number of replicas.")
+	assert.Empty(t, statefulSet.Spec.Template.Spec.NodeSelector, "The node pool nodeSelector should not be used by the SolrCloud's StatefulSet")
+
+	poolStatefulSet := expectStatefulSet(t, g, requests, expectedCloudRequest, poolSsKey)
+	assert.EqualValues(t, 2, *poolStatefulSet.Spec.Replicas, "Node pool StatefulSet has incorrect number of replicas.")
+	assert.Equal(t, "large", poolStatefulSet.Spec.Selector.MatchLabels[util.SolrNodePoolLabel], "The node pool StatefulSet should only select the pods of its pool")
+	assert.Equal(t, map[string]string{"instance-type": "large"}, poolStatefulSet.Spec.Template.Spec.NodeSelector, "The node pool nodeSelector should be used")
+	testPodEnvVariables(t, map[string]string{"SOLR_JAVA_MEM": "-Xms8g -Xmx8g"}, poolStatefulSet.Spec.Template.Spec.Containers[0].Env)
+
+	// Every Solr node, including the nodes of the pool, should be expected in the status
+	g.Eventually(func() string {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		if readyCondition := meta.FindStatusCondition(instance.Status.Conditions, solr.SolrCloudReady); readyCondition != nil {
+			return readyCondition.Message
+		}
+		return ""
+	}, timeout).Should(gomega.Equal("0 of 3 Solr pods are ready"), "The Ready condition should count the pods of the node pool")
+
+	// Remove the node pool and expect its StatefulSet to be deleted
+	g.Expect(testClient.Get(context.TODO(), poolSsKey, poolStatefulSet)).To(gomega.Succeed())
+	instance.Spec.NodePools = nil
+	g.Expect(testClient.Update(context.TODO(), instance)).To(gomega.Succeed())
+	expectNoStatefulSet(g, poolSsKey)
+}
+
+func TestRemovedNodePoolIsVacatedBeforeDeletion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	replicas := int32(1)
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			NodePools: []solr.SolrNodePool{
+				{
+					Name:     "large",
+					Replicas: 2,
+				},
+			},
+			Scaling: solr.SolrScalingOptions{
+				VacatePodsOnScaleDown: true,
+			},
+		},
+	}
+	poolSsKey := types.NamespacedName{Name: cloudSsKey.Name + "-large", Namespace: cloudSsKey.Namespace}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object and expect a StatefulSet for its node pool
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	poolStatefulSet := expectStatefulSet(t, g, requests, expectedCloudRequest, poolSsKey)
+
+	updateCloud := func(update func(cloud *solr.SolrCloud)) {
+		g.Eventually(func() error {
+			if err := testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance); err != nil {
+				return err
+			}
+			update(instance)
+			return testClient.Update(context.TODO(), instance)
+		}, timeout).Should(gomega.Succeed())
+	}
+
+	// Remove the node pool, and expect its StatefulSet to be kept since Solr cannot be reached to move the replicas off of its pods
+	updateCloud(func(cloud *solr.SolrCloud) {
+		cloud.Spec.NodePools = nil
+	})
+	for i := 0; i < 2; i++ {
+		g.Eventually(requests, timeout*2).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	}
+	g.Expect(testClient.Get(context.TODO(), poolSsKey, poolStatefulSet)).To(gomega.Succeed())
+	assert.Nil(t, poolStatefulSet.DeletionTimestamp, "The StatefulSet of a removed node pool should not be deleted until its pods have been vacated")
+
+	// Without vacatePodsOnScaleDown, the replicas are not moved and the StatefulSet is deleted along with them
+	updateCloud(func(cloud *solr.SolrCloud) {
+		cloud.Spec.Scaling.VacatePodsOnScaleDown = false
+	})
+	expectNoStatefulSet(g, poolSsKey)
+}
+
+func TestCloudAdoptsExistingResources(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+		},
+	}
+
+	// A hand-rolled StatefulSet and common Service, created before the SolrCloud with the names that the operator uses
+	podLabels := map[string]string{"solr-cloud": instance.Name, "technology": solr.SolrTechnologyLabel}
+	existingReplicas := int32(1)
+	existingStatefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: cloudSsKey.Name, Namespace: cloudSsKey.Namespace},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &existingReplicas,
+			ServiceName: cloudHsKey.Name,
+			Selector:    &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "solr", Image: "library/solr:8.9"}},
+				},
+			},
+		},
+	}
+	existingService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: cloudCsKey.Name, Namespace: cloudCsKey.Namespace},
+		Spec: corev1.ServiceSpec{
+			Selector: podLabels,
+			Ports:    []corev1.ServicePort{{Name: "solr-client", Port: 8983}},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	g.Expect(testClient.Create(context.TODO(), existingStatefulSet)).To(gomega.Succeed())
+	defer testClient.Delete(context.TODO(), existingStatefulSet)
+	g.Expect(testClient.Create(context.TODO(), existingService)).To(gomega.Succeed())
+	defer testClient.Delete(context.TODO(), existingService)
+
+	// Create the SolrCloud object and expect it to take control of the existing resources, instead of failing to create its own
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	statefulSet := &appsv1.StatefulSet{}
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), cloudSsKey, statefulSet)).To(gomega.Succeed())
+		owner := metav1.GetControllerOf(statefulSet)
+		return owner != nil && owner.Name == instance.Name && owner.Kind == "SolrCloud"
+	}, timeout).Should(gomega.BeTrue(), "The existing StatefulSet should be adopted by the SolrCloud")
+	assert.Equal(t, existingStatefulSet.UID, statefulSet.UID, "The existing StatefulSet should be updated, not recreated")
+	assert.EqualValues(t, 3, *statefulSet.Spec.Replicas, "The adopted StatefulSet should be updated to the spec of the SolrCloud")
+	assert.Equal(t, util.SolrNodeContainer, statefulSet.Spec.Template.Spec.Containers[0].Name, "The adopted StatefulSet should use the pod template of the SolrCloud")
+
+	service := &corev1.Service{}
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), cloudCsKey, service)).To(gomega.Succeed())
+		return metav1.IsControlledBy(service, instance)
+	}, timeout).Should(gomega.BeTrue(), "The existing common Service should be adopted by the SolrCloud")
+	assert.Equal(t, existingService.UID, service.UID, "The existing common Service should be updated, not recreated")
+}
+
+func TestRecreateStatefulSetOnPodManagementPolicyChange(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				StatefulSetOptions: &solr.StatefulSetOptions{
+					PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+				},
+			},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	recorder := record.NewFakeRecorder(100)
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Recorder: recorder,
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Create the SolrCloud object and expect the StatefulSet to be created with the requested policy
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+
+	statefulSet := &appsv1.StatefulSet{}
+	g.Eventually(func() error { return testClient.Get(context.TODO(), cloudSsKey, statefulSet) }, timeout).Should(gomega.Succeed())
+	assert.EqualValues(t, appsv1.OrderedReadyPodManagement, statefulSet.Spec.PodManagementPolicy, "Incorrect statefulset pod management policy")
+	originalUID := statefulSet.UID
+	g.Eventually(recorder.Events, timeout).Should(gomega.Receive(gomega.HavePrefix("Normal CreatedStatefulSet")), "An event should be emitted for the created StatefulSet")
+
+	updateCloud := func(update func(cloud *solr.SolrCloud)) {
+		g.Eventually(func() error {
+			if err := testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance); err != nil {
+				return err
+			}
+			update(instance)
+			return testClient.Update(context.TODO(), instance)
+		}, timeout).Should(gomega.Succeed())
+	}
+
+	// Change the policy without allowing a recreate, and expect it to only be reported
+	updateCloud(func(cloud *solr.SolrCloud) {
+		cloud.Spec.CustomSolrKubeOptions.StatefulSetOptions.PodManagementPolicy = appsv1.ParallelPodManagement
+	})
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		return meta.IsStatusConditionTrue(instance.Status.Conditions, solr.SolrCloudStatefulSetRecreateRequired)
+	}, timeout).Should(gomega.BeTrue(), "A podManagementPolicy change that cannot be applied should be reported in a condition")
+	g.Eventually(recorder.Events, timeout).Should(gomega.Receive(gomega.HavePrefix("Warning StatefulSetRecreateRequired")), "An event should be emitted for the podManagementPolicy change that cannot be applied")
+	g.Expect(testClient.Get(context.TODO(), cloudSsKey, statefulSet)).To(gomega.Succeed())
+	assert.Equal(t, originalUID, statefulSet.UID, "The StatefulSet should not be recreated unless requested")
+	assert.Nil(t, statefulSet.DeletionTimestamp, "The StatefulSet should not be deleted unless requested")
+	assert.EqualValues(t, appsv1.OrderedReadyPodManagement, statefulSet.Spec.PodManagementPolicy, "The podManagementPolicy of a StatefulSet cannot be updated")
+
+	// Allow the recreate, and expect the StatefulSet to be deleted without its pods
+	updateCloud(func(cloud *solr.SolrCloud) {
+		cloud.Spec.CustomSolrKubeOptions.StatefulSetOptions.RecreateOnPodManagementPolicyChange = true
+	})
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), cloudSsKey, statefulSet)).To(gomega.Succeed())
+		return statefulSet.DeletionTimestamp != nil
+	}, timeout).Should(gomega.BeTrue(), "The StatefulSet should be deleted to change its podManagementPolicy")
+	assert.Contains(t, statefulSet.Finalizers, metav1.FinalizerOrphanDependents, "The StatefulSet should be deleted without deleting its pods")
+	g.Eventually(recorder.Events, timeout).Should(gomega.Receive(gomega.HavePrefix("Normal RecreatingStatefulSet")), "An event should be emitted for recreating the StatefulSet")
+
+	// Remove the orphan finalizer, since GC isn't enabled in the test control plane, and expect the StatefulSet to be recreated
+	statefulSet.Finalizers = nil
+	g.Expect(testClient.Update(context.TODO(), statefulSet)).To(gomega.Succeed())
+	g.Eventually(func() bool {
+		if err := testClient.Get(context.TODO(), cloudSsKey, statefulSet); err != nil {
+			return false
+		}
+		return statefulSet.UID != originalUID
+	}, timeout).Should(gomega.BeTrue(), "The StatefulSet should be recreated once it has been deleted")
+	assert.EqualValues(t, appsv1.ParallelPodManagement, statefulSet.Spec.PodManagementPolicy, "The recreated StatefulSet should use the new podManagementPolicy")
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		return meta.FindStatusCondition(instance.Status.Conditions, solr.SolrCloudStatefulSetRecreateRequired) == nil
+	}, timeout).Should(gomega.BeTrue(), "The StatefulSetRecreateRequired condition should be removed once the StatefulSet has been recreated")
+}
+
+func TestWarningEventsOnlyEmittedWhenConditionChanges(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			SolrTLS: createTLSOptions("missing-tls-secret", "keystore-passwords-are-important", false),
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	recorder := record.NewFakeRecorder(100)
+	solrCloudReconciler := &SolrCloudReconciler{
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Recorder: recorder,
+		UseZkCRD: true,
+	}
+	newRec, requests := SetupTestReconcile(solrCloudReconciler)
+	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, instance.Namespace)
+
+	// Reconcile the SolrCloud a few more times while the problem persists, and expect it to not be reported again
+	expectNoRepeatedEvent := func(reason string) {
+		for i := 0; i < 2; i++ {
+			g.Eventually(requests, timeout*2).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+		}
+		for len(recorder.Events) > 0 {
+			g.Expect(<-recorder.Events).NotTo(gomega.HavePrefix("Warning "+reason), "The %s event should only be emitted when the condition changes", reason)
+		}
+	}
+
+	// Create the SolrCloud object with a TLS secret that does not exist
+	err = testClient.Create(context.TODO(), instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(context.TODO(), instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedCloudRequest)))
+	g.Eventually(recorder.Events, timeout).Should(gomega.Receive(gomega.HavePrefix("Warning TLSSecretNotReady")), "An event should be emitted for the missing TLS secret")
+	expectNoRepeatedEvent("TLSSecretNotReady")
+
+	// Remove TLS and load env vars from a ConfigMap that does not exist
+	g.Eventually(func() error {
+		if err := testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance); err != nil {
+			return err
+		}
+		instance.Spec.SolrTLS = nil
+		instance.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{EnvFrom: testEnvFrom}
+		return testClient.Update(context.TODO(), instance)
+	}, timeout).Should(gomega.Succeed())
+	g.Eventually(recorder.Events, timeout).Should(gomega.Receive(gomega.HavePrefix("Warning EnvFromSourceNotFound")), "An event should be emitted for the missing envFrom ConfigMap")
+	g.Eventually(func() bool {
+		g.Expect(testClient.Get(context.TODO(), expectedCloudRequest.NamespacedName, instance)).To(gomega.Succeed())
+		return meta.IsStatusConditionFalse(instance.Status.Conditions, solr.SolrCloudEnvFromSourcesFound)
+	}, timeout).Should(gomega.BeTrue(), "The missing envFrom ConfigMap should be reported in a condition")
+	expectNoRepeatedEvent("EnvFromSourceNotFound")
+}
+
+func TestBackoffForCondition(t *testing.T) {
+	r := &SolrCloudReconciler{RequeueIntervals: RequeueIntervals{Poll: time.Second, MaxBackoff: time.Second * 5}}
+
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "backoff", Namespace: "default"}}
+	otherCloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "backoff-other", Namespace: "default"}}
+
+	assert.Equal(t, time.Second, r.backoffForCondition(cloud, solr.SolrCloudTLSReady), "The backoff should start at the poll interval")
+	assert.Equal(t, time.Second*2, r.backoffForCondition(cloud, solr.SolrCloudTLSReady), "The backoff should double while the condition is not met")
+	assert.Equal(t, time.Second*4, r.backoffForCondition(cloud, solr.SolrCloudTLSReady), "The backoff should double while the condition is not met")
+	assert.Equal(t, time.Second*5, r.backoffForCondition(cloud, solr.SolrCloudTLSReady), "The backoff should be capped at the max backoff")
+	assert.Equal(t, time.Second, r.backoffForCondition(cloud, solr.SolrCloudZookeeperConnected), "Each condition should have its own backoff")
+	assert.Equal(t, time.Second, r.backoffForCondition(otherCloud, solr.SolrCloudTLSReady), "Each SolrCloud should have its own backoff")
+
+	r.resetBackoffForCondition(cloud, solr.SolrCloudTLSReady)
+	assert.Equal(t, time.Second, r.backoffForCondition(cloud, solr.SolrCloudTLSReady), "The backoff should start over once the condition has been met")
+
+	r.backoffForCondition(cloud, solr.SolrCloudTLSReady)
+	r.backoffForCondition(cloud, solr.SolrCloudZookeeperConnected)
+	r.forgetBackoffsForCloud(types.NamespacedName{Name: cloud.Name, Namespace: cloud.Namespace})
+	assert.Equal(t, time.Second, r.backoffForCondition(cloud, solr.SolrCloudTLSReady), "The backoffs should be forgotten once the SolrCloud is deleted")
+	assert.Equal(t, time.Second, r.backoffForCondition(cloud, solr.SolrCloudZookeeperConnected), "The backoffs should be forgotten once the SolrCloud is deleted")
+	assert.Equal(t, time.Second*2, r.backoffForCondition(otherCloud, solr.SolrCloudTLSReady), "The backoffs of other SolrClouds should be kept")
+}
+
+func TestSolrCloudConditionChanged(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "conditions", Namespace: "default"}}
+
+	assert.True(t, solrCloudConditionChanged(cloud, solr.SolrCloudStorageClassesFound, false, "StorageClassNotFound", "StorageClasses not found: fast"), "A condition that is not yet present should be reported as changed")
+
+	newStatus := solr.SolrCloudStatus{}
+	setSolrCloudCondition(cloud, &newStatus, solr.SolrCloudStorageClassesFound, false, "StorageClassNotFound", "StorageClasses not found: fast")
+	cloud.Status.Conditions = newStatus.Conditions
+
+	assert.False(t, solrCloudConditionChanged(cloud, solr.SolrCloudStorageClassesFound, false, "StorageClassNotFound", "StorageClasses not found: fast"), "An unchanged condition should not be reported as changed")
+	assert.True(t, solrCloudConditionChanged(cloud, solr.SolrCloudStorageClassesFound, false, "StorageClassNotFound", "StorageClasses not found: fast, slow"), "A condition with a different message should be reported as changed")
+	assert.True(t, solrCloudConditionChanged(cloud, solr.SolrCloudStorageClassesFound, true, "StorageClassesFound", "All StorageClasses requested by the PVC templates exist"), "A condition with a different status should be reported as changed")
+	assert.True(t, solrCloudConditionChanged(cloud, solr.SolrCloudZookeeperConnected, false, "StorageClassNotFound", "StorageClasses not found: fast"), "Each condition type should be compared separately")
+}
+
+func TestWarnOnPersistentVersionSkew(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &SolrCloudReconciler{Recorder: recorder}
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "skew", Namespace: "default"}}
+
+	// Reconcile the status as reconcileCloudStatus does, then check for a persistent version skew
+	reconcileSkew := func() *time.Duration {
+		newStatus := solr.SolrCloudStatus{Conditions: cloud.Status.DeepCopy().Conditions}
+		setSolrCloudCondition(cloud, &newStatus, solr.SolrCloudVersionSkew, true, "VersionsDiffer", "2 Solr pods are not running version 8.11")
+		waitDuration := warnOnPersistentVersionSkew(r, cloud, &newStatus)
+		cloud.Status.Conditions = newStatus.Conditions
+		return waitDuration
+	}
+
+	waitDuration := reconcileSkew()
+	if assert.NotNil(t, waitDuration, "The SolrCloud should be requeued for when the version skew warning is due") {
+		assert.InDelta(t, time.Hour.Seconds(), waitDuration.Seconds(), 60, "The warning should be due after the default versionSkewWarningSeconds")
+	}
+	assert.Empty(t, recorder.Events, "No event should be emitted before the version skew has lasted long enough")
+
+	// Let the version skew last longer than the versionSkewWarningSeconds
+	cloud.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	assert.Nil(t, reconcileSkew(), "The SolrCloud should not be requeued once the version skew warning has been emitted")
+	if assert.Len(t, recorder.Events, 1, "An event should be emitted once the version skew has lasted long enough") {
+		assert.Contains(t, <-recorder.Events, "Warning VersionSkewPersisting", "Wrong event for a persistent version skew")
+	}
+	assert.Equal(t, "VersionSkewPersisting", cloud.Status.Conditions[0].Reason, "The condition should report that the version skew is persisting")
+
+	assert.Nil(t, reconcileSkew(), "The SolrCloud should not be requeued once the version skew warning has been emitted")
+	assert.Empty(t, recorder.Events, "The event should not be emitted again while the version skew persists")
+}
+
+func TestSetSolrCloudCondition(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "conditions", Namespace: "default", Generation: 2}}
+	newStatus := solr.SolrCloudStatus{}
+
+	setSolrCloudCondition(cloud, &newStatus, solr.SolrCloudReady, false, "PodsNotReady", "0 of 3 Solr pods are ready")
+	readyCondition := meta.FindStatusCondition(newStatus.Conditions, solr.SolrCloudReady)
+	if assert.NotNil(t, readyCondition, "The condition should be added to the status") {
+		assert.Equal(t, metav1.ConditionFalse, readyCondition.Status, "Wrong condition status")
+		assert.Equal(t, "PodsNotReady", readyCondition.Reason, "Wrong condition reason")
+		assert.Equal(t, "0 of 3 Solr pods are ready", readyCondition.Message, "Wrong condition message")
+		assert.EqualValues(t, 2, readyCondition.ObservedGeneration, "The condition should be observed for the generation of the SolrCloud")
+	}
+
+	// Keep the transition time while the status stays the same
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	newStatus.Conditions[0].LastTransitionTime = transitionTime
+	setSolrCloudCondition(cloud, &newStatus, solr.SolrCloudReady, false, "PodsNotReady", "1 of 3 Solr pods are ready")
+	readyCondition = meta.FindStatusCondition(newStatus.Conditions, solr.SolrCloudReady)
+	assert.Equal(t, transitionTime, readyCondition.LastTransitionTime, "The transition time should not change while the condition status is the same")
+	assert.Equal(t, "1 of 3 Solr pods are ready", readyCondition.Message, "The condition message should be updated")
+
+	setSolrCloudCondition(cloud, &newStatus, solr.SolrCloudReady, true, "PodsReady", "3 of 3 Solr pods are ready")
+	readyCondition = meta.FindStatusCondition(newStatus.Conditions, solr.SolrCloudReady)
+	assert.Equal(t, metav1.ConditionTrue, readyCondition.Status, "Wrong condition status")
+	assert.NotEqual(t, transitionTime, readyCondition.LastTransitionTime, "The transition time should change along with the condition status")
+
+	setSolrCloudCondition(cloud, &newStatus, solr.SolrCloudUpgrading, false, "PodsUpToDate", "All Solr pods are running the latest pod spec")
+	assert.Len(t, newStatus.Conditions, 2, "Each condition type should be kept separately")
+}
+
+func TestNeedsStorageFinalizerReconcile(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "storage", Namespace: "default"}}
+	assert.False(t, needsStorageFinalizerReconcile(cloud), "Ephemeral storage does not need the storage finalizer")
+
+	cloud.Spec.StorageOptions.PersistentStorage = &solr.SolrPersistentDataStorageOptions{}
+	assert.True(t, needsStorageFinalizerReconcile(cloud), "Persistent storage needs the storage finalizer")
+
+	cloud.Spec.StorageOptions.PersistentStorage = nil
+	cloud.ObjectMeta.Finalizers = []string{util.SolrStorageFinalizer}
+	assert.True(t, needsStorageFinalizerReconcile(cloud), "The storage finalizer should be removed once persistent storage is no longer used")
+}
+
+func TestMaxConcurrentReconciles(t *testing.T) {
+	maxReconciles, err := (&SolrCloudReconciler{}).maxConcurrentReconciles()
+	assert.NoError(t, err, "The maximum number of concurrent reconciles does not need to be set")
+	assert.Equal(t, 1, maxReconciles, "A single SolrCloud should be reconciled at a time by default")
+
+	maxReconciles, err = (&SolrCloudReconciler{MaxConcurrentReconciles: 4}).maxConcurrentReconciles()
+	assert.NoError(t, err, "Reconciling multiple SolrClouds in parallel is valid")
+	assert.Equal(t, 4, maxReconciles, "The maximum number of concurrent reconciles should be used")
+
+	_, err = (&SolrCloudReconciler{MaxConcurrentReconciles: -1}).maxConcurrentReconciles()
+	assert.Error(t, err, "At least one SolrCloud must be reconciled at a time")
+}
+
+func TestDrainFinalizerDuringDryRun(t *testing.T) {
+	timeoutSeconds := int32(600)
+	deletionTime := metav1.Now()
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "drain",
+			Namespace:         "default",
+			DeletionTimestamp: &deletionTime,
+			Finalizers:        []string{util.SolrDrainFinalizer},
+		},
+		Spec: solr.SolrCloudSpec{
+			DeletionOptions: &solr.SolrCloudDeletionOptions{
+				Method:         solr.DrainMethodBackup,
+				TimeoutSeconds: &timeoutSeconds,
+			},
+		},
+	}
+	r := &SolrCloudReconciler{
+		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		dryRun: true,
+	}
+
+	// No http client is set up for Solr in this test, so any call to Solr would fail
+	newStatus := solr.SolrCloudStatus{}
+	draining, err := r.reconcileDrainFinalizer(cloud, &newStatus, "", r.Log)
+	assert.NoError(t, err, "Draining should not fail during a dry run")
+	assert.True(t, draining, "The deleted SolrCloud should be reported as draining during a dry run")
+	assert.Nil(t, newStatus.Drain, "No collections should be listed from Solr during a dry run")
+	assert.Contains(t, cloud.Finalizers, util.SolrDrainFinalizer, "The drain finalizer should be kept during a dry run")
+
+	drained, err := r.drainCollections(cloud, &newStatus, nil, r.Log)
+	assert.NoError(t, err, "Draining should not fail during a dry run")
+	assert.False(t, drained, "The collections are not drained during a dry run")
+	assert.Nil(t, newStatus.Drain, "No collections should be listed from Solr during a dry run")
+}
+
+func TestZookeeperCleanupStopsSolrPods(t *testing.T) {
+	deletionTime := metav1.Now()
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "zk-cleanup",
+			Namespace:         "default",
+			UID:               "zk-cleanup-uid",
+			DeletionTimestamp: &deletionTime,
+			Finalizers:        []string{util.SolrZookeeperCleanupFinalizer},
+		},
+		Status: solr.SolrCloudStatus{
+			ZookeeperConnectionInfo: solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/zk-cleanup"},
+		},
+	}
+	isController := true
+	replicas := int32(3)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            cloud.StatefulSetName(),
+			Namespace:       cloud.Namespace,
+			Labels:          cloud.SharedLabels(),
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: solr.GroupVersion.String(), Kind: "SolrCloud", Name: cloud.Name, UID: cloud.UID, Controller: &isController}},
+		},
+		Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+	}
+	podLabels := cloud.SharedLabels()
+	podLabels["technology"] = solr.SolrTechnologyLabel
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: cloud.StatefulSetName() + "-0", Namespace: cloud.Namespace, Labels: podLabels}}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &SolrCloudReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, statefulSet, pod),
+		scheme:   scheme.Scheme,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Recorder: recorder,
+	}
+
+	newStatus := solr.SolrCloudStatus{}
+	stopped, err := r.stopSolrPodsForZookeeperCleanup(cloud, &newStatus, r.Log)
+	assert.NoError(t, err, "Stopping the Solr pods should not fail")
+	assert.False(t, stopped, "The Solr pods are not stopped while a Solr pod remains")
+	foundStatefulSet := &appsv1.StatefulSet{}
+	assert.NoError(t, r.Get(context.TODO(), types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace}, foundStatefulSet))
+	assert.EqualValues(t, 0, *foundStatefulSet.Spec.Replicas, "The StatefulSet should be scaled down to 0 pods before the chroot is removed")
+	condition := meta.FindStatusCondition(newStatus.Conditions, solr.SolrCloudZookeeperCleanupPending)
+	if assert.NotNil(t, condition, "The cleanup condition should be set while waiting for the Solr pods") {
+		assert.Equal(t, "StoppingSolrPods", condition.Reason)
+	}
+	jobs := &batchv1.JobList{}
+	assert.NoError(t, r.List(context.TODO(), jobs))
+	assert.Empty(t, jobs.Items, "The cleanup Job should not be created while Solr pods remain")
+
+	assert.NoError(t, r.Delete(context.TODO(), pod))
+	stopped, err = r.stopSolrPodsForZookeeperCleanup(cloud, &newStatus, r.Log)
+	assert.NoError(t, err, "Stopping the Solr pods should not fail")
+	assert.True(t, stopped, "The Solr pods are stopped once no Solr pod remains")
+
+	// A failed cleanup Job is only reported once
+	job := util.GenerateZookeeperCleanupJob(cloud)
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	assert.NoError(t, r.Create(context.TODO(), job))
+	for i := 0; i < 2; i++ {
+		newStatus = solr.SolrCloudStatus{}
+		cleanedUp, err := r.runZookeeperCleanupJob(cloud, &newStatus, r.Log)
+		assert.NoError(t, err, "A failed cleanup Job is not an error")
+		assert.False(t, cleanedUp, "The chroot is not removed when the cleanup Job fails")
+		cloud.Status.Conditions = newStatus.Conditions
+	}
+	assert.Len(t, recorder.Events, 1, "The ZookeeperCleanupFailed event should only be emitted when the condition changes")
+	assert.True(t, strings.HasPrefix(<-recorder.Events, "Warning ZookeeperCleanupFailed"), "The failed cleanup Job should be reported")
+}
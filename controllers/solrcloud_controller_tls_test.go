@@ -30,6 +30,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -57,7 +58,6 @@ func TestBasicAuthBootstrapSecurityJson(t *testing.T) {
 }
 
 func TestBasicAuthBootstrapSecurityJsonWithZkACLs(t *testing.T) {
-	UseZkCRD(true)
 	instance := buildTestSolrCloud()
 	instance.Spec.SolrSecurity = &solr.SolrSecurityOptions{AuthenticationType: solr.Basic, ProbesRequireAuth: true}
 	zkReplicas := int32(1)
@@ -233,6 +233,23 @@ func TestTLSSecretUpdate(t *testing.T) {
 	verifyReconcileUserSuppliedTLS(t, instance, false, true)
 }
 
+func TestTLSSecretUpdateWithSeparateTrustStore(t *testing.T) {
+	tlsSecretName := "tls-cert-secret-update-truststore"
+	keystorePassKey := "some-password-key-thingy"
+	instance := buildTestSolrCloud()
+	instance.Spec.SolrTLS = createTLSOptions(tlsSecretName, keystorePassKey, true)
+	instance.Spec.SolrTLS.TrustStoreSecret = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "custom-truststore-secret-update"},
+		Key:                  "truststore.p12",
+	}
+	instance.Spec.SolrTLS.TrustStorePasswordSecret = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "custom-truststore-secret-update"},
+		Key:                  "truststore-pass",
+	}
+	verifyUserSuppliedTLSConfig(t, instance.Spec.SolrTLS, tlsSecretName, keystorePassKey, tlsSecretName, false)
+	verifyReconcileUserSuppliedTLS(t, instance, false, true)
+}
+
 func verifyReconcileUserSuppliedTLS(t *testing.T, instance *solr.SolrCloud, needsPkcs12InitContainer bool, restartOnTLSSecretUpdate bool) {
 	g := gomega.NewGomegaWithT(t)
 	ctx := context.TODO()
@@ -244,8 +261,9 @@ func verifyReconcileUserSuppliedTLS(t *testing.T, instance *solr.SolrCloud, need
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 
@@ -309,6 +327,19 @@ func verifyReconcileUserSuppliedTLS(t *testing.T, instance *solr.SolrCloud, need
 	expectedCertMd5 := fmt.Sprintf("%x", md5.Sum(foundTLSSecret.Data[util.TLSCertKey]))
 	assert.Equal(t, expectedCertMd5, sts.Spec.Template.ObjectMeta.Annotations[util.SolrTlsCertMd5Annotation],
 		"TLS cert MD5 annotation on STS does not match the secret")
+	if instance.Spec.SolrTLS.KeyStorePasswordSecret.Name == instance.Spec.SolrTLS.PKCS12Secret.Name {
+		assert.Equal(t, foundTLSSecret.ResourceVersion, sts.Spec.Template.ObjectMeta.Annotations[util.SolrTlsPasswordVersionAnnotation],
+			"Keystore password version annotation on STS does not match the password secret")
+	}
+
+	if instance.Spec.SolrTLS.TrustStoreSecret != nil {
+		foundTrustStoreSecret := &corev1.Secret{}
+		err = testClient.Get(ctx, types.NamespacedName{Name: instance.Spec.SolrTLS.TrustStoreSecret.Name, Namespace: instance.Namespace}, foundTrustStoreSecret)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		expectedTrustStoreMd5 := fmt.Sprintf("%x", md5.Sum(foundTrustStoreSecret.Data[instance.Spec.SolrTLS.TrustStoreSecret.Key]))
+		assert.Equal(t, expectedTrustStoreMd5, sts.Spec.Template.ObjectMeta.Annotations[util.SolrTlsTrustStoreMd5Annotation],
+			"TLS truststore MD5 annotation on STS does not match the truststore secret")
+	}
 
 	// change the tls.crt which should trigger a rolling restart
 	updatedTlsCertData := "certificate renewed"
@@ -327,6 +358,10 @@ func verifyReconcileUserSuppliedTLS(t *testing.T, instance *solr.SolrCloud, need
 	expectedCertMd5 = fmt.Sprintf("%x", md5.Sum(foundTLSSecret.Data[util.TLSCertKey]))
 	assert.Equal(t, expectedCertMd5, sts.Spec.Template.ObjectMeta.Annotations[util.SolrTlsCertMd5Annotation],
 		"TLS cert MD5 annotation on STS does not match the UPDATED secret")
+	if instance.Spec.SolrTLS.KeyStorePasswordSecret.Name == instance.Spec.SolrTLS.PKCS12Secret.Name {
+		assert.Equal(t, foundTLSSecret.ResourceVersion, sts.Spec.Template.ObjectMeta.Annotations[util.SolrTlsPasswordVersionAnnotation],
+			"Keystore password version annotation on STS does not match the UPDATED password secret")
+	}
 
 	// does basic-auth work with TLS? That's the most common so we test both here
 	if instance.Spec.SolrSecurity != nil {
@@ -382,8 +417,9 @@ func verifyReconcileWithSecurity(t *testing.T, instance *solr.SolrCloud, deleteB
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 
@@ -565,6 +601,68 @@ func expectBasicAuthConfigOnPodTemplate(t *testing.T, instance *solr.SolrCloud,
 	return &mainContainer // return as a convenience in case tests want to do more checking on the main container
 }
 
+func TestScaleUpWaitsForTLSCertificate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	ctx := context.TODO()
+	helper := NewTLSTestHelper(g)
+	defer helper.StopTest()
+	cleanupTest(g, expectedCloudWithTLSRequest.Namespace)
+
+	replicas := int32(1)
+	instance := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudWithTLSRequest.Name, Namespace: expectedCloudWithTLSRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			SolrTLS: createTLSOptions("tls-hostnames-secret", "keystore-password", false),
+		},
+	}
+	instance.Spec.SolrTLS.CheckPeerName = true
+
+	// The certificate is only valid for the first Solr node
+	tlsSecret, err := createMockTLSSecret(ctx, testClient, instance.Spec.SolrTLS.PKCS12Secret.Name, instance.Spec.SolrTLS.PKCS12Secret.Key, instance.Namespace, instance.Spec.SolrTLS.KeyStorePasswordSecret.Key)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(ctx, &tlsSecret)
+	tlsSecret.Data[util.TLSCertKey] = createMockTLSCertificate(t, "foo-tls-solrcloud-common.default", "foo-tls-solrcloud-0.foo-tls-solrcloud-headless.default")
+	g.Expect(testClient.Update(ctx, &tlsSecret)).To(gomega.Succeed())
+
+	g.Expect(testClient.Create(ctx, instance)).To(gomega.Succeed())
+	defer testClient.Delete(ctx, instance)
+
+	stateful := &appsv1.StatefulSet{}
+	g.Eventually(func() error { return testClient.Get(ctx, expectedStatefulSetName, stateful) }, timeout).Should(gomega.Succeed())
+	assert.EqualValues(t, 1, *stateful.Spec.Replicas, "The StatefulSet should be created with the requested number of replicas")
+
+	// Scale up, the new pods are not covered by the certificate yet
+	g.Expect(testClient.Get(ctx, expectedCloudWithTLSRequest.NamespacedName, instance)).To(gomega.Succeed())
+	replicas = 3
+	instance.Spec.Replicas = &replicas
+	g.Expect(testClient.Update(ctx, instance)).To(gomega.Succeed())
+	g.Eventually(func() string {
+		g.Expect(testClient.Get(ctx, expectedCloudWithTLSRequest.NamespacedName, instance)).To(gomega.Succeed())
+		if condition := meta.FindStatusCondition(instance.Status.Conditions, solr.SolrCloudTLSHostnamesCovered); condition != nil {
+			return condition.Message
+		}
+		return ""
+	}, timeout).Should(gomega.ContainSubstring("foo-tls-solrcloud-1.foo-tls-solrcloud-headless.default"), "The missing hostnames of the new pods should be reported")
+	g.Expect(testClient.Get(ctx, expectedStatefulSetName, stateful)).To(gomega.Succeed())
+	assert.EqualValues(t, 1, *stateful.Spec.Replicas, "The scale up should wait until the certificate is valid for the new pods")
+
+	// Re-issue the certificate for all Solr nodes, and the scale up should continue
+	g.Expect(testClient.Get(ctx, types.NamespacedName{Name: tlsSecret.Name, Namespace: tlsSecret.Namespace}, &tlsSecret)).To(gomega.Succeed())
+	tlsSecret.Data[util.TLSCertKey] = createMockTLSCertificate(t, "foo-tls-solrcloud-common.default", "*.foo-tls-solrcloud-headless.default")
+	g.Expect(testClient.Update(ctx, &tlsSecret)).To(gomega.Succeed())
+	g.Eventually(func() int32 {
+		g.Expect(testClient.Get(ctx, expectedStatefulSetName, stateful)).To(gomega.Succeed())
+		return *stateful.Spec.Replicas
+	}, timeout).Should(gomega.BeEquivalentTo(3), "The scale up should continue once the certificate is valid for the new pods")
+	g.Expect(testClient.Delete(ctx, stateful)).To(gomega.Succeed())
+}
+
 func buildTestSolrCloud() *solr.SolrCloud {
 	replicas := int32(1)
 	instance := &solr.SolrCloud{
@@ -599,7 +697,6 @@ type TLSTestHelper struct {
 }
 
 func NewTLSTestHelper(g *gomega.GomegaWithT) *TLSTestHelper {
-	UseZkCRD(false)
 
 	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
 	// channel when it is finished.
@@ -608,8 +705,9 @@ func NewTLSTestHelper(g *gomega.GomegaWithT) *TLSTestHelper {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 
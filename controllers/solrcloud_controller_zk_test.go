@@ -59,7 +59,6 @@ func expectZookeeperCluster(g *gomega.GomegaWithT, requests chan reconcile.Reque
 }
 
 func TestCloudWithProvidedEphemeralZookeeperReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 	instance := &solr.SolrCloud{
 		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
@@ -99,8 +98,9 @@ func TestCloudWithProvidedEphemeralZookeeperReconcile(t *testing.T) {
 	g.Expect(zookeepercluster.AddZookeeperReconciler(mgr)).NotTo(gomega.HaveOccurred())
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -174,7 +174,6 @@ func TestCloudWithProvidedEphemeralZookeeperReconcile(t *testing.T) {
 }
 
 func TestCloudWithProvidedPersistentZookeeperReconcile(t *testing.T) {
-	UseZkCRD(true)
 	g := gomega.NewGomegaWithT(t)
 	instance := &solr.SolrCloud{
 		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
@@ -210,8 +209,9 @@ func TestCloudWithProvidedPersistentZookeeperReconcile(t *testing.T) {
 	g.Expect(zookeepercluster.AddZookeeperReconciler(mgr)).NotTo(gomega.HaveOccurred())
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: true,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -281,7 +281,6 @@ func TestCloudWithProvidedPersistentZookeeperReconcile(t *testing.T) {
 }
 
 func TestZKACLsCloudReconcile(t *testing.T) {
-	UseZkCRD(false)
 	g := gomega.NewGomegaWithT(t)
 
 	replicas := int32(3)
@@ -316,8 +315,9 @@ func TestZKACLsCloudReconcile(t *testing.T) {
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -479,7 +479,6 @@ func TestZKACLsForProvidedReconcile(t *testing.T) {
 }
 
 func testZkACLsReconcile(t *testing.T, useZkCRD bool, instance *solr.SolrCloud, expectedZkHost string) {
-	UseZkCRD(useZkCRD)
 	g := gomega.NewGomegaWithT(t)
 
 	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
@@ -489,8 +488,9 @@ func testZkACLsReconcile(t *testing.T, useZkCRD bool, instance *solr.SolrCloud,
 	testClient = mgr.GetClient()
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: useZkCRD,
 	}
 	newRec, requests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	solrCloudReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "solr_operator_reconcile_duration_seconds",
+		Help: "Length of time per reconciliation of a SolrCloud",
+	}, []string{"namespace", "name"})
+
+	solrCloudReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solr_operator_reconcile_errors_total",
+		Help: "Total number of reconciliation errors of a SolrCloud",
+	}, []string{"namespace", "name"})
+
+	solrCloudOutOfDatePods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "solr_operator_pods_outofdate",
+		Help: "Number of pods of a SolrCloud that are not running the latest spec of their StatefulSet",
+	}, []string{"namespace", "name"})
+
+	solrCloudUpToDatePods = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "solr_operator_pods_uptodate",
+		Help: "Number of pods of a SolrCloud that are running the latest spec of their StatefulSet",
+	}, []string{"namespace", "name"})
+
+	solrCloudPodsKilledForUpdate = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "solr_operator_pods_killed_for_update_total",
+		Help: "Total number of pods of a SolrCloud that have been killed by managed updates",
+	}, []string{"namespace", "name"})
+
+	solrCloudLastPodKilledForUpdate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "solr_operator_last_pod_killed_for_update_timestamp_seconds",
+		Help: "Unix time of the last pod of a SolrCloud that was killed by a managed update",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	// These are served on the metrics endpoint of the manager, alongside the metrics of controller-runtime
+	metrics.Registry.MustRegister(
+		solrCloudReconcileDuration,
+		solrCloudReconcileErrors,
+		solrCloudOutOfDatePods,
+		solrCloudUpToDatePods,
+		solrCloudPodsKilledForUpdate,
+		solrCloudLastPodKilledForUpdate,
+	)
+}
+
+// observeSolrCloudReconcile records the duration of a reconcile of the given SolrCloud, and whether it failed
+func observeSolrCloudReconcile(solrCloud types.NamespacedName, start time.Time, err error) {
+	solrCloudReconcileDuration.WithLabelValues(solrCloud.Namespace, solrCloud.Name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		solrCloudReconcileErrors.WithLabelValues(solrCloud.Namespace, solrCloud.Name).Inc()
+	}
+}
+
+// observeSolrCloudPodsUpdated records the progress of the rollout of the latest spec to the pods of the given SolrCloud
+func observeSolrCloudPodsUpdated(solrCloud types.NamespacedName, upToDatePods int, outOfDatePods int) {
+	solrCloudUpToDatePods.WithLabelValues(solrCloud.Namespace, solrCloud.Name).Set(float64(upToDatePods))
+	solrCloudOutOfDatePods.WithLabelValues(solrCloud.Namespace, solrCloud.Name).Set(float64(outOfDatePods))
+}
+
+// observeSolrCloudPodKilledForUpdate records that a pod of the given SolrCloud was killed by a managed update.
+// The time of the last kill can be used to alert on managed updates that have stalled.
+func observeSolrCloudPodKilledForUpdate(solrCloud types.NamespacedName) {
+	solrCloudPodsKilledForUpdate.WithLabelValues(solrCloud.Namespace, solrCloud.Name).Inc()
+	solrCloudLastPodKilledForUpdate.WithLabelValues(solrCloud.Namespace, solrCloud.Name).SetToCurrentTime()
+}
+
+// deleteSolrCloudMetrics removes the metrics of a SolrCloud that no longer exists, so that they are not exported forever
+func deleteSolrCloudMetrics(solrCloud types.NamespacedName) {
+	solrCloudReconcileDuration.DeleteLabelValues(solrCloud.Namespace, solrCloud.Name)
+	solrCloudReconcileErrors.DeleteLabelValues(solrCloud.Namespace, solrCloud.Name)
+	solrCloudOutOfDatePods.DeleteLabelValues(solrCloud.Namespace, solrCloud.Name)
+	solrCloudUpToDatePods.DeleteLabelValues(solrCloud.Namespace, solrCloud.Name)
+	solrCloudPodsKilledForUpdate.DeleteLabelValues(solrCloud.Namespace, solrCloud.Name)
+	solrCloudLastPodKilledForUpdate.DeleteLabelValues(solrCloud.Namespace, solrCloud.Name)
+}
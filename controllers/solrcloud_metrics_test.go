@@ -0,0 +1,50 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestSolrCloudReconcileMetrics(t *testing.T) {
+	cloud := types.NamespacedName{Name: "metrics-cloud", Namespace: "metrics"}
+
+	observeSolrCloudReconcile(cloud, time.Now(), nil)
+	observeSolrCloudReconcile(cloud, time.Now(), fmt.Errorf("reconcile failed"))
+	observeSolrCloudPodsUpdated(cloud, 3, 2)
+	observeSolrCloudPodKilledForUpdate(cloud)
+	observeSolrCloudPodKilledForUpdate(cloud)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(solrCloudReconcileErrors.WithLabelValues(cloud.Namespace, cloud.Name)), "Only the failed reconcile should be counted as an error")
+	assert.Equal(t, float64(2), testutil.ToFloat64(solrCloudOutOfDatePods.WithLabelValues(cloud.Namespace, cloud.Name)), "Wrong out of date pod count")
+	assert.Equal(t, float64(3), testutil.ToFloat64(solrCloudUpToDatePods.WithLabelValues(cloud.Namespace, cloud.Name)), "Wrong up to date pod count")
+	assert.Equal(t, float64(2), testutil.ToFloat64(solrCloudPodsKilledForUpdate.WithLabelValues(cloud.Namespace, cloud.Name)), "Every pod killed for an update should be counted")
+	assert.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(solrCloudLastPodKilledForUpdate.WithLabelValues(cloud.Namespace, cloud.Name)), 60, "The time of the last pod killed for an update should be recorded")
+
+	deleteSolrCloudMetrics(cloud)
+	assert.False(t, solrCloudReconcileDuration.DeleteLabelValues(cloud.Namespace, cloud.Name), "The reconcile durations of a deleted SolrCloud should be removed")
+	assert.False(t, solrCloudReconcileErrors.DeleteLabelValues(cloud.Namespace, cloud.Name), "The reconcile errors of a deleted SolrCloud should be removed")
+	assert.False(t, solrCloudOutOfDatePods.DeleteLabelValues(cloud.Namespace, cloud.Name), "The out of date pod count of a deleted SolrCloud should be removed")
+	assert.False(t, solrCloudPodsKilledForUpdate.DeleteLabelValues(cloud.Namespace, cloud.Name), "The pods killed for updates of a deleted SolrCloud should be removed")
+}
@@ -0,0 +1,135 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const SolrCloudValidatingWebhookPath = "/validate-solr-apache-org-v1beta1-solrcloud"
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-solr-apache-org-v1beta1-solrcloud,mutating=false,failurePolicy=fail,groups=solr.apache.org,resources=solrclouds,versions=v1beta1,name=vsolrcloud.solr.apache.org
+
+// SolrCloudValidator rejects SolrCloud resources that the SolrCloudReconciler would not be able to reconcile
+type SolrCloudValidator struct {
+	// APIReader is used to read user-provided resources, since they may live outside of the namespaces watched by the cache
+	APIReader client.Reader
+	decoder   *admission.Decoder
+}
+
+// SetupWebhookWithManager registers the validating webhook with the webhook server of the manager
+func (v *SolrCloudValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if v.APIReader == nil {
+		v.APIReader = mgr.GetAPIReader()
+	}
+	mgr.GetWebhookServer().Register(SolrCloudValidatingWebhookPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// InjectDecoder injects the decoder for the admission requests of the webhook
+func (v *SolrCloudValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle admits SolrClouds that pass validation, and denies all others with the reasons they are invalid
+func (v *SolrCloudValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	solrCloud := &solr.SolrCloud{}
+	if err := v.decoder.Decode(req, solrCloud); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// Never block the removal of finalizers from a SolrCloud that is being deleted
+	if solrCloud.DeletionTimestamp != nil {
+		return admission.Allowed("")
+	}
+
+	if err := v.ValidateSolrCloud(ctx, solrCloud); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// ValidateSolrCloud returns all reasons that the given SolrCloud is invalid, or nil if it is valid
+func (v *SolrCloudValidator) ValidateSolrCloud(ctx context.Context, solrCloud *solr.SolrCloud) error {
+	var errs []error
+	if solrCloud.Spec.Replicas != nil && *solrCloud.Spec.Replicas < 0 {
+		errs = append(errs, fmt.Errorf("replicas cannot be negative, got %d", *solrCloud.Spec.Replicas))
+	}
+	for _, validate := range []func(*solr.SolrCloud) error{
+		util.ValidateContextPath,
+		util.ValidateSolrPodPort,
+		util.ValidateExternalAddressability,
+		util.ValidateSolrTLS,
+		util.ValidateIngressTLSTermination,
+		util.ValidateBackupRepositories,
+		util.ValidateCustomContainers,
+		util.ValidateSolrLifecycleHooks,
+		util.ValidateCustomVolumes,
+		util.ValidateLogStorage,
+		util.ValidateAdditionalJavaOpts,
+		util.ValidateNodePools,
+		util.ValidateAdditionalConfigMaps,
+		util.ValidateSolrLogXml,
+		util.ValidateSolrCloudImages,
+		util.ValidateRestoreOptions,
+		util.ValidateDeletionOptions,
+		util.ValidateZookeeperCleanup,
+	} {
+		if err := validate(solrCloud); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := v.validateProvidedConfigMap(ctx, solrCloud); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateProvidedConfigMap checks the solr.xml of a user-provided ConfigMap, if it exists already.
+// A missing ConfigMap is not rejected, since it may be created after the SolrCloud.
+func (v *SolrCloudValidator) validateProvidedConfigMap(ctx context.Context, solrCloud *solr.SolrCloud) error {
+	configMapOptions := solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions
+	if configMapOptions == nil || configMapOptions.ProvidedConfigMap == "" || v.APIReader == nil {
+		return nil
+	}
+	configMap := &corev1.ConfigMap{}
+	err := v.APIReader.Get(ctx, types.NamespacedName{Name: configMapOptions.ProvidedConfigMap, Namespace: solrCloud.Namespace}, configMap)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read the provided ConfigMap %s: %v", configMapOptions.ProvidedConfigMap, err)
+	}
+	if solrXml, hasSolrXml := configMap.Data[util.SolrXmlFile]; hasSolrXml {
+		return util.ValidateProvidedSolrXml(solrCloud, configMap.Name, solrXml)
+	}
+	return nil
+}
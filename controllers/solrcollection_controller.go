@@ -0,0 +1,230 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// SolrCollectionReconciler reconciles a SolrCollection object
+type SolrCollectionReconciler struct {
+	client.Client
+	Log    logr.Logger
+	scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrcollections,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrcollections/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrcollections/finalizers,verbs=update
+
+func (r *SolrCollectionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("namespace", req.Namespace, "solrCollection", req.Name)
+
+	// Fetch the SolrCollection instance
+	collection := &solrv1beta1.SolrCollection{}
+	err := r.Get(context.TODO(), req.NamespacedName, collection)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the req.
+		return reconcile.Result{}, err
+	}
+
+	if collection.ObjectMeta.DeletionTimestamp.IsZero() {
+		changed := collection.WithDefaults()
+		if changed {
+			logger.Info("Setting default settings for SolrCollection")
+			if err := r.Update(context.TODO(), collection); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{Requeue: true}, nil
+		}
+
+		// Only use a finalizer when the collection needs to be deleted along with the SolrCollection
+		if needsUpdate := r.reconcileCollectionFinalizer(collection); needsUpdate {
+			if err := r.Update(context.TODO(), collection); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{Requeue: true}, nil
+		}
+	} else {
+		return reconcile.Result{}, r.deleteCollection(collection, logger)
+	}
+
+	oldStatus := collection.Status.DeepCopy()
+
+	// Check on the collection again after a minute, so that the status reflects the state of the collection in Solr
+	requeueOrNot := reconcile.Result{RequeueAfter: time.Minute}
+
+	err = r.reconcileSolrCollection(collection, logger)
+	if err != nil {
+		logger.Error(err, "Error while reconciling SolrCollection")
+		collection.Status.Error = err.Error()
+		updateRequeueAfter(&requeueOrNot, time.Second*10)
+	} else {
+		collection.Status.Error = ""
+	}
+
+	if !reflect.DeepEqual(oldStatus, &collection.Status) {
+		logger.Info("Updating status for SolrCollection")
+		if statusErr := r.Status().Update(context.TODO(), collection); statusErr != nil {
+			return requeueOrNot, statusErr
+		}
+	}
+
+	return requeueOrNot, nil
+}
+
+// reconcileCollectionFinalizer adds or removes the finalizer that deletes the Solr collection, depending on the deletionPolicy.
+// It returns whether the SolrCollection needs to be updated.
+func (r *SolrCollectionReconciler) reconcileCollectionFinalizer(collection *solrv1beta1.SolrCollection) (needsUpdate bool) {
+	hasFinalizer := util.ContainsString(collection.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionFinalizer)
+	if collection.Spec.DeletionPolicy == solrv1beta1.CollectionDeletionPolicyDelete && !hasFinalizer {
+		collection.ObjectMeta.Finalizers = append(collection.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionFinalizer)
+		return true
+	} else if collection.Spec.DeletionPolicy != solrv1beta1.CollectionDeletionPolicyDelete && hasFinalizer {
+		collection.ObjectMeta.Finalizers = util.RemoveString(collection.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionFinalizer)
+		return true
+	}
+	return false
+}
+
+// deleteCollection deletes the collection in Solr, if the SolrCollection has the finalizer to do so, and then removes the finalizer.
+func (r *SolrCollectionReconciler) deleteCollection(collection *solrv1beta1.SolrCollection, logger logr.Logger) error {
+	if !util.ContainsString(collection.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionFinalizer) {
+		return nil
+	}
+
+	solrCloud := &solrv1beta1.SolrCloud{}
+	err := r.Get(context.TODO(), types.NamespacedName{Namespace: collection.Namespace, Name: collection.Spec.SolrCloud}, solrCloud)
+	if err == nil && solrCloud.ObjectMeta.DeletionTimestamp.IsZero() {
+		var httpHeaders map[string]string
+		if httpHeaders, err = getBasicAuthHeaders(r, solrCloud); err != nil {
+			return err
+		}
+		var exists bool
+		if _, exists, err = util.GetCollectionState(solrCloud, collection.CollectionName(), httpHeaders); err != nil {
+			return err
+		}
+		if exists {
+			logger.Info("Deleting collection, since the SolrCollection is being deleted", "collection", collection.CollectionName())
+			if err = util.DeleteCollection(solrCloud, collection.CollectionName(), httpHeaders); err != nil {
+				return err
+			}
+		}
+	} else if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	// If the SolrCloud does not exist anymore, or is being deleted, then there is no collection left to delete
+
+	collection.ObjectMeta.Finalizers = util.RemoveString(collection.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionFinalizer)
+	return r.Update(context.TODO(), collection)
+}
+
+// reconcileSolrCollection creates the collection in Solr if it does not exist, modifies it if its attributes have changed,
+// and updates the status of the SolrCollection with the state of the collection.
+func (r *SolrCollectionReconciler) reconcileSolrCollection(collection *solrv1beta1.SolrCollection, logger logr.Logger) (err error) {
+	if collection.Spec.Router.Name == solrv1beta1.ImplicitCollectionRouter && len(collection.Spec.Router.Shards) == 0 {
+		return fmt.Errorf("the shards to create must be provided when using the %s router", solrv1beta1.ImplicitCollectionRouter)
+	}
+
+	// Get the SolrCloud that this collection is in
+	solrCloud := &solrv1beta1.SolrCloud{}
+	err = r.Get(context.TODO(), types.NamespacedName{Namespace: collection.Namespace, Name: collection.Spec.SolrCloud}, solrCloud)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("SolrCloud %s does not exist", collection.Spec.SolrCloud)
+		}
+		return err
+	}
+	if solrCloud.Status.ReadyReplicas == 0 {
+		return fmt.Errorf("SolrCloud %s has no ready Solr nodes", solrCloud.Name)
+	}
+
+	httpHeaders, err := getBasicAuthHeaders(r, solrCloud)
+	if err != nil {
+		return err
+	}
+
+	state, exists, err := util.GetCollectionState(solrCloud, collection.CollectionName(), httpHeaders)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		if collection.Status.Created {
+			logger.Info("Collection no longer exists in Solr, re-creating it", "collection", collection.CollectionName())
+		}
+		if err = util.CreateCollection(solrCloud, collection, httpHeaders); err != nil {
+			return err
+		}
+		if state, exists, err = util.GetCollectionState(solrCloud, collection.CollectionName(), httpHeaders); err != nil {
+			return err
+		} else if !exists {
+			return fmt.Errorf("collection %s was not found after it was created", collection.CollectionName())
+		}
+	} else if modifications := util.CollectionModifications(collection, state); len(modifications) > 0 {
+		modifiedAttributes := make([]string, 0, len(modifications))
+		for attribute := range modifications {
+			modifiedAttributes = append(modifiedAttributes, attribute)
+		}
+		logger.Info("Modifying collection, since its attributes have changed", "collection", collection.CollectionName(), "attributes", strings.Join(modifiedAttributes, ","))
+		if err = util.ModifyCollection(solrCloud, collection.CollectionName(), modifications, httpHeaders); err != nil {
+			return err
+		}
+		if state, _, err = util.GetCollectionState(solrCloud, collection.CollectionName(), httpHeaders); err != nil {
+			return err
+		}
+	}
+
+	util.UpdateSolrCollectionStatus(&collection.Status, state)
+	return nil
+}
+
+func (r *SolrCollectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.SetupWithManagerAndReconciler(mgr, r)
+}
+
+func (r *SolrCollectionReconciler) SetupWithManagerAndReconciler(mgr ctrl.Manager, reconciler reconcile.Reconciler) error {
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&solrv1beta1.SolrCollection{})
+
+	r.scheme = mgr.GetScheme()
+	return ctrlBuilder.Complete(reconciler)
+}
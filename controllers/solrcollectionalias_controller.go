@@ -0,0 +1,210 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/solr-operator/controllers/util"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
+)
+
+// SolrCollectionAliasReconciler reconciles a SolrCollectionAlias object
+type SolrCollectionAliasReconciler struct {
+	client.Client
+	Log    logr.Logger
+	scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrcollectionaliases,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrcollectionaliases/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=solr.apache.org,resources=solrcollectionaliases/finalizers,verbs=update
+
+func (r *SolrCollectionAliasReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("namespace", req.Namespace, "solrCollectionAlias", req.Name)
+
+	// Fetch the SolrCollectionAlias instance
+	alias := &solrv1beta1.SolrCollectionAlias{}
+	err := r.Get(context.TODO(), req.NamespacedName, alias)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the req.
+		return reconcile.Result{}, err
+	}
+
+	if alias.ObjectMeta.DeletionTimestamp.IsZero() {
+		// The finalizer makes sure that the alias is removed from Solr when the SolrCollectionAlias is deleted
+		if !util.ContainsString(alias.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionAliasFinalizer) {
+			alias.ObjectMeta.Finalizers = append(alias.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionAliasFinalizer)
+			if err := r.Update(context.TODO(), alias); err != nil {
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{Requeue: true}, nil
+		}
+	} else {
+		return reconcile.Result{}, r.deleteAlias(alias, logger)
+	}
+
+	oldStatus := alias.Status.DeepCopy()
+
+	// Check on the alias again after a minute, so that the status reflects the collections that routed aliases have created
+	requeueOrNot := reconcile.Result{RequeueAfter: time.Minute}
+
+	err = r.reconcileSolrCollectionAlias(alias, logger)
+	if err != nil {
+		logger.Error(err, "Error while reconciling SolrCollectionAlias")
+		alias.Status.Error = err.Error()
+		updateRequeueAfter(&requeueOrNot, time.Second*10)
+	} else {
+		alias.Status.Error = ""
+	}
+
+	if !reflect.DeepEqual(oldStatus, &alias.Status) {
+		logger.Info("Updating status for SolrCollectionAlias")
+		if statusErr := r.Status().Update(context.TODO(), alias); statusErr != nil {
+			return requeueOrNot, statusErr
+		}
+	}
+
+	return requeueOrNot, nil
+}
+
+// deleteAlias deletes the alias in Solr, if it still exists, and then removes the finalizer from the SolrCollectionAlias.
+func (r *SolrCollectionAliasReconciler) deleteAlias(alias *solrv1beta1.SolrCollectionAlias, logger logr.Logger) error {
+	if !util.ContainsString(alias.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionAliasFinalizer) {
+		return nil
+	}
+
+	solrCloud := &solrv1beta1.SolrCloud{}
+	err := r.Get(context.TODO(), types.NamespacedName{Namespace: alias.Namespace, Name: alias.Spec.SolrCloud}, solrCloud)
+	if err == nil && solrCloud.ObjectMeta.DeletionTimestamp.IsZero() {
+		var httpHeaders map[string]string
+		if httpHeaders, err = getBasicAuthHeaders(r, solrCloud); err != nil {
+			return err
+		}
+		var exists bool
+		if _, _, exists, err = util.GetAliasState(solrCloud, alias.AliasName(), httpHeaders); err != nil {
+			return err
+		}
+		if exists {
+			logger.Info("Deleting alias, since the SolrCollectionAlias is being deleted", "alias", alias.AliasName())
+			if err = util.DeleteAlias(solrCloud, alias.AliasName(), httpHeaders); err != nil {
+				return err
+			}
+		}
+	} else if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	// If the SolrCloud does not exist anymore, or is being deleted, then there is no alias left to delete
+
+	alias.ObjectMeta.Finalizers = util.RemoveString(alias.ObjectMeta.Finalizers, solrv1beta1.SolrCollectionAliasFinalizer)
+	return r.Update(context.TODO(), alias)
+}
+
+// reconcileSolrCollectionAlias creates the alias in Solr if it does not exist, points a standard alias to the given collections,
+// sets the properties of the alias, and updates the status of the SolrCollectionAlias with the collections that the alias resolves to.
+func (r *SolrCollectionAliasReconciler) reconcileSolrCollectionAlias(alias *solrv1beta1.SolrCollectionAlias, logger logr.Logger) (err error) {
+	isRouted := alias.Spec.Routed != nil
+	if isRouted == (len(alias.Spec.Collections) > 0) {
+		return fmt.Errorf("exactly one of collections or routed must be provided for the alias")
+	}
+
+	// Get the SolrCloud that this alias is in
+	solrCloud := &solrv1beta1.SolrCloud{}
+	err = r.Get(context.TODO(), types.NamespacedName{Namespace: alias.Namespace, Name: alias.Spec.SolrCloud}, solrCloud)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fmt.Errorf("SolrCloud %s does not exist", alias.Spec.SolrCloud)
+		}
+		return err
+	}
+	if solrCloud.Status.ReadyReplicas == 0 {
+		return fmt.Errorf("SolrCloud %s has no ready Solr nodes", solrCloud.Name)
+	}
+
+	httpHeaders, err := getBasicAuthHeaders(r, solrCloud)
+	if err != nil {
+		return err
+	}
+
+	collections, properties, exists, err := util.GetAliasState(solrCloud, alias.AliasName(), httpHeaders)
+	if err != nil {
+		return err
+	}
+
+	if exists && isRouted != util.IsRoutedAlias(properties) {
+		return fmt.Errorf("alias %s already exists in Solr, and cannot be changed between a standard and a routed alias", alias.AliasName())
+	}
+
+	// Routed aliases are only created once, Solr manages their collections.
+	// Standard aliases are re-created whenever their collections differ, which replaces the alias atomically.
+	if !exists || (!isRouted && strings.Join(collections, ",") != strings.Join(alias.Spec.Collections, ",")) {
+		if exists {
+			logger.Info("Updating the collections of alias", "alias", alias.AliasName(), "collections", alias.Spec.Collections)
+		}
+		if err = util.CreateAlias(solrCloud, alias, httpHeaders); err != nil {
+			return err
+		}
+		if collections, properties, exists, err = util.GetAliasState(solrCloud, alias.AliasName(), httpHeaders); err != nil {
+			return err
+		} else if !exists {
+			return fmt.Errorf("alias %s was not found after it was created", alias.AliasName())
+		}
+	}
+
+	if modifications := util.AliasPropertyModifications(alias, properties); len(modifications) > 0 {
+		if err = util.SetAliasProperties(solrCloud, alias.AliasName(), modifications, httpHeaders); err != nil {
+			return err
+		}
+	}
+
+	alias.Status.Created = true
+	alias.Status.Collections = collections
+	return nil
+}
+
+func (r *SolrCollectionAliasReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return r.SetupWithManagerAndReconciler(mgr, r)
+}
+
+func (r *SolrCollectionAliasReconciler) SetupWithManagerAndReconciler(mgr ctrl.Manager, reconciler reconcile.Reconciler) error {
+	ctrlBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&solrv1beta1.SolrCollectionAlias{})
+
+	r.scheme = mgr.GetScheme()
+	return ctrlBuilder.Complete(reconciler)
+}
@@ -27,9 +27,12 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -45,6 +48,10 @@ type SolrPrometheusExporterReconciler struct {
 	client.Client
 	Log    logr.Logger
 	scheme *runtime.Scheme
+
+	// UseServiceMonitorCRD is set when the Prometheus Operator's ServiceMonitor CRD is installed.
+	// Otherwise no ServiceMonitors are generated for SolrPrometheusExporters.
+	UseServiceMonitorCRD bool
 }
 
 // +kubebuilder:rbac:groups=,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
@@ -53,6 +60,7 @@ type SolrPrometheusExporterReconciler struct {
 // +kubebuilder:rbac:groups=,resources=services/status,verbs=get
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds,verbs=get;list;watch
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrclouds/status,verbs=get
 // +kubebuilder:rbac:groups=solr.apache.org,resources=solrprometheusexporters,verbs=get;list;watch;create;update;patch;delete
@@ -85,26 +93,54 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(req ctrl.Request) (ctrl.Res
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// Make sure that the exporter image can be pulled
+	if err = util.ValidateContainerImage("image", prometheusExporter.Spec.Image); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err = util.ValidateAdditionalClouds(prometheusExporter); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Make sure that the sidecar containers can listen on their ports, alongside the exporter containers
+	if err = util.ValidateExporterSidecarPorts(prometheusExporter); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Make sure that the custom lifecycle hooks of the exporter container can be run
+	if err = util.ValidateLifecycleHooks(prometheusExporter.Spec.CustomKubeOptions.PodOptions); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	configMapKey := util.PrometheusExporterConfigMapKey
 	configXmlMd5 := ""
 	if prometheusExporter.Spec.Config == "" && prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions != nil && prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap != "" {
+		providedConfigMapName := prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap
 		foundConfigMap := &corev1.ConfigMap{}
-		err = r.Get(context.TODO(), types.NamespacedName{Name: prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap, Namespace: prometheusExporter.Namespace}, foundConfigMap)
-		if err != nil {
+		err = r.Get(context.TODO(), types.NamespacedName{Name: providedConfigMapName, Namespace: prometheusExporter.Namespace}, foundConfigMap)
+		if err != nil && errors.IsNotFound(err) {
+			// Keep the existing Deployment as it is, the ConfigMap watch will trigger a reconcile once it is created
+			logger.Info("Not reconciling the Deployment, the providedConfigMap does not exist", "configMap", providedConfigMapName)
+			return ctrl.Result{}, fmt.Errorf("providedConfigMap %s not found, the exporter Deployment will not be updated until it exists", providedConfigMapName)
+		} else if err != nil {
 			return ctrl.Result{}, err
 		}
 
 		if foundConfigMap.Data != nil {
 			configXml, ok := foundConfigMap.Data[configMapKey]
 			if ok {
+				// make sure the user-provided config can be loaded by the exporter, before restarting it
+				if err = util.ValidateExporterConfigXml(providedConfigMapName, configXml); err != nil {
+					return ctrl.Result{}, err
+				}
 				configXmlMd5 = fmt.Sprintf("%x", md5.Sum([]byte(configXml)))
 			} else {
 				return ctrl.Result{}, fmt.Errorf("required '%s' key not found in provided ConfigMap %s",
-					configMapKey, prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap)
+					configMapKey, providedConfigMapName)
 			}
 		} else {
 			return ctrl.Result{}, fmt.Errorf("provided ConfigMap %s has no data",
-				prometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap)
+				providedConfigMapName)
 		}
 	}
 
@@ -155,9 +191,11 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(req ctrl.Request) (ctrl.Res
 			err = r.Create(context.TODO(), metricsService)
 		}
 	} else if err == nil {
-		var needsUpdate bool
-		needsUpdate, err = util.OvertakeControllerRef(prometheusExporter, foundMetricsService, r.scheme)
-		needsUpdate = util.CopyServiceFields(metricsService, foundMetricsService, serviceLogger) || needsUpdate
+		var recreating, needsUpdate bool
+		if recreating, err = deleteServiceToRecreate(r, metricsService, foundMetricsService, serviceLogger); !recreating {
+			needsUpdate, err = util.OvertakeControllerRef(prometheusExporter, foundMetricsService, r.scheme)
+			needsUpdate = util.CopyServiceFields(metricsService, foundMetricsService, serviceLogger) || needsUpdate
+		}
 
 		// Update the found Metrics Service and write the result back if there are any changes
 		if needsUpdate && err == nil {
@@ -169,51 +207,90 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(req ctrl.Request) (ctrl.Res
 		return ctrl.Result{}, err
 	}
 
+	// Generate the ServiceMonitor, or remove it if it is no longer requested
+	if r.UseServiceMonitorCRD {
+		foundServiceMonitor := &unstructured.Unstructured{}
+		foundServiceMonitor.SetGroupVersionKind(util.ServiceMonitorGVK)
+		err = r.Get(context.TODO(), types.NamespacedName{Name: prometheusExporter.MetricsServiceMonitorName(), Namespace: prometheusExporter.Namespace}, foundServiceMonitor)
+		serviceMonitorLogger := logger.WithValues("serviceMonitor", prometheusExporter.MetricsServiceMonitorName())
+		if prometheusExporter.Spec.ServiceMonitor == nil {
+			if err == nil && metav1.IsControlledBy(foundServiceMonitor, prometheusExporter) {
+				serviceMonitorLogger.Info("Deleting ServiceMonitor, since it is no longer requested")
+				err = r.Delete(context.TODO(), foundServiceMonitor)
+			} else if errors.IsNotFound(err) {
+				err = nil
+			}
+		} else if serviceMonitor := util.GenerateSolrMetricsServiceMonitor(prometheusExporter); err != nil && errors.IsNotFound(err) {
+			serviceMonitorLogger.Info("Creating ServiceMonitor")
+			if err = controllerutil.SetControllerReference(prometheusExporter, serviceMonitor, r.scheme); err == nil {
+				err = r.Create(context.TODO(), serviceMonitor)
+			}
+		} else if err == nil {
+			var needsUpdate bool
+			needsUpdate, err = util.OvertakeControllerRef(prometheusExporter, foundServiceMonitor, r.scheme)
+			needsUpdate = util.CopyServiceMonitorFields(serviceMonitor, foundServiceMonitor, serviceMonitorLogger) || needsUpdate
+
+			// Update the found ServiceMonitor and write the result back if there are any changes
+			if needsUpdate && err == nil {
+				serviceMonitorLogger.Info("Updating ServiceMonitor")
+				err = r.Update(context.TODO(), foundServiceMonitor)
+			}
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if prometheusExporter.Spec.ServiceMonitor != nil {
+		logger.Info("Not generating the requested ServiceMonitor, since the ServiceMonitor CRD is not installed")
+	}
+
 	// Get the ZkConnectionString to connect to
 	solrConnectionInfo := util.SolrConnectionInfo{}
-	if solrConnectionInfo, err = getSolrConnectionInfo(r, prometheusExporter); err != nil {
+	var referencedCloud *solrv1beta1.SolrCloud
+	if solrConnectionInfo, referencedCloud, err = getSolrConnectionInfo(r, prometheusExporter); err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// Use the TLS config of the referenced SolrCloud, unless the exporter has been given its own TLS config.
+	// The secrets must live in the exporter's namespace, so only inherit from clouds in the same namespace.
+	solrTLS := prometheusExporter.Spec.SolrReference.SolrTLS
+	if solrTLS == nil && referencedCloud != nil && referencedCloud.Namespace == prometheusExporter.Namespace {
+		solrTLS = referencedCloud.Spec.SolrTLS
+	}
+
 	// Make sure the TLS config is in order
 	var tlsClientOptions *util.TLSClientOptions = nil
-	if prometheusExporter.Spec.SolrReference.SolrTLS != nil {
+	if solrTLS != nil {
 		requeueOrNot := reconcile.Result{}
 		ctx := context.TODO()
 		foundTLSSecret := &corev1.Secret{}
-		lookupErr := r.Get(ctx, types.NamespacedName{Name: prometheusExporter.Spec.SolrReference.SolrTLS.PKCS12Secret.Name, Namespace: prometheusExporter.Namespace}, foundTLSSecret)
+		lookupErr := r.Get(ctx, types.NamespacedName{Name: solrTLS.PKCS12Secret.Name, Namespace: prometheusExporter.Namespace}, foundTLSSecret)
 		if lookupErr != nil {
 			return requeueOrNot, lookupErr
 		} else {
 			// Make sure the secret containing the keystore password exists as well
 			keyStorePasswordSecret := &corev1.Secret{}
-			err := r.Get(ctx, types.NamespacedName{Name: prometheusExporter.Spec.SolrReference.SolrTLS.KeyStorePasswordSecret.Name, Namespace: foundTLSSecret.Namespace}, keyStorePasswordSecret)
+			err := r.Get(ctx, types.NamespacedName{Name: solrTLS.KeyStorePasswordSecret.Name, Namespace: foundTLSSecret.Namespace}, keyStorePasswordSecret)
 			if err != nil {
-				return requeueOrNot, lookupErr
+				return requeueOrNot, err
 			}
 			// we found the keystore secret, but does it have the key we expect?
-			if _, ok := keyStorePasswordSecret.Data[prometheusExporter.Spec.SolrReference.SolrTLS.KeyStorePasswordSecret.Key]; !ok {
+			if _, ok := keyStorePasswordSecret.Data[solrTLS.KeyStorePasswordSecret.Key]; !ok {
 				return requeueOrNot, fmt.Errorf("%s key not found in keystore password secret %s",
-					prometheusExporter.Spec.SolrReference.SolrTLS.KeyStorePasswordSecret.Key, keyStorePasswordSecret.Name)
+					solrTLS.KeyStorePasswordSecret.Key, keyStorePasswordSecret.Name)
 			}
 
 			tlsClientOptions = &util.TLSClientOptions{}
-			tlsClientOptions.TLSOptions = prometheusExporter.Spec.SolrReference.SolrTLS
+			tlsClientOptions.TLSOptions = solrTLS
 
-			if _, ok := foundTLSSecret.Data[prometheusExporter.Spec.SolrReference.SolrTLS.PKCS12Secret.Key]; !ok {
-				// the keystore.p12 key is not in the TLS secret, indicating we need to create it using an initContainer
-				tlsClientOptions.NeedsPkcs12InitContainer = true
+			// If the keystore is not in the TLS secret, then it is generated from the PEM encoded certificate and key using an initContainer
+			if tlsClientOptions.NeedsPkcs12InitContainer, err = util.NeedsPkcs12InitContainer(solrTLS, foundTLSSecret); err != nil {
+				return requeueOrNot, err
 			}
 
 			// We have a watch on secrets, so will get notified when the secret changes (such as after cert renewal)
 			// capture the hash of the secret and stash in an annotation so that pods get restarted if the cert changes
-			if prometheusExporter.Spec.SolrReference.SolrTLS.RestartOnTLSSecretUpdate {
-				if tlsCertBytes, ok := foundTLSSecret.Data[util.TLSCertKey]; ok {
-					tlsClientOptions.TLSCertMd5 = fmt.Sprintf("%x", md5.Sum(tlsCertBytes))
-				} else {
-					return requeueOrNot, fmt.Errorf("%s key not found in TLS secret %s, cannot watch for updates to the cert without this data but 'solrTLS.restartOnTLSSecretUpdate' is enabled",
-						util.TLSCertKey, foundTLSSecret.Name)
-				}
+			if solrTLS.RestartOnTLSSecretUpdate {
+				tlsClientOptions.TLSCertMd5 = util.TLSSecretMd5(solrTLS, foundTLSSecret)
 			}
 		}
 	}
@@ -236,7 +313,9 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(req ctrl.Request) (ctrl.Res
 
 	deploy := util.GenerateSolrPrometheusExporterDeployment(prometheusExporter, solrConnectionInfo, configXmlMd5, tlsClientOptions, basicAuthMd5)
 
-	ready := false
+	newStatus := solrv1beta1.SolrPrometheusExporterStatus{
+		ScrapeTarget: prometheusExporter.MetricsScrapeTarget(util.ExtSolrMetricsPort),
+	}
 	// Check if the Metrics Deployment already exists
 	deploymentLogger := logger.WithValues("deployment", deploy.Name)
 	foundDeploy := &appsv1.Deployment{}
@@ -256,14 +335,16 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(req ctrl.Request) (ctrl.Res
 			deploymentLogger.Info("Updating Deployment")
 			err = r.Update(context.TODO(), foundDeploy)
 		}
-		ready = foundDeploy.Status.ReadyReplicas > 0
+		newStatus.Replicas = foundDeploy.Status.Replicas
+		newStatus.ReadyReplicas = foundDeploy.Status.ReadyReplicas
+		newStatus.Ready = foundDeploy.Status.ReadyReplicas > 0
 	}
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	if ready != prometheusExporter.Status.Ready {
-		prometheusExporter.Status.Ready = ready
+	if !reflect.DeepEqual(prometheusExporter.Status, newStatus) {
+		prometheusExporter.Status = newStatus
 		logger.Info("Updating status for solr-prometheus-exporter")
 		err = r.Status().Update(context.TODO(), prometheusExporter)
 	}
@@ -271,7 +352,7 @@ func (r *SolrPrometheusExporterReconciler) Reconcile(req ctrl.Request) (ctrl.Res
 	return ctrl.Result{}, err
 }
 
-func getSolrConnectionInfo(r *SolrPrometheusExporterReconciler, prometheusExporter *solrv1beta1.SolrPrometheusExporter) (solrConnectionInfo util.SolrConnectionInfo, err error) {
+func getSolrConnectionInfo(r *SolrPrometheusExporterReconciler, prometheusExporter *solrv1beta1.SolrPrometheusExporter) (solrConnectionInfo util.SolrConnectionInfo, referencedCloud *solrv1beta1.SolrCloud, err error) {
 	solrConnectionInfo = util.SolrConnectionInfo{}
 
 	if prometheusExporter.Spec.SolrReference.Standalone != nil {
@@ -286,10 +367,33 @@ func getSolrConnectionInfo(r *SolrPrometheusExporterReconciler, prometheusExport
 			err = r.Get(context.TODO(), types.NamespacedName{Name: prometheusExporter.Spec.SolrReference.Cloud.Name, Namespace: prometheusExporter.Spec.SolrReference.Cloud.Namespace}, solrCloud)
 			if err == nil {
 				solrConnectionInfo.CloudZkConnnectionInfo = &solrCloud.Status.ZookeeperConnectionInfo
+				referencedCloud = solrCloud
+			}
+		}
+	}
+	if err != nil {
+		return solrConnectionInfo, referencedCloud, err
+	}
+
+	for i, cloudRef := range prometheusExporter.Spec.SolrReference.AdditionalClouds {
+		zkConnectionInfo := cloudRef.ZookeeperConnectionInfo
+		if zkConnectionInfo == nil {
+			solrCloud := &solrv1beta1.SolrCloud{}
+			if err = r.Get(context.TODO(), types.NamespacedName{Name: cloudRef.Name, Namespace: cloudRef.Namespace}, solrCloud); err != nil {
+				return solrConnectionInfo, referencedCloud, err
+			}
+			// The SolrCloud watch will trigger a reconcile once the cloud has resolved its ZooKeeper connection
+			if solrCloud.Status.ZookeeperConnectionInfo.InternalConnectionString == "" {
+				return solrConnectionInfo, referencedCloud, fmt.Errorf("solrReference.additionalClouds[%d] cannot be scraped yet, since SolrCloud %s/%s has no ZooKeeper connection string in its status", i, cloudRef.Namespace, cloudRef.Name)
 			}
+			zkConnectionInfo = &solrCloud.Status.ZookeeperConnectionInfo
 		}
+		if zkConnectionInfo.TLS != nil {
+			return solrConnectionInfo, referencedCloud, fmt.Errorf("solrReference.additionalClouds[%d] cannot be scraped, since it connects to ZooKeeper over TLS", i)
+		}
+		solrConnectionInfo.AdditionalCloudZkConnectionInfos = append(solrConnectionInfo.AdditionalCloudZkConnectionInfos, zkConnectionInfo)
 	}
-	return solrConnectionInfo, err
+	return solrConnectionInfo, referencedCloud, nil
 }
 
 func (r *SolrPrometheusExporterReconciler) SetupWithManager(mgr ctrl.Manager) error {
@@ -303,12 +407,25 @@ func (r *SolrPrometheusExporterReconciler) SetupWithManagerAndReconciler(mgr ctr
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.Deployment{})
 
+	if r.UseServiceMonitorCRD {
+		serviceMonitor := &unstructured.Unstructured{}
+		serviceMonitor.SetGroupVersionKind(util.ServiceMonitorGVK)
+		ctrlBuilder = ctrlBuilder.Owns(serviceMonitor)
+	}
+
 	var err error
 	ctrlBuilder, err = r.indexAndWatchForProvidedConfigMaps(mgr, ctrlBuilder)
 	if err != nil {
 		return err
 	}
 
+	// Get notified when a referenced SolrCloud changes, such as its TLS config which the exporter may inherit,
+	// or the ZooKeeper connection of one of the additionalClouds
+	ctrlBuilder, err = r.indexAndWatchForSolrClouds(mgr, ctrlBuilder)
+	if err != nil {
+		return err
+	}
+
 	// Get notified when the TLS secret updates (such as when the cert gets renewed)
 	ctrlBuilder, err = r.indexAndWatchForTLSSecret(mgr, ctrlBuilder)
 	if err != nil {
@@ -374,22 +491,177 @@ func (r *SolrPrometheusExporterReconciler) indexAndWatchForProvidedConfigMaps(mg
 		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
 }
 
+// The field index of the SolrCloud that an exporter references by name, stored as "namespace/name"
+const exporterSolrCloudField = ".spec.solrReference.cloud.name"
+
+// The field index of the additional SolrClouds that an exporter references by name, stored as "namespace/name"
+const exporterAdditionalSolrCloudsField = ".spec.solrReference.additionalClouds.name"
+
+func (r *SolrPrometheusExporterReconciler) indexAndWatchForSolrClouds(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &solrv1beta1.SolrPrometheusExporter{}, exporterSolrCloudField, func(rawObj runtime.Object) []string {
+		// grab the SolrPrometheusExporter object, extract the referenced SolrCloud...
+		exporter := rawObj.(*solrv1beta1.SolrPrometheusExporter)
+		if exporter.Spec.SolrReference.Cloud == nil || exporter.Spec.SolrReference.Cloud.Name == "" {
+			return nil
+		}
+		// ...and if so, return it
+		return []string{exporter.Spec.SolrReference.Cloud.Namespace + "/" + exporter.Spec.SolrReference.Cloud.Name}
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &solrv1beta1.SolrPrometheusExporter{}, exporterAdditionalSolrCloudsField, func(rawObj runtime.Object) []string {
+		// grab the SolrPrometheusExporter object, extract the additional SolrClouds referenced by name
+		exporter := rawObj.(*solrv1beta1.SolrPrometheusExporter)
+		var additionalClouds []string
+		for _, cloudRef := range exporter.Spec.SolrReference.AdditionalClouds {
+			if cloudRef.Name != "" {
+				additionalClouds = append(additionalClouds, cloudRef.Namespace+"/"+cloudRef.Name)
+			}
+		}
+		return additionalClouds
+	}); err != nil {
+		return ctrlBuilder, err
+	}
+
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &solrv1beta1.SolrCloud{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				return r.exporterRequestsForSolrCloud(a.Meta.GetNamespace(), a.Meta.GetName(), false)
+			}),
+		},
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+// exporterRequestsForSolrCloud returns a reconcile request for every exporter that references the given SolrCloud.
+// If onlyInheritingTLS is set, then only the exporters that use the TLS config of the SolrCloud are returned.
+// Otherwise the exporters that scrape the SolrCloud as one of their additionalClouds are returned as well.
+func (r *SolrPrometheusExporterReconciler) exporterRequestsForSolrCloud(namespace string, name string, onlyInheritingTLS bool) []reconcile.Request {
+	foundExporters := &solrv1beta1.SolrPrometheusExporterList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(exporterSolrCloudField, namespace+"/"+name),
+	}
+	err := r.List(context.TODO(), foundExporters, listOps)
+	if err != nil {
+		// if no exporters found, just no-op this
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	requested := map[types.NamespacedName]bool{}
+	for _, item := range foundExporters.Items {
+		// The TLS config is only inherited from clouds in the exporter's namespace, see Reconcile()
+		if onlyInheritingTLS && (item.Spec.SolrReference.SolrTLS != nil || item.Namespace != namespace) {
+			continue
+		}
+		request := reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		}
+		requested[request.NamespacedName] = true
+		requests = append(requests, request)
+	}
+
+	if onlyInheritingTLS {
+		return requests
+	}
+
+	// The TLS config is never inherited from additional clouds, but their ZooKeeper connection is
+	foundAdditionalExporters := &solrv1beta1.SolrPrometheusExporterList{}
+	listOps = &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(exporterAdditionalSolrCloudsField, namespace+"/"+name),
+	}
+	if err = r.List(context.TODO(), foundAdditionalExporters, listOps); err != nil {
+		return requests
+	}
+	for _, item := range foundAdditionalExporters.Items {
+		request := reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      item.GetName(),
+				Namespace: item.GetNamespace(),
+			},
+		}
+		if !requested[request.NamespacedName] {
+			requested[request.NamespacedName] = true
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
 func (r *SolrPrometheusExporterReconciler) indexAndWatchForTLSSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
 	tlsSecretField := ".spec.solrReference.solrTLS.pkcs12Secret"
 
 	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &solrv1beta1.SolrPrometheusExporter{}, tlsSecretField, func(rawObj runtime.Object) []string {
-		// grab the SolrCloud object, extract the referenced TLS secret...
+		// grab the SolrCloud object, extract the referenced TLS secrets...
 		exporter := rawObj.(*solrv1beta1.SolrPrometheusExporter)
 		if exporter.Spec.SolrReference.SolrTLS == nil {
 			return nil
 		}
-		// ...and if so, return it
-		return []string{exporter.Spec.SolrReference.SolrTLS.PKCS12Secret.Name}
+		// ...and if so, return them
+		return tlsSecretNames(exporter.Spec.SolrReference.SolrTLS)
 	}); err != nil {
 		return ctrlBuilder, err
 	}
 
-	return r.buildSecretWatch(tlsSecretField, ctrlBuilder)
+	return ctrlBuilder.Watches(
+		&source.Kind{Type: &corev1.Secret{}},
+		&handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				foundExporters := &solrv1beta1.SolrPrometheusExporterList{}
+				listOps := &client.ListOptions{
+					FieldSelector: fields.OneTermEqualSelector(tlsSecretField, a.Meta.GetName()),
+					Namespace:     a.Meta.GetNamespace(),
+				}
+				if err := r.List(context.TODO(), foundExporters, listOps); err != nil {
+					// if no exporters found, just no-op this
+					return []reconcile.Request{}
+				}
+
+				var requests []reconcile.Request
+				for _, item := range foundExporters.Items {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name:      item.GetName(),
+							Namespace: item.GetNamespace(),
+						},
+					})
+				}
+
+				// Exporters without their own TLS config use the TLS secrets of the SolrCloud they reference
+				foundClouds := &solrv1beta1.SolrCloudList{}
+				if err := r.List(context.TODO(), foundClouds, client.InNamespace(a.Meta.GetNamespace())); err != nil {
+					return requests
+				}
+				for _, cloud := range foundClouds.Items {
+					if cloud.Spec.SolrTLS == nil {
+						continue
+					}
+					for _, secretName := range tlsSecretNames(cloud.Spec.SolrTLS) {
+						if secretName == a.Meta.GetName() {
+							requests = append(requests, r.exporterRequestsForSolrCloud(cloud.Namespace, cloud.Name, true)...)
+							break
+						}
+					}
+				}
+				return requests
+			}),
+		},
+		builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})), nil
+}
+
+// tlsSecretNames returns the names of the secrets holding the keystore and truststore of the given TLS config
+func tlsSecretNames(solrTLS *solrv1beta1.SolrTLSOptions) (secretNames []string) {
+	if solrTLS.PKCS12Secret != nil {
+		secretNames = append(secretNames, solrTLS.PKCS12Secret.Name)
+	}
+	if solrTLS.TrustStoreSecret != nil && solrTLS.TrustStoreSecret.Name != "" {
+		secretNames = append(secretNames, solrTLS.TrustStoreSecret.Name)
+	}
+	return secretNames
 }
 
 func (r *SolrPrometheusExporterReconciler) indexAndWatchForBasicAuthSecret(mgr ctrl.Manager, ctrlBuilder *builder.Builder) (*builder.Builder, error) {
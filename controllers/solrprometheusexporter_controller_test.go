@@ -25,6 +25,7 @@ import (
 	"github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -51,9 +52,11 @@ var (
 
 func TestMetricsReconcileWithoutExporterConfig(t *testing.T) {
 	g := gomega.NewGomegaWithT(t)
+	replicas := int32(2)
 	instance := &solr.SolrPrometheusExporter{
 		ObjectMeta: metav1.ObjectMeta{Name: expectedMetricsRequest.Name, Namespace: expectedMetricsRequest.Namespace},
 		Spec: solr.SolrPrometheusExporterSpec{
+			Replicas: &replicas,
 			CustomKubeOptions: solr.CustomExporterKubeOptions{
 				PodOptions: &solr.PodOptions{
 					EnvVariables:                  extraVars,
@@ -117,6 +120,15 @@ func TestMetricsReconcileWithoutExporterConfig(t *testing.T) {
 	assert.Equal(t, 2, len(deployment.Spec.Template.Spec.InitContainers), "PrometheusExporter deployment requires the additional specified init containers.")
 	assert.EqualValues(t, extraContainers1, deployment.Spec.Template.Spec.InitContainers)
 
+	assert.EqualValues(t, &replicas, deployment.Spec.Replicas, "Incorrect number of exporter replicas")
+	g.Eventually(func() string {
+		foundExporter := &solr.SolrPrometheusExporter{}
+		if err := testClient.Get(context.TODO(), expectedMetricsRequest.NamespacedName, foundExporter); err != nil {
+			return ""
+		}
+		return foundExporter.Status.ScrapeTarget
+	}, timeout).Should(gomega.Equal("http://foo-met-solr-metrics.default:80/metrics"), "The scrape target should be set in the exporter status")
+
 	// Pod Options Checks
 	assert.Equal(t, extraVars, deployment.Spec.Template.Spec.Containers[0].Env, "Extra Env Vars are not the same as the ones provided in podOptions")
 	assert.Equal(t, podSecurityContext, *deployment.Spec.Template.Spec.SecurityContext, "PodSecurityContext is not the same as the one provided in podOptions")
@@ -151,6 +163,7 @@ func TestMetricsReconcileWithExporterConfig(t *testing.T) {
 					Tolerations:       testTolerationsPromExporter,
 					NodeSelector:      testNodeSelectors,
 					PriorityClassName: testPriorityClass,
+					RuntimeClassName:  &testRuntimeClassName,
 				},
 				DeploymentOptions: &solr.DeploymentOptions{
 					Annotations: testDeploymentAnnotations,
@@ -215,6 +228,7 @@ func TestMetricsReconcileWithExporterConfig(t *testing.T) {
 	testPodAnnotations[util.PrometheusExporterConfigXmlMd5Annotation] = expectedMd5
 	testMapsEqual(t, "pod annotations", testPodAnnotations, deployment.Spec.Template.ObjectMeta.Annotations)
 	assert.EqualValues(t, testPriorityClass, deployment.Spec.Template.Spec.PriorityClassName, "Incorrect Priority class name for Pod Spec")
+	assert.EqualValues(t, &testRuntimeClassName, deployment.Spec.Template.Spec.RuntimeClassName, "Incorrect Runtime class name for Pod Spec")
 
 	// Test tolerations and node selectors
 	testMapsEqual(t, "pod node selectors", testNodeSelectors, deployment.Spec.Template.Spec.NodeSelector)
@@ -468,8 +482,9 @@ func TestMetricsReconcileWithSolrZkAcls(t *testing.T) {
 	g.Expect(solrPrometheusExporterReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
 
 	solrCloudReconciler := &SolrCloudReconciler{
-		Client: testClient,
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:   testClient,
+		Log:      ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		UseZkCRD: false,
 	}
 	cloudRec, cloudRequests := SetupTestReconcile(solrCloudReconciler)
 	g.Expect(solrCloudReconciler.SetupWithManagerAndReconciler(mgr, cloudRec)).NotTo(gomega.HaveOccurred())
@@ -872,6 +887,181 @@ func testReconcileWithTLS(t *testing.T, tlsSecretName string, needsPkcs12InitCon
 	}
 }
 
+func TestMetricsReconcileWithTLSInheritedFromCloud(t *testing.T) {
+	ctx := context.TODO()
+
+	g := gomega.NewGomegaWithT(t)
+	tlsSecretName := "tls-cert-secret-from-cloud"
+	keystorePassKey := "keystore-passwords-are-important"
+
+	solrRef := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "host:7271",
+				},
+			},
+			SolrTLS: createTLSOptions(tlsSecretName, keystorePassKey, true),
+		},
+	}
+
+	// The exporter has no TLS config of its own, so it must use the SolrCloud's
+	instance := &solr.SolrPrometheusExporter{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedMetricsRequest.Name, Namespace: expectedMetricsRequest.Namespace},
+		Spec: solr.SolrPrometheusExporterSpec{
+			SolrReference: solr.SolrReference{
+				Cloud: &solr.SolrCloudReference{
+					Name: expectedCloudRequest.Name,
+				},
+			},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrPrometheusExporterReconciler := &SolrPrometheusExporterReconciler{
+		Client: testClient,
+		Log:    ctrl.Log.WithName("controllers").WithName("SolrPrometheusExporter"),
+	}
+	newRec, requests := SetupTestReconcile(solrPrometheusExporterReconciler)
+	g.Expect(solrPrometheusExporterReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, expectedMetricsRequest.Namespace)
+
+	mockSecret, err := createMockTLSSecret(ctx, testClient, tlsSecretName, util.Pkcs12KeystoreFile, instance.Namespace, keystorePassKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(ctx, &mockSecret)
+
+	err = testClient.Create(ctx, solrRef)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(ctx, solrRef)
+
+	err = testClient.Create(ctx, instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(ctx, instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedMetricsRequest)))
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedMetricsRequest)))
+
+	deployment := expectDeployment(t, g, requests, expectedMetricsRequest, metricsDKey, "")
+	expectTLSConfigOnPodTemplate(t, solrRef.Spec.SolrTLS, &deployment.Spec.Template, false)
+	testMapsEqual(t, "pod annotations", map[string]string{util.SolrTlsCertMd5Annotation: fmt.Sprintf("%x", md5.Sum(mockSecret.Data[util.TLSCertKey]))}, deployment.Spec.Template.ObjectMeta.Annotations)
+
+	podAnnotations := func() map[string]string {
+		foundDeployment := &appsv1.Deployment{}
+		if err := testClient.Get(ctx, metricsDKey, foundDeployment); err != nil {
+			return nil
+		}
+		return foundDeployment.Spec.Template.ObjectMeta.Annotations
+	}
+
+	// Renewing the cert in the SolrCloud's TLS secret must restart the exporter
+	foundTLSSecret := &corev1.Secret{}
+	g.Expect(testClient.Get(ctx, types.NamespacedName{Name: tlsSecretName, Namespace: instance.Namespace}, foundTLSSecret)).To(gomega.Succeed())
+	foundTLSSecret.Data[util.TLSCertKey] = []byte("certificate renewed")
+	g.Expect(testClient.Update(ctx, foundTLSSecret)).To(gomega.Succeed())
+	g.Eventually(podAnnotations, timeout).Should(gomega.HaveKeyWithValue(util.SolrTlsCertMd5Annotation, fmt.Sprintf("%x", md5.Sum(foundTLSSecret.Data[util.TLSCertKey]))),
+		"The exporter should be restarted when the TLS secret of the referenced SolrCloud is updated")
+
+	// Changes to the SolrCloud's TLS config must be picked up by the exporter
+	foundCloud := &solr.SolrCloud{}
+	g.Expect(testClient.Get(ctx, expectedCloudRequest.NamespacedName, foundCloud)).To(gomega.Succeed())
+	foundCloud.Spec.SolrTLS.RestartOnTLSSecretUpdate = false
+	g.Expect(testClient.Update(ctx, foundCloud)).To(gomega.Succeed())
+	g.Eventually(podAnnotations, timeout).ShouldNot(gomega.HaveKey(util.SolrTlsCertMd5Annotation),
+		"The exporter should use the updated TLS config of the referenced SolrCloud")
+}
+
+func TestMetricsReconcileWithAdditionalCloudWaitingForZookeeper(t *testing.T) {
+	ctx := context.TODO()
+
+	g := gomega.NewGomegaWithT(t)
+
+	additionalCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedCloudRequest.Name, Namespace: expectedCloudRequest.Namespace},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "additional-host:2181",
+				},
+			},
+		},
+	}
+
+	instance := &solr.SolrPrometheusExporter{
+		ObjectMeta: metav1.ObjectMeta{Name: expectedMetricsRequest.Name, Namespace: expectedMetricsRequest.Namespace},
+		Spec: solr.SolrPrometheusExporterSpec{
+			SolrReference: solr.SolrReference{
+				Cloud: &solr.SolrCloudReference{
+					ZookeeperConnectionInfo: &solr.ZookeeperConnectionInfo{
+						InternalConnectionString: "host:2181",
+						ChRoot:                   "/this/path",
+					},
+				},
+				AdditionalClouds: []solr.SolrCloudReference{{Name: expectedCloudRequest.Name}},
+			},
+		},
+	}
+
+	// Setup the Manager and Controller.  Wrap the Controller Reconcile function so it writes each request to a
+	// channel when it is finished.
+	mgr, err := manager.New(testCfg, manager.Options{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	testClient = mgr.GetClient()
+
+	solrPrometheusExporterReconciler := &SolrPrometheusExporterReconciler{
+		Client: testClient,
+		Log:    ctrl.Log.WithName("controllers").WithName("SolrPrometheusExporter"),
+	}
+	newRec, requests := SetupTestReconcile(solrPrometheusExporterReconciler)
+	g.Expect(solrPrometheusExporterReconciler.SetupWithManagerAndReconciler(mgr, newRec)).NotTo(gomega.HaveOccurred())
+
+	stopMgr, mgrStopped := StartTestManager(mgr, g)
+
+	defer func() {
+		close(stopMgr)
+		mgrStopped.Wait()
+	}()
+
+	cleanupTest(g, expectedMetricsRequest.Namespace)
+
+	// No SolrCloud controller is running, so the status of the additional cloud stays empty until it is set below
+	err = testClient.Create(ctx, additionalCloud)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(ctx, additionalCloud)
+
+	err = testClient.Create(ctx, instance)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer testClient.Delete(ctx, instance)
+	g.Eventually(requests, timeout).Should(gomega.Receive(gomega.Equal(expectedMetricsRequest)))
+
+	deployment := &appsv1.Deployment{}
+	g.Consistently(func() error { return testClient.Get(ctx, metricsDKey, deployment) }, time.Second).ShouldNot(gomega.Succeed(),
+		"The exporter Deployment should not be created before the additional cloud has a ZooKeeper connection string")
+
+	// Resolving the ZooKeeper connection of the additional cloud must trigger a reconcile of the exporter
+	foundCloud := &solr.SolrCloud{}
+	g.Expect(testClient.Get(ctx, expectedCloudRequest.NamespacedName, foundCloud)).To(gomega.Succeed())
+	foundCloud.Status.ZookeeperConnectionInfo = *additionalCloud.Spec.ZookeeperRef.ConnectionInfo
+	g.Expect(testClient.Status().Update(ctx, foundCloud)).To(gomega.Succeed())
+
+	g.Eventually(func() error { return testClient.Get(ctx, metricsDKey, deployment) }, timeout).Should(gomega.Succeed(),
+		"The exporter Deployment should be created once the additional cloud has a ZooKeeper connection string")
+	assert.Equal(t, 2, len(deployment.Spec.Template.Spec.Containers), "The exporter should have a container for the additional cloud")
+	assert.Contains(t, strings.Join(deployment.Spec.Template.Spec.Containers[1].Args, " "), "additional-host:2181", "The container of the additional cloud should connect to its ZooKeeper")
+}
+
 func expectBasicAuthEnvVars(t *testing.T, envVars []corev1.EnvVar, basicAuthSecret *corev1.Secret) {
 	assert.NotNil(t, envVars)
 	envVars = filterVarsByName(envVars, func(n string) bool {
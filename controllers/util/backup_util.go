@@ -22,14 +22,21 @@ import (
 	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/robfig/cron/v3"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -41,8 +48,36 @@ const (
 	AWSSecretDir = "/var/aws"
 
 	JobTTLSeconds = int32(60)
+
+	S3BackupRepositoryClass        = "org.apache.solr.s3.S3BackupRepository"
+	S3BackupRepositoryModule       = "s3-repository"
+	S3CredentialsVolume            = "s3-credentials"
+	S3CredentialsFile              = "credentials"
+	GCSBackupRepositoryClass       = "org.apache.solr.gcs.GCSBackupRepository"
+	GCSBackupRepositoryModule      = "gcs-repository"
+	GCSCredentialsVolumePrefix     = "gcs-credentials-"
+	GCSCredentialsBaseDir          = "/var/gcs-credentials"
+	GCSCredentialsFile             = "service-account-key.json"
+	BackupRepositoryBackupLocation = "/"
+
+	// The label given to VolumeSnapshots, so that the snapshots of a retained backup can be pruned together
+	VolumeSnapshotBackupNameLabel = "solr-backup-name"
+
+	// The number of times a collection restore is attempted, if Solr refuses to start it, before it is given up on
+	MaxRestoreAttempts = int32(5)
 )
 
+// VolumeSnapshotGVK is the kind of CSI VolumeSnapshots.
+// They are generated as unstructured objects, so that the external snapshotter is not a dependency of the Solr Operator.
+var VolumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// The directories containing the jars needed for each BackupRepository in Solr 8.x.
+// In Solr 9+ the repositories are instead loaded as modules.
+var s3BackupRepositoryLibs = []string{"/opt/solr/contrib/s3-repository/lib", "/opt/solr/dist"}
+var gcsBackupRepositoryLibs = []string{"/opt/solr/contrib/gcs-repository/lib", "/opt/solr/dist"}
+
+var gcsBucketNameRegex = regexp.MustCompile("^[a-z0-9][-_.a-z0-9]*[a-z0-9]$")
+
 func BackupRestoreSubPathForCloud(directoryOverride string, cloud string) string {
 	if directoryOverride == "" {
 		directoryOverride = cloud
@@ -70,6 +105,10 @@ func AsyncIdForCollectionBackup(collection string, backupName string) string {
 	return backupName + "-" + collection
 }
 
+func AsyncIdForCollectionRestore(collection string, backupName string) string {
+	return "restore-" + backupName + "-" + collection
+}
+
 func CheckStatusOfCollectionBackups(backup *solr.SolrBackup) (allFinished bool) {
 	fals := false
 
@@ -92,18 +131,129 @@ func CheckStatusOfCollectionBackups(backup *solr.SolrBackup) (allFinished bool)
 	return
 }
 
-func GenerateBackupPersistenceJobForCloud(backup *solr.SolrBackup, solrCloud *solr.SolrCloud) *batchv1.Job {
-	var backupVolume corev1.VolumeSource
+// AllCollectionBackupsSuccessful returns whether every collection in the backup was backed up successfully
+func AllCollectionBackupsSuccessful(backup *solr.SolrBackup) bool {
+	for _, collectionStatus := range backup.Status.CollectionBackupStatuses {
+		if collectionStatus.Successful == nil || !*collectionStatus.Successful {
+			return false
+		}
+	}
+	return true
+}
+
+// CanStartCollectionBackup returns whether another collection backup can be started, given the number of backups already in progress
+func CanStartCollectionBackup(backup *solr.SolrBackup, inProgress int) bool {
+	return backup.Spec.MaxConcurrentBackups <= 0 || inProgress < int(backup.Spec.MaxConcurrentBackups)
+}
+
+// CheckStatusOfVolumeSnapshots returns whether all VolumeSnapshots of the backup have either become ready to use, or failed
+func CheckStatusOfVolumeSnapshots(backup *solr.SolrBackup) (allFinished bool) {
+	allFinished = len(backup.Status.VolumeSnapshotStatuses) > 0
+	for _, snapshotStatus := range backup.Status.VolumeSnapshotStatuses {
+		allFinished = allFinished && snapshotStatus.Finished()
+	}
+	return allFinished
+}
+
+// AllVolumeSnapshotsReady returns whether every VolumeSnapshot of the backup is ready to use
+func AllVolumeSnapshotsReady(backup *solr.SolrBackup) bool {
+	for _, snapshotStatus := range backup.Status.VolumeSnapshotStatuses {
+		if !snapshotStatus.ReadyToUse {
+			return false
+		}
+	}
+	return true
+}
+
+// IsBackupInProgress returns whether the current backup has been started, but has not yet finished
+func IsBackupInProgress(backup *solr.SolrBackup) bool {
+	return !backup.Status.Finished && (len(backup.Status.CollectionBackupStatuses) > 0 || len(backup.Status.VolumeSnapshotStatuses) > 0 || backup.Status.PersistenceStatus.InProgress)
+}
+
+// ScheduleNextBackup determines whether a new scheduled backup should be started now, and when the following backup is scheduled for.
+func ScheduleNextBackup(backup *solr.SolrBackup) (startBackup bool, nextScheduledTime time.Time, err error) {
+	return scheduleNextBackupWithTime(backup, time.Now())
+}
+
+func scheduleNextBackupWithTime(backup *solr.SolrBackup, currentTime time.Time) (startBackup bool, nextScheduledTime time.Time, err error) {
+	currentTime = currentTime.UTC()
+	schedule, err := cron.ParseStandard(backup.Spec.Schedule)
+	if err != nil {
+		return false, nextScheduledTime, err
+	}
+
+	if backup.Status.NextScheduledTime == nil {
+		// The schedule is new, so only start a backup if one has not already been taken within the scheduled interval
+		var lastBackupTime *time.Time
+		if backup.Status.LastBackupTime != nil {
+			lastBackupTime = &backup.Status.LastBackupTime.Time
+		} else if backup.Status.FinishTime != nil {
+			lastBackupTime = &backup.Status.FinishTime.Time
+		}
+		if lastBackupTime != nil {
+			if nextScheduledTime = schedule.Next(*lastBackupTime); nextScheduledTime.After(currentTime) {
+				return false, nextScheduledTime, nil
+			}
+		}
+	} else if backup.Status.NextScheduledTime.After(currentTime) {
+		// The next backup is not due yet
+		return false, backup.Status.NextScheduledTime.Time, nil
+	}
+
+	// The backup is due. Skip it, and wait for the next one, if the previous backup is still in progress.
+	return !IsBackupInProgress(backup), schedule.Next(currentTime), nil
+}
+
+// RetainedBackupsToPrune returns the oldest retained backups of a scheduled SolrBackup that must be deleted, oldest first.
+// The current backup, which may still be in progress, counts towards maxRetained. So once a new backup is started,
+// only maxRetained-1 of the previous backups are kept, and no more than maxRetained backups exist at once.
+func RetainedBackupsToPrune(backup *solr.SolrBackup) []solr.RetainedBackupStatus {
+	retainedLimit := int(backup.Spec.MaxRetained) - 1
+	if retainedLimit < 0 {
+		retainedLimit = 0
+	}
+	if len(backup.Status.RetainedBackups) <= retainedLimit {
+		return nil
+	}
+	return backup.Status.RetainedBackups[:len(backup.Status.RetainedBackups)-retainedLimit]
+}
+
+// PersistedBackupFileName returns the name of the file (or S3 key) that the given backup is persisted to.
+// Scheduled backups include the time the backup was started in the filename, so that each one is persisted separately.
+func PersistedBackupFileName(solrBackup *solr.SolrBackup, configuredFileName string, backupName string) string {
+	backupSuffix := strings.TrimPrefix(backupName, solrBackup.Name)
+	if backupSuffix == "" {
+		return configuredFileName
+	}
+	return strings.TrimSuffix(configuredFileName, ".tgz") + backupSuffix + ".tgz"
+}
+
+func backupVolumeForCloud(solrCloud *solr.SolrCloud, backupName string) (backupVolume corev1.VolumeSource, backupSubPath string) {
 	var solrCloudBackupDirectoryOverride string
 	if solrCloud.Spec.StorageOptions.BackupRestoreOptions != nil {
 		backupVolume = solrCloud.Spec.StorageOptions.BackupRestoreOptions.Volume
 		solrCloudBackupDirectoryOverride = solrCloud.Spec.StorageOptions.BackupRestoreOptions.Directory
 	}
-	return GenerateBackupPersistenceJob(backup, backupVolume, BackupSubPathForCloud(solrCloudBackupDirectoryOverride, solrCloud.Name, backup.Name))
+	return backupVolume, BackupSubPathForCloud(solrCloudBackupDirectoryOverride, solrCloud.Name, backupName)
+}
+
+func GenerateBackupPersistenceJobForCloud(backup *solr.SolrBackup, solrCloud *solr.SolrCloud) *batchv1.Job {
+	backupVolume, backupSubPath := backupVolumeForCloud(solrCloud, backup.CurrentBackupName())
+	return GenerateBackupPersistenceJob(backup, backupVolume, backupSubPath)
 }
 
 // GenerateBackupPersistenceJob creates a Job that will persist backup data and purge the backup from the solrBackupVolume
 func GenerateBackupPersistenceJob(solrBackup *solr.SolrBackup, solrBackupVolume corev1.VolumeSource, backupSubPath string) *batchv1.Job {
+	return generateBackupJob(solrBackup, solrBackupVolume, backupSubPath, solrBackup.CurrentBackupName(), false)
+}
+
+// GenerateBackupPruneJobForCloud creates a Job that will delete the persisted data of a retained scheduled backup
+func GenerateBackupPruneJobForCloud(backup *solr.SolrBackup, solrCloud *solr.SolrCloud, backupName string) *batchv1.Job {
+	backupVolume, backupSubPath := backupVolumeForCloud(solrCloud, backupName)
+	return generateBackupJob(backup, backupVolume, backupSubPath, backupName, true)
+}
+
+func generateBackupJob(solrBackup *solr.SolrBackup, solrBackupVolume corev1.VolumeSource, backupSubPath string, backupName string, prune bool) *batchv1.Job {
 	copyLabels := solrBackup.GetLabels()
 	if copyLabels == nil {
 		copyLabels = map[string]string{}
@@ -112,7 +262,14 @@ func GenerateBackupPersistenceJob(solrBackup *solr.SolrBackup, solrBackupVolume
 
 	// ttlSeconds := JobTTLSeconds
 
-	image, env, command, volume, volumeMount, numRetries := GeneratePersistenceOptions(solrBackup, solrBackupVolume)
+	image, env, command, volume, volumeMount, numRetries := generatePersistenceOptions(solrBackup, solrBackupVolume, backupName, prune)
+
+	jobName := solr.PersistenceJobNameForBackup(backupName)
+	containerName := "backup-persistence"
+	if prune {
+		jobName = solrBackup.PruneJobName(backupName)
+		containerName = "backup-prune"
+	}
 
 	volumes := []corev1.Volume{
 		{
@@ -139,7 +296,7 @@ func GenerateBackupPersistenceJob(solrBackup *solr.SolrBackup, solrBackupVolume
 
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      solrBackup.PersistenceJobName(),
+			Name:      jobName,
 			Namespace: solrBackup.GetNamespace(),
 			Labels:    labels,
 		},
@@ -156,7 +313,7 @@ func GenerateBackupPersistenceJob(solrBackup *solr.SolrBackup, solrBackupVolume
 					Volumes: volumes,
 					Containers: []corev1.Container{
 						{
-							Name:            "backup-persistence",
+							Name:            containerName,
 							Image:           image.ToImageName(),
 							ImagePullPolicy: image.PullPolicy,
 							VolumeMounts:    volumeMounts,
@@ -174,11 +331,20 @@ func GenerateBackupPersistenceJob(solrBackup *solr.SolrBackup, solrBackupVolume
 			},
 		},
 	}
+	if image.ImagePullSecret != "" {
+		job.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: image.ImagePullSecret}}
+	}
 	return job
 }
 
 // GeneratePersistenceOptions creates options for a Job that will persist backup data
 func GeneratePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume corev1.VolumeSource) (image solr.ContainerImage, envVars []corev1.EnvVar, command []string, volume *corev1.Volume, volumeMount *corev1.VolumeMount, numRetries *int32) {
+	return generatePersistenceOptions(solrBackup, solrBackupVolume, solrBackup.CurrentBackupName(), false)
+}
+
+// generatePersistenceOptions creates options for a Job that will either persist the data of the given backup,
+// or delete the persisted data of the given backup if prune is true
+func generatePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume corev1.VolumeSource, backupName string, prune bool) (image solr.ContainerImage, envVars []corev1.EnvVar, command []string, volume *corev1.Volume, volumeMount *corev1.VolumeMount, numRetries *int32) {
 	persistenceSource := solrBackup.Spec.Persistence
 	if persistenceSource.Volume != nil {
 		// Options for persisting to a volume
@@ -186,7 +352,7 @@ func GeneratePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume co
 		envVars = []corev1.EnvVar{
 			{
 				Name:  "FILE_NAME",
-				Value: persistenceSource.Volume.Filename,
+				Value: PersistedBackupFileName(solrBackup, persistenceSource.Volume.Filename, backupName),
 			},
 		}
 
@@ -205,8 +371,12 @@ func GeneratePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume co
 				MountPath: finalLocation,
 			}
 		}
-		// Copy the information to the persistent storage, and delete it from the backup-restore volume.
-		command = []string{"sh", "-c", BackupTarCommand + "mv " + TarredFile + " \"" + finalLocation + "/${FILE_NAME}\""}
+		if prune {
+			command = []string{"sh", "-c", "rm -f \"" + finalLocation + "/${FILE_NAME}\""}
+		} else {
+			// Copy the information to the persistent storage, and delete it from the backup-restore volume.
+			command = []string{"sh", "-c", BackupTarCommand + "mv " + TarredFile + " \"" + finalLocation + "/${FILE_NAME}\""}
+		}
 
 		r := int32(1)
 		numRetries = &r
@@ -221,7 +391,7 @@ func GeneratePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume co
 			},
 			{
 				Name:  "KEY",
-				Value: s3.Key,
+				Value: PersistedBackupFileName(solrBackup, s3.Key, backupName),
 			},
 			{
 				Name:  "ENDPOINT_URL",
@@ -310,19 +480,30 @@ func GeneratePersistenceOptions(solrBackup *solr.SolrBackup, solrBackupVolume co
 			includeUrl = "--endpoint-url \"${ENDPOINT_URL}\" "
 		}
 
-		command = []string{"sh", "-c", BackupTarCommand + "aws s3 cp " + includeUrl + TarredFile + " \"s3://${BUCKET}/${KEY}\""}
+		if prune {
+			command = []string{"sh", "-c", "aws s3 rm " + includeUrl + "\"s3://${BUCKET}/${KEY}\""}
+		} else {
+			command = []string{"sh", "-c", BackupTarCommand + "aws s3 cp " + includeUrl + TarredFile + " \"s3://${BUCKET}/${KEY}\""}
+		}
 		numRetries = persistenceSource.S3.Retries
 	}
 
 	return image, envVars, command, volume, volumeMount, numRetries
 }
 
-func StartBackupForCollection(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string) (success bool, err error) {
+func StartBackupForCollection(cloud *solr.SolrCloud, collection string, backupName string, repositoryName string, httpHeaders map[string]string) (success bool, err error) {
 	queryParams := url.Values{}
 	queryParams.Add("action", "BACKUP")
 	queryParams.Add("collection", collection)
-	queryParams.Add("name", collection)
-	queryParams.Add("location", BackupPath(backupName))
+	if repositoryName != "" {
+		// All backups share the root of the repository, so the backup name must be unique for each collection
+		queryParams.Add("repository", repositoryName)
+		queryParams.Add("name", AsyncIdForCollectionBackup(collection, backupName))
+		queryParams.Add("location", BackupRepositoryBackupLocation)
+	} else {
+		queryParams.Add("name", collection)
+		queryParams.Add("location", BackupPath(backupName))
+	}
 	queryParams.Add("async", AsyncIdForCollectionBackup(collection, backupName))
 
 	resp := &solr_api.SolrAsyncResponse{}
@@ -341,7 +522,8 @@ func StartBackupForCollection(cloud *solr.SolrCloud, collection string, backupNa
 	return success, err
 }
 
-func CheckBackupForCollection(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string) (finished bool, success bool, asyncStatus string, err error) {
+// CheckBackupForCollection returns the state of a collection's asynchronous backup call, and solr's message for it.
+func CheckBackupForCollection(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string) (finished bool, success bool, asyncStatus string, message string, err error) {
 	queryParams := url.Values{}
 	queryParams.Add("action", "REQUESTSTATUS")
 	queryParams.Add("requestid", AsyncIdForCollectionBackup(collection, backupName))
@@ -354,6 +536,7 @@ func CheckBackupForCollection(cloud *solr.SolrCloud, collection string, backupNa
 	if err == nil {
 		if resp.ResponseHeader.Status == 0 {
 			asyncStatus = resp.Status.AsyncState
+			message = resp.Status.Message
 			if resp.Status.AsyncState == "completed" {
 				finished = true
 				success = true
@@ -367,7 +550,7 @@ func CheckBackupForCollection(cloud *solr.SolrCloud, collection string, backupNa
 		log.Error(err, "Error checking on collection backup", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection, "backup", backupName)
 	}
 
-	return finished, success, asyncStatus, err
+	return finished, success, asyncStatus, message, err
 }
 
 func DeleteAsyncInfoForBackup(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string) (err error) {
@@ -440,3 +623,489 @@ func RunExecForPod(podName string, namespace string, command []string, config re
 
 	return nil
 }
+
+// DeleteRepositoryBackupForCollection deletes a collection's backup from the backup repository it was stored in
+func DeleteRepositoryBackupForCollection(cloud *solr.SolrCloud, collection string, backupName string, repositoryName string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETEBACKUP")
+	queryParams.Add("repository", repositoryName)
+	queryParams.Add("name", AsyncIdForCollectionBackup(collection, backupName))
+	queryParams.Add("location", BackupRepositoryBackupLocation)
+	// Each backup is taken under a unique name, so it only ever has the one backup point.
+	// DELETEBACKUP only accepts one of backupId, maxNumBackupPoints or purgeUnused.
+	queryParams.Add("backupId", "0")
+
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to delete collection backup", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection, "backup", backupName, "repository", repositoryName)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+
+	if err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("DELETEBACKUP", resp.ResponseHeader)
+	}
+	if err != nil {
+		log.Error(err, "Error deleting collection backup", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection, "backup", backupName, "repository", repositoryName)
+	}
+
+	return err
+}
+
+// ValidateRestoreOptions makes sure that the backup given in the SolrCloud's restore options can be found
+func ValidateRestoreOptions(solrCloud *solr.SolrCloud) error {
+	restore := solrCloud.Spec.Restore
+	if restore == nil {
+		return nil
+	}
+	if restore.Repository == "" {
+		if solrCloud.Spec.StorageOptions.BackupRestoreOptions == nil {
+			return fmt.Errorf("restore must specify a backup repository when dataStorage.backupRestoreOptions is not provided")
+		}
+	} else {
+		found := false
+		for _, repo := range solrCloud.Spec.StorageOptions.BackupRepositories {
+			found = found || repo.Name == restore.Repository
+		}
+		if !found {
+			return fmt.Errorf("restore uses backup repository %s, which is not defined in dataStorage.backupRepositories", restore.Repository)
+		}
+	}
+	targetCollections := make(map[string]bool, len(restore.Collections))
+	for _, collection := range restore.Collections {
+		target := collection.TargetCollection()
+		if targetCollections[target] {
+			return fmt.Errorf("restore cannot restore multiple collections as %s", target)
+		}
+		targetCollections[target] = true
+	}
+	return nil
+}
+
+// ValidateDeletionOptions makes sure that the collections of the SolrCloud can be drained as requested when it is deleted
+func ValidateDeletionOptions(solrCloud *solr.SolrCloud) error {
+	opts := solrCloud.Spec.DeletionOptions
+	if opts == nil || opts.Method != solr.DrainMethodBackup {
+		return nil
+	}
+	if opts.Repository == "" {
+		return fmt.Errorf("deletionOptions.repository must be provided when using the %s drain method", solr.DrainMethodBackup)
+	}
+	for _, repo := range solrCloud.Spec.StorageOptions.BackupRepositories {
+		if repo.Name == opts.Repository {
+			return nil
+		}
+	}
+	return fmt.Errorf("deletionOptions uses backup repository %s, which is not defined in dataStorage.backupRepositories", opts.Repository)
+}
+
+// DrainBackupName returns the name of the backup that the collections of a deleted SolrCloud are backed up to.
+// It includes the time that the SolrCloud was deleted, so that the backups of recreated SolrClouds with the same name do not collide.
+func DrainBackupName(solrCloud *solr.SolrCloud) string {
+	deletionTime := time.Now()
+	if solrCloud.DeletionTimestamp != nil {
+		deletionTime = solrCloud.DeletionTimestamp.Time
+	}
+	return solrCloud.Name + "-deletion-" + deletionTime.UTC().Format("20060102-150405")
+}
+
+// RestoreStatusForCloud returns the restore status for the collections in the SolrCloud's restore options.
+// The progress of collections that were already being restored from the same backup is carried over from the current status.
+func RestoreStatusForCloud(solrCloud *solr.SolrCloud) *solr.SolrCloudRestoreStatus {
+	restore := solrCloud.Spec.Restore
+	if restore == nil {
+		return nil
+	}
+	previousStatuses := make(map[string]solr.CollectionRestoreStatus)
+	if previous := solrCloud.Status.Restore; previous != nil && previous.BackupName == restore.BackupName {
+		for _, collectionStatus := range previous.Collections {
+			previousStatuses[collectionStatus.Collection] = collectionStatus
+		}
+	}
+
+	restoreStatus := &solr.SolrCloudRestoreStatus{
+		BackupName:  restore.BackupName,
+		Finished:    true,
+		Collections: make([]solr.CollectionRestoreStatus, len(restore.Collections)),
+	}
+	for i, collection := range restore.Collections {
+		target := collection.TargetCollection()
+		if previous, hasPrevious := previousStatuses[target]; hasPrevious {
+			restoreStatus.Collections[i] = *previous.DeepCopy()
+		} else {
+			restoreStatus.Collections[i] = solr.CollectionRestoreStatus{Collection: target}
+		}
+		restoreStatus.Finished = restoreStatus.Finished && restoreStatus.Collections[i].Finished
+	}
+	return restoreStatus
+}
+
+// RecordFailedRestoreAttempt records that Solr refused to start the restore of a collection.
+// Once MaxRestoreAttempts is reached, the restore is marked as finished and unsuccessful, so that it is not started again.
+func RecordFailedRestoreAttempt(collectionStatus *solr.CollectionRestoreStatus, message string) (givenUp bool) {
+	collectionStatus.FailedAttempts += 1
+	collectionStatus.Message = message
+	if collectionStatus.FailedAttempts >= MaxRestoreAttempts {
+		fals := false
+		collectionStatus.Finished = true
+		collectionStatus.Successful = &fals
+		collectionStatus.Message = fmt.Sprintf("Gave up after %d attempts: %s", collectionStatus.FailedAttempts, message)
+		givenUp = true
+	}
+	return givenUp
+}
+
+func StartRestoreForCollection(cloud *solr.SolrCloud, restoreCollection solr.RestoreCollection, backupName string, repositoryName string, httpHeaders map[string]string) (success bool, err error) {
+	target := restoreCollection.TargetCollection()
+	queryParams := url.Values{}
+	queryParams.Add("action", "RESTORE")
+	queryParams.Add("collection", target)
+	if repositoryName != "" {
+		// Backups in repositories are stored under a unique name for each collection, see StartBackupForCollection
+		queryParams.Add("repository", repositoryName)
+		queryParams.Add("name", AsyncIdForCollectionBackup(restoreCollection.Collection, backupName))
+		queryParams.Add("location", BackupRepositoryBackupLocation)
+	} else {
+		queryParams.Add("name", restoreCollection.Collection)
+		queryParams.Add("location", BackupPath(backupName))
+	}
+	queryParams.Add("async", AsyncIdForCollectionRestore(target, backupName))
+
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to start collection restore", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", target, "backup", backupName)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+
+	if err == nil {
+		if resp.ResponseHeader.Status == 0 {
+			success = true
+		}
+	} else {
+		log.Error(err, "Error starting collection restore", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", target, "backup", backupName)
+	}
+
+	return success, err
+}
+
+// CheckRestoreForCollection returns the state of a collection's asynchronous restore call, and Solr's message for it.
+// The asyncStatus is "notfound" if the restore has not been started.
+func CheckRestoreForCollection(cloud *solr.SolrCloud, collection string, backupName string, httpHeaders map[string]string) (finished bool, success bool, asyncStatus string, message string, err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "REQUESTSTATUS")
+	queryParams.Add("requestid", AsyncIdForCollectionRestore(collection, backupName))
+
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to check on collection restore", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection, "backup", backupName)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+
+	if err == nil {
+		if resp.ResponseHeader.Status == 0 {
+			asyncStatus = resp.Status.AsyncState
+			message = resp.Status.Message
+			if resp.Status.AsyncState == "completed" {
+				finished = true
+				success = true
+			}
+			if resp.Status.AsyncState == "failed" {
+				finished = true
+				success = false
+			}
+		}
+	} else {
+		log.Error(err, "Error checking on collection restore", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection, "backup", backupName)
+	}
+
+	return finished, success, asyncStatus, message, err
+}
+
+// ValidateVolumeSnapshotBackup makes sure that the SolrCloud's data can be backed up using volume snapshots
+func ValidateVolumeSnapshotBackup(backup *solr.SolrBackup, solrCloud *solr.SolrCloud) error {
+	if backup.UsesBackupRepository() {
+		return fmt.Errorf("a backup cannot both use volume snapshots and be stored in backup repository %s", backup.Spec.RepositoryName)
+	}
+	if solrCloud.Spec.StorageOptions.PersistentStorage == nil {
+		return fmt.Errorf("SolrCloud %s does not use persistent data storage, so its data cannot be backed up using volume snapshots", solrCloud.Name)
+	}
+	return nil
+}
+
+// VolumeSnapshotName returns the name of the VolumeSnapshot of the given PVC, for the given backup
+func VolumeSnapshotName(backupName string, pvcName string) string {
+	return backupName + "-" + pvcName
+}
+
+// GenerateVolumeSnapshot returns a new VolumeSnapshot of the given Solr data PVC, for the current backup
+func GenerateVolumeSnapshot(backup *solr.SolrBackup, pvcName string) *unstructured.Unstructured {
+	backupName := backup.CurrentBackupName()
+	labels := backup.SharedLabelsWith(backup.GetLabels())
+	labels[VolumeSnapshotBackupNameLabel] = backupName
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGVK)
+	snapshot.SetName(VolumeSnapshotName(backupName, pvcName))
+	snapshot.SetNamespace(backup.GetNamespace())
+	snapshot.SetLabels(labels)
+	snapshot.Object["spec"] = map[string]interface{}{
+		"volumeSnapshotClassName": backup.Spec.VolumeSnapshot.VolumeSnapshotClassName,
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvcName,
+		},
+	}
+	return snapshot
+}
+
+// VolumeSnapshotReadiness returns whether the given VolumeSnapshot is ready to use, and the error that the snapshot controller reported for it
+func VolumeSnapshotReadiness(snapshot *unstructured.Unstructured) (readyToUse bool, errorMessage string) {
+	readyToUse, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	errorMessage, _, _ = unstructured.NestedString(snapshot.Object, "status", "error", "message")
+	return readyToUse, errorMessage
+}
+
+// CommitCollection issues a hard commit for the given collection, so that all of its indexed documents are flushed to disk
+func CommitCollection(cloud *solr.SolrCloud, collection string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("commit", "true")
+	queryParams.Add("openSearcher", "false")
+
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to commit collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection)
+	err = solr_api.CallCollectionUpdateApi(cloud, collection, queryParams, httpHeaders, resp)
+
+	if err == nil {
+		_, err = solr_api.CheckForCollectionsApiError("COMMIT", resp.ResponseHeader)
+	}
+	if err != nil {
+		log.Error(err, "Error committing collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection)
+	}
+
+	return err
+}
+
+// SupportsS3BackupRepository returns whether the given Solr version is able to use the S3 BackupRepository, which was added in Solr 8.10.
+// Versions that cannot be parsed, such as "latest", are assumed to support it.
+func SupportsS3BackupRepository(solrVersion string) bool {
+	return solrVersionAtLeast(solrVersion, 8, 10)
+}
+
+// SupportsGCSBackupRepository returns whether the given Solr version is able to use the GCS BackupRepository, which was added in Solr 8.9.
+// Versions that cannot be parsed, such as "latest", are assumed to support it.
+func SupportsGCSBackupRepository(solrVersion string) bool {
+	return solrVersionAtLeast(solrVersion, 8, 9)
+}
+
+func solrVersionAtLeast(solrVersion string, minMajor int, minMinor int) bool {
+	versionParts := strings.SplitN(solrVersion, ".", 3)
+	if len(versionParts) < 2 {
+		return true
+	}
+	major, err := strconv.Atoi(versionParts[0])
+	if err != nil {
+		return true
+	}
+	minor, err := strconv.Atoi(strings.SplitN(versionParts[1], "-", 2)[0])
+	if err != nil {
+		return true
+	}
+	return major > minMajor || (major == minMajor && minor >= minMinor)
+}
+
+// IsValidGCSBucketName returns whether the given name follows the GCS bucket naming requirements.
+// Bucket names containing dots can be up to 222 characters, as long as each dot-separated component is at most 63 characters.
+func IsValidGCSBucketName(bucket string) bool {
+	if len(bucket) < 3 || len(bucket) > 222 || !gcsBucketNameRegex.MatchString(bucket) || strings.HasPrefix(bucket, "goog") {
+		return false
+	}
+	if !strings.Contains(bucket, ".") && len(bucket) > 63 {
+		return false
+	}
+	for _, component := range strings.Split(bucket, ".") {
+		if len(component) == 0 || len(component) > 63 {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateBackupRepositories makes sure that the backup repositories of the SolrCloud can be configured
+func ValidateBackupRepositories(solrCloud *solr.SolrCloud) error {
+	reposWithS3Credentials := 0
+	for _, repo := range solrCloud.Spec.StorageOptions.BackupRepositories {
+		if (repo.S3 == nil) == (repo.GCS == nil) {
+			return fmt.Errorf("backup repository %s must specify exactly one repository type", repo.Name)
+		}
+		if repo.S3 != nil {
+			if !SupportsS3BackupRepository(solrCloud.Spec.SolrImage.Tag) {
+				return fmt.Errorf("backup repository %s is an S3 repository, which requires Solr 8.10 or above, but the SolrCloud is running version %s", repo.Name, solrCloud.Spec.SolrImage.Tag)
+			}
+			if repo.S3.Credentials != nil {
+				reposWithS3Credentials += 1
+			}
+		}
+		if repo.GCS != nil {
+			if !SupportsGCSBackupRepository(solrCloud.Spec.SolrImage.Tag) {
+				return fmt.Errorf("backup repository %s is a GCS repository, which requires Solr 8.9 or above, but the SolrCloud is running version %s", repo.Name, solrCloud.Spec.SolrImage.Tag)
+			}
+			if !IsValidGCSBucketName(repo.GCS.Bucket) {
+				return fmt.Errorf("backup repository %s has an invalid GCS bucket name: %s", repo.Name, repo.GCS.Bucket)
+			}
+		}
+	}
+	// The AWS credentials are provided through the environment, so they are shared by all S3 repositories
+	if reposWithS3Credentials > 1 {
+		return fmt.Errorf("only one S3 backup repository can provide credentials, but %d do", reposWithS3Credentials)
+	}
+	return nil
+}
+
+// GenerateBackupRepositoriesForSolrXml returns the <backup> section of the solr.xml for the given repositories,
+// along with any additional libraries that need to be loaded for them.
+func GenerateBackupRepositoriesForSolrXml(backupRepos []solr.SolrBackupRepository) (backupSection string, additionalLibs []string) {
+	if len(backupRepos) == 0 {
+		return "", nil
+	}
+	repoXmls := make([]string, 0)
+	for _, repo := range backupRepos {
+		if repo.GCS != nil {
+			repoXmls = append(repoXmls, fmt.Sprintf(`    <repository name="%s" class="%s" default="false">
+      <str name="gcsBucket">%s</str>
+      <str name="gcsCredentialPath">%s</str>
+    </repository>`, repo.Name, GCSBackupRepositoryClass, repo.GCS.Bucket, GCSCredentialsPath(repo.Name)))
+			for _, lib := range gcsBackupRepositoryLibs {
+				if !ContainsString(additionalLibs, lib) {
+					additionalLibs = append(additionalLibs, lib)
+				}
+			}
+		}
+		if repo.S3 != nil {
+			repoXml := fmt.Sprintf(`    <repository name="%s" class="%s" default="false">
+      <str name="s3.bucket.name">%s</str>
+      <str name="s3.region">%s</str>`, repo.Name, S3BackupRepositoryClass, repo.S3.Bucket, repo.S3.Region)
+			if repo.S3.Endpoint != "" {
+				repoXml += fmt.Sprintf(`
+      <str name="s3.endpoint">%s</str>`, repo.S3.Endpoint)
+			}
+			repoXmls = append(repoXmls, repoXml+`
+    </repository>`)
+			for _, lib := range s3BackupRepositoryLibs {
+				if !ContainsString(additionalLibs, lib) {
+					additionalLibs = append(additionalLibs, lib)
+				}
+			}
+		}
+	}
+	backupSection = fmt.Sprintf(`  <backup>
+%s
+  </backup>
+`, strings.Join(repoXmls, "\n"))
+	return backupSection, additionalLibs
+}
+
+// BackupRepositoryModules returns the Solr modules that need to be enabled for the given repositories
+func BackupRepositoryModules(backupRepos []solr.SolrBackupRepository) (modules []string) {
+	for _, repo := range backupRepos {
+		if repo.S3 != nil && !ContainsString(modules, S3BackupRepositoryModule) {
+			modules = append(modules, S3BackupRepositoryModule)
+		}
+		if repo.GCS != nil && !ContainsString(modules, GCSBackupRepositoryModule) {
+			modules = append(modules, GCSBackupRepositoryModule)
+		}
+	}
+	return modules
+}
+
+// BackupRepositoryEnvVarsAndVolumes returns the environment variables and volumes that provide the Solr pods with the credentials
+// of the given repositories. Credentials are always referenced from secrets, and never inlined.
+func BackupRepositoryEnvVarsAndVolumes(backupRepos []solr.SolrBackupRepository) (envVars []corev1.EnvVar, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) {
+	if modules := BackupRepositoryModules(backupRepos); len(modules) > 0 {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "SOLR_MODULES",
+			Value: strings.Join(modules, ","),
+		})
+	}
+	hasGoogleCredentials := false
+	for i, repo := range backupRepos {
+		if repo.GCS != nil {
+			volumeName := fmt.Sprintf("%s%d", GCSCredentialsVolumePrefix, i)
+			volumes = append(volumes, corev1.Volume{
+				Name: volumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: repo.GCS.CredentialsSecret.Name,
+						Items: []corev1.KeyToPath{
+							{
+								Key:  repo.GCS.CredentialsSecret.Key,
+								Path: GCSCredentialsFile,
+							},
+						},
+					},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: GCSCredentialsBaseDir + "/" + repo.Name,
+				ReadOnly:  true,
+			})
+			// Each repository references its own key in the solr.xml, but only one can be the default for the Google client
+			if !hasGoogleCredentials {
+				envVars = append(envVars, corev1.EnvVar{
+					Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+					Value: GCSCredentialsPath(repo.Name),
+				})
+				hasGoogleCredentials = true
+			}
+		}
+		if repo.S3 == nil || repo.S3.Credentials == nil {
+			continue
+		}
+		creds := repo.S3.Credentials
+		if creds.AccessKeyIdSecret != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:      "AWS_ACCESS_KEY_ID",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: creds.AccessKeyIdSecret},
+			})
+		}
+		if creds.SecretAccessKeySecret != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:      "AWS_SECRET_ACCESS_KEY",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: creds.SecretAccessKeySecret},
+			})
+		}
+		if creds.SessionTokenSecret != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:      "AWS_SESSION_TOKEN",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: creds.SessionTokenSecret},
+			})
+		}
+		if creds.CredentialsFileSecret != nil {
+			volumes = append(volumes, corev1.Volume{
+				Name: S3CredentialsVolume,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: creds.CredentialsFileSecret.Name,
+						Items: []corev1.KeyToPath{
+							{
+								Key:  creds.CredentialsFileSecret.Key,
+								Path: S3CredentialsFile,
+							},
+						},
+					},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      S3CredentialsVolume,
+				MountPath: AWSSecretDir,
+				ReadOnly:  true,
+			})
+			envVars = append(envVars, corev1.EnvVar{
+				Name:  "AWS_SHARED_CREDENTIALS_FILE",
+				Value: AWSSecretDir + "/" + S3CredentialsFile,
+			})
+		}
+	}
+	return envVars, volumes, volumeMounts
+}
+
+// GCSCredentialsPath returns the path of the mounted service account key for the given GCS backup repository
+func GCSCredentialsPath(repositoryName string) string {
+	return GCSCredentialsBaseDir + "/" + repositoryName + "/" + GCSCredentialsFile
+}
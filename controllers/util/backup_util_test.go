@@ -0,0 +1,399 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScheduleNextBackup(t *testing.T) {
+	now := time.Date(2020, 8, 10, 20, 10, 22, 0, time.Local)
+	utcNow := now.UTC()
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+		Spec:       solr.SolrBackupSpec{Schedule: "@every 10m"},
+	}
+
+	// Test a bad schedule string
+	backup.Spec.Schedule = "adasfdsdas"
+	_, _, err := scheduleNextBackupWithTime(backup, now)
+	assert.Error(t, err, "There should be a parsing error for a bad schedule")
+
+	// Test first backup
+	backup.Spec.Schedule = "@every 10m"
+	startBackup, nextBackup, err := scheduleNextBackupWithTime(backup, now)
+	assert.Emptyf(t, err, "There should be no error when the schedule is: %s", backup.Spec.Schedule)
+	assert.True(t, startBackup, "The first backup should be started immediately")
+	assert.EqualValues(t, utcNow.Add(time.Minute*10), nextBackup, "The next backup time is incorrect for the first scheduled backup")
+
+	// Test a new schedule for a backup that was taken within the interval
+	finishTime := metav1.NewTime(utcNow.Add(time.Minute * -4))
+	backup.Status.Finished = true
+	backup.Status.FinishTime = &finishTime
+	startBackup, nextBackup, err = scheduleNextBackupWithTime(backup, now)
+	assert.Emptyf(t, err, "There should be no error when the schedule is: %s", backup.Spec.Schedule)
+	assert.False(t, startBackup, "A backup should not be started when one was taken within the scheduled interval")
+	assert.EqualValues(t, utcNow.Add(time.Minute*6), nextBackup, "The next backup should be scheduled relative to the previous backup")
+
+	// Test a new schedule for a backup that was taken before the interval
+	finishTime = metav1.NewTime(utcNow.Add(time.Minute * -15))
+	startBackup, nextBackup, err = scheduleNextBackupWithTime(backup, now)
+	assert.Emptyf(t, err, "There should be no error when the schedule is: %s", backup.Spec.Schedule)
+	assert.True(t, startBackup, "A backup should be started when the previous backup was taken before the scheduled interval")
+	assert.EqualValues(t, utcNow.Add(time.Minute*10), nextBackup, "The next backup time is incorrect")
+
+	// Test a scheduled backup that is not yet due
+	nextScheduledTime := metav1.NewTime(utcNow.Add(time.Minute * 3))
+	backup.Status.NextScheduledTime = &nextScheduledTime
+	startBackup, nextBackup, err = scheduleNextBackupWithTime(backup, now)
+	assert.Emptyf(t, err, "There should be no error when the schedule is: %s", backup.Spec.Schedule)
+	assert.False(t, startBackup, "A backup should not be started before it is due")
+	assert.EqualValues(t, nextScheduledTime.Time, nextBackup, "The next backup time should not change before it is due")
+
+	// Test a scheduled backup that is due
+	nextScheduledTime = metav1.NewTime(utcNow.Add(time.Minute * -1))
+	startBackup, nextBackup, err = scheduleNextBackupWithTime(backup, now)
+	assert.Emptyf(t, err, "There should be no error when the schedule is: %s", backup.Spec.Schedule)
+	assert.True(t, startBackup, "A backup should be started when it is due")
+	assert.EqualValues(t, utcNow.Add(time.Minute*10), nextBackup, "The next backup time is incorrect")
+
+	// Test a scheduled backup that is due, while the previous backup is still in progress
+	backup.Status.Finished = false
+	backup.Status.CollectionBackupStatuses = []solr.CollectionBackupStatus{{Collection: "col", InProgress: true}}
+	startBackup, nextBackup, err = scheduleNextBackupWithTime(backup, now)
+	assert.Emptyf(t, err, "There should be no error when the schedule is: %s", backup.Spec.Schedule)
+	assert.False(t, startBackup, "A backup should be skipped when the previous backup is still in progress")
+	assert.EqualValues(t, utcNow.Add(time.Minute*10), nextBackup, "The skipped backup should be rescheduled")
+}
+
+func TestRetainedBackupsToPrune(t *testing.T) {
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly"},
+		Spec:       solr.SolrBackupSpec{Schedule: "@daily", MaxRetained: 3},
+		Status: solr.SolrBackupStatus{
+			RetainedBackups: []solr.RetainedBackupStatus{{Name: "nightly-1"}, {Name: "nightly-2"}},
+		},
+	}
+	assert.Empty(t, RetainedBackupsToPrune(backup), "Nothing should be pruned while the retained backups and the current backup fit in maxRetained")
+
+	backup.Status.RetainedBackups = append(backup.Status.RetainedBackups, solr.RetainedBackupStatus{Name: "nightly-3"}, solr.RetainedBackupStatus{Name: "nightly-4"})
+	assert.Equal(t, []solr.RetainedBackupStatus{{Name: "nightly-1"}, {Name: "nightly-2"}}, RetainedBackupsToPrune(backup), "The oldest backups should be pruned, counting the current backup towards maxRetained")
+
+	backup.Spec.MaxRetained = 1
+	assert.Len(t, RetainedBackupsToPrune(backup), 4, "Only the current backup should be kept when maxRetained is 1")
+}
+
+func TestPersistedBackupFileName(t *testing.T) {
+	backup := &solr.SolrBackup{ObjectMeta: metav1.ObjectMeta{Name: "nightly"}}
+	assert.Equal(t, "nightly.tgz", PersistedBackupFileName(backup, "nightly.tgz", backup.CurrentBackupName()), "A one-time backup should use the configured filename")
+
+	startTime := metav1.NewTime(time.Date(2020, 8, 10, 20, 10, 22, 0, time.UTC))
+	backup.Status.LastBackupTime = &startTime
+	assert.Equal(t, "nightly-20200810-201022", backup.CurrentBackupName(), "Scheduled backup names should include the start time")
+	assert.Equal(t, "nightly-20200810-201022.tgz", PersistedBackupFileName(backup, "nightly.tgz", backup.CurrentBackupName()), "Scheduled backups should be persisted separately")
+}
+
+func TestSupportsS3BackupRepository(t *testing.T) {
+	assert.False(t, SupportsS3BackupRepository("8.9"), "Solr 8.9 does not support the S3 BackupRepository")
+	assert.False(t, SupportsS3BackupRepository("7.7.3"), "Solr 7.7.3 does not support the S3 BackupRepository")
+	assert.True(t, SupportsS3BackupRepository("8.10"), "Solr 8.10 supports the S3 BackupRepository")
+	assert.True(t, SupportsS3BackupRepository("8.11.1-slim"), "Solr 8.11.1-slim supports the S3 BackupRepository")
+	assert.True(t, SupportsS3BackupRepository("9.0.0"), "Solr 9.0.0 supports the S3 BackupRepository")
+	assert.True(t, SupportsS3BackupRepository("latest"), "Unparseable versions should be assumed to support the S3 BackupRepository")
+
+	assert.False(t, SupportsGCSBackupRepository("8.8.2"), "Solr 8.8.2 does not support the GCS BackupRepository")
+	assert.True(t, SupportsGCSBackupRepository("8.9"), "Solr 8.9 supports the GCS BackupRepository")
+}
+
+func TestIsValidGCSBucketName(t *testing.T) {
+	assert.True(t, IsValidGCSBucketName("solr-backups"), "Simple bucket names are valid")
+	assert.True(t, IsValidGCSBucketName("solr_backups.example.com"), "Bucket names can contain dots and underscores")
+	assert.False(t, IsValidGCSBucketName("so"), "Bucket names must be at least 3 characters")
+	assert.False(t, IsValidGCSBucketName("Solr-Backups"), "Bucket names cannot contain uppercase characters")
+	assert.False(t, IsValidGCSBucketName("-solr-backups"), "Bucket names must start with a letter or number")
+	assert.False(t, IsValidGCSBucketName("solr-backups."), "Bucket names must end with a letter or number")
+	assert.False(t, IsValidGCSBucketName("google-solr-backups"), "Bucket names cannot start with goog")
+	assert.False(t, IsValidGCSBucketName(strings.Repeat("a", 64)), "Bucket names without dots can be at most 63 characters")
+	assert.True(t, IsValidGCSBucketName(strings.Repeat("a", 63)+"."+strings.Repeat("b", 63)), "Bucket names with dots can be longer than 63 characters")
+	assert.False(t, IsValidGCSBucketName(strings.Repeat("a", 64)+".example"), "Each dot-separated component can be at most 63 characters")
+	assert.False(t, IsValidGCSBucketName("solr..backups"), "Bucket names cannot contain empty components")
+}
+
+func TestGCSBackupRepository(t *testing.T) {
+	repos := []solr.SolrBackupRepository{
+		{
+			Name: "gcs_repo",
+			GCS: &solr.GCSBackupRepository{
+				Bucket: "solr-backups",
+				CredentialsSecret: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "gcs-secret"},
+					Key:                  "service-account.json",
+				},
+			},
+		},
+	}
+
+	backupSection, additionalLibs := GenerateBackupRepositoriesForSolrXml(repos)
+	assert.Contains(t, backupSection, "<repository name=\"gcs_repo\" class=\""+GCSBackupRepositoryClass+"\" default=\"false\">", "The GCS repository should be defined in the solr.xml")
+	assert.Contains(t, backupSection, "<str name=\"gcsBucket\">solr-backups</str>", "The GCS repository bucket is wrong")
+	assert.Contains(t, backupSection, "<str name=\"gcsCredentialPath\">/var/gcs-credentials/gcs_repo/service-account-key.json</str>", "The GCS repository should reference the mounted credentials")
+	assert.ElementsMatch(t, gcsBackupRepositoryLibs, additionalLibs, "The GCS repository libraries should be added")
+
+	envVars, volumes, volumeMounts := BackupRepositoryEnvVarsAndVolumes(repos)
+	assert.ElementsMatch(t, []corev1.EnvVar{
+		{Name: "SOLR_MODULES", Value: GCSBackupRepositoryModule},
+		{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/var/gcs-credentials/gcs_repo/service-account-key.json"},
+	}, envVars, "Wrong env vars for the GCS repository")
+	assert.Equal(t, 1, len(volumes), "The GCS credentials should be mounted as a volume")
+	assert.Equal(t, "gcs-credentials-0", volumes[0].Name, "Wrong name for the GCS credentials volume")
+	assert.Equal(t, "gcs-secret", volumes[0].Secret.SecretName, "The GCS credentials volume should reference the secret")
+	assert.Equal(t, "service-account.json", volumes[0].Secret.Items[0].Key, "The GCS credentials volume should reference the secret key")
+	assert.Equal(t, 1, len(volumeMounts), "The GCS credentials should be mounted into the Solr container")
+	assert.Equal(t, "/var/gcs-credentials/gcs_repo", volumeMounts[0].MountPath, "Wrong mount path for the GCS credentials")
+}
+
+func TestValidateRestoreOptions(t *testing.T) {
+	cloud := &solr.SolrCloud{}
+	assert.NoError(t, ValidateRestoreOptions(cloud), "A SolrCloud without restore options is valid")
+
+	cloud.Spec.Restore = &solr.SolrCloudRestoreOptions{
+		BackupName:  "nightly-20200810-201022",
+		Collections: []solr.RestoreCollection{{Collection: "col1"}, {Collection: "col2", RestoreAs: "col2-restored"}},
+	}
+	assert.Error(t, ValidateRestoreOptions(cloud), "Restoring without a repository requires the backupRestoreOptions volume")
+
+	cloud.Spec.StorageOptions.BackupRestoreOptions = &solr.SolrBackupRestoreOptions{}
+	assert.NoError(t, ValidateRestoreOptions(cloud), "Restoring from the backupRestoreOptions volume is valid")
+
+	cloud.Spec.Restore.Repository = "s3_repo"
+	assert.Error(t, ValidateRestoreOptions(cloud), "The restore repository must be defined")
+
+	cloud.Spec.StorageOptions.BackupRepositories = []solr.SolrBackupRepository{{Name: "s3_repo"}}
+	assert.NoError(t, ValidateRestoreOptions(cloud), "Restoring from a defined repository is valid")
+
+	cloud.Spec.Restore.Collections = append(cloud.Spec.Restore.Collections, solr.RestoreCollection{Collection: "col3", RestoreAs: "col1"})
+	assert.Error(t, ValidateRestoreOptions(cloud), "Two collections cannot be restored as the same collection")
+}
+
+func TestValidateDeletionOptions(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+	assert.NoError(t, ValidateDeletionOptions(cloud), "A SolrCloud without deletion options is valid")
+
+	cloud.Spec.DeletionOptions = &solr.SolrCloudDeletionOptions{Method: solr.DrainMethodCommit}
+	assert.NoError(t, ValidateDeletionOptions(cloud), "Committing collections does not require a repository")
+
+	cloud.Spec.DeletionOptions.Method = solr.DrainMethodBackup
+	assert.Error(t, ValidateDeletionOptions(cloud), "Backing up collections requires a repository")
+
+	cloud.Spec.DeletionOptions.Repository = "s3_repo"
+	assert.Error(t, ValidateDeletionOptions(cloud), "The drain repository must be defined")
+
+	cloud.Spec.StorageOptions.BackupRepositories = []solr.SolrBackupRepository{{Name: "s3_repo"}}
+	assert.NoError(t, ValidateDeletionOptions(cloud), "Backing up to a defined repository is valid")
+
+	deletionTime := metav1.NewTime(time.Date(2020, 8, 10, 20, 10, 22, 0, time.UTC))
+	cloud.DeletionTimestamp = &deletionTime
+	assert.Equal(t, "foo-deletion-20200810-201022", DrainBackupName(cloud), "The drain backup should be named after the deletion time")
+}
+
+func TestRestoreStatusForCloud(t *testing.T) {
+	tru := true
+	cloud := &solr.SolrCloud{}
+	assert.Nil(t, RestoreStatusForCloud(cloud), "There is no restore status without restore options")
+
+	cloud.Spec.Restore = &solr.SolrCloudRestoreOptions{
+		BackupName:  "nightly-20200810-201022",
+		Collections: []solr.RestoreCollection{{Collection: "col1"}, {Collection: "col2", RestoreAs: "col2-restored"}},
+	}
+	status := RestoreStatusForCloud(cloud)
+	assert.Equal(t, "nightly-20200810-201022", status.BackupName, "Wrong backup name in the restore status")
+	assert.False(t, status.Finished, "A new restore should not be finished")
+	assert.Equal(t, []solr.CollectionRestoreStatus{{Collection: "col1"}, {Collection: "col2-restored"}}, status.Collections, "The restore status should track the restored collection names")
+
+	// Carry over the progress of the same backup
+	cloud.Status.Restore = &solr.SolrCloudRestoreStatus{
+		BackupName: "nightly-20200810-201022",
+		Collections: []solr.CollectionRestoreStatus{
+			{Collection: "col1", AsyncRestoreStatus: "completed", Finished: true, Successful: &tru},
+			{Collection: "col2-restored", AsyncRestoreStatus: "running"},
+		},
+	}
+	status = RestoreStatusForCloud(cloud)
+	assert.False(t, status.Finished, "The restore should not be finished while a collection is still being restored")
+	assert.Equal(t, cloud.Status.Restore.Collections, status.Collections, "The progress of the restore should be carried over")
+
+	cloud.Status.Restore.Collections[1] = solr.CollectionRestoreStatus{Collection: "col2-restored", AsyncRestoreStatus: "failed", Finished: true, Message: "error"}
+	status = RestoreStatusForCloud(cloud)
+	assert.True(t, status.Finished, "The restore should be finished once all collections are finished, successfully or not")
+
+	// The progress of a different backup should not be carried over
+	cloud.Spec.Restore.BackupName = "nightly-20200811-201022"
+	status = RestoreStatusForCloud(cloud)
+	assert.False(t, status.Finished, "Restoring a different backup should start over")
+	assert.Equal(t, []solr.CollectionRestoreStatus{{Collection: "col1"}, {Collection: "col2-restored"}}, status.Collections, "Restoring a different backup should start over")
+}
+
+func TestRecordFailedRestoreAttempt(t *testing.T) {
+	collectionStatus := &solr.CollectionRestoreStatus{Collection: "col1"}
+	for attempt := int32(1); attempt < MaxRestoreAttempts; attempt++ {
+		assert.False(t, RecordFailedRestoreAttempt(collectionStatus, "refused"), "The restore should not be given up on before the maximum number of attempts")
+		assert.Equal(t, attempt, collectionStatus.FailedAttempts, "Wrong number of failed attempts")
+		assert.Equal(t, "refused", collectionStatus.Message, "The reason of the failed attempt should be recorded")
+		assert.False(t, collectionStatus.Finished, "The restore should be attempted again")
+		assert.Nil(t, collectionStatus.Successful, "The restore should be attempted again")
+	}
+
+	assert.True(t, RecordFailedRestoreAttempt(collectionStatus, "refused"), "The restore should be given up on after the maximum number of attempts")
+	assert.Equal(t, MaxRestoreAttempts, collectionStatus.FailedAttempts, "Wrong number of failed attempts")
+	assert.True(t, collectionStatus.Finished, "A restore that was given up on should be finished")
+	if assert.NotNil(t, collectionStatus.Successful, "A restore that was given up on should be unsuccessful") {
+		assert.False(t, *collectionStatus.Successful, "A restore that was given up on should be unsuccessful")
+	}
+	assert.Contains(t, collectionStatus.Message, "refused", "The reason of the last failed attempt should be recorded")
+}
+
+// solrResponder answers the Solr API calls of a test with a fixed JSON response, instead of calling a Solr pod
+type solrResponder string
+
+func (response solrResponder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(string(response))),
+		Request:    req,
+	}, nil
+}
+
+func TestCheckBackupForCollection(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	defer solr_api.RemoveHttpClientForCloud(cloud)
+
+	solr_api.SetHttpClientForCloud(cloud, &http.Client{Transport: solrResponder(`{"responseHeader":{"status":0},"status":{"state":"running","msg":"found [nightly-col1] in running tasks"}}`)})
+	finished, successful, asyncStatus, message, err := CheckBackupForCollection(cloud, "col1", "nightly", nil)
+	assert.NoError(t, err, "Checking on a running backup should not fail")
+	assert.False(t, finished, "A running backup is not finished")
+	assert.False(t, successful, "A running backup is not successful yet")
+	assert.Equal(t, "running", asyncStatus, "The async state of the backup should be returned")
+	assert.Equal(t, "found [nightly-col1] in running tasks", message, "Solr's message for the backup should be returned")
+
+	solr_api.SetHttpClientForCloud(cloud, &http.Client{Transport: solrResponder(`{"responseHeader":{"status":0},"status":{"state":"failed","msg":"found [nightly-col1] in failed tasks"}}`)})
+	finished, successful, asyncStatus, message, err = CheckBackupForCollection(cloud, "col1", "nightly", nil)
+	assert.NoError(t, err, "Checking on a failed backup should not fail")
+	assert.True(t, finished, "A failed backup is finished")
+	assert.False(t, successful, "A failed backup is not successful")
+	assert.Equal(t, "failed", asyncStatus, "The async state of the backup should be returned")
+	assert.Equal(t, "found [nightly-col1] in failed tasks", message, "Solr's message for the failed backup should be returned")
+
+	solr_api.SetHttpClientForCloud(cloud, &http.Client{Transport: solrResponder(`{"responseHeader":{"status":0},"status":{"state":"completed","msg":"found [nightly-col1] in completed tasks"}}`)})
+	finished, successful, asyncStatus, _, err = CheckBackupForCollection(cloud, "col1", "nightly", nil)
+	assert.NoError(t, err, "Checking on a completed backup should not fail")
+	assert.True(t, finished, "A completed backup is finished")
+	assert.True(t, successful, "A completed backup is successful")
+	assert.Equal(t, "completed", asyncStatus, "The async state of the backup should be returned")
+}
+
+func TestConcurrentCollectionBackups(t *testing.T) {
+	tru := true
+	fals := false
+	backup := &solr.SolrBackup{Spec: solr.SolrBackupSpec{Collections: []string{"col1", "col2", "col3"}}}
+	assert.True(t, CanStartCollectionBackup(backup, 5), "All collection backups can be started when there is no concurrency limit")
+
+	backup.Spec.MaxConcurrentBackups = 2
+	assert.True(t, CanStartCollectionBackup(backup, 1), "A collection backup can be started below the concurrency limit")
+	assert.False(t, CanStartCollectionBackup(backup, 2), "A collection backup cannot be started at the concurrency limit")
+
+	backup.Status.CollectionBackupStatuses = []solr.CollectionBackupStatus{
+		{Collection: "col1", Finished: true, Successful: &tru},
+		{Collection: "col2", Finished: true, Successful: &fals},
+		{Collection: "col3", InProgress: true},
+	}
+	assert.False(t, CheckStatusOfCollectionBackups(backup), "The backup is not finished while a collection is still being backed up")
+	assert.False(t, AllCollectionBackupsSuccessful(backup), "The backup cannot be successful when a collection failed")
+
+	backup.Status.CollectionBackupStatuses[2] = solr.CollectionBackupStatus{Collection: "col3", Finished: true, Successful: &tru}
+	assert.True(t, CheckStatusOfCollectionBackups(backup), "The collection backups are finished once all collections are finished")
+	assert.False(t, backup.Status.Finished, "A partially failed backup should still be persisted")
+	assert.False(t, AllCollectionBackupsSuccessful(backup), "The backup cannot be successful when a collection failed")
+
+	backup.Status.CollectionBackupStatuses[1].Successful = &tru
+	assert.True(t, AllCollectionBackupsSuccessful(backup), "The backup is successful when all collections are backed up")
+}
+
+func TestVolumeSnapshotBackup(t *testing.T) {
+	backup := &solr.SolrBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: solr.SolrBackupSpec{
+			SolrCloud:      "example",
+			VolumeSnapshot: &solr.VolumeSnapshotBackupOptions{VolumeSnapshotClassName: "csi-snapclass"},
+		},
+	}
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "example"}}
+	assert.Error(t, ValidateVolumeSnapshotBackup(backup, cloud), "Volume snapshots require persistent data storage")
+
+	cloud.Spec.StorageOptions.PersistentStorage = &solr.SolrPersistentDataStorageOptions{}
+	assert.NoError(t, ValidateVolumeSnapshotBackup(backup, cloud), "Volume snapshots of persistent data storage are valid")
+
+	backup.Spec.RepositoryName = "s3_repo"
+	assert.Error(t, ValidateVolumeSnapshotBackup(backup, cloud), "Volume snapshots cannot be stored in a backup repository")
+	backup.Spec.RepositoryName = ""
+
+	startTime := metav1.NewTime(time.Date(2020, 8, 10, 20, 10, 22, 0, time.UTC))
+	backup.Status.LastBackupTime = &startTime
+	snapshot := GenerateVolumeSnapshot(backup, "data-example-solrcloud-0")
+	assert.Equal(t, VolumeSnapshotGVK, snapshot.GroupVersionKind(), "Wrong kind for the VolumeSnapshot")
+	assert.Equal(t, "nightly-20200810-201022-data-example-solrcloud-0", snapshot.GetName(), "Wrong name for the VolumeSnapshot")
+	assert.Equal(t, "default", snapshot.GetNamespace(), "Wrong namespace for the VolumeSnapshot")
+	assert.Equal(t, "nightly-20200810-201022", snapshot.GetLabels()[VolumeSnapshotBackupNameLabel], "The VolumeSnapshot should be labeled with the backup it belongs to")
+	className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "csi-snapclass", className, "Wrong VolumeSnapshotClass for the VolumeSnapshot")
+	pvcName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "data-example-solrcloud-0", pvcName, "The VolumeSnapshot should be taken of the PVC")
+
+	readyToUse, errorMessage := VolumeSnapshotReadiness(snapshot)
+	assert.False(t, readyToUse, "A VolumeSnapshot without a status is not ready to use")
+	assert.Empty(t, errorMessage, "A VolumeSnapshot without a status has no error")
+	snapshot.Object["status"] = map[string]interface{}{"readyToUse": false, "error": map[string]interface{}{"message": "snapshot failed"}}
+	readyToUse, errorMessage = VolumeSnapshotReadiness(snapshot)
+	assert.False(t, readyToUse, "A failed VolumeSnapshot is not ready to use")
+	assert.Equal(t, "snapshot failed", errorMessage, "The error of the VolumeSnapshot should be returned")
+
+	assert.False(t, CheckStatusOfVolumeSnapshots(backup), "A backup without snapshots is not finished")
+	backup.Status.VolumeSnapshotStatuses = []solr.VolumeSnapshotStatus{
+		{PersistentVolumeClaim: "data-example-solrcloud-0", VolumeSnapshot: "snap-0", ReadyToUse: true},
+		{PersistentVolumeClaim: "data-example-solrcloud-1", VolumeSnapshot: "snap-1"},
+	}
+	assert.False(t, CheckStatusOfVolumeSnapshots(backup), "The backup is not finished while a snapshot is not ready")
+	assert.True(t, IsBackupInProgress(backup), "The backup is in progress while its snapshots are being taken")
+
+	backup.Status.VolumeSnapshotStatuses[1].Error = "snapshot failed"
+	assert.True(t, CheckStatusOfVolumeSnapshots(backup), "The backup is finished once all snapshots are ready or failed")
+	assert.False(t, AllVolumeSnapshotsReady(backup), "The backup is not successful when a snapshot failed")
+
+	backup.Status.VolumeSnapshotStatuses[1] = solr.VolumeSnapshotStatus{PersistentVolumeClaim: "data-example-solrcloud-1", VolumeSnapshot: "snap-1", ReadyToUse: true}
+	assert.True(t, AllVolumeSnapshotsReady(backup), "The backup is successful when all snapshots are ready")
+}
@@ -0,0 +1,179 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Prefixes of the alias properties that Solr uses to store the options of routed aliases
+	routedAliasRouterPropertyPrefix           = "router."
+	routedAliasCreateCollectionPropertyPrefix = "create-collection."
+)
+
+// GetAliasState returns the collections and properties of the given alias, using the LISTALIASES action of the Collections API.
+// The returned exists flag is false if the alias does not exist in the SolrCloud.
+func GetAliasState(cloud *solr.SolrCloud, aliasName string, httpHeaders map[string]string) (collections []string, properties map[string]string, exists bool, err error) {
+	aliasesResp := &solr_api.SolrListAliasesResponse{}
+	queryParams := url.Values{}
+	queryParams.Add("action", "LISTALIASES")
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, aliasesResp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("LISTALIASES", aliasesResp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var aliasCollections string
+	if aliasCollections, exists = aliasesResp.Aliases[aliasName]; exists && aliasCollections != "" {
+		collections = strings.Split(aliasCollections, ",")
+	}
+	return collections, aliasesResp.Properties[aliasName], exists, nil
+}
+
+// CreateAlias creates, or replaces, the alias for the given SolrCollectionAlias, using the CREATEALIAS action of the Collections API
+func CreateAlias(cloud *solr.SolrCloud, alias *solr.SolrCollectionAlias, httpHeaders map[string]string) (err error) {
+	queryParams := createAliasParams(alias)
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to create alias", "namespace", cloud.Namespace, "cloud", cloud.Name, "alias", alias.AliasName())
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("CREATEALIAS", resp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		log.Error(err, "Error creating alias", "namespace", cloud.Namespace, "cloud", cloud.Name, "alias", alias.AliasName())
+	}
+
+	return err
+}
+
+func createAliasParams(alias *solr.SolrCollectionAlias) url.Values {
+	queryParams := url.Values{}
+	queryParams.Add("action", "CREATEALIAS")
+	queryParams.Add("name", alias.AliasName())
+	if routed := alias.Spec.Routed; routed != nil {
+		queryParams.Add("router.name", string(routed.RouterName))
+		queryParams.Add("router.field", routed.Field)
+		if routed.Start != "" {
+			queryParams.Add("router.start", routed.Start)
+		}
+		if routed.Interval != "" {
+			queryParams.Add("router.interval", routed.Interval)
+		}
+		if routed.MaxCardinality != nil {
+			queryParams.Add("router.maxCardinality", strconv.Itoa(int(*routed.MaxCardinality)))
+		}
+		if routed.ConfigName != "" {
+			queryParams.Add("create-collection.collection.configName", routed.ConfigName)
+		}
+		if routed.NumShards != nil {
+			queryParams.Add("create-collection.numShards", strconv.Itoa(int(*routed.NumShards)))
+		}
+		if routed.ReplicationFactor != nil {
+			queryParams.Add("create-collection.replicationFactor", strconv.Itoa(int(*routed.ReplicationFactor)))
+		}
+	} else {
+		queryParams.Add("collections", strings.Join(alias.Spec.Collections, ","))
+	}
+	return queryParams
+}
+
+// IsRoutedAlias returns whether the properties of an alias, as returned by GetAliasState, belong to a routed alias
+func IsRoutedAlias(properties map[string]string) bool {
+	_, hasRouter := properties[routedAliasRouterPropertyPrefix+"name"]
+	return hasRouter
+}
+
+// AliasPropertyModifications returns the properties that need to be set, through the ALIASPROP action of the Collections API,
+// so that the properties of an existing alias match the SolrCollectionAlias.
+// Properties that need to be removed are given an empty value.
+// The properties that Solr uses to store the options of routed aliases are never modified.
+func AliasPropertyModifications(alias *solr.SolrCollectionAlias, properties map[string]string) (modifications map[string]string) {
+	modifications = map[string]string{}
+	for property, value := range alias.Spec.Properties {
+		if currentValue, hasProperty := properties[property]; !hasProperty || currentValue != value {
+			modifications[property] = value
+		}
+	}
+	for property := range properties {
+		if strings.HasPrefix(property, routedAliasRouterPropertyPrefix) || strings.HasPrefix(property, routedAliasCreateCollectionPropertyPrefix) {
+			continue
+		}
+		if _, isDesired := alias.Spec.Properties[property]; !isDesired {
+			modifications[property] = ""
+		}
+	}
+	return modifications
+}
+
+// SetAliasProperties sets the given properties of an alias, using the ALIASPROP action of the Collections API.
+// Properties with an empty value are removed from the alias.
+func SetAliasProperties(cloud *solr.SolrCloud, aliasName string, properties map[string]string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "ALIASPROP")
+	queryParams.Add("name", aliasName)
+	for property, value := range properties {
+		queryParams.Add("property."+property, value)
+	}
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to set alias properties", "namespace", cloud.Namespace, "cloud", cloud.Name, "alias", aliasName, "properties", properties)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("ALIASPROP", resp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		log.Error(err, "Error setting alias properties", "namespace", cloud.Namespace, "cloud", cloud.Name, "alias", aliasName)
+	}
+
+	return err
+}
+
+// DeleteAlias deletes an alias, using the DELETEALIAS action of the Collections API.
+// The collections that the alias points to are not deleted.
+func DeleteAlias(cloud *solr.SolrCloud, aliasName string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETEALIAS")
+	queryParams.Add("name", aliasName)
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to delete alias", "namespace", cloud.Namespace, "cloud", cloud.Name, "alias", aliasName)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("DELETEALIAS", resp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		log.Error(err, "Error deleting alias", "namespace", cloud.Namespace, "cloud", cloud.Name, "alias", aliasName)
+	}
+
+	return err
+}
@@ -0,0 +1,94 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestCreateAliasParams(t *testing.T) {
+	alias := &solr.SolrCollectionAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "products"},
+		Spec: solr.SolrCollectionAliasSpec{
+			SolrCloud:   "example",
+			Collections: []string{"products_green", "products_blue"},
+		},
+	}
+
+	params := createAliasParams(alias)
+	assert.Equal(t, "CREATEALIAS", params.Get("action"), "Wrong action to create an alias")
+	assert.Equal(t, "products", params.Get("name"), "The alias name should default to the name of the SolrCollectionAlias")
+	assert.Equal(t, "products_green,products_blue", params.Get("collections"), "The collections of a standard alias should be given in order")
+	assert.Empty(t, params.Get("router.name"), "A router should not be given for a standard alias")
+
+	numShards := int32(2)
+	alias.Spec.AliasName = "logs"
+	alias.Spec.Collections = nil
+	alias.Spec.Routed = &solr.RoutedAliasOptions{
+		RouterName: solr.TimeRoutedAlias,
+		Field:      "timestamp_dt",
+		Start:      "NOW/DAY",
+		Interval:   "+1DAY",
+		ConfigName: "logs_config",
+		NumShards:  &numShards,
+	}
+	params = createAliasParams(alias)
+	assert.Equal(t, "logs", params.Get("name"), "The aliasName should be used when provided")
+	assert.Empty(t, params.Get("collections"), "Collections should not be given for a routed alias")
+	assert.Equal(t, "time", params.Get("router.name"), "Wrong router for the routed alias")
+	assert.Equal(t, "timestamp_dt", params.Get("router.field"), "Wrong router field for the routed alias")
+	assert.Equal(t, "NOW/DAY", params.Get("router.start"), "Wrong start for the time routed alias")
+	assert.Equal(t, "+1DAY", params.Get("router.interval"), "Wrong interval for the time routed alias")
+	assert.Empty(t, params.Get("router.maxCardinality"), "The maxCardinality should not be given when it is not set")
+	assert.Equal(t, "logs_config", params.Get("create-collection.collection.configName"), "Wrong configset for the collections of the routed alias")
+	assert.Equal(t, "2", params.Get("create-collection.numShards"), "Wrong number of shards for the collections of the routed alias")
+	assert.Empty(t, params.Get("create-collection.replicationFactor"), "The replicationFactor should not be given when it is not set")
+}
+
+func TestAliasPropertyModifications(t *testing.T) {
+	alias := &solr.SolrCollectionAlias{
+		ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+		Spec: solr.SolrCollectionAliasSpec{
+			SolrCloud:  "example",
+			Properties: map[string]string{"owner": "search-team", "env": "prod"},
+		},
+	}
+
+	assert.Equal(t, map[string]string{
+		"owner": "search-team",
+		"env":   "prod",
+	}, AliasPropertyModifications(alias, nil), "All properties should be set on an alias without properties")
+
+	properties := map[string]string{
+		"owner":                       "search-team",
+		"env":                         "dev",
+		"retired":                     "true",
+		"router.name":                 "time",
+		"create-collection.numShards": "2",
+	}
+	assert.Equal(t, map[string]string{
+		"env":     "prod",
+		"retired": "",
+	}, AliasPropertyModifications(alias, properties), "Changed properties should be set and unknown properties removed, while leaving routed alias properties alone")
+
+	assert.True(t, IsRoutedAlias(properties), "An alias with a router.name property is a routed alias")
+	assert.False(t, IsRoutedAlias(alias.Spec.Properties), "An alias without a router.name property is a standard alias")
+}
@@ -0,0 +1,224 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GetCollectionState returns the state of the given collection, using the CLUSTERSTATUS action of the Collections API.
+// The returned exists flag is false if the collection does not exist in the SolrCloud.
+func GetCollectionState(cloud *solr.SolrCloud, collectionName string, httpHeaders map[string]string) (state solr_api.SolrCollectionStatus, exists bool, err error) {
+	// Fetch the state for all collections, since Solr returns an error when asking for a collection that does not exist
+	clusterResp := &solr_api.SolrClusterStatusResponse{}
+	queryParams := url.Values{}
+	queryParams.Add("action", "CLUSTERSTATUS")
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, clusterResp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("CLUSTERSTATUS", clusterResp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		return state, false, err
+	}
+	state, exists = clusterResp.ClusterStatus.Collections[collectionName]
+	return state, exists, nil
+}
+
+// GetCollectionStates returns the states of all collections in the SolrCloud, using the CLUSTERSTATUS action of the Collections API
+func GetCollectionStates(cloud *solr.SolrCloud, httpHeaders map[string]string) (states map[string]solr_api.SolrCollectionStatus, err error) {
+	clusterResp := &solr_api.SolrClusterStatusResponse{}
+	queryParams := url.Values{}
+	queryParams.Add("action", "CLUSTERSTATUS")
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, clusterResp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("CLUSTERSTATUS", clusterResp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return clusterResp.ClusterStatus.Collections, nil
+}
+
+// ListCollections returns the names of all collections in the SolrCloud, in sorted order
+func ListCollections(cloud *solr.SolrCloud, httpHeaders map[string]string) (collections []string, err error) {
+	states, err := GetCollectionStates(cloud, httpHeaders)
+	if err != nil {
+		return nil, err
+	}
+	for collection := range states {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
+// CreateCollection creates the collection for the given SolrCollection, using the CREATE action of the Collections API
+func CreateCollection(cloud *solr.SolrCloud, collection *solr.SolrCollection, httpHeaders map[string]string) (err error) {
+	queryParams := createCollectionParams(collection)
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to create collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection.CollectionName())
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("CREATE", resp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		log.Error(err, "Error creating collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collection.CollectionName())
+	}
+
+	return err
+}
+
+func createCollectionParams(collection *solr.SolrCollection) url.Values {
+	queryParams := url.Values{}
+	queryParams.Add("action", "CREATE")
+	queryParams.Add("name", collection.CollectionName())
+	queryParams.Add("router.name", string(collection.Spec.Router.Name))
+	if collection.Spec.Router.Name == solr.ImplicitCollectionRouter {
+		queryParams.Add("shards", strings.Join(collection.Spec.Router.Shards, ","))
+	} else if collection.Spec.NumShards != nil {
+		queryParams.Add("numShards", strconv.Itoa(int(*collection.Spec.NumShards)))
+	}
+	if collection.Spec.Router.Field != "" {
+		queryParams.Add("router.field", collection.Spec.Router.Field)
+	}
+	if collection.Spec.ReplicationFactor != nil {
+		queryParams.Add("replicationFactor", strconv.Itoa(int(*collection.Spec.ReplicationFactor)))
+	}
+	if collection.Spec.ConfigName != "" {
+		queryParams.Add("collection.configName", collection.Spec.ConfigName)
+	}
+	return queryParams
+}
+
+// CollectionModifications returns the collection attributes that need to be changed, through the MODIFYCOLLECTION action of the Collections API,
+// so that the state of an existing collection matches the SolrCollection.
+func CollectionModifications(collection *solr.SolrCollection, state solr_api.SolrCollectionStatus) (modifications map[string]string) {
+	modifications = map[string]string{}
+	if collection.Spec.ReplicationFactor != nil {
+		replicationFactor := strconv.Itoa(int(*collection.Spec.ReplicationFactor))
+		if state.ReplicationFactor != replicationFactor {
+			modifications["replicationFactor"] = replicationFactor
+		}
+	}
+	if collection.Spec.ConfigName != "" && state.ConfigName != collection.Spec.ConfigName {
+		modifications["collection.configName"] = collection.Spec.ConfigName
+	}
+	return modifications
+}
+
+// ModifyCollection changes the given attributes of a collection, using the MODIFYCOLLECTION action of the Collections API
+func ModifyCollection(cloud *solr.SolrCloud, collectionName string, modifications map[string]string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "MODIFYCOLLECTION")
+	queryParams.Add("collection", collectionName)
+	for attribute, value := range modifications {
+		queryParams.Add(attribute, value)
+	}
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to modify collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collectionName, "modifications", modifications)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("MODIFYCOLLECTION", resp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		log.Error(err, "Error modifying collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collectionName)
+	}
+
+	return err
+}
+
+// ReadOnlyCollectionChanges returns the collections that need to be made read-only or writable again, through the readOnly property of MODIFYCOLLECTION,
+// so that the collections of the SolrCloud match its readOnly option.
+// The given readOnlyCollections are the ones that have already been made read-only by the Solr Operator, which are the only ones ever made writable again.
+// Changes made to the readOnly property by others are left alone: collections that were already read-only are not taken over,
+// and a collection that has been made writable again while readOnly is enabled is not made read-only a second time.
+// The returned unchanged collections are the ones that have been made read-only by the Solr Operator, and need no change.
+func ReadOnlyCollectionChanges(readOnly bool, states map[string]solr_api.SolrCollectionStatus, readOnlyCollections []string) (makeReadOnly []string, makeWritable []string, unchanged []string) {
+	madeReadOnly := make(map[string]bool, len(readOnlyCollections))
+	for _, collection := range readOnlyCollections {
+		madeReadOnly[collection] = true
+	}
+	for collection, state := range states {
+		if madeReadOnly[collection] {
+			if !readOnly && state.IsReadOnly() {
+				makeWritable = append(makeWritable, collection)
+			} else if readOnly {
+				unchanged = append(unchanged, collection)
+			}
+		} else if readOnly && !state.IsReadOnly() {
+			makeReadOnly = append(makeReadOnly, collection)
+		}
+	}
+	sort.Strings(makeReadOnly)
+	sort.Strings(makeWritable)
+	sort.Strings(unchanged)
+	return makeReadOnly, makeWritable, unchanged
+}
+
+// DeleteCollection deletes a collection, using the DELETE action of the Collections API
+func DeleteCollection(cloud *solr.SolrCloud, collectionName string, httpHeaders map[string]string) (err error) {
+	queryParams := url.Values{}
+	queryParams.Add("action", "DELETE")
+	queryParams.Add("name", collectionName)
+	resp := &solr_api.SolrAsyncResponse{}
+
+	log.Info("Calling to delete collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collectionName)
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, resp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("DELETE", resp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		log.Error(err, "Error deleting collection", "namespace", cloud.Namespace, "cloud", cloud.Name, "collection", collectionName)
+	}
+
+	return err
+}
+
+// UpdateSolrCollectionStatus fills in the status of a SolrCollection, using the state of the collection in Solr
+func UpdateSolrCollectionStatus(status *solr.SolrCollectionStatus, state solr_api.SolrCollectionStatus) {
+	status.Created = true
+	status.ConfigName = state.ConfigName
+	status.Shards = int32(len(state.Shards))
+	status.Replicas = 0
+	status.ActiveReplicas = 0
+	for _, shard := range state.Shards {
+		for _, replica := range shard.Replicas {
+			status.Replicas += 1
+			if replica.State == solr_api.ReplicaActive {
+				status.ActiveReplicas += 1
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestCreateCollectionParams(t *testing.T) {
+	collection := &solr.SolrCollection{
+		ObjectMeta: metav1.ObjectMeta{Name: "products"},
+		Spec:       solr.SolrCollectionSpec{SolrCloud: "example", ConfigName: "products_config"},
+	}
+	collection.WithDefaults()
+
+	params := createCollectionParams(collection)
+	assert.Equal(t, "CREATE", params.Get("action"), "Wrong action to create a collection")
+	assert.Equal(t, "products", params.Get("name"), "The collection name should default to the name of the SolrCollection")
+	assert.Equal(t, "compositeId", params.Get("router.name"), "The router should default to compositeId")
+	assert.Equal(t, "1", params.Get("numShards"), "The number of shards should default to 1")
+	assert.Equal(t, "1", params.Get("replicationFactor"), "The replicationFactor should default to 1")
+	assert.Equal(t, "products_config", params.Get("collection.configName"), "Wrong configset for the collection")
+	assert.Empty(t, params.Get("shards"), "Shard names should not be given for the compositeId router")
+
+	collection.Spec.CollectionName = "products_v2"
+	collection.Spec.Router = solr.SolrCollectionRouterOptions{
+		Name:   solr.ImplicitCollectionRouter,
+		Shards: []string{"a", "b"},
+		Field:  "region",
+	}
+	params = createCollectionParams(collection)
+	assert.Equal(t, "products_v2", params.Get("name"), "The collectionName should be used when provided")
+	assert.Equal(t, "implicit", params.Get("router.name"), "Wrong router for the collection")
+	assert.Equal(t, "a,b", params.Get("shards"), "The shard names should be given for the implicit router")
+	assert.Empty(t, params.Get("numShards"), "The number of shards should not be given for the implicit router")
+	assert.Equal(t, "region", params.Get("router.field"), "Wrong router field for the collection")
+}
+
+func TestCollectionModifications(t *testing.T) {
+	replicationFactor := int32(2)
+	collection := &solr.SolrCollection{
+		ObjectMeta: metav1.ObjectMeta{Name: "products"},
+		Spec: solr.SolrCollectionSpec{
+			SolrCloud:         "example",
+			ConfigName:        "products_config",
+			ReplicationFactor: &replicationFactor,
+		},
+	}
+
+	state := solr_api.SolrCollectionStatus{ConfigName: "products_config", ReplicationFactor: "2"}
+	assert.Empty(t, CollectionModifications(collection, state), "No modifications are needed when the collection matches the SolrCollection")
+
+	state = solr_api.SolrCollectionStatus{ConfigName: "_default", ReplicationFactor: "1"}
+	assert.Equal(t, map[string]string{
+		"replicationFactor":     "2",
+		"collection.configName": "products_config",
+	}, CollectionModifications(collection, state), "Wrong modifications for a collection that does not match the SolrCollection")
+
+	collection.Spec.ConfigName = ""
+	collection.Spec.ReplicationFactor = nil
+	assert.Empty(t, CollectionModifications(collection, state), "Unset options should not modify the collection")
+}
+
+func TestUpdateSolrCollectionStatus(t *testing.T) {
+	state := solr_api.SolrCollectionStatus{
+		ConfigName: "products_config",
+		Shards: map[string]solr_api.SolrShardStatus{
+			"shard1": {Replicas: map[string]solr_api.SolrReplicaStatus{
+				"core_node1": {State: solr_api.ReplicaActive},
+				"core_node2": {State: solr_api.ReplicaDown},
+			}},
+			"shard2": {Replicas: map[string]solr_api.SolrReplicaStatus{
+				"core_node3": {State: solr_api.ReplicaActive},
+			}},
+		},
+	}
+
+	status := &solr.SolrCollectionStatus{}
+	UpdateSolrCollectionStatus(status, state)
+	assert.True(t, status.Created, "The collection should be marked as created")
+	assert.Equal(t, "products_config", status.ConfigName, "Wrong configset in the status")
+	assert.EqualValues(t, 2, status.Shards, "Wrong number of shards in the status")
+	assert.EqualValues(t, 3, status.Replicas, "Wrong number of replicas in the status")
+	assert.EqualValues(t, 2, status.ActiveReplicas, "Wrong number of active replicas in the status")
+}
+
+func TestReadOnlyCollectionChanges(t *testing.T) {
+	states := map[string]solr_api.SolrCollectionStatus{
+		"products": {},
+		"orders":   {ReadOnly: "false"},
+		"archive":  {ReadOnly: "true"},
+		"users":    {ReadOnly: "true"},
+	}
+
+	makeReadOnly, makeWritable, unchanged := ReadOnlyCollectionChanges(true, states, nil)
+	assert.Equal(t, []string{"orders", "products"}, makeReadOnly, "All writable collections should be made read-only")
+	assert.Empty(t, makeWritable, "No collections should be made writable while readOnly is enabled")
+	assert.Empty(t, unchanged, "Collections that were already read-only should not be taken over by the operator")
+
+	makeReadOnly, makeWritable, unchanged = ReadOnlyCollectionChanges(true, states, []string{"orders", "users", "deleted"})
+	assert.Equal(t, []string{"products"}, makeReadOnly, "Only the writable collections not yet made read-only by the operator should be made read-only")
+	assert.Empty(t, makeWritable, "No collections should be made writable while readOnly is enabled")
+	assert.Equal(t, []string{"orders", "users"}, unchanged, "A collection made writable by someone else should be left alone, and deleted collections should be forgotten")
+
+	makeReadOnly, makeWritable, unchanged = ReadOnlyCollectionChanges(false, states, []string{"orders", "users", "deleted"})
+	assert.Empty(t, makeReadOnly, "No collections should be made read-only once readOnly is disabled")
+	assert.Equal(t, []string{"users"}, makeWritable, "Only the read-only collections made read-only by the operator should be made writable again")
+	assert.Empty(t, unchanged, "No collections should be left read-only by the operator once readOnly is disabled")
+}
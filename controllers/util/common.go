@@ -18,19 +18,28 @@
 package util
 
 import (
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
 	"reflect"
+	"regexp"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+var imageTagRegex = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
 // CopyLabelsAndAnnotations copies the labels and annotations from one object to another.
 // Additional Labels and Annotations in the 'to' object will not be removed.
 // Returns true if there are updates required to the object.
@@ -132,6 +141,32 @@ func IsPVCOrphan(pvcName string, replicas int32) bool {
 	return int32(ordinal) >= replicas
 }
 
+// IsSolrCloudPVCOrphan returns whether the given data PVC of the SolrCloud is no longer used by the StatefulSet that created it.
+// PVCs of node pools are labeled with the name of their node pool, and are orphaned if the node pool has been removed.
+func IsSolrCloudPVCOrphan(solrCloud *solr.SolrCloud, pvc corev1.PersistentVolumeClaim) bool {
+	replicas := int32(0)
+	if poolName, inPool := pvc.Labels[SolrNodePoolLabel]; inPool {
+		for _, pool := range solrCloud.Spec.NodePools {
+			if pool.Name == poolName {
+				replicas = pool.Replicas
+			}
+		}
+	} else if solrCloud.Spec.Replicas != nil {
+		replicas = *solrCloud.Spec.Replicas
+	}
+	return IsPVCOrphan(pvc.Name, replicas)
+}
+
+// PVCNeedsExpansion returns whether the given PVC is smaller than the requested storage size,
+// and whether the storage request of the PVC still needs to be increased to start the expansion.
+func PVCNeedsExpansion(pvc *corev1.PersistentVolumeClaim, requestedSize resource.Quantity) (needsExpansion bool, needsResizeRequest bool) {
+	currentRequest := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	needsResizeRequest = currentRequest.Cmp(requestedSize) < 0
+	capacity, hasCapacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	needsExpansion = needsResizeRequest || (hasCapacity && capacity.Cmp(requestedSize) < 0)
+	return needsExpansion, needsResizeRequest
+}
+
 // CopyConfigMapFields copies the owned fields from one ConfigMap to another
 func CopyConfigMapFields(from, to *corev1.ConfigMap, logger logr.Logger) bool {
 	logger = logger.WithValues("kind", "configMap")
@@ -153,8 +188,53 @@ func CopyServiceFields(from, to *corev1.Service, logger logr.Logger) bool {
 	logger = logger.WithValues("kind", "service")
 	requireUpdate := CopyLabelsAndAnnotations(&from.ObjectMeta, &to.ObjectMeta, logger)
 
+	// The external-dns annotations are owned by the operator, so remove them if they are no longer generated
+	for _, annotation := range []string{ExternalDNSHostnameAnnotation, ExternalDNSTTLAnnotation} {
+		if _, hasAnnotation := from.Annotations[annotation]; !hasAnnotation {
+			if oldValue, hadAnnotation := to.Annotations[annotation]; hadAnnotation {
+				requireUpdate = true
+				logger.Info("Remove Annotation", "annotation", annotation, "oldValue", oldValue)
+				delete(to.Annotations, annotation)
+			}
+		}
+	}
+
 	// Don't copy the entire Spec, because we can't overwrite the clusterIp field
 
+	// An empty service type defaults to ClusterIP
+	fromType, toType := from.Spec.Type, to.Spec.Type
+	if fromType == "" {
+		fromType = corev1.ServiceTypeClusterIP
+	}
+	if toType == "" {
+		toType = corev1.ServiceTypeClusterIP
+	}
+	if toType != fromType {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec.Type", "from", to.Spec.Type, "to", fromType)
+		to.Spec.Type = fromType
+
+		// The type can be changed in place, as long as the fields that are only valid for the previous type are removed.
+		// The nodePorts are removed below, when the ports are copied.
+		if fromType != corev1.ServiceTypeLoadBalancer {
+			to.Spec.LoadBalancerIP = ""
+			to.Spec.LoadBalancerSourceRanges = nil
+			to.Spec.HealthCheckNodePort = 0
+		}
+		if fromType == corev1.ServiceTypeClusterIP {
+			to.Spec.ExternalTrafficPolicy = ""
+		}
+	} else {
+		// Keep the nodePorts that Kubernetes allocated, unless specific nodePorts are requested
+		for i, fromPort := range from.Spec.Ports {
+			for _, toPort := range to.Spec.Ports {
+				if fromPort.NodePort == 0 && fromPort.Name == toPort.Name {
+					from.Spec.Ports[i].NodePort = toPort.NodePort
+				}
+			}
+		}
+	}
+
 	if !DeepEqualWithNils(to.Spec.Selector, from.Spec.Selector) {
 		requireUpdate = true
 		logger.Info("Update required because field changed", "field", "Spec.Selector", "from", to.Spec.Selector, "to", from.Spec.Selector)
@@ -179,14 +259,79 @@ func CopyServiceFields(from, to *corev1.Service, logger logr.Logger) bool {
 	}
 	to.Spec.PublishNotReadyAddresses = from.Spec.PublishNotReadyAddresses
 
+	// The IP families are defaulted by Kubernetes, so they are only owned by the operator when they are requested
+	if from.Spec.IPFamilyPolicy != nil && !DeepEqualWithNils(to.Spec.IPFamilyPolicy, from.Spec.IPFamilyPolicy) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec.IPFamilyPolicy", "from", to.Spec.IPFamilyPolicy, "to", from.Spec.IPFamilyPolicy)
+		to.Spec.IPFamilyPolicy = from.Spec.IPFamilyPolicy
+	}
+	if len(from.Spec.IPFamilies) > 0 && !DeepEqualWithNils(to.Spec.IPFamilies, from.Spec.IPFamilies) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec.IPFamilies", "from", to.Spec.IPFamilies, "to", from.Spec.IPFamilies)
+		to.Spec.IPFamilies = from.Spec.IPFamilies
+	}
+
 	return requireUpdate
 }
 
+// ServiceRecreateReason returns why the existing Service cannot be updated in place to match the generated Service, or an empty string if it can.
+// The clusterIP of a Service is immutable, so a Service can only become headless, or stop being headless, by being recreated.
+// The primary IP family of a Service is immutable as well, so changing it also requires a recreate,
+// however a secondary IP family can be added or removed in place.
+// The type of a Service can be updated in place, which CopyServiceFields takes care of.
+func ServiceRecreateReason(from, to *corev1.Service) string {
+	fromHeadless, toHeadless := from.Spec.ClusterIP == corev1.ClusterIPNone, to.Spec.ClusterIP == corev1.ClusterIPNone
+	if fromHeadless != toHeadless || (from.Spec.ClusterIP != "" && from.Spec.ClusterIP != to.Spec.ClusterIP) {
+		return fmt.Sprintf("the immutable clusterIP must change from \"%s\" to \"%s\"", to.Spec.ClusterIP, from.Spec.ClusterIP)
+	}
+	if len(from.Spec.IPFamilies) > 0 && len(to.Spec.IPFamilies) > 0 && from.Spec.IPFamilies[0] != to.Spec.IPFamilies[0] {
+		return fmt.Sprintf("the immutable primary IP family must change from \"%s\" to \"%s\"", to.Spec.IPFamilies[0], from.Spec.IPFamilies[0])
+	}
+	return ""
+}
+
+// setServiceIPFamilies sets the IP family policy and IP families of the given custom service options on the Service.
+// Options that are not provided are left empty, so that Kubernetes can default them.
+func setServiceIPFamilies(service *corev1.Service, options *solr.ServiceOptions) {
+	if options == nil {
+		return
+	}
+	service.Spec.IPFamilyPolicy = options.IPFamilyPolicy
+	service.Spec.IPFamilies = options.IPFamilies
+}
+
 // CopyIngressFields copies the owned fields from one Ingress to another
 func CopyIngressFields(from, to *netv1.Ingress, logger logr.Logger) bool {
 	logger = logger.WithValues("kind", "ingress")
 	requireUpdate := CopyLabelsAndAnnotations(&from.ObjectMeta, &to.ObjectMeta, logger)
 
+	if !DeepEqualWithNils(to.Spec.IngressClassName, from.Spec.IngressClassName) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec.IngressClassName", "from", to.Spec.IngressClassName, "to", from.Spec.IngressClassName)
+		to.Spec.IngressClassName = from.Spec.IngressClassName
+	}
+
+	// The ingress class annotation cannot be used alongside the ingressClassName, so remove it if it was set previously
+	if from.Spec.IngressClassName != nil {
+		if oldValue, hasAnnotation := to.Annotations[IngressClassAnnotation]; hasAnnotation {
+			requireUpdate = true
+			logger.Info("Remove Annotation", "annotation", IngressClassAnnotation, "oldValue", oldValue)
+			delete(to.Annotations, IngressClassAnnotation)
+		}
+	}
+
+	// cert-manager cannot use both an Issuer and a ClusterIssuer, so remove the one that is no longer used if the issuer kind changed
+	for usedAnnotation, unusedAnnotation := range map[string]string{CertManagerIssuerAnnotation: CertManagerClusterIssuerAnnotation, CertManagerClusterIssuerAnnotation: CertManagerIssuerAnnotation} {
+		if _, usesIssuer := from.Annotations[usedAnnotation]; !usesIssuer {
+			continue
+		}
+		if oldValue, hasAnnotation := to.Annotations[unusedAnnotation]; hasAnnotation {
+			requireUpdate = true
+			logger.Info("Remove Annotation", "annotation", unusedAnnotation, "oldValue", oldValue)
+			delete(to.Annotations, unusedAnnotation)
+		}
+	}
+
 	if len(to.Spec.Rules) != len(from.Spec.Rules) {
 		requireUpdate = true
 		logger.Info("Update required because field changed", "field", "Spec.Rules", "from", to.Spec.Rules, "to", from.Spec.Rules)
@@ -259,6 +404,43 @@ func CopyIngressFields(from, to *netv1.Ingress, logger logr.Logger) bool {
 	return requireUpdate
 }
 
+// CopyPodDisruptionBudgetFields copies the owned fields from one PodDisruptionBudget to another
+func CopyPodDisruptionBudgetFields(from, to *policyv1beta1.PodDisruptionBudget, logger logr.Logger) bool {
+	logger = logger.WithValues("kind", "podDisruptionBudget")
+	requireUpdate := CopyLabelsAndAnnotations(&from.ObjectMeta, &to.ObjectMeta, logger)
+
+	if !DeepEqualWithNils(to.Spec.MaxUnavailable, from.Spec.MaxUnavailable) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec.MaxUnavailable", "from", to.Spec.MaxUnavailable, "to", from.Spec.MaxUnavailable)
+		to.Spec.MaxUnavailable = from.Spec.MaxUnavailable
+	}
+
+	if !DeepEqualWithNils(to.Spec.MinAvailable, from.Spec.MinAvailable) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec.MinAvailable", "from", to.Spec.MinAvailable, "to", from.Spec.MinAvailable)
+		to.Spec.MinAvailable = from.Spec.MinAvailable
+	}
+
+	if !DeepEqualWithNils(to.Spec.Selector, from.Spec.Selector) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec.Selector", "from", to.Spec.Selector, "to", from.Spec.Selector)
+		to.Spec.Selector = from.Spec.Selector
+	}
+
+	return requireUpdate
+}
+
+// VolumeClaimTemplateNames returns the sorted names of the volumeClaimTemplates of the StatefulSet.
+// These cannot be changed once the StatefulSet has been created, so a StatefulSet whose names differ must be recreated.
+func VolumeClaimTemplateNames(statefulSet *appsv1.StatefulSet) []string {
+	names := make([]string, len(statefulSet.Spec.VolumeClaimTemplates))
+	for i, vct := range statefulSet.Spec.VolumeClaimTemplates {
+		names[i] = vct.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
 // CopyStatefulSetFields copies the owned fields from one StatefulSet to another
 // Returns true if the fields copied from don't match to.
 func CopyStatefulSetFields(from, to *appsv1.StatefulSet, logger logr.Logger) bool {
@@ -357,6 +539,26 @@ func CopyDeploymentFields(from, to *appsv1.Deployment, logger logr.Logger) bool
 	return requireUpdate
 }
 
+// CopyServiceMonitorFields copies the owned fields from one ServiceMonitor to another
+// Returns true if the fields copied from don't match to.
+func CopyServiceMonitorFields(from, to *unstructured.Unstructured, logger logr.Logger) bool {
+	logger = logger.WithValues("kind", "serviceMonitor")
+	toMeta := &metav1.ObjectMeta{Labels: to.GetLabels(), Annotations: to.GetAnnotations()}
+	requireUpdate := CopyLabelsAndAnnotations(&metav1.ObjectMeta{Labels: from.GetLabels(), Annotations: from.GetAnnotations()}, toMeta, logger)
+	if requireUpdate {
+		to.SetLabels(toMeta.Labels)
+		to.SetAnnotations(toMeta.Annotations)
+	}
+
+	if !DeepEqualWithNils(to.Object["spec"], from.Object["spec"]) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", "Spec", "from", to.Object["spec"], "to", from.Object["spec"])
+		to.Object["spec"] = from.Object["spec"]
+	}
+
+	return requireUpdate
+}
+
 func CopyPodTemplates(from, to *corev1.PodTemplateSpec, basePath string, logger logr.Logger) (requireUpdate bool) {
 	if basePath == "" {
 		logger = logger.WithValues("kind", "pod")
@@ -383,6 +585,19 @@ func CopyPodTemplates(from, to *corev1.PodTemplateSpec, basePath string, logger
 		logger.Info("Update required because field changed", "field", basePath+"Spec.HostAliases", "from", to.Spec.HostAliases, "to", from.Spec.HostAliases)
 	}
 
+	// Kubernetes defaults an empty dnsPolicy to ClusterFirst, so only a change to the effective policy requires an update
+	if dnsPolicyOrDefault(to.Spec.DNSPolicy) != dnsPolicyOrDefault(from.Spec.DNSPolicy) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.DNSPolicy", "from", to.Spec.DNSPolicy, "to", from.Spec.DNSPolicy)
+		to.Spec.DNSPolicy = from.Spec.DNSPolicy
+	}
+
+	if !DeepEqualWithNils(to.Spec.DNSConfig, from.Spec.DNSConfig) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.DNSConfig", "from", to.Spec.DNSConfig, "to", from.Spec.DNSConfig)
+		to.Spec.DNSConfig = from.Spec.DNSConfig
+	}
+
 	if !DeepEqualWithNils(to.Spec.Volumes, from.Spec.Volumes) {
 		requireUpdate = true
 		to.Spec.Volumes = from.Spec.Volumes
@@ -401,6 +616,12 @@ func CopyPodTemplates(from, to *corev1.PodTemplateSpec, basePath string, logger
 		to.Spec.Affinity = from.Spec.Affinity
 	}
 
+	if !DeepEqualWithNils(to.Spec.TopologySpreadConstraints, from.Spec.TopologySpreadConstraints) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.TopologySpreadConstraints", "from", to.Spec.TopologySpreadConstraints, "to", from.Spec.TopologySpreadConstraints)
+		to.Spec.TopologySpreadConstraints = from.Spec.TopologySpreadConstraints
+	}
+
 	if !DeepEqualWithNils(to.Spec.SecurityContext, from.Spec.SecurityContext) {
 		requireUpdate = true
 		logger.Info("Update required because field changed", "field", basePath+"Spec.SecurityContext", "from", to.Spec.SecurityContext, "to", from.Spec.SecurityContext)
@@ -425,6 +646,12 @@ func CopyPodTemplates(from, to *corev1.PodTemplateSpec, basePath string, logger
 		to.Spec.PriorityClassName = from.Spec.PriorityClassName
 	}
 
+	if !DeepEqualWithNils(to.Spec.RuntimeClassName, from.Spec.RuntimeClassName) {
+		requireUpdate = true
+		logger.Info("Update required because field changed", "field", basePath+"Spec.RuntimeClassName", "from", to.Spec.RuntimeClassName, "to", from.Spec.RuntimeClassName)
+		to.Spec.RuntimeClassName = from.Spec.RuntimeClassName
+	}
+
 	if !DeepEqualWithNils(to.Spec.TerminationGracePeriodSeconds, from.Spec.TerminationGracePeriodSeconds) {
 		requireUpdate = true
 		logger.Info("Update required because field changed", "field", basePath+"Spec.TerminationGracePeriodSeconds", "from", to.Spec.TerminationGracePeriodSeconds, "to", from.Spec.TerminationGracePeriodSeconds)
@@ -440,6 +667,61 @@ func CopyPodTemplates(from, to *corev1.PodTemplateSpec, basePath string, logger
 	return requireUpdate
 }
 
+// dnsPolicyOrDefault returns the given dnsPolicy, or ClusterFirst if none is given, as Kubernetes would default it
+func dnsPolicyOrDefault(dnsPolicy corev1.DNSPolicy) corev1.DNSPolicy {
+	if dnsPolicy == "" {
+		return corev1.DNSClusterFirst
+	}
+	return dnsPolicy
+}
+
+// fillTopologySpreadConstraints returns a copy of the given constraints,
+// using the given selector labels for any constraint that does not specify a labelSelector.
+func fillTopologySpreadConstraints(constraints []corev1.TopologySpreadConstraint, selectorLabels map[string]string) []corev1.TopologySpreadConstraint {
+	filledConstraints := make([]corev1.TopologySpreadConstraint, len(constraints))
+	for i, constraint := range constraints {
+		constraint.DeepCopyInto(&filledConstraints[i])
+		if filledConstraints[i].LabelSelector == nil {
+			filledConstraints[i].LabelSelector = &metav1.LabelSelector{
+				MatchLabels: DuplicateLabelsOrAnnotations(selectorLabels),
+			}
+		}
+	}
+	return filledConstraints
+}
+
+// containerSecurityContext returns the security context that should be used for the default container of a pod,
+// and any init containers that the Solr Operator creates using the same image.
+func containerSecurityContext(podOptions *solr.PodOptions) *corev1.SecurityContext {
+	if podOptions == nil || podOptions.ContainerSecurityContext == nil {
+		return nil
+	}
+	return podOptions.ContainerSecurityContext.DeepCopy()
+}
+
+// DefaultInitContainerResources returns the resources of the init containers that the Solr Operator creates, when none are given through the podOptions.
+// Some of these init containers run short-lived Java tools, such as "solr zk" and keytool, so they are given enough memory for a small JVM.
+func DefaultInitContainerResources() corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+	}
+}
+
+// initContainerResources returns the resources to give the init containers that the Solr Operator creates
+func initContainerResources(podOptions *solr.PodOptions) corev1.ResourceRequirements {
+	if podOptions == nil || podOptions.InitContainerResources == nil {
+		return DefaultInitContainerResources()
+	}
+	return *podOptions.InitContainerResources.DeepCopy()
+}
+
 func CopyPodContainers(fromPtr, toPtr *[]corev1.Container, basePath string, logger logr.Logger) (requireUpdate bool) {
 	to := *toPtr
 	from := *fromPtr
@@ -488,6 +770,12 @@ func CopyPodContainers(fromPtr, toPtr *[]corev1.Container, basePath string, logg
 				to[i].Env = from[i].Env
 			}
 
+			if !DeepEqualWithNils(to[i].EnvFrom, from[i].EnvFrom) {
+				requireUpdate = true
+				logger.Info("Update required because field changed", "field", containerBasePath+"EnvFrom", "from", to[i].EnvFrom, "to", from[i].EnvFrom)
+				to[i].EnvFrom = from[i].EnvFrom
+			}
+
 			if !DeepEqualWithNils(to[i].Resources, from[i].Resources) {
 				requireUpdate = true
 				logger.Info("Update required because field changed", "field", containerBasePath+"Resources", "from", to[i].Resources, "to", from[i].Resources)
@@ -530,6 +818,12 @@ func CopyPodContainers(fromPtr, toPtr *[]corev1.Container, basePath string, logg
 				to[i].StartupProbe = from[i].StartupProbe
 			}
 
+			if !DeepEqualWithNils(to[i].SecurityContext, from[i].SecurityContext) {
+				requireUpdate = true
+				logger.Info("Update required because field changed", "field", containerBasePath+"SecurityContext", "from", to[i].SecurityContext, "to", from[i].SecurityContext)
+				to[i].SecurityContext = from[i].SecurityContext
+			}
+
 			if from[i].TerminationMessagePath != "" && !DeepEqualWithNils(to[i].TerminationMessagePath, from[i].TerminationMessagePath) {
 				requireUpdate = true
 				logger.Info("Update required because field changed", "field", containerBasePath+"TerminationMessagePath", "from", to[i].TerminationMessagePath, "to", from[i].TerminationMessagePath)
@@ -559,3 +853,77 @@ func OvertakeControllerRef(owner metav1.Object, controlled metav1.Object, scheme
 	}
 	return needsUpdate, err
 }
+
+// ValidateContainerImage makes sure that the given image has a valid tag, and that its repository does not include a tag of its own.
+// An empty tag is valid, since it is filled in by the defaults.
+func ValidateContainerImage(imageField string, image *solr.ContainerImage) error {
+	if image == nil {
+		return nil
+	}
+	if image.Tag != "" && !imageTagRegex.MatchString(image.Tag) {
+		return fmt.Errorf("the tag \"%s\" of %s is not a valid image tag", image.Tag, imageField)
+	}
+	if solr.ImageVersion(image.Repository) != "" {
+		return fmt.Errorf("the repository \"%s\" of %s cannot include a tag, use the tag field instead", image.Repository, imageField)
+	}
+	return nil
+}
+
+type containerPortKey struct {
+	port     int32
+	protocol corev1.Protocol
+}
+
+// ValidateSidecarPorts makes sure that the ports of the sidecar containers do not collide with each other,
+// or with the ports of the containers that the operator manages, which are given as a map from their TCP ports to the container names.
+// Otherwise the pods fail with errors that are hard to trace back to the sidecars.
+func ValidateSidecarPorts(managedPorts map[int]string, sidecarContainers []corev1.Container) error {
+	usedPorts := make(map[containerPortKey]string, len(managedPorts))
+	for port, containerName := range managedPorts {
+		usedPorts[containerPortKey{port: int32(port), protocol: corev1.ProtocolTCP}] = containerName
+	}
+	for _, container := range sidecarContainers {
+		for _, containerPort := range container.Ports {
+			key := containerPortKey{port: containerPort.ContainerPort, protocol: containerPort.Protocol}
+			if key.protocol == "" {
+				key.protocol = corev1.ProtocolTCP
+			}
+			if otherContainer, isUsed := usedPorts[key]; isUsed {
+				return fmt.Errorf("port %d of the sidecar container \"%s\" is already used by the container \"%s\"", key.port, container.Name, otherContainer)
+			}
+			usedPorts[key] = container.Name
+		}
+	}
+	return nil
+}
+
+// ValidateLifecycleHooks makes sure that the custom preStop and postStart hooks of the pod options can be run.
+// Kubernetes accepts hooks without a command, which then fail every time a container is started or stopped,
+// and tcpSocket hooks, which are never run.
+func ValidateLifecycleHooks(podOptions *solr.PodOptions) error {
+	if podOptions == nil {
+		return nil
+	}
+	hooks := []struct {
+		name    string
+		handler *corev1.Handler
+	}{
+		{name: "preStop", handler: podOptions.PreStop},
+		{name: "postStart", handler: podOptions.PostStart},
+	}
+	for _, hook := range hooks {
+		if hook.handler == nil {
+			continue
+		}
+		if hook.handler.TCPSocket != nil {
+			return fmt.Errorf("the %s hook cannot use a tcpSocket handler, since Kubernetes does not run tcpSocket lifecycle hooks", hook.name)
+		}
+		if hook.handler.Exec == nil && hook.handler.HTTPGet == nil {
+			return fmt.Errorf("the %s hook must provide an exec or httpGet handler", hook.name)
+		}
+		if hook.handler.Exec != nil && (len(hook.handler.Exec.Command) == 0 || strings.TrimSpace(hook.handler.Exec.Command[0]) == "") {
+			return fmt.Errorf("the exec command of the %s hook cannot be empty", hook.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DryRunChange is a change to a Kubernetes object that a reconcile would have made, if it was not a dry run.
+type DryRunChange struct {
+	// The action that would have been taken, Create, Update, Patch or Delete
+	Action string
+
+	// The kind and name of the object that would have been changed
+	Kind string
+	Name string
+
+	// The fields that differ from the existing object, as reported by the Copy*Fields helpers.
+	// Only set for updates.
+	Fields []string
+}
+
+func (change DryRunChange) String() string {
+	description := fmt.Sprintf("%s %s %s", change.Action, change.Kind, change.Name)
+	if len(change.Fields) > 0 {
+		description += " (" + strings.Join(change.Fields, ", ") + ")"
+	}
+	return description
+}
+
+// DryRunPlan collects the changes that a reconcile would have made, in the order they would have been made.
+type DryRunPlan struct {
+	Changes []DryRunChange
+
+	// Fields that have been reported as changed, but not yet been attached to an update
+	pendingFields []string
+}
+
+func (plan *DryRunPlan) recordChange(action string, obj runtime.Object) {
+	change := DryRunChange{
+		Action: action,
+		Kind:   reflect.Indirect(reflect.ValueOf(obj)).Type().Name(),
+	}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		change.Name = accessor.GetName()
+	}
+	// The Copy*Fields helpers are always called right before the object they compared is updated
+	if action == "Update" || action == "Patch" {
+		change.Fields = plan.pendingFields
+	}
+	plan.pendingFields = nil
+	plan.Changes = append(plan.Changes, change)
+}
+
+// NewDryRunClient returns a client that reads from the given client, but records all writes in the plan instead of making them.
+// Writes to the status of an object are discarded, since they are not changes to the resources managed by the operator.
+func NewDryRunClient(c client.Client, plan *DryRunPlan) client.Client {
+	return &dryRunClient{
+		Client: c,
+		plan:   plan,
+	}
+}
+
+type dryRunClient struct {
+	client.Client
+	plan *DryRunPlan
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	c.plan.recordChange("Create", obj)
+	return nil
+}
+
+func (c *dryRunClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	c.plan.recordChange("Update", obj)
+	return nil
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.plan.recordChange("Patch", obj)
+	return nil
+}
+
+func (c *dryRunClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	c.plan.recordChange("Delete", obj)
+	return nil
+}
+
+func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	c.plan.recordChange("Delete", obj)
+	return nil
+}
+
+func (c *dryRunClient) Status() client.StatusWriter {
+	return dryRunStatusWriter{}
+}
+
+type dryRunStatusWriter struct{}
+
+func (dryRunStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+
+func (dryRunStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+
+// NewDryRunLogger returns a logger that passes everything to the given logger,
+// and also records the fields that the Copy*Fields helpers report as changed, so that they can be attached to the planned updates.
+func NewDryRunLogger(logger logr.Logger, plan *DryRunPlan) logr.Logger {
+	return dryRunLogger{
+		Logger: logger,
+		plan:   plan,
+	}
+}
+
+type dryRunLogger struct {
+	logr.Logger
+	plan *DryRunPlan
+}
+
+func (l dryRunLogger) Info(msg string, keysAndValues ...interface{}) {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		value := fmt.Sprintf("%v", keysAndValues[i+1])
+		switch keysAndValues[i] {
+		case "field":
+			l.plan.pendingFields = append(l.plan.pendingFields, value)
+		case "label":
+			l.plan.pendingFields = append(l.plan.pendingFields, "Labels["+value+"]")
+		case "annotation":
+			l.plan.pendingFields = append(l.plan.pendingFields, "Annotations["+value+"]")
+		}
+	}
+	l.Logger.Info(msg, keysAndValues...)
+}
+
+func (l dryRunLogger) V(level int) logr.Logger {
+	return dryRunLogger{
+		Logger: l.Logger.V(level),
+		plan:   l.plan,
+	}
+}
+
+func (l dryRunLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return dryRunLogger{
+		Logger: l.Logger.WithValues(keysAndValues...),
+		plan:   l.plan,
+	}
+}
+
+func (l dryRunLogger) WithName(name string) logr.Logger {
+	return dryRunLogger{
+		Logger: l.Logger.WithName(name),
+		plan:   l.plan,
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"testing"
+)
+
+func TestDryRunPlan(t *testing.T) {
+	plan := &DryRunPlan{}
+	// The dry run client never writes, so it does not need a client to read from in this test
+	dryRunClient := NewDryRunClient(nil, plan)
+	logger := NewDryRunLogger(ctrl.Log.WithName("dry-run-test"), plan)
+
+	foundService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-common", Namespace: "default", Labels: map[string]string{"a": "b"}},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "solr-client", Port: 80}},
+		},
+	}
+	service := foundService.DeepCopy()
+	service.Labels["a"] = "c"
+	service.Spec.Ports[0].Port = 8983
+
+	assert.True(t, CopyServiceFields(service, foundService, logger.WithValues("service", foundService.Name)), "The service should need an update")
+	assert.NoError(t, dryRunClient.Update(context.TODO(), foundService), "Planned updates should never fail")
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-configmap", Namespace: "default"}}
+	assert.NoError(t, dryRunClient.Create(context.TODO(), configMap), "Planned creates should never fail")
+	assert.NoError(t, dryRunClient.Delete(context.TODO(), &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-0"}}), "Planned deletes should never fail")
+	assert.NoError(t, dryRunClient.Status().Update(context.TODO(), configMap), "Status updates should be discarded")
+
+	assert.Equal(t, []DryRunChange{
+		{Action: "Update", Kind: "Service", Name: "foo-solrcloud-common", Fields: []string{"Labels[a]", "Spec.Ports"}},
+		{Action: "Create", Kind: "ConfigMap", Name: "foo-solrcloud-configmap"},
+		{Action: "Delete", Kind: "Pod", Name: "foo-solrcloud-0"},
+	}, plan.Changes, "Wrong changes planned")
+	assert.Equal(t, "Update Service foo-solrcloud-common (Labels[a], Spec.Ports)", plan.Changes[0].String(), "Wrong description of the planned update")
+	assert.Equal(t, "Create ConfigMap foo-solrcloud-configmap", plan.Changes[1].String(), "Wrong description of the planned create")
+}
@@ -18,11 +18,16 @@
 package util
 
 import (
+	"encoding/xml"
+	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
+	"io"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"strconv"
 	"strings"
@@ -38,11 +43,18 @@ const (
 	PrometheusExporterConfigXmlMd5Annotation = "solr.apache.org/exporterConfigXmlMd5"
 )
 
+// ServiceMonitorGVK is the kind of the Prometheus Operator's ServiceMonitors.
+// They are generated as unstructured objects, so that the Prometheus Operator is not a dependency of the Solr Operator.
+var ServiceMonitorGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "ServiceMonitor"}
+
 // SolrConnectionInfo defines how to connect to a cloud or standalone solr instance.
 // One, and only one, of Cloud or Standalone must be provided.
 type SolrConnectionInfo struct {
 	CloudZkConnnectionInfo *solr.ZookeeperConnectionInfo
 	StandaloneAddress      string
+
+	// The ZK connection info of the additional clouds to scrape, alongside the cloud above
+	AdditionalCloudZkConnectionInfos []*solr.ZookeeperConnectionInfo
 }
 
 // Used internally to capture config needed to provided Solr client apps like the exporter
@@ -57,7 +69,6 @@ type TLSClientOptions struct {
 // solrPrometheusExporter: SolrPrometheusExporter instance
 func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrPrometheusExporter, solrConnectionInfo SolrConnectionInfo, configXmlMd5 string, tls *TLSClientOptions, basicAuthMd5 string) *appsv1.Deployment {
 	gracePeriodTerm := int64(10)
-	singleReplica := int32(1)
 	fsGroup := int64(SolrMetricsPort)
 
 	labels := solrPrometheusExporter.SharedLabelsWith(solrPrometheusExporter.GetLabels())
@@ -84,43 +95,41 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 		imagePullSecrets = customPodOptions.ImagePullSecrets
 	}
 
+	// The env vars and JAVA_OPTS needed to connect to the ZooKeeper of the scraped cloud, these differ per exporter container
+	var zkEnvVars []corev1.EnvVar
+	var zkJavaOpts []string
+
+	// The env vars and JAVA_OPTS shared by all exporter containers
 	var envVars []corev1.EnvVar
 	var allJavaOpts []string
 
 	var solrVolumes []corev1.Volume
 	var volumeMounts []corev1.VolumeMount
-	exporterArgs := []string{
-		"-p", strconv.Itoa(SolrMetricsPort),
-		"-n", strconv.Itoa(int(solrPrometheusExporter.Spec.NumThreads)),
-	}
-
-	if solrPrometheusExporter.Spec.ScrapeInterval > 0 {
-		exporterArgs = append(exporterArgs, "-s", strconv.Itoa(int(solrPrometheusExporter.Spec.ScrapeInterval)))
-	}
+	var connectionArgs []string
 
 	// Setup the solrConnectionInfo
 	if solrConnectionInfo.CloudZkConnnectionInfo != nil {
-		exporterArgs = append(exporterArgs, "-z", solrConnectionInfo.CloudZkConnnectionInfo.ZkConnectionString())
-
-		// Add ACL information, if given, through Env Vars
-		if hasACLs, aclEnvs := AddACLsToEnv(solrConnectionInfo.CloudZkConnnectionInfo.AllACL, solrConnectionInfo.CloudZkConnnectionInfo.ReadOnlyACL); hasACLs {
-			envVars = append(envVars, aclEnvs...)
+		connectionArgs, zkEnvVars, zkJavaOpts = exporterZkConnection(solrConnectionInfo.CloudZkConnnectionInfo)
 
-			// The $SOLR_ZK_CREDS_AND_ACLS parameter does not get picked up when running the Prometheus Exporter, it must be added to the JAVA_OPTS.
-			allJavaOpts = append(allJavaOpts, "$(SOLR_ZK_CREDS_AND_ACLS)")
+		// Add ZK TLS information, if given, through Volumes
+		if zkTLS := solrConnectionInfo.CloudZkConnnectionInfo.TLS; zkTLS != nil {
+			_, tlsVolumes, tlsVolumeMounts := ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+			solrVolumes = append(solrVolumes, tlsVolumes...)
+			volumeMounts = append(volumeMounts, tlsVolumeMounts...)
 		}
 	} else if solrConnectionInfo.StandaloneAddress != "" {
-		exporterArgs = append(exporterArgs, "-b", solrConnectionInfo.StandaloneAddress)
+		connectionArgs = []string{"-b", solrConnectionInfo.StandaloneAddress}
 	}
 
 	// Only add the config if it is passed in from the user. Otherwise, use the default.
+	configFile := "/opt/solr/contrib/prometheus-exporter/conf/solr-exporter-config.xml"
 	if solrPrometheusExporter.Spec.Config != "" ||
 		(solrPrometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions != nil && solrPrometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap != "") {
 		configMapName := solrPrometheusExporter.MetricsConfigMapName()
 		if solrPrometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions != nil && solrPrometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap != "" {
 			configMapName = solrPrometheusExporter.Spec.CustomKubeOptions.ConfigMapOptions.ProvidedConfigMap
 		}
-		solrVolumes = []corev1.Volume{{
+		solrVolumes = append(solrVolumes, corev1.Volume{
 			Name: "solr-prometheus-exporter-xml",
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
@@ -135,13 +144,11 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 					},
 				},
 			},
-		}}
+		})
 
-		volumeMounts = []corev1.VolumeMount{{Name: "solr-prometheus-exporter-xml", MountPath: "/opt/solr-exporter", ReadOnly: true}}
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "solr-prometheus-exporter-xml", MountPath: "/opt/solr-exporter", ReadOnly: true})
 
-		exporterArgs = append(exporterArgs, "-f", "/opt/solr-exporter/"+PrometheusExporterConfigMapKey)
-	} else {
-		exporterArgs = append(exporterArgs, "-f", "/opt/solr/contrib/prometheus-exporter/conf/solr-exporter-config.xml")
+		configFile = "/opt/solr-exporter/" + PrometheusExporterConfigMapKey
 	}
 
 	entrypoint := DefaultPrometheusExporterEntrypoint
@@ -187,12 +194,6 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 		allJavaOpts = append(allJavaOpts, "-Dsolr.httpclient.builder.factory=org.apache.solr.client.solrj.impl.PreemptiveBasicAuthClientBuilderFactory")
 	}
 
-	// the order of env vars in the array is important for the $(var) syntax to work
-	// since JAVA_OPTS refers to $(SOLR_SSL_*) if TLS is enabled, it needs to be last
-	if len(allJavaOpts) > 0 {
-		envVars = append(envVars, corev1.EnvVar{Name: "JAVA_OPTS", Value: strings.Join(allJavaOpts, " ")})
-	}
-
 	containers := []corev1.Container{
 		{
 			Name:            "solr-prometheus-exporter",
@@ -201,8 +202,8 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 			Ports:           []corev1.ContainerPort{{ContainerPort: SolrMetricsPort, Name: SolrMetricsPortName, Protocol: corev1.ProtocolTCP}},
 			VolumeMounts:    volumeMounts,
 			Command:         []string{entrypoint},
-			Args:            exporterArgs,
-			Env:             envVars,
+			Args:            exporterArgs(solrPrometheusExporter, SolrMetricsPort, connectionArgs, configFile),
+			Env:             exporterEnvVars(zkEnvVars, zkJavaOpts, envVars, allJavaOpts),
 
 			LivenessProbe: &corev1.Probe{
 				Handler: corev1.Handler{
@@ -226,19 +227,20 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 		if len(customPodOptions.SidecarContainers) > 0 {
 			containers = append(containers, customPodOptions.SidecarContainers...)
 		}
-
-		if len(customPodOptions.InitContainers) > 0 {
-			initContainers = customPodOptions.InitContainers
-		}
 	}
 
 	// if the supplied TLS secret does not have the pkcs12 keystore, use an initContainer to create its
 	if tls != nil && tls.NeedsPkcs12InitContainer {
 		pkcs12InitContainer := generatePkcs12InitContainer(tls.TLSOptions,
-			solrPrometheusExporter.Spec.Image.ToImageName(), solrPrometheusExporter.Spec.Image.PullPolicy)
+			solrPrometheusExporter.Spec.Image.ToImageName(), solrPrometheusExporter.Spec.Image.PullPolicy, containerSecurityContext(customPodOptions), initContainerResources(customPodOptions))
 		initContainers = append(initContainers, pkcs12InitContainer)
 	}
 
+	// Add user defined additional init containers, these run after the init containers that the operator requires
+	if customPodOptions != nil && len(customPodOptions.InitContainers) > 0 {
+		initContainers = append(initContainers, customPodOptions.InitContainers...)
+	}
+
 	// track the MD5 of the custom exporter config in the pod spec annotations,
 	// so we get a rolling restart when the configMap changes
 	if configXmlMd5 != "" {
@@ -274,7 +276,7 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 			Selector: &metav1.LabelSelector{
 				MatchLabels: selectorLabels,
 			},
-			Replicas: &singleReplica,
+			Replicas: solrPrometheusExporter.Spec.Replicas,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      podLabels,
@@ -323,6 +325,14 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 			deployment.Spec.Template.Spec.SecurityContext = customPodOptions.PodSecurityContext
 		}
 
+		if customPodOptions.ContainerSecurityContext != nil {
+			deployment.Spec.Template.Spec.Containers[0].SecurityContext = containerSecurityContext(customPodOptions)
+		}
+
+		if len(customPodOptions.EnvFrom) > 0 {
+			deployment.Spec.Template.Spec.Containers[0].EnvFrom = customPodOptions.EnvFrom
+		}
+
 		if customPodOptions.Tolerations != nil {
 			deployment.Spec.Template.Spec.Tolerations = customPodOptions.Tolerations
 		}
@@ -331,18 +341,181 @@ func GenerateSolrPrometheusExporterDeployment(solrPrometheusExporter *solr.SolrP
 			deployment.Spec.Template.Spec.NodeSelector = customPodOptions.NodeSelector
 		}
 
+		if customPodOptions.TopologySpreadConstraints != nil {
+			deployment.Spec.Template.Spec.TopologySpreadConstraints = fillTopologySpreadConstraints(customPodOptions.TopologySpreadConstraints, selectorLabels)
+		}
+
 		if customPodOptions.PriorityClassName != "" {
 			deployment.Spec.Template.Spec.PriorityClassName = customPodOptions.PriorityClassName
 		}
 
+		if customPodOptions.RuntimeClassName != nil {
+			deployment.Spec.Template.Spec.RuntimeClassName = customPodOptions.RuntimeClassName
+		}
+
+		if len(customPodOptions.HostAliases) > 0 {
+			deployment.Spec.Template.Spec.HostAliases = customPodOptions.HostAliases
+		}
+
+		if customPodOptions.DNSPolicy != "" {
+			deployment.Spec.Template.Spec.DNSPolicy = customPodOptions.DNSPolicy
+		}
+
+		if customPodOptions.DNSConfig != nil {
+			deployment.Spec.Template.Spec.DNSConfig = customPodOptions.DNSConfig
+		}
+
 		if customPodOptions.TerminationGracePeriodSeconds != nil {
 			deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = customPodOptions.TerminationGracePeriodSeconds
 		}
+
+		if customPodOptions.PreStop != nil || customPodOptions.PostStart != nil {
+			deployment.Spec.Template.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{
+				PreStop:   customPodOptions.PreStop,
+				PostStart: customPodOptions.PostStart,
+			}
+		}
+	}
+
+	// The exporter can only connect to a single cloud, so every additional cloud is scraped by a copy of the exporter container, listening on its own port
+	if len(solrConnectionInfo.AdditionalCloudZkConnectionInfos) > 0 {
+		podContainers := deployment.Spec.Template.Spec.Containers
+		exporterContainers := []corev1.Container{podContainers[0]}
+		for i, zkConnectionInfo := range solrConnectionInfo.AdditionalCloudZkConnectionInfos {
+			port := SolrMetricsPort + i + 1
+			additionalConnectionArgs, additionalZkEnvVars, additionalZkJavaOpts := exporterZkConnection(zkConnectionInfo)
+
+			container := podContainers[0].DeepCopy()
+			container.Name = fmt.Sprintf("%s-%d", podContainers[0].Name, i+1)
+			container.Ports = []corev1.ContainerPort{{ContainerPort: int32(port), Name: AdditionalSolrMetricsPortName(i), Protocol: corev1.ProtocolTCP}}
+			container.Args = exporterArgs(solrPrometheusExporter, port, additionalConnectionArgs, configFile)
+			container.Env = exporterEnvVars(additionalZkEnvVars, additionalZkJavaOpts, envVars, allJavaOpts)
+			container.LivenessProbe.HTTPGet.Port = intstr.FromInt(port)
+			exporterContainers = append(exporterContainers, *container)
+		}
+		deployment.Spec.Template.Spec.Containers = append(exporterContainers, podContainers[1:]...)
 	}
 
 	return deployment
 }
 
+// AdditionalSolrMetricsPortName returns the name of the metrics port of the exporter container that scrapes the additional cloud with the given index
+func AdditionalSolrMetricsPortName(index int) string {
+	return fmt.Sprintf("%s-%d", SolrMetricsPortName, index+1)
+}
+
+// exporterArgs returns the arguments of an exporter container that listens on the given port, and connects to Solr using the given connection args
+func exporterArgs(solrPrometheusExporter *solr.SolrPrometheusExporter, port int, connectionArgs []string, configFile string) []string {
+	args := []string{
+		"-p", strconv.Itoa(port),
+		"-n", strconv.Itoa(int(solrPrometheusExporter.Spec.NumThreads)),
+	}
+
+	if solrPrometheusExporter.Spec.ScrapeInterval > 0 {
+		args = append(args, "-s", strconv.Itoa(int(solrPrometheusExporter.Spec.ScrapeInterval)))
+	}
+
+	args = append(args, connectionArgs...)
+	return append(args, "-f", configFile)
+}
+
+// exporterZkConnection returns the arguments, env vars and JAVA_OPTS that an exporter container needs to connect to the ZooKeeper of a cloud
+func exporterZkConnection(zkConnectionInfo *solr.ZookeeperConnectionInfo) (args []string, envVars []corev1.EnvVar, javaOpts []string) {
+	args = []string{"-z", zkConnectionInfo.ZkConnectionString()}
+
+	// Add ACL information, if given, through Env Vars
+	hasACLs, aclEnvs := AddACLsToEnv(zkConnectionInfo.AllACL, zkConnectionInfo.ReadOnlyACL)
+
+	// Add ZK TLS information, if given, through Env Vars. The volumes are added by the caller.
+	if zkTLS := zkConnectionInfo.TLS; zkTLS != nil {
+		tlsEnvVars, _, _ := ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+		envVars = append(envVars, tlsEnvVars...)
+		aclEnvs = AddZkTLSOptsToCredsAndACLs(aclEnvs)
+		hasACLs = true
+	}
+
+	if hasACLs {
+		envVars = append(envVars, aclEnvs...)
+
+		// The $SOLR_ZK_CREDS_AND_ACLS parameter does not get picked up when running the Prometheus Exporter, it must be added to the JAVA_OPTS.
+		javaOpts = append(javaOpts, "$(SOLR_ZK_CREDS_AND_ACLS)")
+	}
+	return args, envVars, javaOpts
+}
+
+// exporterEnvVars combines the env vars needed to connect to the ZooKeeper of the scraped cloud with the env vars shared by all exporter containers
+func exporterEnvVars(zkEnvVars []corev1.EnvVar, zkJavaOpts []string, sharedEnvVars []corev1.EnvVar, sharedJavaOpts []string) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	envVars = append(envVars, zkEnvVars...)
+	envVars = append(envVars, sharedEnvVars...)
+
+	var allJavaOpts []string
+	allJavaOpts = append(allJavaOpts, zkJavaOpts...)
+	allJavaOpts = append(allJavaOpts, sharedJavaOpts...)
+
+	// the order of env vars in the array is important for the $(var) syntax to work
+	// since JAVA_OPTS refers to $(SOLR_SSL_*) if TLS is enabled, it needs to be last
+	if len(allJavaOpts) > 0 {
+		envVars = append(envVars, corev1.EnvVar{Name: "JAVA_OPTS", Value: strings.Join(allJavaOpts, " ")})
+	}
+	return envVars
+}
+
+// ValidateAdditionalClouds makes sure that every additional cloud of the SolrPrometheusExporter can be scraped by its own exporter container
+func ValidateAdditionalClouds(solrPrometheusExporter *solr.SolrPrometheusExporter) error {
+	solrReference := solrPrometheusExporter.Spec.SolrReference
+	if len(solrReference.AdditionalClouds) == 0 {
+		return nil
+	}
+	if solrReference.Cloud == nil {
+		return fmt.Errorf("solrReference.additionalClouds can only be used alongside solrReference.cloud")
+	}
+	for i, cloud := range solrReference.AdditionalClouds {
+		if cloud.Name == "" && cloud.ZookeeperConnectionInfo == nil {
+			return fmt.Errorf("solrReference.additionalClouds[%d] must provide either a name or zkConnectionInfo", i)
+		}
+		if cloud.ZookeeperConnectionInfo != nil && cloud.ZookeeperConnectionInfo.TLS != nil {
+			return fmt.Errorf("solrReference.additionalClouds[%d] cannot connect to ZooKeeper over TLS, since the exporter containers share the ZooKeeper TLS volumes", i)
+		}
+	}
+	return nil
+}
+
+// ValidateExporterSidecarPorts makes sure that the ports of the sidecar containers do not collide with the ports of the exporter containers
+func ValidateExporterSidecarPorts(solrPrometheusExporter *solr.SolrPrometheusExporter) error {
+	podOptions := solrPrometheusExporter.Spec.CustomKubeOptions.PodOptions
+	if podOptions == nil {
+		return nil
+	}
+	exporterPorts := map[int]string{SolrMetricsPort: "solr-prometheus-exporter"}
+	for i := range solrPrometheusExporter.Spec.SolrReference.AdditionalClouds {
+		exporterPorts[SolrMetricsPort+i+1] = fmt.Sprintf("solr-prometheus-exporter-%d", i+1)
+	}
+	return ValidateSidecarPorts(exporterPorts, podOptions.SidecarContainers)
+}
+
+// ValidateExporterConfigXml makes sure that the exporter config in the user-provided ConfigMap is well-formed XML with a <config> root element.
+// Otherwise the exporter would fail to start once it is restarted with the new config.
+func ValidateExporterConfigXml(configMapName string, configXml string) error {
+	decoder := xml.NewDecoder(strings.NewReader(configXml))
+	rootElement := ""
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("Custom exporter config in ConfigMap %s is not valid XML: %s", configMapName, err)
+		}
+		if start, isStart := token.(xml.StartElement); isStart && rootElement == "" {
+			rootElement = start.Name.Local
+		}
+	}
+	if rootElement != "config" {
+		return fmt.Errorf("Custom exporter config in ConfigMap %s must have a <config> root element", configMapName)
+	}
+	return nil
+}
+
 // GenerateMetricsConfigMap returns a new corev1.ConfigMap pointer generated for the Solr Prometheus Exporter instance solr-prometheus-exporter.xml
 // solrPrometheusExporter: SolrPrometheusExporter instance
 func GenerateMetricsConfigMap(solrPrometheusExporter *solr.SolrPrometheusExporter) *corev1.ConfigMap {
@@ -409,9 +582,68 @@ func GenerateSolrMetricsService(solrPrometheusExporter *solr.SolrPrometheusExpor
 			Selector: selectorLabels,
 		},
 	}
+
+	// Every additional cloud is scraped by its own exporter container, which listens on the next port
+	for i := range solrPrometheusExporter.Spec.SolrReference.AdditionalClouds {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name:       AdditionalSolrMetricsPortName(i),
+			Port:       int32(ExtSolrMetricsPort + i + 1),
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromInt(SolrMetricsPort + i + 1),
+		})
+	}
+	setServiceIPFamilies(service, customOptions)
 	return service
 }
 
+// GenerateSolrMetricsServiceMonitor returns a new Prometheus Operator ServiceMonitor, that scrapes the metrics Service of the SolrPrometheusExporter
+func GenerateSolrMetricsServiceMonitor(solrPrometheusExporter *solr.SolrPrometheusExporter) *unstructured.Unstructured {
+	options := solrPrometheusExporter.Spec.ServiceMonitor
+	labels := MergeLabelsOrAnnotations(solrPrometheusExporter.SharedLabelsWith(solrPrometheusExporter.GetLabels()), options.Labels)
+
+	// Scrape the metrics port of every exporter container, one for each cloud
+	portNames := []string{SolrMetricsPortName}
+	for i := range solrPrometheusExporter.Spec.SolrReference.AdditionalClouds {
+		portNames = append(portNames, AdditionalSolrMetricsPortName(i))
+	}
+	endpoints := make([]interface{}, len(portNames))
+	for i, portName := range portNames {
+		// The exporter serves its metrics over plain HTTP, even when it connects to Solr over TLS
+		endpoint := map[string]interface{}{
+			"port":   portName,
+			"path":   "/metrics",
+			"scheme": "http",
+		}
+		if options.Interval != "" {
+			endpoint["interval"] = options.Interval
+		}
+		if options.ScrapeTimeout != "" {
+			endpoint["scrapeTimeout"] = options.ScrapeTimeout
+		}
+		endpoints[i] = endpoint
+	}
+
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetGroupVersionKind(ServiceMonitorGVK)
+	serviceMonitor.SetName(solrPrometheusExporter.MetricsServiceMonitorName())
+	serviceMonitor.SetNamespace(solrPrometheusExporter.GetNamespace())
+	serviceMonitor.SetLabels(labels)
+	serviceMonitor.SetAnnotations(options.Annotations)
+	serviceMonitor.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				solr.SolrPrometheusExporterTechnologyLabel: solrPrometheusExporter.GetName(),
+				"service-type": "metrics",
+			},
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{solrPrometheusExporter.GetNamespace()},
+		},
+		"endpoints": endpoints,
+	}
+	return serviceMonitor
+}
+
 // CreateMetricsIngressRule returns a new Ingress Rule generated for the solr metrics endpoint
 // This is not currently used, as an ingress is not created for the metrics endpoint.
 // solrCloud: SolrCloud instance
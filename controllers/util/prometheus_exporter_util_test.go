@@ -0,0 +1,192 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestSolrMetricsServiceMonitor(t *testing.T) {
+	exporter := &solr.SolrPrometheusExporter{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrPrometheusExporterSpec{
+			ServiceMonitor: &solr.ServiceMonitorOptions{
+				Interval: "30s",
+				Labels:   map[string]string{"release": "mon"},
+			},
+		},
+	}
+
+	serviceMonitor := GenerateSolrMetricsServiceMonitor(exporter)
+	assert.Equal(t, ServiceMonitorGVK, serviceMonitor.GroupVersionKind(), "Wrong kind for the ServiceMonitor")
+	assert.Equal(t, "foo-solr-metrics", serviceMonitor.GetName(), "Wrong name for the ServiceMonitor")
+	assert.Equal(t, "mon", serviceMonitor.GetLabels()["release"], "The custom labels should be added to the ServiceMonitor")
+	assert.Equal(t, "foo", serviceMonitor.GetLabels()[solr.SolrPrometheusExporterTechnologyLabel], "The ServiceMonitor should have the shared labels of the exporter")
+
+	matchLabels := serviceMonitor.Object["spec"].(map[string]interface{})["selector"].(map[string]interface{})["matchLabels"].(map[string]interface{})
+	metricsService := GenerateSolrMetricsService(exporter)
+	for label, value := range matchLabels {
+		assert.Equal(t, value, metricsService.Labels[label], "The ServiceMonitor should select the metrics Service")
+	}
+	endpoint := serviceMonitor.Object["spec"].(map[string]interface{})["endpoints"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, SolrMetricsPortName, endpoint["port"], "The ServiceMonitor should scrape the metrics port")
+	assert.Equal(t, "/metrics", endpoint["path"], "Wrong scrape path")
+	assert.Equal(t, "30s", endpoint["interval"], "Wrong scrape interval")
+	_, hasTimeout := endpoint["scrapeTimeout"]
+	assert.False(t, hasTimeout, "The scrape timeout should only be set when provided")
+
+	foundServiceMonitor := serviceMonitor.DeepCopy()
+	assert.False(t, CopyServiceMonitorFields(GenerateSolrMetricsServiceMonitor(exporter), foundServiceMonitor, log), "No update should be required for an up-to-date ServiceMonitor")
+	exporter.Spec.ServiceMonitor.Interval = "1m"
+	assert.True(t, CopyServiceMonitorFields(GenerateSolrMetricsServiceMonitor(exporter), foundServiceMonitor, log), "Changing the scrape interval should require an update")
+	assert.Equal(t, "1m", foundServiceMonitor.Object["spec"].(map[string]interface{})["endpoints"].([]interface{})[0].(map[string]interface{})["interval"], "The new scrape interval should be copied to the ServiceMonitor")
+	exporter.Spec.ServiceMonitor.Labels["team"] = "search"
+	assert.True(t, CopyServiceMonitorFields(GenerateSolrMetricsServiceMonitor(exporter), foundServiceMonitor, log), "Adding a label should require an update")
+	assert.Equal(t, "search", foundServiceMonitor.GetLabels()["team"], "The new label should be copied to the ServiceMonitor")
+}
+
+func TestExporterDeploymentScheduling(t *testing.T) {
+	exporter := &solr.SolrPrometheusExporter{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrPrometheusExporterSpec{
+			CustomKubeOptions: solr.CustomExporterKubeOptions{
+				PodOptions: &solr.PodOptions{
+					NodeSelector: map[string]string{"node-role": "monitoring"},
+				},
+			},
+		},
+	}
+	exporter.WithDefaults()
+
+	deployment := GenerateSolrPrometheusExporterDeployment(exporter, SolrConnectionInfo{}, "", nil, "")
+	assert.Equal(t, map[string]string{"node-role": "monitoring"}, deployment.Spec.Template.Spec.NodeSelector, "The node selector should be added to the exporter pods")
+	assert.Nil(t, deployment.Spec.Template.Spec.Tolerations, "No tolerations should be added when none are provided")
+
+	// Changes to the scheduling options should be reconciled
+	foundDeployment := deployment.DeepCopy()
+	tolerations := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "monitoring", Effect: corev1.TaintEffectNoSchedule}}
+	affinity := &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role", Operator: corev1.NodeSelectorOpIn, Values: []string{"monitoring"}}}}},
+	}}}
+	exporter.Spec.CustomKubeOptions.PodOptions.Tolerations = tolerations
+	exporter.Spec.CustomKubeOptions.PodOptions.Affinity = affinity
+	exporter.Spec.CustomKubeOptions.PodOptions.NodeSelector = nil
+	assert.True(t, CopyDeploymentFields(GenerateSolrPrometheusExporterDeployment(exporter, SolrConnectionInfo{}, "", nil, ""), foundDeployment, log), "Changing the scheduling options should require an update")
+	assert.Equal(t, tolerations, foundDeployment.Spec.Template.Spec.Tolerations, "The new tolerations should be copied to the exporter Deployment")
+	assert.Equal(t, affinity, foundDeployment.Spec.Template.Spec.Affinity, "The new affinity should be copied to the exporter Deployment")
+	assert.Empty(t, foundDeployment.Spec.Template.Spec.NodeSelector, "The removed node selector should be removed from the exporter Deployment")
+}
+
+func TestExporterAdditionalClouds(t *testing.T) {
+	primaryZk := &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk-a:2181", ChRoot: "/a"}
+	additionalZk := &solr.ZookeeperConnectionInfo{
+		InternalConnectionString: "zk-b:2181",
+		ChRoot:                   "/b",
+		AllACL:                   &solr.ZookeeperACL{SecretRef: "zk-b-acl", UsernameKey: "user", PasswordKey: "pass"},
+	}
+	exporter := &solr.SolrPrometheusExporter{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrPrometheusExporterSpec{
+			SolrReference: solr.SolrReference{
+				Cloud:            &solr.SolrCloudReference{ZookeeperConnectionInfo: primaryZk},
+				AdditionalClouds: []solr.SolrCloudReference{{ZookeeperConnectionInfo: additionalZk}},
+			},
+			ServiceMonitor: &solr.ServiceMonitorOptions{},
+			CustomKubeOptions: solr.CustomExporterKubeOptions{
+				PodOptions: &solr.PodOptions{
+					SidecarContainers: []corev1.Container{{Name: "sidecar"}},
+				},
+			},
+		},
+	}
+	exporter.WithDefaults()
+	assert.NoError(t, ValidateAdditionalClouds(exporter), "Additional clouds should be valid alongside a cloud")
+
+	connectionInfo := SolrConnectionInfo{CloudZkConnnectionInfo: primaryZk, AdditionalCloudZkConnectionInfos: []*solr.ZookeeperConnectionInfo{additionalZk}}
+	containers := GenerateSolrPrometheusExporterDeployment(exporter, connectionInfo, "", nil, "").Spec.Template.Spec.Containers
+	assert.Equal(t, []string{"solr-prometheus-exporter", "solr-prometheus-exporter-1", "sidecar"}, []string{containers[0].Name, containers[1].Name, containers[2].Name}, "Every additional cloud should be scraped by its own exporter container, before the sidecars")
+	assert.Contains(t, containers[0].Args, "zk-a:2181/a", "The first exporter container should scrape the cloud")
+	assert.Contains(t, containers[1].Args, "zk-b:2181/b", "The second exporter container should scrape the additional cloud")
+	assert.Equal(t, []string{"-p", "8081"}, containers[1].Args[:2], "The second exporter container should listen on the next port")
+	assert.Equal(t, AdditionalSolrMetricsPortName(0), containers[1].Ports[0].Name, "Wrong metrics port name for the second exporter container")
+	assert.EqualValues(t, 8081, containers[1].LivenessProbe.HTTPGet.Port.IntValue(), "The second exporter container should be probed on its own port")
+	assert.Empty(t, containers[0].Env, "The ZK ACL of the additional cloud should not be given to the first exporter container")
+	assert.NotEmpty(t, filterEnvVarsByName(containers[1].Env, "ZK_ALL_ACL_USERNAME"), "The ZK ACL of the additional cloud should be given to its exporter container")
+
+	service := GenerateSolrMetricsService(exporter)
+	assert.Len(t, service.Spec.Ports, 2, "The metrics Service should expose the port of every exporter container")
+	assert.EqualValues(t, 81, service.Spec.Ports[1].Port, "Wrong Service port for the additional cloud")
+	assert.EqualValues(t, 8081, service.Spec.Ports[1].TargetPort.IntValue(), "Wrong target port for the additional cloud")
+
+	endpoints := GenerateSolrMetricsServiceMonitor(exporter).Object["spec"].(map[string]interface{})["endpoints"].([]interface{})
+	assert.Len(t, endpoints, 2, "The ServiceMonitor should scrape every exporter container")
+	assert.Equal(t, AdditionalSolrMetricsPortName(0), endpoints[1].(map[string]interface{})["port"], "The ServiceMonitor should scrape the port of the additional cloud")
+
+	exporter.Spec.SolrReference.AdditionalClouds[0].ZookeeperConnectionInfo.TLS = &solr.ZookeeperTLSOptions{}
+	assert.Error(t, ValidateAdditionalClouds(exporter), "Additional clouds should not be able to use ZK TLS")
+	exporter.Spec.SolrReference.AdditionalClouds[0] = solr.SolrCloudReference{}
+	assert.Error(t, ValidateAdditionalClouds(exporter), "Additional clouds need either a name or ZK connection info")
+	exporter.Spec.SolrReference.AdditionalClouds[0] = solr.SolrCloudReference{Name: "bar"}
+	exporter.Spec.SolrReference.Cloud = nil
+	exporter.Spec.SolrReference.Standalone = &solr.StandaloneSolrReference{Address: "http://solr:8983/solr"}
+	assert.Error(t, ValidateAdditionalClouds(exporter), "Additional clouds cannot be used alongside a standalone Solr")
+}
+
+func filterEnvVarsByName(envVars []corev1.EnvVar, name string) (filtered []corev1.EnvVar) {
+	for _, envVar := range envVars {
+		if envVar.Name == name {
+			filtered = append(filtered, envVar)
+		}
+	}
+	return filtered
+}
+
+func TestValidateExporterConfigXml(t *testing.T) {
+	assert.NoError(t, ValidateExporterConfigXml("custom-config", "<?xml version=\"1.0\" encoding=\"UTF-8\" ?>\n<config><rules/></config>"), "A well-formed exporter config should be accepted")
+	assert.Error(t, ValidateExporterConfigXml("custom-config", "<config><rules></config>"), "A malformed exporter config should be rejected")
+	assert.Error(t, ValidateExporterConfigXml("custom-config", "<solr></solr>"), "An exporter config must have a config root element")
+	assert.Error(t, ValidateExporterConfigXml("custom-config", ""), "An empty exporter config should be rejected")
+}
+
+func TestExporterSidecarPorts(t *testing.T) {
+	exporter := &solr.SolrPrometheusExporter{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrPrometheusExporterSpec{
+			SolrReference: solr.SolrReference{
+				Cloud:            &solr.SolrCloudReference{Name: "foo"},
+				AdditionalClouds: []solr.SolrCloudReference{{Name: "bar"}},
+			},
+			CustomKubeOptions: solr.CustomExporterKubeOptions{
+				PodOptions: &solr.PodOptions{
+					SidecarContainers: []corev1.Container{{Name: "proxy", Ports: []corev1.ContainerPort{{ContainerPort: 8443}}}},
+				},
+			},
+		},
+	}
+	assert.NoError(t, ValidateExporterSidecarPorts(exporter), "Sidecars can listen on ports that are not used by the exporter")
+
+	exporter.Spec.CustomKubeOptions.PodOptions.SidecarContainers[0].Ports[0].ContainerPort = SolrMetricsPort
+	assert.EqualError(t, ValidateExporterSidecarPorts(exporter), "port 8080 of the sidecar container \"proxy\" is already used by the container \"solr-prometheus-exporter\"", "Sidecars cannot listen on the exporter port")
+
+	exporter.Spec.CustomKubeOptions.PodOptions.SidecarContainers[0].Ports[0].ContainerPort = SolrMetricsPort + 1
+	assert.EqualError(t, ValidateExporterSidecarPorts(exporter), "port 8081 of the sidecar container \"proxy\" is already used by the container \"solr-prometheus-exporter-1\"", "Sidecars cannot listen on the port of the exporter of an additional cloud")
+}
@@ -0,0 +1,169 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+)
+
+// The sections of the security.json that Solr exposes through its Security API
+var securityJsonSections = []string{"authentication", "authorization", "auditlogging"}
+
+// GetSecurityJson returns the security.json of the SolrCloud, as it is currently used by Solr, through the Security API.
+// Sections that are not configured are left out.
+func GetSecurityJson(cloud *solr.SolrCloud, httpHeaders map[string]string) (securityJson map[string]interface{}, err error) {
+	securityJson = make(map[string]interface{}, len(securityJsonSections))
+	for _, section := range securityJsonSections {
+		response := map[string]interface{}{}
+		if err = solr_api.CallSecurityApi(cloud, section, httpHeaders, &response); err != nil {
+			return nil, err
+		}
+		if config, isConfigured := response[section].(map[string]interface{}); isConfigured {
+			securityJson[section] = config
+		}
+	}
+	return securityJson, nil
+}
+
+// MergeSecurityJson merges the users, user-roles and permissions of the given security.json, which are owned by the Solr Operator,
+// into the current security.json of the SolrCloud. Everything else, such as users and permissions added through the Security API, is kept.
+// Permissions are matched by name, and missing permissions are appended, the same as the set-permission command of the Security API does.
+// A section of the security.json is replaced as a whole if it does not use the same plugin class yet.
+func MergeSecurityJson(current map[string]interface{}, securityJson []byte) (merged []byte, changed bool, err error) {
+	desired := map[string]interface{}{}
+	if err = json.Unmarshal(securityJson, &desired); err != nil {
+		return nil, false, fmt.Errorf("unable to parse the security.json: %s", err)
+	}
+
+	result := make(map[string]interface{}, len(current))
+	for section, config := range current {
+		result[section] = config
+	}
+	for _, section := range []string{"authentication", "authorization"} {
+		desiredConfig, isConfigured := desired[section].(map[string]interface{})
+		if !isConfigured {
+			continue
+		}
+		currentConfig, isConfigured := current[section].(map[string]interface{})
+		if !isConfigured || currentConfig["class"] != desiredConfig["class"] {
+			result[section] = desiredConfig
+			changed = true
+			continue
+		}
+
+		mergedConfig := make(map[string]interface{}, len(currentConfig))
+		for key, value := range currentConfig {
+			mergedConfig[key] = value
+		}
+		if section == "authentication" {
+			changed = mergeSecurityJsonEntries(mergedConfig, desiredConfig, "credentials") || changed
+		} else {
+			changed = mergeSecurityJsonEntries(mergedConfig, desiredConfig, "user-role") || changed
+			changed = mergeSecurityJsonPermissions(mergedConfig, desiredConfig) || changed
+		}
+		result[section] = mergedConfig
+	}
+
+	merged, err = json.Marshal(result)
+	return merged, changed, err
+}
+
+// GenerateSecurityJsonSecret returns a new secret holding the merged security.json, for the security.json Job to write to Zookeeper
+func GenerateSecurityJsonSecret(solrCloud *solr.SolrCloud, securityJson []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      solrCloud.SecurityJsonSecretName(),
+			Namespace: solrCloud.GetNamespace(),
+			Labels:    solrCloud.SharedLabelsWith(solrCloud.GetLabels()),
+		},
+		Data: map[string][]byte{SecurityJsonFile: securityJson},
+		Type: corev1.SecretTypeOpaque,
+	}
+}
+
+// mergeSecurityJsonEntries sets the entries of the given map, such as the credentials of users, to the desired values
+func mergeSecurityJsonEntries(mergedConfig map[string]interface{}, desiredConfig map[string]interface{}, key string) (changed bool) {
+	desiredEntries, hasEntries := desiredConfig[key].(map[string]interface{})
+	if !hasEntries {
+		return false
+	}
+	currentEntries, _ := mergedConfig[key].(map[string]interface{})
+	mergedEntries := make(map[string]interface{}, len(currentEntries)+len(desiredEntries))
+	for name, value := range currentEntries {
+		mergedEntries[name] = value
+	}
+	for name, value := range desiredEntries {
+		if !reflect.DeepEqual(mergedEntries[name], value) {
+			mergedEntries[name] = value
+			changed = true
+		}
+	}
+	mergedConfig[key] = mergedEntries
+	return changed
+}
+
+// mergeSecurityJsonPermissions sets the permissions of the authorization config with the same names as the desired ones, appending the missing ones.
+// The indexes that Solr adds to the permissions are removed, since Solr assigns them again when loading the security.json.
+func mergeSecurityJsonPermissions(mergedConfig map[string]interface{}, desiredConfig map[string]interface{}) (changed bool) {
+	desiredPermissions, hasPermissions := desiredConfig["permissions"].([]interface{})
+	if !hasPermissions {
+		return false
+	}
+	currentPermissions, _ := mergedConfig["permissions"].([]interface{})
+	mergedPermissions := make([]interface{}, 0, len(currentPermissions)+len(desiredPermissions))
+	permissionIndexes := make(map[string]int, len(currentPermissions))
+	for _, permission := range currentPermissions {
+		if permissionConfig, isMap := permission.(map[string]interface{}); isMap {
+			withoutIndex := make(map[string]interface{}, len(permissionConfig))
+			for key, value := range permissionConfig {
+				if key != "index" {
+					withoutIndex[key] = value
+				}
+			}
+			if name, hasName := withoutIndex["name"].(string); hasName {
+				permissionIndexes[name] = len(mergedPermissions)
+			}
+			permission = withoutIndex
+		}
+		mergedPermissions = append(mergedPermissions, permission)
+	}
+	for _, permission := range desiredPermissions {
+		permissionConfig, isMap := permission.(map[string]interface{})
+		if !isMap {
+			continue
+		}
+		name, _ := permissionConfig["name"].(string)
+		if i, exists := permissionIndexes[name]; exists && name != "" {
+			if !reflect.DeepEqual(mergedPermissions[i], permissionConfig) {
+				mergedPermissions[i] = permissionConfig
+				changed = true
+			}
+		} else {
+			mergedPermissions = append(mergedPermissions, permissionConfig)
+			changed = true
+		}
+	}
+	mergedConfig["permissions"] = mergedPermissions
+	return changed
+}
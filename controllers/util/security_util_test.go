@@ -0,0 +1,86 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMergeSecurityJson(t *testing.T) {
+	securityJson := []byte(`{
+      "authentication": {"class": "solr.BasicAuthPlugin", "blockUnknown": true, "credentials": {"k8s-oper": "hash1 salt1"}},
+      "authorization": {
+        "class": "solr.RuleBasedAuthorizationPlugin",
+        "user-role": {"k8s-oper": ["k8s"]},
+        "permissions": [{"name": "k8s-status", "role": "k8s", "path": "/admin/collections"}, {"name": "all", "role": "admin"}]
+      }
+    }`)
+	parse := func(securityJson string) map[string]interface{} {
+		parsed := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(securityJson), &parsed))
+		return parsed
+	}
+
+	// Nothing has drifted, the indexes that Solr adds to the permissions are ignored
+	current := parse(`{
+      "authentication": {"class": "solr.BasicAuthPlugin", "blockUnknown": true, "credentials": {"k8s-oper": "hash1 salt1", "alice": "hash2 salt2"}},
+      "authorization": {
+        "class": "solr.RuleBasedAuthorizationPlugin",
+        "user-role": {"k8s-oper": ["k8s"], "alice": ["admin"]},
+        "permissions": [{"name": "k8s-status", "role": "k8s", "path": "/admin/collections", "index": 1}, {"name": "all", "role": "admin", "index": 2}]
+      },
+      "auditlogging": {"class": "solr.SolrLogAuditLoggerPlugin"}
+    }`)
+	_, changed, err := MergeSecurityJson(current, securityJson)
+	assert.NoError(t, err)
+	assert.False(t, changed, "The security.json should not be changed when the operator-owned users, roles and permissions have not drifted")
+
+	// The operator-owned parts have been changed through the Security API
+	current = parse(`{
+      "authentication": {"class": "solr.BasicAuthPlugin", "blockUnknown": false, "credentials": {"k8s-oper": "other salt", "alice": "hash2 salt2"}},
+      "authorization": {
+        "class": "solr.RuleBasedAuthorizationPlugin",
+        "user-role": {"alice": ["admin"]},
+        "permissions": [{"name": "read", "role": "alice", "index": 1}, {"name": "all", "role": ["admin", "users"], "index": 2}]
+      },
+      "auditlogging": {"class": "solr.SolrLogAuditLoggerPlugin"}
+    }`)
+	merged, changed, err := MergeSecurityJson(current, securityJson)
+	assert.NoError(t, err)
+	assert.True(t, changed, "The security.json should be changed when the operator-owned users, roles and permissions have drifted")
+	assert.Equal(t, parse(`{
+      "authentication": {"class": "solr.BasicAuthPlugin", "blockUnknown": false, "credentials": {"k8s-oper": "hash1 salt1", "alice": "hash2 salt2"}},
+      "authorization": {
+        "class": "solr.RuleBasedAuthorizationPlugin",
+        "user-role": {"k8s-oper": ["k8s"], "alice": ["admin"]},
+        "permissions": [{"name": "read", "role": "alice"}, {"name": "all", "role": "admin"}, {"name": "k8s-status", "role": "k8s", "path": "/admin/collections"}]
+      },
+      "auditlogging": {"class": "solr.SolrLogAuditLoggerPlugin"}
+    }`), parse(string(merged)), "Only the operator-owned users, roles and permissions should be restored, everything else should be kept")
+
+	// Without the same plugin, the whole section is replaced
+	merged, changed, err = MergeSecurityJson(map[string]interface{}{}, securityJson)
+	assert.NoError(t, err)
+	assert.True(t, changed, "The security.json should be changed when it is not configured yet")
+	assert.Equal(t, parse(string(securityJson)), parse(string(merged)), "The whole security.json should be used when Solr has no security.json yet")
+
+	_, _, err = MergeSecurityJson(current, []byte("{not json"))
+	assert.Error(t, err, "An invalid security.json cannot be merged")
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"net/http"
 	"net/url"
+	"sync"
 )
 
 // Used to call a Solr pod over https when using a self-signed cert
@@ -34,6 +35,14 @@ import (
 var noVerifyTLSHttpClient *http.Client
 var mTLSHttpClient *http.Client
 
+// Clients set for a specific SolrCloud. These take precedence over the resolved and operator-wide clients.
+var cloudHttpClients = map[string]*http.Client{}
+var cloudHttpClientsLock sync.RWMutex
+
+// Resolves the client of a SolrCloud that needs its own client, such as when the operator must present a client certificate
+// that is provided for that cloud. A nil client is returned for clouds that use the operator-wide clients above.
+var cloudHttpClientResolver func(cloud *solr.SolrCloud) (*http.Client, error)
+
 func SetNoVerifyTLSHttpClient(client *http.Client) {
 	noVerifyTLSHttpClient = client
 }
@@ -42,6 +51,66 @@ func SetMTLSHttpClient(client *http.Client) {
 	mTLSHttpClient = client
 }
 
+func SetHttpClientForCloud(cloud *solr.SolrCloud, client *http.Client) {
+	cloudHttpClientsLock.Lock()
+	defer cloudHttpClientsLock.Unlock()
+	cloudHttpClients[cloud.Namespace+"/"+cloud.Name] = client
+}
+
+func RemoveHttpClientForCloud(cloud *solr.SolrCloud) {
+	cloudHttpClientsLock.Lock()
+	defer cloudHttpClientsLock.Unlock()
+	delete(cloudHttpClients, cloud.Namespace+"/"+cloud.Name)
+}
+
+// SetHttpClientResolver sets the function that resolves the client of each SolrCloud when it is called,
+// so that every controller uses the same client for a cloud, whether or not the SolrCloud has been reconciled yet.
+func SetHttpClientResolver(resolver func(cloud *solr.SolrCloud) (*http.Client, error)) {
+	cloudHttpClientsLock.Lock()
+	defer cloudHttpClientsLock.Unlock()
+	cloudHttpClientResolver = resolver
+}
+
+// HttpClientForCloud returns the client that should be used to call the Solr API of the given cloud.
+func HttpClientForCloud(cloud *solr.SolrCloud) (*http.Client, error) {
+	cloudHttpClientsLock.RLock()
+	client, hasCloudClient := cloudHttpClients[cloud.Namespace+"/"+cloud.Name]
+	resolver := cloudHttpClientResolver
+	cloudHttpClientsLock.RUnlock()
+	if hasCloudClient {
+		return client, nil
+	}
+	if resolver != nil {
+		if client, err := resolver(cloud); err != nil || client != nil {
+			return client, err
+		}
+	}
+	return operatorHttpClient(), nil
+}
+
+// DefaultTLSConfig returns a copy of the TLS config of the operator-wide client, without its client certificate,
+// so that clients built for a specific SolrCloud verify the Solr server the same way the operator-wide client does.
+func DefaultTLSConfig() *tls.Config {
+	if transport, isHttpTransport := operatorHttpClient().Transport.(*http.Transport); isHttpTransport && transport.TLSClientConfig != nil {
+		tlsConfig := transport.TLSClientConfig.Clone()
+		tlsConfig.Certificates = nil
+		return tlsConfig
+	}
+	return &tls.Config{}
+}
+
+// HasOperatorClientCert returns whether the operator-wide client presents a client certificate, as given through -tls-client-cert-path
+func HasOperatorClientCert() bool {
+	return mTLSHttpClient != nil
+}
+
+func operatorHttpClient() *http.Client {
+	if mTLSHttpClient != nil {
+		return mTLSHttpClient
+	}
+	return noVerifyTLSHttpClient
+}
+
 type SolrAsyncResponse struct {
 	ResponseHeader SolrResponseHeader `json:"responseHeader"`
 
@@ -66,16 +135,30 @@ type SolrAsyncStatus struct {
 }
 
 func CallCollectionsApi(cloud *solr.SolrCloud, urlParams url.Values, httpHeaders map[string]string, response interface{}) (err error) {
+	return callSolrApi(cloud, cloud.SolrContextPath()+"/admin/collections", urlParams, httpHeaders, response)
+}
+
+// CallCollectionUpdateApi calls the update handler of the given collection, e.g. to commit it
+func CallCollectionUpdateApi(cloud *solr.SolrCloud, collection string, urlParams url.Values, httpHeaders map[string]string, response interface{}) (err error) {
+	return callSolrApi(cloud, cloud.SolrContextPath()+"/"+url.PathEscape(collection)+"/update", urlParams, httpHeaders, response)
+}
+
+// CallSecurityApi fetches the given section of the security.json, such as "authentication", from the Security API
+func CallSecurityApi(cloud *solr.SolrCloud, section string, httpHeaders map[string]string, response interface{}) (err error) {
+	return callSolrApi(cloud, cloud.SolrContextPath()+"/admin/"+section, url.Values{}, httpHeaders, response)
+}
+
+func callSolrApi(cloud *solr.SolrCloud, path string, urlParams url.Values, httpHeaders map[string]string, response interface{}) (err error) {
 	cloudUrl := solr.InternalURLForCloud(cloud)
 
-	client := noVerifyTLSHttpClient
-	if mTLSHttpClient != nil {
-		client = mTLSHttpClient
+	client, err := HttpClientForCloud(cloud)
+	if err != nil {
+		return err
 	}
 
 	urlParams.Set("wt", "json")
 
-	cloudUrl = cloudUrl + "/solr/admin/collections?" + urlParams.Encode()
+	cloudUrl = cloudUrl + path + "?" + urlParams.Encode()
 
 	resp := &http.Response{}
 
@@ -40,6 +40,16 @@ type SolrClusterStatusResponse struct {
 	ClusterStatus SolrClusterStatus `json:"cluster"`
 }
 
+type SolrListAliasesResponse struct {
+	ResponseHeader SolrResponseHeader `json:"responseHeader"`
+
+	// +optional
+	Aliases map[string]string `json:"aliases"`
+
+	// +optional
+	Properties map[string]map[string]string `json:"properties"`
+}
+
 type SolrClusterStatus struct {
 	// +optional
 	Collections map[string]SolrCollectionStatus `json:"collections"`
@@ -84,6 +94,15 @@ type SolrCollectionStatus struct {
 
 	// +optional
 	Router SolrCollectionRouter `json:"router"`
+
+	// Only set when the collection has been made read-only, or writable again, through MODIFYCOLLECTION
+	// +optional
+	ReadOnly string `json:"readOnly"`
+}
+
+// IsReadOnly returns whether updates to the collection are rejected, because its readOnly property is set
+func (collection SolrCollectionStatus) IsReadOnly() bool {
+	return collection.ReadOnly == "true"
 }
 
 type SolrCollectionRouter struct {
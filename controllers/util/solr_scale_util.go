@@ -0,0 +1,246 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/url"
+	"sort"
+)
+
+// replicaMove describes a Solr replica that needs to be moved off of a pod that will be removed by a scale down
+type replicaMove struct {
+	collection string
+	shard      string
+	replica    string
+	sourceNode string
+	targetNode string
+}
+
+// AsyncIdForReplicaMove returns the async id used when moving the given replica off of a pod that will be removed
+func AsyncIdForReplicaMove(collection string, replica string) string {
+	return fmt.Sprintf("scaledown-%s-%s", collection, replica)
+}
+
+// VacatePodsForScaleDown moves all Solr replicas off of the pods that will be removed when scaling the given StatefulSet of the SolrCloud
+// down from currentReplicas to desiredReplicas. Replicas are only moved to the remaining pods of the same StatefulSet.
+// Replicas are moved asynchronously, so this should be called until it reports that the pods have been vacated.
+// If Solr cannot be reached, the pods are not considered vacated and an error is returned.
+func VacatePodsForScaleDown(cloud *solr.SolrCloud, statefulSetName string, desiredReplicas int, currentReplicas int, httpHeaders map[string]string, logger logr.Logger) (vacated bool, scaleDownStatus *solr.SolrScaleDownStatus, err error) {
+	removedNodes := statefulSetNodeNames(cloud, statefulSetName, desiredReplicas, currentReplicas)
+	remainingNodes := statefulSetNodeNames(cloud, statefulSetName, 0, desiredReplicas)
+	return vacateSolrNodes(cloud, removedNodes, remainingNodes, desiredReplicas, httpHeaders, logger)
+}
+
+// VacatePodsOfRemovedNodePool moves all Solr replicas off of the pods of the StatefulSet of a node pool that has been removed from the SolrCloud.
+// Replicas are moved to the pods of the remainingStatefulSets, which map the name of each remaining StatefulSet to its number of pods.
+// Like VacatePodsForScaleDown, this should be called until it reports that the pods have been vacated.
+func VacatePodsOfRemovedNodePool(cloud *solr.SolrCloud, statefulSetName string, replicas int, remainingStatefulSets map[string]int, httpHeaders map[string]string, logger logr.Logger) (vacated bool, scaleDownStatus *solr.SolrScaleDownStatus, err error) {
+	remainingStatefulSetNames := make([]string, 0, len(remainingStatefulSets))
+	for name := range remainingStatefulSets {
+		remainingStatefulSetNames = append(remainingStatefulSetNames, name)
+	}
+	sort.Strings(remainingStatefulSetNames)
+	var remainingNodes []string
+	for _, name := range remainingStatefulSetNames {
+		remainingNodes = append(remainingNodes, statefulSetNodeNames(cloud, name, 0, remainingStatefulSets[name])...)
+	}
+	return vacateSolrNodes(cloud, statefulSetNodeNames(cloud, statefulSetName, 0, replicas), remainingNodes, 0, httpHeaders, logger)
+}
+
+// vacateSolrNodes moves all Solr replicas off of the removedNodes, onto the live nodes of the remainingNodes
+func vacateSolrNodes(cloud *solr.SolrCloud, removedNodes []string, remainingNodes []string, targetReplicas int, httpHeaders map[string]string, logger logr.Logger) (vacated bool, scaleDownStatus *solr.SolrScaleDownStatus, err error) {
+	clusterResp := &solr_api.SolrClusterStatusResponse{}
+	queryParams := url.Values{}
+	queryParams.Add("action", "CLUSTERSTATUS")
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, clusterResp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("CLUSTERSTATUS", clusterResp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		return false, nil, err
+	}
+
+	moves, err := findReplicaMoves(clusterResp.ClusterStatus, removedNodes, remainingNodes)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(moves) == 0 {
+		return true, nil, nil
+	}
+
+	scaleDownStatus = &solr.SolrScaleDownStatus{
+		TargetReplicas:    int32(targetReplicas),
+		RemainingReplicas: int32(len(moves)),
+	}
+	vacatingNodes := map[string]bool{}
+	for _, move := range moves {
+		if !vacatingNodes[move.sourceNode] {
+			vacatingNodes[move.sourceNode] = true
+			scaleDownStatus.VacatingNodes = append(scaleDownStatus.VacatingNodes, move.sourceNode)
+		}
+		if err = moveReplicaForScaleDown(cloud, move, httpHeaders, logger); err != nil {
+			return false, scaleDownStatus, err
+		}
+	}
+	sort.Strings(scaleDownStatus.VacatingNodes)
+
+	return false, scaleDownStatus, nil
+}
+
+// statefulSetNodeNames returns the Solr node names of the pods of the given StatefulSet, from ordinal fromOrdinal up to, but not including, toOrdinal
+func statefulSetNodeNames(cloud *solr.SolrCloud, statefulSetName string, fromOrdinal int, toOrdinal int) (nodeNames []string) {
+	for i := fromOrdinal; i < toOrdinal; i++ {
+		nodeNames = append(nodeNames, SolrNodeName(cloud, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", statefulSetName, i)}}))
+	}
+	return nodeNames
+}
+
+// moveReplicaForScaleDown starts the move of the given replica, unless a move is already in progress.
+// Finished moves have their async status removed, so that the replica can be moved again if it was not successful.
+func moveReplicaForScaleDown(cloud *solr.SolrCloud, move replicaMove, httpHeaders map[string]string, logger logr.Logger) (err error) {
+	asyncId := AsyncIdForReplicaMove(move.collection, move.replica)
+
+	queryParams := url.Values{}
+	queryParams.Add("action", "REQUESTSTATUS")
+	queryParams.Add("requestid", asyncId)
+	statusResp := &solr_api.SolrAsyncResponse{}
+	if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, statusResp); err != nil {
+		return err
+	}
+	if hasError, apiErr := solr_api.CheckForCollectionsApiError("REQUESTSTATUS", statusResp.ResponseHeader); hasError {
+		return apiErr
+	}
+
+	switch statusResp.Status.AsyncState {
+	case "notfound":
+		queryParams = url.Values{}
+		queryParams.Add("action", "MOVEREPLICA")
+		queryParams.Add("collection", move.collection)
+		queryParams.Add("replica", move.replica)
+		queryParams.Add("targetNode", move.targetNode)
+		queryParams.Add("async", asyncId)
+		moveResp := &solr_api.SolrAsyncResponse{}
+		logger.Info("Moving replica off of a pod that will be removed by a scale down", "collection", move.collection, "shard", move.shard, "replica", move.replica, "sourceNode", move.sourceNode, "targetNode", move.targetNode)
+		if err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, moveResp); err == nil {
+			if hasError, apiErr := solr_api.CheckForCollectionsApiError("MOVEREPLICA", moveResp.ResponseHeader); hasError {
+				err = apiErr
+			}
+		}
+	case "completed", "failed":
+		if statusResp.Status.AsyncState == "failed" {
+			logger.Info("Moving replica failed, it will be retried", "collection", move.collection, "replica", move.replica, "message", statusResp.Status.Message)
+		}
+		queryParams = url.Values{}
+		queryParams.Add("action", "DELETESTATUS")
+		queryParams.Add("requestid", asyncId)
+		err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, &solr_api.SolrAsyncResponse{})
+	default:
+		logger.Info("Waiting for replica to be moved", "collection", move.collection, "replica", move.replica, "state", statusResp.Status.AsyncState)
+	}
+	return err
+}
+
+// findReplicaMoves determines which replicas live on the removedNodes, and which of the live remainingNodes each should be moved to.
+// Replicas are moved to the node with the fewest replicas that does not already host a replica of the same shard, if possible.
+func findReplicaMoves(clusterStatus solr_api.SolrClusterStatus, removedNodes []string, remainingNodes []string) (moves []replicaMove, err error) {
+	liveNodes := make(map[string]bool, len(clusterStatus.LiveNodes))
+	for _, node := range clusterStatus.LiveNodes {
+		liveNodes[node] = true
+	}
+
+	isRemoved := make(map[string]bool, len(removedNodes))
+	for _, nodeName := range removedNodes {
+		isRemoved[nodeName] = true
+	}
+	replicasPerNode := map[string]int{}
+	var liveRemainingNodes []string
+	for _, nodeName := range remainingNodes {
+		if liveNodes[nodeName] {
+			liveRemainingNodes = append(liveRemainingNodes, nodeName)
+			replicasPerNode[nodeName] = 0
+		}
+	}
+
+	// Sort the collections, shards and replicas so that replicas are placed deterministically
+	collections := make([]string, 0, len(clusterStatus.Collections))
+	for collectionName, collection := range clusterStatus.Collections {
+		collections = append(collections, collectionName)
+		for _, shard := range collection.Shards {
+			for _, replica := range shard.Replicas {
+				if _, isRemaining := replicasPerNode[replica.NodeName]; isRemaining {
+					replicasPerNode[replica.NodeName] += 1
+				}
+			}
+		}
+	}
+	sort.Strings(collections)
+
+	for _, collectionName := range collections {
+		collection := clusterStatus.Collections[collectionName]
+		shards := make([]string, 0, len(collection.Shards))
+		for shardName := range collection.Shards {
+			shards = append(shards, shardName)
+		}
+		sort.Strings(shards)
+
+		for _, shardName := range shards {
+			shard := collection.Shards[shardName]
+			shardNodes := map[string]bool{}
+			replicas := make([]string, 0, len(shard.Replicas))
+			for replicaName, replica := range shard.Replicas {
+				shardNodes[replica.NodeName] = true
+				replicas = append(replicas, replicaName)
+			}
+			sort.Strings(replicas)
+
+			for _, replicaName := range replicas {
+				replica := shard.Replicas[replicaName]
+				if !isRemoved[replica.NodeName] {
+					continue
+				}
+				if len(liveRemainingNodes) == 0 {
+					return nil, fmt.Errorf("cannot move replica %s of collection %s, since none of the remaining %d Solr nodes are live", replicaName, collectionName, len(remainingNodes))
+				}
+				targetNode := ""
+				for _, node := range liveRemainingNodes {
+					if targetNode == "" || (shardNodes[targetNode] && !shardNodes[node]) || (shardNodes[targetNode] == shardNodes[node] && replicasPerNode[node] < replicasPerNode[targetNode]) {
+						targetNode = node
+					}
+				}
+				replicasPerNode[targetNode] += 1
+				shardNodes[targetNode] = true
+				moves = append(moves, replicaMove{
+					collection: collectionName,
+					shard:      shardName,
+					replica:    replicaName,
+					sourceNode: replica.NodeName,
+					targetNode: targetNode,
+				})
+			}
+		}
+	}
+	return moves, nil
+}
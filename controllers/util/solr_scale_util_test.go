@@ -0,0 +1,101 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestFindReplicaMoves(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				PodPort: 2000,
+			},
+		},
+	}
+	node := func(ordinal string) string {
+		return "foo-solrcloud-" + ordinal + ".foo-solrcloud-headless.default:2000_solr"
+	}
+
+	clusterStatus := solr_api.SolrClusterStatus{
+		LiveNodes: []string{node("0"), node("1"), node("2"), node("3")},
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"col1": {
+				Shards: map[string]solr_api.SolrShardStatus{
+					"shard1": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node1": {State: solr_api.ReplicaActive, NodeName: node("0"), Leader: true},
+							"core_node2": {State: solr_api.ReplicaActive, NodeName: node("3")},
+						},
+						State: solr_api.ShardActive,
+					},
+					"shard2": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"core_node3": {State: solr_api.ReplicaActive, NodeName: node("2"), Leader: true},
+							"core_node4": {State: solr_api.ReplicaActive, NodeName: node("1")},
+						},
+						State: solr_api.ShardActive,
+					},
+				},
+			},
+		},
+	}
+
+	// Scaling down to the nodes that already host every replica requires no moves
+	moves, err := findReplicaMoves(clusterStatus, statefulSetNodeNames(solrCloud, solrCloud.StatefulSetName(), 4, 4), statefulSetNodeNames(solrCloud, solrCloud.StatefulSetName(), 0, 4))
+	assert.NoError(t, err, "No error should occur when no nodes are removed")
+	assert.Empty(t, moves, "No replicas should be moved when no nodes are removed")
+
+	// Replicas should be moved to the remaining node that does not already host a replica of the shard
+	moves, err = findReplicaMoves(clusterStatus, statefulSetNodeNames(solrCloud, solrCloud.StatefulSetName(), 2, 4), statefulSetNodeNames(solrCloud, solrCloud.StatefulSetName(), 0, 2))
+	assert.NoError(t, err, "No error should occur when there are live nodes to move replicas to")
+	assert.ElementsMatch(t, []replicaMove{
+		{collection: "col1", shard: "shard1", replica: "core_node2", sourceNode: node("3"), targetNode: node("1")},
+		{collection: "col1", shard: "shard2", replica: "core_node3", sourceNode: node("2"), targetNode: node("0")},
+	}, moves, "Incorrect replica moves for the scale down")
+
+	// Replicas cannot be moved if none of the remaining nodes are live
+	clusterStatus.LiveNodes = []string{node("2"), node("3")}
+	_, err = findReplicaMoves(clusterStatus, statefulSetNodeNames(solrCloud, solrCloud.StatefulSetName(), 2, 4), statefulSetNodeNames(solrCloud, solrCloud.StatefulSetName(), 0, 2))
+	assert.Error(t, err, "Replicas cannot be moved when none of the remaining nodes are live")
+
+	// Replicas of a removed node pool should be moved to the nodes of the remaining StatefulSets
+	poolNode := "foo-solrcloud-large-0.foo-solrcloud-headless.default:2000_solr"
+	clusterStatus.LiveNodes = []string{node("0"), node("1"), poolNode}
+	clusterStatus.Collections["col2"] = solr_api.SolrCollectionStatus{
+		Shards: map[string]solr_api.SolrShardStatus{
+			"shard1": {
+				Replicas: map[string]solr_api.SolrReplicaStatus{
+					"core_node5": {State: solr_api.ReplicaActive, NodeName: poolNode, Leader: true},
+				},
+				State: solr_api.ShardActive,
+			},
+		},
+	}
+	moves, err = findReplicaMoves(clusterStatus, statefulSetNodeNames(solrCloud, solrCloud.NodePoolStatefulSetName("large"), 0, 1), statefulSetNodeNames(solrCloud, solrCloud.StatefulSetName(), 0, 2))
+	assert.NoError(t, err, "No error should occur when there are live nodes to move the replicas of the node pool to")
+	assert.Equal(t, []replicaMove{
+		{collection: "col2", shard: "shard1", replica: "core_node5", sourceNode: poolNode, targetNode: node("0")},
+	}, moves, "The replicas of the removed node pool should be moved to the remaining StatefulSet")
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"net/url"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -113,13 +114,17 @@ func DeterminePodsSafeToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod
 				} else {
 					queryParams.Set("action", "OVERSEERSTATUS")
 					err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, overseerResp)
-					if hasError, apiErr := solr_api.CheckForCollectionsApiError("OVERSEERSTATUS", clusterResp.ResponseHeader); hasError {
-						err = apiErr
+					if err == nil {
+						if hasError, apiErr := solr_api.CheckForCollectionsApiError("OVERSEERSTATUS", overseerResp.ResponseHeader); hasError {
+							err = apiErr
+						}
 					}
 				}
-			} else {
+			}
+			if err != nil {
 				logger.Error(err, "Error retrieving cluster status, delaying pod update selection")
 				// If there is an error fetching the clusterState, retry later.
+				// Choosing pods without an accurate picture of the cluster is not safe.
 				retryLater = true
 			}
 		}
@@ -158,6 +163,19 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 	sortNodePodsBySafety(outOfDatePods, nodeContents, cloud)
 
 	updateOptions := cloud.Spec.UpdateStrategy.ManagedUpdateOptions
+
+	// When respecting shard placement, update the pods hosting active shard leaders after the pods hosting only followers, where possible.
+	// This avoids needless leader elections, since leadership can move to the updated followers before the leaders are restarted.
+	// If no leader information is available in the cluster state, then the pods are left in the order chosen above.
+	var activeLeadersPerNode map[string]int
+	if updateOptions.RespectShardPlacement {
+		activeLeadersPerNode = findSolrNodesWithActiveLeaders(clusterStatus)
+		sort.SliceStable(outOfDatePods, func(i, j int) bool {
+			return activeLeadersPerNode[SolrNodeName(cloud, outOfDatePods[i])] == 0 && activeLeadersPerNode[SolrNodeName(cloud, outOfDatePods[j])] > 0
+		})
+	}
+	followerPodSelected := false
+
 	var maxShardReplicasUnavailableCache map[string]int
 	// In case the user wants all shardReplicas to be unavailable at the same time, populate the cache with the total number of replicas per shard.
 	if updateOptions.MaxShardReplicasUnavailable != nil && updateOptions.MaxShardReplicasUnavailable.Type == intstr.Int && updateOptions.MaxShardReplicasUnavailable.IntVal <= int32(0) {
@@ -166,6 +184,10 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 		maxShardReplicasUnavailableCache = make(map[string]int, len(totalShardReplicas))
 	}
 
+	// Pods that can only be taken down by leaving shards without an active replica, along with those shards
+	var podsHostingLastActiveReplicas []corev1.Pod
+	var lastActiveReplicaShards [][]string
+
 	for _, pod := range outOfDatePods {
 		isSafeToUpdate := true
 		nodeName := SolrNodeName(cloud, pod)
@@ -190,12 +212,19 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 					reason = "Pod is overseer and must wait for all other pods to be updated and healthy."
 				}
 			}
+			// Pods hosting active leaders are not taken down in the same batch as pods hosting only followers.
+			// Once no more follower pods can be updated, the leader pods are chosen, so the update cannot stall here.
+			if isSafeToUpdate && followerPodSelected && nodeContent.live && activeLeadersPerNode[nodeName] > 0 {
+				isSafeToUpdate = false
+				reason = fmt.Sprintf("Pod hosts %d active shard leaders, and must wait for the pods hosting only followers to be updated first.", activeLeadersPerNode[nodeName])
+			}
 			// Only check the replicaSaftey if the node starts out as isSafeToUpdate, otherwise the check is redundant
 			// If the node is not live, then consider it safe to be updated.
 			if isSafeToUpdate {
 				if !nodeContent.live {
 					reason = "Pod's Solr Node is not live, therefore it is safe to take down."
 				} else {
+					var shardsLosingLastActiveReplica []string
 					for shard, additionalReplicaCount := range nodeContent.totalReplicasPerShard {
 						// If all of the replicas for a shard on the node are down, then this is safe to kill.
 						// Currently this logic lets replicas in recovery continue recovery rather than killing them.
@@ -205,6 +234,12 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 
 						notActiveReplicaCount, _ := shardReplicasNotActive[shard]
 
+						// If requested, do not take down the last active replicas of a shard, regardless of the maxShardReplicasUnavailable.
+						if updateOptions.RespectShardPlacement && nodeContent.activeReplicasPerShard[shard] > 0 && totalShardReplicas[shard]-notActiveReplicaCount-nodeContent.activeReplicasPerShard[shard] <= 0 {
+							shardsLosingLastActiveReplica = append(shardsLosingLastActiveReplica, shard)
+							continue
+						}
+
 						// If the maxBatchNodeUpgradeSpec is passed as a decimal between 0 and 1, then calculate as a percentage of the number of nodes
 						maxShardReplicasDown, _ := ResolveMaxShardReplicasUnavailable(updateOptions.MaxShardReplicasUnavailable, shard, totalShardReplicas, maxShardReplicasUnavailableCache)
 
@@ -218,6 +253,14 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 						}
 					}
 
+					if isSafeToUpdate && len(shardsLosingLastActiveReplica) > 0 {
+						sort.Strings(shardsLosingLastActiveReplica)
+						reason = fmt.Sprintf("Shards %s have no active replicas besides the ones on this node, or the others are already being taken down. Taking down this node would leave these shards without an active replica", strings.Join(shardsLosingLastActiveReplica, ", "))
+						isSafeToUpdate = false
+						podsHostingLastActiveReplicas = append(podsHostingLastActiveReplicas, pod)
+						lastActiveReplicaShards = append(lastActiveReplicaShards, shardsLosingLastActiveReplica)
+					}
+
 					if reason == "" {
 						reason = "Pod's replicas are safe to take down, adhering to the minimum active replicas per shard."
 					}
@@ -232,6 +275,9 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 					shardReplicasNotActive[shard] += additionalReplicaCount
 				}
 			}
+			if activeLeadersPerNode != nil && activeLeadersPerNode[nodeName] == 0 {
+				followerPodSelected = true
+			}
 			logger.Info("Pod killed for update.", "pod", pod.Name, "reason", reason)
 			podsToUpdate = append(podsToUpdate, pod)
 
@@ -244,9 +290,28 @@ func pickPodsToUpdate(cloud *solr.SolrCloud, outOfDatePods []corev1.Pod, cluster
 			logger.Info("Pod not able to be killed for update.", "pod", pod.Name, "reason", reason)
 		}
 	}
+
+	// The pods hosting the only replica of a shard, such as of single-replica collections, can never be taken down without leaving that shard
+	// without an active replica. So once no other pods can be updated, and every replica of the SolrCloud is active, update these pods one at a time.
+	if len(podsToUpdate) == 0 && len(podsHostingLastActiveReplicas) > 0 && len(clusterStatus.LiveNodes) == totalPods && !hasReplicasNotActive(shardReplicasNotActive) {
+		pod := podsHostingLastActiveReplicas[0]
+		logger.Info("Pod killed for update, leaving shards without an active replica until it is back up. No other pods can be updated, and the shards have no other active replicas.",
+			"pod", pod.Name, "shards", lastActiveReplicaShards[0])
+		podsToUpdate = append(podsToUpdate, pod)
+	}
 	return podsToUpdate
 }
 
+// hasReplicasNotActive returns whether any shard has replicas that are not active, or are being taken down
+func hasReplicasNotActive(shardReplicasNotActive map[string]int) bool {
+	for _, notActive := range shardReplicasNotActive {
+		if notActive > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func sortNodePodsBySafety(outOfDatePods []corev1.Pod, nodeMap map[string]*SolrNodeContents, solrCloud *solr.SolrCloud) {
 	sort.SliceStable(outOfDatePods, func(i, j int) bool {
 		// First sort by if the node is in the ClusterState
@@ -289,16 +354,22 @@ func sortNodePodsBySafety(outOfDatePods []corev1.Pod, nodeMap map[string]*SolrNo
 }
 
 // ResolveMaxPodsUnavailable resolves the maximum number of pods that are allowed to be unavailable, when choosing pods to update.
+// Percentages are calculated against the desired number of pods, rounding up.
+// The result is always between 1 and the desired number of pods, unless no pods are desired.
 func ResolveMaxPodsUnavailable(maxPodsUnavailable *intstr.IntOrString, desiredPods int) (int, error) {
 	if maxPodsUnavailable != nil && maxPodsUnavailable.Type == intstr.Int && maxPodsUnavailable.IntVal <= int32(0) {
 		return desiredPods, nil
 	}
-	podsUnavailable, err := intstr.GetValueFromIntOrPercent(intstr.ValueOrDefault(maxPodsUnavailable, intstr.FromString(DefaultMaxPodsUnavailable)), desiredPods, false)
+	podsUnavailable, err := intstr.GetValueFromIntOrPercent(intstr.ValueOrDefault(maxPodsUnavailable, intstr.FromString(DefaultMaxPodsUnavailable)), desiredPods, true)
 	if err != nil {
 		return 1, err
 	}
 
-	if podsUnavailable == 0 {
+	if podsUnavailable > desiredPods {
+		// More pods cannot be unavailable than exist
+		podsUnavailable = desiredPods
+	}
+	if podsUnavailable <= 0 {
 		// podsUnavailable can never be 0, otherwise pods would never be able to be upgraded.
 		podsUnavailable = 1
 	}
@@ -421,6 +492,52 @@ func findSolrNodeContents(cluster solr_api.SolrClusterStatus, overseerLeader str
 	return nodeContents, totalShardReplicas, shardReplicasNotActive
 }
 
+// FindSolrNodesWithInactiveReplicas fetches the cluster state of the SolrCloud, and returns the Solr nodes that host at least one replica that is not "active".
+func FindSolrNodesWithInactiveReplicas(cloud *solr.SolrCloud, httpHeaders map[string]string) (nodesWithInactiveReplicas map[string]bool, err error) {
+	clusterResp := &solr_api.SolrClusterStatusResponse{}
+	queryParams := url.Values{}
+	queryParams.Add("action", "CLUSTERSTATUS")
+	err = solr_api.CallCollectionsApi(cloud, queryParams, httpHeaders, clusterResp)
+	if err == nil {
+		if hasError, apiErr := solr_api.CheckForCollectionsApiError("CLUSTERSTATUS", clusterResp.ResponseHeader); hasError {
+			err = apiErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return findSolrNodesWithInactiveReplicas(clusterResp.ClusterStatus), nil
+}
+
+func findSolrNodesWithInactiveReplicas(cluster solr_api.SolrClusterStatus) (nodesWithInactiveReplicas map[string]bool) {
+	nodesWithInactiveReplicas = map[string]bool{}
+	for _, collection := range cluster.Collections {
+		for _, shard := range collection.Shards {
+			for _, replica := range shard.Replicas {
+				if replica.State != solr_api.ReplicaActive {
+					nodesWithInactiveReplicas[replica.NodeName] = true
+				}
+			}
+		}
+	}
+	return nodesWithInactiveReplicas
+}
+
+// findSolrNodesWithActiveLeaders returns the number of "active" shard leaders hosted by each Solr node in the cluster state.
+func findSolrNodesWithActiveLeaders(cluster solr_api.SolrClusterStatus) (activeLeadersPerNode map[string]int) {
+	activeLeadersPerNode = map[string]int{}
+	for _, collection := range cluster.Collections {
+		for _, shard := range collection.Shards {
+			for _, replica := range shard.Replicas {
+				if replica.Leader && replica.State == solr_api.ReplicaActive {
+					activeLeadersPerNode[replica.NodeName] += 1
+				}
+			}
+		}
+	}
+	return activeLeadersPerNode
+}
+
 type SolrNodeContents struct {
 	// The name of the Solr Node (or pod)
 	nodeName string
@@ -156,6 +156,231 @@ func TestPickPodsToUpgrade(t *testing.T) {
 	assert.ElementsMatch(t, []string{"pod-0"}, podsToUpgrade, "Incorrect set of next pods to upgrade. The overseer should be upgraded when everything is healthy and it is the last node")
 }
 
+func TestPickPodsToUpgradeRespectingShardPlacement(t *testing.T) {
+	overseerLeader := "pod-0.foo-solrcloud-headless.default:2000_solr"
+
+	// Allow all replicas of a shard to be unavailable, so that only respectShardPlacement protects the shards
+	maxshardReplicasUnavailable := intstr.FromInt(0)
+
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				PodPort: 2000,
+			},
+			UpdateStrategy: solr.SolrUpdateStrategy{
+				Method: solr.ManagedUpdate,
+				ManagedUpdateOptions: solr.ManagedUpdateOptions{
+					MaxShardReplicasUnavailable: &maxshardReplicasUnavailable,
+				},
+			},
+		},
+	}
+
+	outOfDatePods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-1"}, Spec: corev1.PodSpec{}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-2"}, Spec: corev1.PodSpec{}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-3"}, Spec: corev1.PodSpec{}},
+	}
+
+	clusterStatus := solr_api.SolrClusterStatus{
+		LiveNodes: []string{
+			"pod-0.foo-solrcloud-headless.default:2000_solr",
+			"pod-1.foo-solrcloud-headless.default:2000_solr",
+			"pod-2.foo-solrcloud-headless.default:2000_solr",
+			"pod-3.foo-solrcloud-headless.default:2000_solr",
+		},
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"single": {
+				Shards: map[string]solr_api.SolrShardStatus{
+					"shard1": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"rep-1-1-1": {
+								State:    solr_api.ReplicaActive,
+								Core:     "core1",
+								NodeName: "pod-1.foo-solrcloud-headless.default:2000_solr",
+								BaseUrl:  "pod-1.foo-solrcloud-headless.default:2000/solr/rep-1-1-1",
+								Leader:   true,
+								Type:     solr_api.NRT,
+							},
+						},
+						State: solr_api.ShardActive,
+					},
+				},
+			},
+			"double": {
+				Shards: map[string]solr_api.SolrShardStatus{
+					"shard1": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"rep-2-1-1": {
+								State:    solr_api.ReplicaActive,
+								Core:     "core2",
+								NodeName: "pod-2.foo-solrcloud-headless.default:2000_solr",
+								BaseUrl:  "pod-2.foo-solrcloud-headless.default:2000/solr/rep-2-1-1",
+								Leader:   true,
+								Type:     solr_api.NRT,
+							},
+							"rep-2-1-2": {
+								State:    solr_api.ReplicaActive,
+								Core:     "core2",
+								NodeName: "pod-3.foo-solrcloud-headless.default:2000_solr",
+								BaseUrl:  "pod-3.foo-solrcloud-headless.default:2000/solr/rep-2-1-2",
+								Leader:   false,
+								Type:     solr_api.NRT,
+							},
+						},
+						State: solr_api.ShardActive,
+					},
+				},
+			},
+		},
+	}
+
+	// Without respecting shard placement, every pod can be taken down at once
+	podsToUpgrade := getPodNames(pickPodsToUpdate(solrCloud, outOfDatePods, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-1", "pod-2", "pod-3"}, podsToUpgrade, "Incorrect set of next pods to upgrade. All pods should be upgraded when all shard replicas are allowed to be unavailable.")
+
+	// When respecting shard placement, no shard can be left without an active replica
+	solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.RespectShardPlacement = true
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, outOfDatePods, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-3"}, podsToUpgrade, "Incorrect set of next pods to upgrade. The pod hosting the only replica of a shard, and the pod hosting the last active replica of a shard, should not be upgraded.")
+
+	// A shard whose only replica is already down cannot lose any more active replicas, so its pod is safe to take down
+	downShard := clusterStatus.Collections["single"].Shards["shard1"]
+	downReplica := downShard.Replicas["rep-1-1-1"]
+	downReplica.State = solr_api.ReplicaDown
+	downShard.Replicas["rep-1-1-1"] = downReplica
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, outOfDatePods, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-1", "pod-3"}, podsToUpgrade, "Incorrect set of next pods to upgrade. A pod whose replicas are all down should be upgraded when respecting shard placement.")
+
+	// A pod hosting the only replica of a shard is updated once no other pod can be updated, and every replica is active
+	downReplica.State = solr_api.ReplicaActive
+	downShard.Replicas["rep-1-1-1"] = downReplica
+	singleReplicaPod := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "pod-1"}, Spec: corev1.PodSpec{}}}
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, singleReplicaPod, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-1"}, podsToUpgrade, "Incorrect set of next pods to upgrade. The pod hosting the only replica of a shard should be upgraded once it is the only pod that can be upgraded.")
+
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, singleReplicaPod, clusterStatus, overseerLeader, 5, 5, log))
+	assert.Empty(t, podsToUpgrade, "Incorrect set of next pods to upgrade. The pod hosting the only replica of a shard should not be upgraded while other Solr nodes are not live.")
+
+	recoveringShard := clusterStatus.Collections["double"].Shards["shard1"]
+	recoveringReplica := recoveringShard.Replicas["rep-2-1-2"]
+	recoveringReplica.State = solr_api.ReplicaRecovering
+	recoveringShard.Replicas["rep-2-1-2"] = recoveringReplica
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, singleReplicaPod, clusterStatus, overseerLeader, 4, 4, log))
+	assert.Empty(t, podsToUpgrade, "Incorrect set of next pods to upgrade. The pod hosting the only replica of a shard should not be upgraded while other replicas are not active.")
+}
+
+func TestPickPodsToUpgradeUpdatingLeadersLast(t *testing.T) {
+	overseerLeader := "pod-0.foo-solrcloud-headless.default:2000_solr"
+
+	// Allow all replicas of a shard to be unavailable, so that only the leader ordering limits the pods chosen
+	maxshardReplicasUnavailable := intstr.FromInt(0)
+
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				PodPort: 2000,
+			},
+			UpdateStrategy: solr.SolrUpdateStrategy{
+				Method: solr.ManagedUpdate,
+				ManagedUpdateOptions: solr.ManagedUpdateOptions{
+					MaxShardReplicasUnavailable: &maxshardReplicasUnavailable,
+				},
+			},
+		},
+	}
+
+	outOfDatePods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-1"}, Spec: corev1.PodSpec{}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-2"}, Spec: corev1.PodSpec{}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-3"}, Spec: corev1.PodSpec{}},
+	}
+
+	clusterStatus := solr_api.SolrClusterStatus{
+		LiveNodes: []string{
+			"pod-0.foo-solrcloud-headless.default:2000_solr",
+			"pod-1.foo-solrcloud-headless.default:2000_solr",
+			"pod-2.foo-solrcloud-headless.default:2000_solr",
+			"pod-3.foo-solrcloud-headless.default:2000_solr",
+		},
+		Collections: map[string]solr_api.SolrCollectionStatus{
+			"col1": {
+				Shards: map[string]solr_api.SolrShardStatus{
+					"shard1": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"rep-1-1-1": {
+								State:    solr_api.ReplicaActive,
+								Core:     "core1",
+								NodeName: "pod-1.foo-solrcloud-headless.default:2000_solr",
+								BaseUrl:  "pod-1.foo-solrcloud-headless.default:2000/solr/rep-1-1-1",
+								Leader:   true,
+								Type:     solr_api.NRT,
+							},
+							"rep-1-1-2": {
+								State:    solr_api.ReplicaActive,
+								Core:     "core1",
+								NodeName: "pod-2.foo-solrcloud-headless.default:2000_solr",
+								BaseUrl:  "pod-2.foo-solrcloud-headless.default:2000/solr/rep-1-1-2",
+								Leader:   false,
+								Type:     solr_api.NRT,
+							},
+						},
+						State: solr_api.ShardActive,
+					},
+					"shard2": {
+						Replicas: map[string]solr_api.SolrReplicaStatus{
+							"rep-1-2-1": {
+								State:    solr_api.ReplicaActive,
+								Core:     "core1",
+								NodeName: "pod-3.foo-solrcloud-headless.default:2000_solr",
+								BaseUrl:  "pod-3.foo-solrcloud-headless.default:2000/solr/rep-1-2-1",
+								Leader:   true,
+								Type:     solr_api.NRT,
+							},
+							"rep-1-2-2": {
+								State:    solr_api.ReplicaActive,
+								Core:     "core1",
+								NodeName: "pod-0.foo-solrcloud-headless.default:2000_solr",
+								BaseUrl:  "pod-0.foo-solrcloud-headless.default:2000/solr/rep-1-2-2",
+								Leader:   false,
+								Type:     solr_api.NRT,
+							},
+						},
+						State: solr_api.ShardActive,
+					},
+				},
+			},
+		},
+	}
+
+	// Without respecting shard placement, leaders and followers can be taken down together
+	podsToUpgrade := getPodNames(pickPodsToUpdate(solrCloud, outOfDatePods, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-1", "pod-2", "pod-3"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Leaders should not be delayed when not respecting shard placement.")
+
+	// When respecting shard placement, the pods hosting leaders must wait for the pods hosting only followers
+	solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.RespectShardPlacement = true
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, outOfDatePods, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-2"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Pods hosting active leaders should be updated after the pods hosting only followers.")
+
+	// Once only pods hosting leaders are out of date, they can be updated
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, []corev1.Pod{outOfDatePods[0], outOfDatePods[2]}, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-1", "pod-3"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Pods hosting leaders should be updated when no follower pods are left to update.")
+
+	// Leaders that are not active do not hold back a pod
+	leaderShard := clusterStatus.Collections["col1"].Shards["shard2"]
+	downLeader := leaderShard.Replicas["rep-1-2-1"]
+	downLeader.State = solr_api.ReplicaDown
+	leaderShard.Replicas["rep-1-2-1"] = downLeader
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, outOfDatePods, clusterStatus, overseerLeader, 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-2", "pod-3"}, podsToUpgrade, "Incorrect set of next pods to upgrade. Pods hosting only leaders that are not active should be treated like follower pods.")
+
+	// Without any leader information, the pods are chosen as they would be otherwise
+	podsToUpgrade = getPodNames(pickPodsToUpdate(solrCloud, outOfDatePods, solr_api.SolrClusterStatus{}, "", 4, 4, log))
+	assert.ElementsMatch(t, []string{"pod-1", "pod-2", "pod-3"}, podsToUpgrade, "Incorrect set of next pods to upgrade. All pods should be upgraded when there is no cluster state.")
+}
+
 func TestPodUpgradeOrdering(t *testing.T) {
 	solrCloud := &solr.SolrCloud{
 		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
@@ -419,6 +644,19 @@ func TestFindSolrNodeContents(t *testing.T) {
 	assert.EqualValues(t, expectedShardReplicasNotActive, shardReplicasNotActive, "Shards with replicas not active information is incorrect.")
 }
 
+func TestFindSolrNodesWithInactiveReplicas(t *testing.T) {
+	nodesWithInactiveReplicas := findSolrNodesWithInactiveReplicas(testRecoveringClusterStatus)
+
+	assert.Equal(t, map[string]bool{
+		"pod-0.foo-solrcloud-headless.default:2000_solr": true,
+		"pod-2.foo-solrcloud-headless.default:2000_solr": true,
+		"pod-3.foo-solrcloud-headless.default:2000_solr": true,
+		"pod-5.foo-solrcloud-headless.default:2000_solr": true,
+	}, nodesWithInactiveReplicas, "Only nodes hosting recovering, down or recovery_failed replicas should be returned")
+
+	assert.Empty(t, findSolrNodesWithInactiveReplicas(solr_api.SolrClusterStatus{}), "No nodes should be returned for an empty cluster state")
+}
+
 func TestCalculateMaxPodsToUpgrade(t *testing.T) {
 	maxPodsUnavailable := intstr.FromInt(2)
 
@@ -454,23 +692,43 @@ func TestCalculateMaxPodsToUpgrade(t *testing.T) {
 
 	maxPodsUnavailable = intstr.FromString("45%")
 	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 3, 0, 5)
-	assert.Equal(t, 4, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"45%\")")
-	assert.Equal(t, 2, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
+	assert.Equal(t, 5, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"45%\"), percentages should be rounded up")
+	assert.Equal(t, 3, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
 
 	maxPodsUnavailable = intstr.FromString("45%")
 	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 1, 2, 5)
-	assert.Equal(t, 4, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"45%\")")
-	assert.Equal(t, 0, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
+	assert.Equal(t, 5, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"45%\"), percentages should be rounded up")
+	assert.Equal(t, 1, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
 
 	maxPodsUnavailable = intstr.FromString("70%")
 	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 3, 0, 2)
 	assert.Equal(t, 7, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"70%\")")
 	assert.Equal(t, 2, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
 
+	maxPodsUnavailable = intstr.FromString("0%")
+	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 3, 0, 7)
+	assert.Equal(t, 1, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"0%\"), at least 1 pod should be allowed to be unavailable")
+	assert.Equal(t, 1, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
+
+	maxPodsUnavailable = intstr.FromString("150%")
+	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 3, 0, 7)
+	assert.Equal(t, 10, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"150%\"), it should be capped at the number of pods")
+	assert.Equal(t, 10, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
+
+	maxPodsUnavailable = intstr.FromInt(15)
+	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 3, 0, 7)
+	assert.Equal(t, 10, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromInt(15), it should be capped at the number of pods")
+	assert.Equal(t, 10, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
+
+	maxPodsUnavailable = intstr.FromInt(0)
+	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 3, 0, 7)
+	assert.Equal(t, 10, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromInt(0), all pods should be allowed to be unavailable")
+	assert.Equal(t, 10, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
+
 	solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.MaxPodsUnavailable = nil
 	foundMaxPodsUnavailable, foundUnavailableUpdatedPodCount, foundMaxPodsToUpdate = calculateMaxPodsToUpdate(solrCloud, 10, 3, 0, 2)
-	assert.Equal(t, 2, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"25%\")")
-	assert.Equal(t, -3, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
+	assert.Equal(t, 3, foundMaxPodsUnavailable, "Incorrect value of maxPodsUnavailable given fromString(\"25%\"), percentages should be rounded up")
+	assert.Equal(t, -2, foundMaxPodsToUpdate, "Incorrect value of maxPodsToUpdate")
 }
 
 func TestSolrNodeName(t *testing.T) {
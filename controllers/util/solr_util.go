@@ -18,17 +18,22 @@
 package util
 
 import (
+	"crypto/md5"
 	"crypto/sha256"
 	b64 "encoding/base64"
 	"encoding/json"
 	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"math/rand"
+	"net"
+	"path"
 	"regexp"
 	"sort"
 	"strconv"
@@ -40,29 +45,57 @@ const (
 	SolrClientPortName  = "solr-client"
 	BackupRestoreVolume = "backup-restore"
 
+	// SolrLogsDir is the directory that the Solr logs are written to, when they are stored in a separate volume
+	SolrLogsDir = "/var/solr/logs"
+
 	SolrNodeContainer = "solrcloud-node"
 
+	ZookeeperWaitInitContainer   = "wait-for-zk"
+	ZookeeperWaitIntervalSeconds = 5
+
 	DefaultSolrUser  = 8983
 	DefaultSolrGroup = 8983
 
-	SolrStorageFinalizer             = "storage.finalizers.solr.apache.org"
-	SolrZKConnectionStringAnnotation = "solr.apache.org/zkConnectionString"
-	SolrPVCTechnologyLabel           = "solr.apache.org/technology"
-	SolrCloudPVCTechnology           = "solr-cloud"
-	SolrPVCStorageLabel              = "solr.apache.org/storage"
-	SolrCloudPVCDataStorage          = "data"
-	SolrPVCInstanceLabel             = "solr.apache.org/instance"
-	SolrXmlMd5Annotation             = "solr.apache.org/solrXmlMd5"
-	SolrTlsCertMd5Annotation         = "solr.apache.org/tlsCertMd5"
-	SolrXmlFile                      = "solr.xml"
-	LogXmlMd5Annotation              = "solr.apache.org/logXmlMd5"
-	LogXmlFile                       = "log4j2.xml"
-	SecurityJsonFile                 = "security.json"
-	BasicAuthMd5Annotation           = "solr.apache.org/basicAuthMd5"
-	DefaultProbePath                 = "/admin/info/system"
+	SolrStorageFinalizer               = "storage.finalizers.solr.apache.org"
+	SolrDrainFinalizer                 = "drain.finalizers.solr.apache.org"
+	SolrZookeeperCleanupFinalizer      = "zookeeper.finalizers.solr.apache.org"
+	SolrForceDeleteAnnotation          = "solr.apache.org/forceDelete"
+	SolrSkipZookeeperCleanupAnnotation = "solr.apache.org/skipZookeeperCleanup"
+	SolrZKConnectionStringAnnotation   = "solr.apache.org/zkConnectionString"
+	SolrPVCTechnologyLabel             = "solr.apache.org/technology"
+	SolrCloudPVCTechnology             = "solr-cloud"
+	SolrPVCStorageLabel                = "solr.apache.org/storage"
+	SolrCloudPVCDataStorage            = "data"
+	SolrCloudPVCLogStorage             = "logs"
+	SolrPVCInstanceLabel               = "solr.apache.org/instance"
+	SolrPVCOrphanedLabel               = "solr.apache.org/orphaned"
+	SolrPVCOrphanedTimeAnnotation      = "solr.apache.org/orphanedTime"
+	SolrNodePoolLabel                  = "solr.apache.org/node-pool"
+	SolrXmlMd5Annotation               = "solr.apache.org/solrXmlMd5"
+	SolrTlsCertMd5Annotation           = "solr.apache.org/tlsCertMd5"
+	SolrTlsTrustStoreMd5Annotation     = "solr.apache.org/tlsTrustStoreMd5"
+	SolrTlsPasswordVersionAnnotation   = "solr.apache.org/tlsPasswordVersion"
+	SolrXmlFile                        = "solr.xml"
+	LogXmlMd5Annotation                = "solr.apache.org/logXmlMd5"
+	LogXmlFile                         = "log4j2.xml"
+	AdditionalConfigMd5Annotation      = "solr.apache.org/additionalConfigMd5"
+	SecurityJsonFile                   = "security.json"
+	BasicAuthMd5Annotation             = "solr.apache.org/basicAuthMd5"
+	SolrRestartAnnotation              = "solr.apache.org/restart"
+	SolrDryRunAnnotation               = "solr.apache.org/dryRun"
+	DefaultProbePath                   = "/admin/info/system"
+	ExternalDNSHostnameAnnotation      = "external-dns.alpha.kubernetes.io/hostname"
+	ExternalDNSTTLAnnotation           = "external-dns.alpha.kubernetes.io/ttl"
+	IngressClassAnnotation             = "kubernetes.io/ingress.class"
+	IngressBackendProtocolAnnotation   = "nginx.ingress.kubernetes.io/backend-protocol"
+
+	CertManagerIssuerAnnotation        = "cert-manager.io/issuer"
+	CertManagerClusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
 
 	DefaultStatefulSetPodManagementPolicy = appsv1.ParallelPodManagement
 
+	DefaultSolrTerminationGracePeriodSeconds = 60
+
 	DefaultLivenessProbeInitialDelaySeconds = 20
 	DefaultLivenessProbeTimeoutSeconds      = 1
 	DefaultLivenessProbeSuccessThreshold    = 1
@@ -83,6 +116,7 @@ const (
 
 	DefaultKeyStorePath         = "/var/solr/tls"
 	Pkcs12KeystoreFile          = "keystore.p12"
+	Pkcs12TruststoreFile        = "truststore.p12"
 	DefaultWritableKeyStorePath = "/var/solr/tls/pkcs12"
 	TLSCertKey                  = "tls.crt"
 	TLSKeyKey                   = "tls.key"
@@ -95,7 +129,7 @@ const (
 // storage: the size of the storage for the SolrCloud instance (e.g. 100Gi)
 // zkConnectionString: the connectionString of the ZK instance to connect to
 func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, hostNameIPs map[string]string, reconcileConfigInfo map[string]string, createPkcs12InitContainer bool, tlsCertMd5 string) *appsv1.StatefulSet {
-	terminationGracePeriod := int64(60)
+	terminationGracePeriod := SolrTerminationGracePeriodSeconds(solrCloud)
 	solrPodPort := solrCloud.Spec.SolrAddressability.PodPort
 	fsGroup := int64(DefaultSolrGroup)
 	defaultMode := int32(420)
@@ -108,7 +142,7 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 	defaultHandler := corev1.Handler{
 		HTTPGet: &corev1.HTTPGetAction{
 			Scheme: probeScheme,
-			Path:   "/solr" + DefaultProbePath,
+			Path:   solrCloud.SolrContextPath() + DefaultProbePath,
 			Port:   intstr.FromInt(solrPodPort),
 		},
 	}
@@ -136,16 +170,20 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 	if nil != customPodOptions {
 		podLabels = MergeLabelsOrAnnotations(podLabels, customPodOptions.Labels)
 		podAnnotations = customPodOptions.Annotations
-
-		if customPodOptions.TerminationGracePeriodSeconds != nil {
-			terminationGracePeriod = *customPodOptions.TerminationGracePeriodSeconds
-		}
 	}
 
+	labels, annotations = withCommonLabelsAndAnnotations(solrCloud, labels, annotations)
+	podLabels, podAnnotations = withCommonLabelsAndAnnotations(solrCloud, podLabels, podAnnotations)
+
 	// Keep track of the SolrOpts that the Solr Operator needs to set
 	// These will be added to the SolrOpts given by the user.
 	allSolrOpts := []string{"-DhostPort=$(SOLR_NODE_PORT)"}
 
+	// Only set the context path when it differs from the default of Solr, so that existing pods are not restarted
+	if contextPath := solrCloud.SolrContextPath(); contextPath != solr.DefaultSolrContextPath {
+		allSolrOpts = append(allSolrOpts, "-DhostContext="+contextPath)
+	}
+
 	// Volumes & Mounts
 	solrVolumes := []corev1.Volume{
 		{
@@ -175,43 +213,16 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		volumeMounts = append(volumeMounts, tlsVolumeMounts(solrCloud.Spec.SolrTLS, createPkcs12InitContainer)...)
 	}
 
+	if zkTLS := solrCloud.Spec.ZookeeperRef.GetTLS(); zkTLS != nil {
+		_, zkTLSVolumes, zkTLSVolumeMounts := ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+		solrVolumes = append(solrVolumes, zkTLSVolumes...)
+		volumeMounts = append(volumeMounts, zkTLSVolumeMounts...)
+	}
+
 	var pvcs []corev1.PersistentVolumeClaim
 	if solrCloud.UsesPersistentStorage() {
-		pvc := solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.DeepCopy()
-
-		// Set the default name of the pvc
-		if pvc.ObjectMeta.Name == "" {
-			pvc.ObjectMeta.Name = solrDataVolumeName
-		}
-
-		// Set some defaults in the PVC Spec
-		if len(pvc.Spec.AccessModes) == 0 {
-			pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteOnce,
-			}
-		}
-		if pvc.Spec.VolumeMode == nil {
-			temp := corev1.PersistentVolumeFilesystem
-			pvc.Spec.VolumeMode = &temp
-		}
-
-		//  Add internally-used labels.
-		internalLabels := map[string]string{
-			SolrPVCTechnologyLabel: SolrCloudPVCTechnology,
-			SolrPVCStorageLabel:    SolrCloudPVCDataStorage,
-			SolrPVCInstanceLabel:   solrCloud.Name,
-		}
-		pvc.ObjectMeta.Labels = MergeLabelsOrAnnotations(internalLabels, pvc.ObjectMeta.Labels)
-
 		pvcs = []corev1.PersistentVolumeClaim{
-			{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        pvc.ObjectMeta.Name,
-					Labels:      pvc.ObjectMeta.Labels,
-					Annotations: pvc.ObjectMeta.Annotations,
-				},
-				Spec: pvc.Spec,
-			},
+			generateSolrPVC(solrCloud, &solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate, solrDataVolumeName, SolrCloudPVCDataStorage),
 		}
 	} else {
 		ephemeralVolume := corev1.Volume{
@@ -231,6 +242,24 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		}
 		solrVolumes = append(solrVolumes, ephemeralVolume)
 	}
+	// Store the Solr logs in a separate volume, so that their growth does not affect the Solr data
+	if logStorage := solrCloud.Spec.StorageOptions.LogStorage; logStorage != nil {
+		logsVolumeName := SolrLogsVolumeName(solrCloud)
+		if logStorage.PersistentVolumeClaimTemplate != nil {
+			pvcs = append(pvcs, generateSolrPVC(solrCloud, logStorage.PersistentVolumeClaimTemplate, logsVolumeName, SolrCloudPVCLogStorage))
+		} else {
+			emptyDir := logStorage.EmptyDir
+			if emptyDir == nil {
+				emptyDir = &corev1.EmptyDirVolumeSource{}
+			}
+			solrVolumes = append(solrVolumes, corev1.Volume{
+				Name:         logsVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: emptyDir},
+			})
+		}
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: logsVolumeName, MountPath: SolrLogsDir})
+	}
+
 	// Add backup volumes
 	if solrCloud.Spec.StorageOptions.BackupRestoreOptions != nil {
 		solrVolumes = append(solrVolumes, corev1.Volume{
@@ -262,27 +291,11 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 	}
 
 	// Host Aliases
-	hostAliases := make([]corev1.HostAlias, len(hostNameIPs))
-	if len(hostAliases) == 0 {
-		hostAliases = nil
-	} else {
-		hostNames := make([]string, len(hostNameIPs))
-		index := 0
-		for hostName := range hostNameIPs {
-			hostNames[index] = hostName
-			index += 1
-		}
-
-		sort.Strings(hostNames)
-
-		for index, hostName := range hostNames {
-			hostAliases[index] = corev1.HostAlias{
-				IP:        hostNameIPs[hostName],
-				Hostnames: []string{hostName},
-			}
-			index++
-		}
+	var customHostAliases []corev1.HostAlias
+	if customPodOptions != nil {
+		customHostAliases = customPodOptions.HostAliases
 	}
+	hostAliases := GenerateHostAliases(hostNameIPs, customHostAliases)
 
 	solrHostName := solrCloud.AdvertisedNodeHost("$(POD_HOSTNAME)")
 	solrAdressingPort := solrCloud.NodePort()
@@ -293,11 +306,21 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		solrStopWait = 0
 	}
 
+	// Merge the JVM options that the user supplied through custom env vars with the options from the SolrCloud spec,
+	// so that the spec options are not overridden by those env vars.
+	var customEnvVars []corev1.EnvVar
+	if nil != customPodOptions {
+		customEnvVars = customPodOptions.EnvVariables
+	}
+	solrJavaMem, customEnvVars := mergeCustomEnvVarValue("SOLR_JAVA_MEM", solrCloud.Spec.SolrJavaMem, customEnvVars)
+	solrGCTune, customEnvVars := mergeCustomEnvVarValue("GC_TUNE", solrCloud.Spec.SolrGCTune, customEnvVars)
+	customSolrOpts, customEnvVars := mergeCustomEnvVarValue("SOLR_OPTS", "", customEnvVars)
+
 	// Environment Variables
 	envVars := []corev1.EnvVar{
 		{
 			Name:  "SOLR_JAVA_MEM",
-			Value: solrCloud.Spec.SolrJavaMem,
+			Value: solrJavaMem,
 		},
 		{
 			Name:  "SOLR_HOME",
@@ -332,7 +355,7 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		},
 		{
 			Name:  "GC_TUNE",
-			Value: solrCloud.Spec.SolrGCTune,
+			Value: solrGCTune,
 		},
 		{
 			Name:  "SOLR_STOP_WAIT",
@@ -348,8 +371,9 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 	envVars = append(envVars, zkEnvVars...)
 
 	// Only have a postStart command to create the chRoot, if it is not '/' (which does not need to be created)
+	// If the pod waits for Zookeeper to be available, then the chRoot is created by that initContainer instead.
 	var postStart *corev1.Handler
-	if hasChroot {
+	if hasChroot && !solrCloud.Spec.ZookeeperRef.WaitForZookeeper {
 		postStart = &corev1.Handler{
 			Exec: &corev1.ExecAction{
 				Command: []string{"sh", "-c", "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}"},
@@ -362,11 +386,21 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		envVars = append(envVars, TLSEnvVars(solrCloud.Spec.SolrTLS, createPkcs12InitContainer)...)
 	}
 
-	// Add Custom EnvironmentVariables to the solr container
-	if nil != customPodOptions {
-		envVars = append(envVars, customPodOptions.EnvVariables...)
+	if solrCloud.Spec.StorageOptions.LogStorage != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "SOLR_LOGS_DIR", Value: SolrLogsDir})
 	}
 
+	// Append the env vars and volumes needed to access the backup repositories
+	if len(solrCloud.Spec.StorageOptions.BackupRepositories) > 0 {
+		repoEnvVars, repoVolumes, repoVolumeMounts := BackupRepositoryEnvVarsAndVolumes(solrCloud.Spec.StorageOptions.BackupRepositories)
+		envVars = append(envVars, repoEnvVars...)
+		solrVolumes = append(solrVolumes, repoVolumes...)
+		volumeMounts = append(volumeMounts, repoVolumeMounts...)
+	}
+
+	// Add Custom EnvironmentVariables to the solr container
+	envVars = append(envVars, customEnvVars...)
+
 	// Did the user provide a custom log config?
 	if reconcileConfigInfo[LogXmlFile] != "" {
 		if reconcileConfigInfo[LogXmlMd5Annotation] != "" {
@@ -386,6 +420,29 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		}
 	}
 
+	// Mount any additional user-provided ConfigMaps, and restart the pods when their contents change
+	if configMapOptions := solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions; configMapOptions != nil {
+		for i, additionalConfigMap := range configMapOptions.AdditionalConfigMaps {
+			volName := fmt.Sprintf("additional-configmap-%d", i)
+			solrVolumes = append(solrVolumes, corev1.Volume{
+				Name: volName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: additionalConfigMap.Name},
+						DefaultMode:          &defaultMode,
+					},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: volName, MountPath: additionalConfigMap.MountPath, ReadOnly: true})
+		}
+	}
+	if reconcileConfigInfo[AdditionalConfigMd5Annotation] != "" {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string, 1)
+		}
+		podAnnotations[AdditionalConfigMd5Annotation] = reconcileConfigInfo[AdditionalConfigMd5Annotation]
+	}
+
 	if (solrCloud.Spec.SolrTLS != nil && solrCloud.Spec.SolrTLS.ClientAuth != solr.None) || (solrCloud.Spec.SolrSecurity != nil && solrCloud.Spec.SolrSecurity.ProbesRequireAuth) {
 		probeCommand, vol, volMount := configureSecureProbeCommand(solrCloud, defaultHandler.HTTPGet)
 		if vol != nil {
@@ -415,10 +472,42 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		podAnnotations[SolrTlsCertMd5Annotation] = tlsCertMd5
 	}
 
+	// track the MD5 of the TLS truststore (from secret) to trigger restarts if the truststore changes
+	if solrCloud.Spec.SolrTLS != nil && solrCloud.Spec.SolrTLS.RestartOnTLSSecretUpdate && reconcileConfigInfo[SolrTlsTrustStoreMd5Annotation] != "" {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string, 1)
+		}
+		podAnnotations[SolrTlsTrustStoreMd5Annotation] = reconcileConfigInfo[SolrTlsTrustStoreMd5Annotation]
+	}
+
+	// track the version of the keystore password secret, since the password is passed to Solr through env vars when it starts
+	if solrCloud.Spec.SolrTLS != nil && solrCloud.Spec.SolrTLS.RestartOnTLSSecretUpdate && reconcileConfigInfo[SolrTlsPasswordVersionAnnotation] != "" {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string, 1)
+		}
+		podAnnotations[SolrTlsPasswordVersionAnnotation] = reconcileConfigInfo[SolrTlsPasswordVersionAnnotation]
+	}
+
+	// copy the restart annotation of the SolrCloud to the pods, so that changing it triggers a rolling restart
+	if restart := solrCloud.Annotations[SolrRestartAnnotation]; restart != "" {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string, 1)
+		}
+		podAnnotations[SolrRestartAnnotation] = restart
+	}
+
+	if customSolrOpts != "" {
+		allSolrOpts = append(allSolrOpts, customSolrOpts)
+	}
+
 	if solrCloud.Spec.SolrOpts != "" {
 		allSolrOpts = append(allSolrOpts, solrCloud.Spec.SolrOpts)
 	}
 
+	for _, javaOpt := range solrCloud.Spec.AdditionalJavaOpts {
+		allSolrOpts = append(allSolrOpts, string(javaOpt))
+	}
+
 	// Add SOLR_OPTS last, so that it can use values from all of the other ENV_VARS
 	envVars = append(envVars, corev1.EnvVar{
 		Name:  "SOLR_OPTS",
@@ -427,7 +516,13 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 
 	initContainers := generateSolrSetupInitContainers(solrCloud, solrCloudStatus, solrDataVolumeName, reconcileConfigInfo)
 
-	// Add user defined additional init containers
+	if createPkcs12InitContainer {
+		pkcs12InitContainer := generatePkcs12InitContainer(solrCloud.Spec.SolrTLS,
+			solrCloud.Spec.SolrImage.ToImageName(), solrCloud.Spec.SolrImage.PullPolicy, containerSecurityContext(customPodOptions), initContainerResources(customPodOptions))
+		initContainers = append(initContainers, pkcs12InitContainer)
+	}
+
+	// Add user defined additional init containers, these run after the init containers that the operator requires
 	if customPodOptions != nil && len(customPodOptions.InitContainers) > 0 {
 		initContainers = append(initContainers, customPodOptions.InitContainers...)
 	}
@@ -479,10 +574,11 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 	}
 
 	// Decide which update strategy to use
-	updateStrategy := appsv1.OnDeleteStatefulSetStrategyType
+	updateStrategy := appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
 	if solrCloud.Spec.UpdateStrategy.Method == solr.StatefulSetUpdate {
-		// Only use the rolling update strategy if the StatefulSetUpdate method is specified.
-		updateStrategy = appsv1.RollingUpdateStatefulSetStrategyType
+		// Only use the StatefulSet's own update strategy if the StatefulSetUpdate method is specified.
+		// Otherwise the Solr Operator, or the user, is responsible for deleting pods to update them.
+		updateStrategy = statefulSetUpdateStrategy(solrCloud.Spec.UpdateStrategy.StatefulSetUpdateOptions)
 	}
 
 	// Determine which podManagementPolicy to use for the statefulSet
@@ -491,12 +587,6 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		podManagementPolicy = solrCloud.Spec.CustomSolrKubeOptions.StatefulSetOptions.PodManagementPolicy
 	}
 
-	if createPkcs12InitContainer {
-		pkcs12InitContainer := generatePkcs12InitContainer(solrCloud.Spec.SolrTLS,
-			solrCloud.Spec.SolrImage.ToImageName(), solrCloud.Spec.SolrImage.PullPolicy)
-		initContainers = append(initContainers, pkcs12InitContainer)
-	}
-
 	// Create the Stateful Set
 	stateful := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -512,9 +602,7 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 			ServiceName:         solrCloud.HeadlessServiceName(),
 			Replicas:            solrCloud.Spec.Replicas,
 			PodManagementPolicy: podManagementPolicy,
-			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
-				Type: updateStrategy,
-			},
+			UpdateStrategy:      updateStrategy,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      podLabels,
@@ -549,6 +637,14 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		)
 	}
 
+	// The setup init containers use the BusyBox image, which may come from a different registry than Solr
+	if solrCloud.Spec.BusyBoxImage != nil && solrCloud.Spec.BusyBoxImage.ImagePullSecret != "" && solrCloud.Spec.BusyBoxImage.ImagePullSecret != solrCloud.Spec.SolrImage.ImagePullSecret {
+		imagePullSecrets = append(
+			imagePullSecrets,
+			corev1.LocalObjectReference{Name: solrCloud.Spec.BusyBoxImage.ImagePullSecret},
+		)
+	}
+
 	stateful.Spec.Template.Spec.ImagePullSecrets = imagePullSecrets
 
 	if nil != customPodOptions {
@@ -568,6 +664,14 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 			stateful.Spec.Template.Spec.SecurityContext = customPodOptions.PodSecurityContext
 		}
 
+		if customPodOptions.ContainerSecurityContext != nil {
+			stateful.Spec.Template.Spec.Containers[0].SecurityContext = containerSecurityContext(customPodOptions)
+		}
+
+		if len(customPodOptions.EnvFrom) > 0 {
+			stateful.Spec.Template.Spec.Containers[0].EnvFrom = customPodOptions.EnvFrom
+		}
+
 		if customPodOptions.Tolerations != nil {
 			stateful.Spec.Template.Spec.Tolerations = customPodOptions.Tolerations
 		}
@@ -576,6 +680,10 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 			stateful.Spec.Template.Spec.NodeSelector = customPodOptions.NodeSelector
 		}
 
+		if customPodOptions.TopologySpreadConstraints != nil {
+			stateful.Spec.Template.Spec.TopologySpreadConstraints = fillTopologySpreadConstraints(customPodOptions.TopologySpreadConstraints, selectorLabels)
+		}
+
 		if customPodOptions.LivenessProbe != nil {
 			stateful.Spec.Template.Spec.Containers[0].LivenessProbe = fillProbe(*customPodOptions.LivenessProbe, DefaultLivenessProbeInitialDelaySeconds, DefaultLivenessProbeTimeoutSeconds, DefaultLivenessProbeSuccessThreshold, DefaultLivenessProbeFailureThreshold, DefaultLivenessProbePeriodSeconds, &defaultHandler)
 		}
@@ -591,11 +699,183 @@ func GenerateStatefulSet(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCl
 		if customPodOptions.PriorityClassName != "" {
 			stateful.Spec.Template.Spec.PriorityClassName = customPodOptions.PriorityClassName
 		}
+
+		if customPodOptions.RuntimeClassName != nil {
+			stateful.Spec.Template.Spec.RuntimeClassName = customPodOptions.RuntimeClassName
+		}
+
+		if customPodOptions.DNSPolicy != "" {
+			stateful.Spec.Template.Spec.DNSPolicy = customPodOptions.DNSPolicy
+		}
+
+		if customPodOptions.DNSConfig != nil {
+			stateful.Spec.Template.Spec.DNSConfig = customPodOptions.DNSConfig
+		}
+
+		if customPodOptions.PreStop != nil {
+			stateful.Spec.Template.Spec.Containers[0].Lifecycle.PreStop = customPodOptions.PreStop
+		}
+
+		if customPodOptions.PostStart != nil {
+			stateful.Spec.Template.Spec.Containers[0].Lifecycle.PostStart = mergePostStartHooks(stateful.Spec.Template.Spec.Containers[0].Lifecycle.PostStart, customPodOptions.PostStart)
+		}
+	}
+
+	// Give Solr the expected time to start, before the liveness probe is able to restart it
+	if maxStartupSeconds := solrCloud.Spec.MaxStartupSeconds; maxStartupSeconds != nil {
+		startupProbe := stateful.Spec.Template.Spec.Containers[0].StartupProbe
+		if startupProbe == nil {
+			startupProbe = fillProbe(corev1.Probe{}, DefaultStartupProbeInitialDelaySeconds, DefaultStartupProbeTimeoutSeconds, DefaultStartupProbeSuccessThreshold, DefaultStartupProbeFailureThreshold, DefaultStartupProbePeriodSeconds, &defaultHandler)
+			stateful.Spec.Template.Spec.Containers[0].StartupProbe = startupProbe
+		}
+		if customPodOptions == nil || customPodOptions.StartupProbe == nil || customPodOptions.StartupProbe.FailureThreshold == 0 {
+			startupProbe.FailureThreshold = startupProbeFailureThreshold(*maxStartupSeconds, startupProbe)
+		}
 	}
 
 	return stateful
 }
 
+// startupProbeFailureThreshold returns the number of failed checks of the startupProbe, after which Solr has been starting for at least maxStartupSeconds
+func startupProbeFailureThreshold(maxStartupSeconds int32, startupProbe *corev1.Probe) int32 {
+	checkSeconds := maxStartupSeconds - startupProbe.InitialDelaySeconds
+	failureThreshold := (checkSeconds + startupProbe.PeriodSeconds - 1) / startupProbe.PeriodSeconds
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return failureThreshold
+}
+
+// mergePostStartHooks runs the custom postStart hook after the postStart hook that creates the Zookeeper chroot, if there is one.
+// The custom command is passed as arguments to the shell of the chroot hook, so that its quoting is kept intact.
+// Custom hooks that are not exec handlers replace the chroot hook, which is only allowed when the chroot is created by an initContainer.
+func mergePostStartHooks(chrootHook *corev1.Handler, customHook *corev1.Handler) *corev1.Handler {
+	if chrootHook == nil || chrootHook.Exec == nil || customHook.Exec == nil {
+		return customHook
+	}
+	chrootCmd := chrootHook.Exec.Command[len(chrootHook.Exec.Command)-1]
+	return &corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: append([]string{"sh", "-c", "(" + chrootCmd + ") && exec \"$@\"", "postStart"}, customHook.Exec.Command...),
+		},
+	}
+}
+
+// statefulSetUpdateStrategy returns the StatefulSet's update strategy for the StatefulSet update method.
+// The partition is always set for rolling updates, since Kubernetes defaults it to 0 if it is not provided.
+func statefulSetUpdateStrategy(options *solr.StatefulSetUpdateOptions) appsv1.StatefulSetUpdateStrategy {
+	updateStrategy := appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType}
+	partition := int32(0)
+	if options != nil {
+		if options.Type != "" {
+			updateStrategy.Type = options.Type
+		}
+		if options.Partition != nil {
+			partition = *options.Partition
+		}
+	}
+	if updateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType {
+		updateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: &partition}
+	}
+	return updateStrategy
+}
+
+// SolrTerminationGracePeriodSeconds returns the time that Solr pods are given to stop gracefully, before they are killed.
+func SolrTerminationGracePeriodSeconds(solrCloud *solr.SolrCloud) int64 {
+	if podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions; podOptions != nil && podOptions.TerminationGracePeriodSeconds != nil {
+		return *podOptions.TerminationGracePeriodSeconds
+	}
+	return DefaultSolrTerminationGracePeriodSeconds
+}
+
+// GenerateNodePoolStatefulSet returns a new appsv1.StatefulSet pointer generated for the given node pool of the SolrCloud instance.
+// The pods of the node pool are the same as the pods of the SolrCloud's StatefulSet, except for the options that the node pool overrides.
+func GenerateNodePoolStatefulSet(solrCloud *solr.SolrCloud, nodePool solr.SolrNodePool, solrCloudStatus *solr.SolrCloudStatus, hostNameIPs map[string]string, reconcileConfigInfo map[string]string, createPkcs12InitContainer bool, tlsCertMd5 string) *appsv1.StatefulSet {
+	poolCloud := solrCloud.DeepCopy()
+	poolCloud.Spec.Replicas = &nodePool.Replicas
+	if nodePool.SolrJavaMem != "" {
+		poolCloud.Spec.SolrJavaMem = nodePool.SolrJavaMem
+	}
+
+	podOptions := poolCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		podOptions = &solr.PodOptions{}
+		poolCloud.Spec.CustomSolrKubeOptions.PodOptions = podOptions
+	}
+	if nodePool.Resources.Limits != nil || nodePool.Resources.Requests != nil {
+		podOptions.Resources = nodePool.Resources
+	}
+	if nodePool.NodeSelector != nil {
+		podOptions.NodeSelector = nodePool.NodeSelector
+	}
+	if nodePool.Tolerations != nil {
+		podOptions.Tolerations = nodePool.Tolerations
+	}
+
+	if nodePool.StorageCapacity != nil && poolCloud.UsesPersistentStorage() {
+		pvcSpec := &poolCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.Spec
+		if pvcSpec.Resources.Requests == nil {
+			pvcSpec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvcSpec.Resources.Requests[corev1.ResourceStorage] = *nodePool.StorageCapacity
+	}
+
+	stateful := GenerateStatefulSet(poolCloud, solrCloudStatus, hostNameIPs, reconcileConfigInfo, createPkcs12InitContainer, tlsCertMd5)
+	stateful.Name = solrCloud.NodePoolStatefulSetName(nodePool.Name)
+	stateful.Labels[SolrNodePoolLabel] = nodePool.Name
+	stateful.Spec.Selector.MatchLabels[SolrNodePoolLabel] = nodePool.Name
+	stateful.Spec.Template.Labels[SolrNodePoolLabel] = nodePool.Name
+	return stateful
+}
+
+// withCommonLabelsAndAnnotations merges the commonLabels and commonAnnotations of the SolrCloud into the given labels and annotations.
+// Labels and annotations that are already set, by the Solr Operator or the options of a specific resource, take precedence.
+func withCommonLabelsAndAnnotations(solrCloud *solr.SolrCloud, labels map[string]string, annotations map[string]string) (map[string]string, map[string]string) {
+	customOptions := solrCloud.Spec.CustomSolrKubeOptions
+	if len(customOptions.CommonLabels) > 0 {
+		labels = MergeLabelsOrAnnotations(labels, customOptions.CommonLabels)
+	}
+	if len(customOptions.CommonAnnotations) > 0 {
+		annotations = MergeLabelsOrAnnotations(annotations, customOptions.CommonAnnotations)
+	}
+	return labels, annotations
+}
+
+// GenerateHostAliases returns the hostAliases for the Solr pods.
+// The operator-managed hostname to IP mappings are sorted by hostname and come first, followed by the user-provided host aliases.
+// Hostnames that the operator manages are removed from the user-provided host aliases, so that they cannot be overridden.
+func GenerateHostAliases(hostNameIPs map[string]string, customHostAliases []corev1.HostAlias) (hostAliases []corev1.HostAlias) {
+	hostNames := make([]string, 0, len(hostNameIPs))
+	for hostName := range hostNameIPs {
+		hostNames = append(hostNames, hostName)
+	}
+	sort.Strings(hostNames)
+
+	for _, hostName := range hostNames {
+		hostAliases = append(hostAliases, corev1.HostAlias{
+			IP:        hostNameIPs[hostName],
+			Hostnames: []string{hostName},
+		})
+	}
+
+	for _, customHostAlias := range customHostAliases {
+		var customHostNames []string
+		for _, hostName := range customHostAlias.Hostnames {
+			if _, managed := hostNameIPs[hostName]; !managed {
+				customHostNames = append(customHostNames, hostName)
+			}
+		}
+		if len(customHostNames) > 0 {
+			hostAliases = append(hostAliases, corev1.HostAlias{
+				IP:        customHostAlias.IP,
+				Hostnames: customHostNames,
+			})
+		}
+	}
+
+	return hostAliases
+}
+
 func generateSolrSetupInitContainers(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, solrDataVolumeName string, reconcileConfigInfo map[string]string) (containers []corev1.Container) {
 	// The setup of the solr.xml will always be necessary
 	volumeMounts := []corev1.VolumeMount{
@@ -629,10 +909,15 @@ func generateSolrSetupInitContainers(solrCloud *solr.SolrCloud, solrCloudStatus
 		ImagePullPolicy: solrCloud.Spec.BusyBoxImage.PullPolicy,
 		Command:         []string{"sh", "-c", strings.Join(setupCommands, " && ")},
 		VolumeMounts:    volumeMounts,
+		Resources:       initContainerResources(solrCloud.Spec.CustomSolrKubeOptions.PodOptions),
 	}
 
 	containers = append(containers, volumePrepInitContainer)
 
+	if solrCloud.Spec.ZookeeperRef.WaitForZookeeper {
+		containers = append(containers, generateZKWaitInitContainer(solrCloud, solrCloudStatus))
+	}
+
 	if hasZKSetupContainer, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, reconcileConfigInfo); hasZKSetupContainer {
 		containers = append(containers, zkSetupContainer)
 	}
@@ -651,6 +936,14 @@ func GenerateConfigMap(solrCloud *solr.SolrCloud) *corev1.ConfigMap {
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	labels, annotations = withCommonLabelsAndAnnotations(solrCloud, labels, annotations)
+
+	// Configure any backup repositories, and the libraries that they require
+	backupSection, additionalLibs := GenerateBackupRepositoriesForSolrXml(solrCloud.Spec.StorageOptions.BackupRepositories)
+	sharedLib := ""
+	if len(additionalLibs) > 0 {
+		sharedLib = fmt.Sprintf("  <str name=\"sharedLib\">${solr.sharedLib:%s}</str>\n", strings.Join(additionalLibs, ","))
+	}
 
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -662,7 +955,7 @@ func GenerateConfigMap(solrCloud *solr.SolrCloud) *corev1.ConfigMap {
 		Data: map[string]string{
 			"solr.xml": `<?xml version="1.0" encoding="UTF-8" ?>
 <solr>
-  <solrcloud>
+` + sharedLib + `  <solrcloud>
     <str name="host">${host:}</str>
     <int name="hostPort">${hostPort:80}</int>
     <str name="hostContext">${hostContext:solr}</str>
@@ -678,11 +971,15 @@ func GenerateConfigMap(solrCloud *solr.SolrCloud) *corev1.ConfigMap {
     <int name="socketTimeout">${socketTimeout:600000}</int>
     <int name="connTimeout">${connTimeout:60000}</int>
   </shardHandlerFactory>
-</solr>
+` + backupSection + `</solr>
 `,
 		},
 	}
 
+	if solrCloud.Spec.SolrLogXml != "" {
+		configMap.Data[LogXmlFile] = solrCloud.Spec.SolrLogXml
+	}
+
 	return configMap
 }
 
@@ -719,6 +1016,13 @@ func fillProbe(customProbe corev1.Probe, defaultInitialDelaySeconds int32, defau
 
 	if customProbe.Handler.Exec != nil || customProbe.Handler.HTTPGet != nil || customProbe.Handler.TCPSocket != nil {
 		probe.Handler = customProbe.Handler
+
+		// Custom HTTP probes that do not give a port are sent to the port that Solr listens on
+		if httpGet := probe.Handler.HTTPGet; httpGet != nil && httpGet.Port == (intstr.IntOrString{}) && defaultHandler.HTTPGet != nil {
+			httpGetWithPort := *httpGet
+			httpGetWithPort.Port = defaultHandler.HTTPGet.Port
+			probe.Handler.HTTPGet = &httpGetWithPort
+		}
 	}
 
 	return probe
@@ -738,12 +1042,17 @@ func GenerateCommonService(solrCloud *solr.SolrCloud) *corev1.Service {
 	// Add externalDNS annotation if necessary
 	extOpts := solrCloud.Spec.SolrAddressability.External
 	if extOpts != nil && extOpts.Method == solr.ExternalDNS && !extOpts.HideCommon {
-		annotations = make(map[string]string, 1)
 		urls := []string{solrCloud.ExternalDnsDomain(extOpts.DomainName)}
 		for _, domain := range extOpts.AdditionalDomainNames {
 			urls = append(urls, solrCloud.ExternalDnsDomain(domain))
 		}
-		annotations["external-dns.alpha.kubernetes.io/hostname"] = strings.Join(urls, ",")
+		annotations = externalDNSAnnotations(extOpts, urls)
+	} else if extOpts != nil && extOpts.UseExternalDNSAnnotations && extOpts.Method != solr.NodePort && !extOpts.HideCommon {
+		urls := []string{solrCloud.ExternalCommonUrl(extOpts.DomainName, false)}
+		for _, domain := range extOpts.AdditionalDomainNames {
+			urls = append(urls, solrCloud.ExternalCommonUrl(domain, false))
+		}
+		annotations = externalDNSAnnotations(extOpts, urls)
 	}
 
 	customOptions := solrCloud.Spec.CustomSolrKubeOptions.CommonServiceOptions
@@ -751,6 +1060,7 @@ func GenerateCommonService(solrCloud *solr.SolrCloud) *corev1.Service {
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	labels, annotations = withCommonLabelsAndAnnotations(solrCloud, labels, annotations)
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -766,9 +1076,29 @@ func GenerateCommonService(solrCloud *solr.SolrCloud) *corev1.Service {
 			Selector: selectorLabels,
 		},
 	}
+
+	service.Spec.Type = CommonServiceType(solrCloud)
+	setServiceIPFamilies(service, customOptions)
 	return service
 }
 
+// CommonServiceType returns the type of the common service for the SolrCloud.
+// A type provided in the commonServiceOptions takes precedence over the type that the external addressability method uses.
+func CommonServiceType(solrCloud *solr.SolrCloud) corev1.ServiceType {
+	if customOptions := solrCloud.Spec.CustomSolrKubeOptions.CommonServiceOptions; customOptions != nil && customOptions.Type != "" {
+		return customOptions.Type
+	}
+
+	// Expose the common service on the Kubernetes nodes or through a load balancer if necessary
+	extOpts := solrCloud.Spec.SolrAddressability.External
+	if extOpts != nil && extOpts.Method == solr.NodePort && !extOpts.HideCommon {
+		return corev1.ServiceTypeNodePort
+	} else if extOpts != nil && extOpts.Method == solr.LoadBalancer && !extOpts.HideCommon {
+		return corev1.ServiceTypeLoadBalancer
+	}
+	return corev1.ServiceTypeClusterIP
+}
+
 // GenerateHeadlessService returns a new Headless corev1.Service pointer generated for the SolrCloud instance
 // The PublishNotReadyAddresses option is set as true, because we want each pod to be reachable no matter the readiness of the pod.
 // solrCloud: SolrCloud instance
@@ -784,12 +1114,11 @@ func GenerateHeadlessService(solrCloud *solr.SolrCloud) *corev1.Service {
 	// Add externalDNS annotation if necessary
 	extOpts := solrCloud.Spec.SolrAddressability.External
 	if extOpts != nil && extOpts.Method == solr.ExternalDNS && !extOpts.HideNodes {
-		annotations = make(map[string]string, 1)
 		urls := []string{solrCloud.ExternalDnsDomain(extOpts.DomainName)}
 		for _, domain := range extOpts.AdditionalDomainNames {
 			urls = append(urls, solrCloud.ExternalDnsDomain(domain))
 		}
-		annotations["external-dns.alpha.kubernetes.io/hostname"] = strings.Join(urls, ",")
+		annotations = externalDNSAnnotations(extOpts, urls)
 	}
 
 	customOptions := solrCloud.Spec.CustomSolrKubeOptions.HeadlessServiceOptions
@@ -797,6 +1126,7 @@ func GenerateHeadlessService(solrCloud *solr.SolrCloud) *corev1.Service {
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	labels, annotations = withCommonLabelsAndAnnotations(solrCloud, labels, annotations)
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -814,6 +1144,7 @@ func GenerateHeadlessService(solrCloud *solr.SolrCloud) *corev1.Service {
 			PublishNotReadyAddresses: true,
 		},
 	}
+	setServiceIPFamilies(service, customOptions)
 	return service
 }
 
@@ -831,11 +1162,22 @@ func GenerateNodeService(solrCloud *solr.SolrCloud, nodeName string) *corev1.Ser
 
 	var annotations map[string]string
 
+	// Add externalDNS annotation if requested, advertising the node's external address
+	extOpts := solrCloud.Spec.SolrAddressability.External
+	if extOpts != nil && extOpts.UseExternalDNSAnnotations && extOpts.Method != solr.NodePort && !extOpts.HideNodes {
+		urls := []string{solrCloud.ExternalNodeUrl(nodeName, extOpts.DomainName, false)}
+		for _, domain := range extOpts.AdditionalDomainNames {
+			urls = append(urls, solrCloud.ExternalNodeUrl(nodeName, domain, false))
+		}
+		annotations = externalDNSAnnotations(extOpts, urls)
+	}
+
 	customOptions := solrCloud.Spec.CustomSolrKubeOptions.NodeServiceOptions
 	if nil != customOptions {
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
 	}
+	labels, annotations = withCommonLabelsAndAnnotations(solrCloud, labels, annotations)
 
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -852,20 +1194,158 @@ func GenerateNodeService(solrCloud *solr.SolrCloud, nodeName string) *corev1.Ser
 			PublishNotReadyAddresses: true,
 		},
 	}
+
+	// Expose the node service on the Kubernetes nodes or through a load balancer if necessary
+	if extOpts != nil && extOpts.Method == solr.NodePort && !extOpts.HideNodes {
+		service.Spec.Type = corev1.ServiceTypeNodePort
+		// The ordinals of node pools overlap with the ordinals of the SolrCloud's StatefulSet, so Kubernetes allocates their nodePorts
+		if extOpts.BaseNodePort > 0 && solrCloud.NodePoolOfNode(nodeName) == "" {
+			service.Spec.Ports[0].NodePort = int32(extOpts.BaseNodePort + nodeOrdinal(nodeName))
+		}
+	} else if extOpts != nil && extOpts.Method == solr.LoadBalancer && !extOpts.HideNodes {
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+	setServiceIPFamilies(service, customOptions)
 	return service
 }
 
+// nodeOrdinal returns the ordinal of the given Solr Node within its StatefulSet
+func nodeOrdinal(nodeName string) int {
+	ordinal, _ := strconv.Atoi(nodeName[strings.LastIndex(nodeName, "-")+1:])
+	return ordinal
+}
+
+// ExternalNodePortAddress returns the address, without a scheme, that the given Kubernetes node exposes a nodePort on.
+// The external IP of the node is preferred, falling back to its internal IP.
+func ExternalNodePortAddress(node *corev1.Node, nodePort int32) string {
+	ip := ""
+	for _, addressType := range []corev1.NodeAddressType{corev1.NodeExternalIP, corev1.NodeInternalIP} {
+		for _, address := range node.Status.Addresses {
+			if address.Type == addressType && ip == "" {
+				ip = address.Address
+			}
+		}
+	}
+	if ip == "" {
+		return ""
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(int(nodePort)))
+}
+
+// LoadBalancerAddress returns the hostname or IP address of the load balancer that has been provisioned for the given service.
+// An empty string is returned if the load balancer is not yet available.
+func LoadBalancerAddress(service *corev1.Service) string {
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			return ingress.Hostname
+		} else if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+	return ""
+}
+
+// UrlHost returns the given hostname or IP address in the form that it can be used as the host of a URL.
+// IPv6 addresses are enclosed in brackets, so that they can be followed by a port.
+func UrlHost(address string) string {
+	if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+		return "[" + address + "]"
+	}
+	return address
+}
+
+// SolrVersionCounts returns the number of Solr nodes running each version of solr, sorted by version.
+// Nodes without a known version are not counted.
+func SolrVersionCounts(solrNodes []solr.SolrNodeStatus) (versionCounts []solr.SolrVersionCount) {
+	nodesPerVersion := map[string]int32{}
+	for _, node := range solrNodes {
+		if node.Version != "" {
+			nodesPerVersion[node.Version] += 1
+		}
+	}
+	for version, nodes := range nodesPerVersion {
+		versionCounts = append(versionCounts, solr.SolrVersionCount{Version: version, Nodes: nodes})
+	}
+	sort.Slice(versionCounts, func(i, j int) bool {
+		return versionCounts[i].Version < versionCounts[j].Version
+	})
+	return versionCounts
+}
+
+// externalDNSAnnotations returns the annotations that external-dns uses to create DNS records for the given hostnames
+func externalDNSAnnotations(extOpts *solr.ExternalAddressability, hostnames []string) map[string]string {
+	annotations := map[string]string{
+		ExternalDNSHostnameAnnotation: strings.Join(hostnames, ","),
+	}
+	if extOpts.ExternalDNSTTL != nil {
+		annotations[ExternalDNSTTLAnnotation] = strconv.Itoa(int(*extOpts.ExternalDNSTTL))
+	}
+	return annotations
+}
+
+// GeneratePodDisruptionBudget returns a new PodDisruptionBudget pointer generated for the SolrCloud pods.
+// Unless overridden, the maxUnavailable mirrors the maxPodsUnavailable of the managed update strategy,
+// so that voluntary disruptions cannot take down more pods than a managed update would.
+// solrCloud: SolrCloud instance
+//
+// TODO: Use policy/v1 once k8s.io/api and controller-runtime are upgraded, k8s.io/api v0.20 only provides policy/v1beta1.
+func GeneratePodDisruptionBudget(solrCloud *solr.SolrCloud) *policyv1beta1.PodDisruptionBudget {
+	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	var annotations map[string]string
+
+	selectorLabels := solrCloud.SharedLabels()
+	selectorLabels["technology"] = solr.SolrTechnologyLabel
+
+	maxUnavailable := solrCloud.Spec.UpdateStrategy.ManagedUpdateOptions.MaxPodsUnavailable
+	customOptions := solrCloud.Spec.CustomSolrKubeOptions.PodDisruptionBudgetOptions
+	if nil != customOptions {
+		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
+		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
+		if customOptions.MaxUnavailable != nil {
+			maxUnavailable = customOptions.MaxUnavailable
+		}
+	}
+	labels, annotations = withCommonLabelsAndAnnotations(solrCloud, labels, annotations)
+
+	// Resolve the value the same way that managed updates do, since a PDB treats 0 as "no pods can be disrupted"
+	desiredPods := int(solrCloud.TotalReplicas())
+	maxUnavailablePods, _ := ResolveMaxPodsUnavailable(maxUnavailable, desiredPods)
+	resolvedMaxUnavailable := intstr.FromInt(maxUnavailablePods)
+
+	return &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        solrCloud.PodDisruptionBudgetName(),
+			Namespace:   solrCloud.GetNamespace(),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			},
+			MaxUnavailable: &resolvedMaxUnavailable,
+		},
+	}
+}
+
 // GenerateIngress returns a new Ingress pointer generated for the entire SolrCloud, pointing to all instances
 // solrCloud: SolrCloud instance
 // nodeStatuses: []SolrNodeStatus the nodeStatuses
 func GenerateIngress(solrCloud *solr.SolrCloud, nodeNames []string) (ingress *netv1.Ingress) {
 	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
 	var annotations map[string]string
+	var ingressClassName *string
 
 	customOptions := solrCloud.Spec.CustomSolrKubeOptions.IngressOptions
 	if nil != customOptions {
 		labels = MergeLabelsOrAnnotations(labels, customOptions.Labels)
 		annotations = MergeLabelsOrAnnotations(annotations, customOptions.Annotations)
+		ingressClassName = customOptions.IngressClassName
+	}
+	labels, annotations = withCommonLabelsAndAnnotations(solrCloud, labels, annotations)
+	// The ingress class annotation cannot be used alongside the ingressClassName
+	if ingressClassName != nil {
+		delete(annotations, IngressClassAnnotation)
 	}
 
 	extOpts := solrCloud.Spec.SolrAddressability.External
@@ -878,11 +1358,35 @@ func GenerateIngress(solrCloud *solr.SolrCloud, nodeNames []string) (ingress *ne
 		if annotations == nil {
 			annotations = make(map[string]string, 1)
 		}
-		_, ok := annotations["nginx.ingress.kubernetes.io/backend-protocol"]
+		_, ok := annotations[IngressBackendProtocolAnnotation]
 		if !ok {
-			annotations["nginx.ingress.kubernetes.io/backend-protocol"] = "HTTPS"
+			annotations[IngressBackendProtocolAnnotation] = "HTTPS"
+		}
+		if extOpts.IngressTLSTermination == nil {
+			ingressTLS = append(ingressTLS, netv1.IngressTLS{SecretName: solrCloud.Spec.SolrTLS.PKCS12Secret.Name})
+		}
+	}
+
+	// Terminate TLS with the given certificate, for all hosts exposed through the Ingress
+	if tlsTermination := extOpts.IngressTLSTermination; tlsTermination != nil {
+		hosts := make([]string, len(rules))
+		for i, rule := range rules {
+			hosts[i] = rule.Host
+		}
+		ingressTLS = append(ingressTLS, netv1.IngressTLS{Hosts: hosts, SecretName: tlsTermination.TLSSecret})
+
+		if issuerRef := tlsTermination.IssuerRef; issuerRef != nil {
+			if annotations == nil {
+				annotations = make(map[string]string, 1)
+			}
+			issuerAnnotation := CertManagerClusterIssuerAnnotation
+			if issuerRef.Kind == solr.CertManagerIssuerKindIssuer {
+				issuerAnnotation = CertManagerIssuerAnnotation
+			}
+			if _, ok := annotations[issuerAnnotation]; !ok {
+				annotations[issuerAnnotation] = issuerRef.Name
+			}
 		}
-		ingressTLS = append(ingressTLS, netv1.IngressTLS{SecretName: solrCloud.Spec.SolrTLS.PKCS12Secret.Name})
 	}
 
 	ingress = &netv1.Ingress{
@@ -893,13 +1397,565 @@ func GenerateIngress(solrCloud *solr.SolrCloud, nodeNames []string) (ingress *ne
 			Annotations: annotations,
 		},
 		Spec: netv1.IngressSpec{
-			Rules: rules,
-			TLS:   ingressTLS,
+			IngressClassName: ingressClassName,
+			Rules:            rules,
+			TLS:              ingressTLS,
 		},
 	}
 	return ingress
 }
 
+// ValidateIngressTLSTermination makes sure that the Ingress TLS termination mode agrees with the TLS settings of Solr
+func ValidateIngressTLSTermination(solrCloud *solr.SolrCloud) error {
+	extOpts := solrCloud.Spec.SolrAddressability.External
+	if extOpts == nil || extOpts.Method != solr.Ingress || extOpts.IngressTLSTermination == nil {
+		return nil
+	}
+	tlsTermination := extOpts.IngressTLSTermination
+	if tlsTermination.TLSSecret == "" {
+		return fmt.Errorf("a tlsSecret must be provided to terminate TLS at the Ingress")
+	}
+	switch tlsTermination.Mode {
+	case solr.IngressTLSTerminationEdge:
+		if solrCloud.Spec.SolrTLS != nil {
+			return fmt.Errorf("the %s ingressTLSTermination mode cannot be used when solrTLS is configured, use %s instead", solr.IngressTLSTerminationEdge, solr.IngressTLSTerminationReencrypt)
+		}
+	case solr.IngressTLSTerminationReencrypt:
+		if solrCloud.Spec.SolrTLS == nil {
+			return fmt.Errorf("the %s ingressTLSTermination mode requires solrTLS to be configured, use %s instead", solr.IngressTLSTerminationReencrypt, solr.IngressTLSTerminationEdge)
+		}
+	default:
+		return fmt.Errorf("unknown ingressTLSTermination mode: %s", tlsTermination.Mode)
+	}
+	if issuerRef := tlsTermination.IssuerRef; issuerRef != nil {
+		if issuerRef.Name == "" {
+			return fmt.Errorf("a name must be provided for the ingressTLSTermination issuerRef")
+		}
+		switch issuerRef.Kind {
+		case solr.CertManagerIssuerKindIssuer:
+			if issuerRef.Namespace != "" && issuerRef.Namespace != solrCloud.Namespace {
+				return fmt.Errorf("the %s %s/%s cannot be used, since cert-manager only uses Issuers in the namespace of the Ingress (%s), use a %s instead", solr.CertManagerIssuerKindIssuer, issuerRef.Namespace, issuerRef.Name, solrCloud.Namespace, solr.CertManagerIssuerKindClusterIssuer)
+			}
+		case solr.CertManagerIssuerKindClusterIssuer:
+			if issuerRef.Namespace != "" {
+				return fmt.Errorf("a namespace cannot be provided for the %s %s, since ClusterIssuers are not namespaced", solr.CertManagerIssuerKindClusterIssuer, issuerRef.Name)
+			}
+		default:
+			return fmt.Errorf("unknown ingressTLSTermination issuerRef kind: %s", issuerRef.Kind)
+		}
+	}
+	return nil
+}
+
+// ValidateContextPath makes sure that Solr can be served under the custom context path of the SolrCloud
+func ValidateContextPath(solrCloud *solr.SolrCloud) error {
+	contextPath := solrCloud.Spec.SolrAddressability.ContextPath
+	if contextPath == "" {
+		return nil
+	}
+	if !strings.HasPrefix(contextPath, "/") {
+		return fmt.Errorf("solrAddressability.contextPath must start with \"/\", got \"%s\"", contextPath)
+	}
+	if contextPath == "/" || strings.HasSuffix(contextPath, "/") {
+		return fmt.Errorf("solrAddressability.contextPath cannot be \"/\" or end with \"/\", got \"%s\"", contextPath)
+	}
+	if strings.ContainsAny(contextPath, " ?#") {
+		return fmt.Errorf("solrAddressability.contextPath must be a plain URL path, got \"%s\"", contextPath)
+	}
+	return nil
+}
+
+// ValidateSolrPodPort makes sure that Solr can listen on the podPort of the SolrCloud, without colliding with the ports of the sidecar containers
+func ValidateSolrPodPort(solrCloud *solr.SolrCloud) error {
+	podPort := solrCloud.Spec.SolrAddressability.PodPort
+	if podPort == 0 {
+		podPort = solr.DefaultSolrPodPort
+	}
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		return nil
+	}
+	return ValidateSidecarPorts(map[int]string{podPort: SolrNodeContainer}, podOptions.SidecarContainers)
+}
+
+// ValidateExternalAddressability makes sure that the external addressability options of the SolrCloud can be used together.
+// Combinations that the defaulting of the SolrCloud corrects, such as useExternalAddress with hideNodes, are not rejected.
+func ValidateExternalAddressability(solrCloud *solr.SolrCloud) error {
+	extOpts := solrCloud.Spec.SolrAddressability.External
+	if extOpts == nil {
+		return nil
+	}
+	if extOpts.IngressTLSTermination != nil && extOpts.Method != solr.Ingress {
+		return fmt.Errorf("solrAddressability.external.ingressTLSTermination can only be used with the %s method", solr.Ingress)
+	}
+	if extOpts.DomainName == "" {
+		switch extOpts.Method {
+		case solr.Ingress, solr.ExternalDNS:
+			return fmt.Errorf("solrAddressability.external.domainName is required for the %s method", extOpts.Method)
+		case solr.LoadBalancer:
+			// The external address is not used when the nodes are hidden, see ExternalAddressability.withDefaults()
+			if extOpts.UseExternalAddress && !extOpts.HideNodes {
+				return fmt.Errorf("solrAddressability.external.domainName is required to use the external address with the %s method", extOpts.Method)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSolrTLS makes sure that the keystore of the SolrCloud's TLS options can be found,
+// and that the Solr Operator has a client certificate to present if Solr requires one, either its own or one for the SolrCloud
+func ValidateSolrTLS(solrCloud *solr.SolrCloud) error {
+	tls := solrCloud.Spec.SolrTLS
+	if tls == nil {
+		return nil
+	}
+	if tls.PKCS12Secret == nil || tls.PKCS12Secret.Name == "" {
+		return fmt.Errorf("solrTLS.pkcs12Secret is required to enable TLS")
+	}
+	if tls.KeyStorePasswordSecret == nil || tls.KeyStorePasswordSecret.Name == "" {
+		return fmt.Errorf("solrTLS.keyStorePasswordSecret is required to enable TLS")
+	}
+	if tls.ClientAuth == solr.Need && tls.ClientCertSecret == "" && !solr_api.HasOperatorClientCert() {
+		return fmt.Errorf("solrTLS.clientCertSecret is required when solrTLS.clientAuth is %s, since the Solr Operator must present a client certificate to call Solr", solr.Need)
+	}
+	return nil
+}
+
+// NeedsPkcs12InitContainer determines whether the PKCS12 keystore has to be generated from the PEM encoded certificate and key in the TLS secret.
+// TLS secrets that are not managed by cert-manager, such as ones synced from Vault, often only hold the PEM encoded files.
+// The keys of the PEM encoded files can be overridden through solrTLS.pemKeys.
+// An error is returned if the secret holds neither the keystore nor the files needed to generate it.
+func NeedsPkcs12InitContainer(tls *solr.SolrTLSOptions, tlsSecret *corev1.Secret) (bool, error) {
+	if _, ok := tlsSecret.Data[tls.PKCS12Secret.Key]; ok {
+		return false, nil
+	}
+	var missingKeys []string
+	for _, key := range []string{tls.PEMCertKey(), tls.PEMPrivateKeyKey()} {
+		if _, ok := tlsSecret.Data[key]; !ok {
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	if len(missingKeys) > 0 {
+		return false, fmt.Errorf("TLS secret %s contains neither the %s keystore, nor the %s keys needed to generate it",
+			tlsSecret.Name, tls.PKCS12Secret.Key, strings.Join(missingKeys, " and "))
+	}
+	return true, nil
+}
+
+// TLSSecretMd5 returns a hash of the certificate in the TLS secret, so that the pods can be restarted when it is renewed.
+// If the secret only holds a PKCS12 keystore, then the keystore is hashed instead.
+func TLSSecretMd5(tls *solr.SolrTLSOptions, tlsSecret *corev1.Secret) string {
+	if tlsCertBytes, ok := tlsSecret.Data[tls.PEMCertKey()]; ok {
+		return fmt.Sprintf("%x", md5.Sum(tlsCertBytes))
+	}
+	return fmt.Sprintf("%x", md5.Sum(tlsSecret.Data[tls.PKCS12Secret.Key]))
+}
+
+// ValidateProvidedSolrXml makes sure that a solr.xml provided through a user ConfigMap can be used by the SolrCloud
+func ValidateProvidedSolrXml(solrCloud *solr.SolrCloud, configMapName string, solrXml string) error {
+	if !strings.Contains(solrXml, "${hostPort:") {
+		return fmt.Errorf("Custom solr.xml in ConfigMap %s must contain a placeholder for the 'hostPort' variable, such as <int name=\"hostPort\">${hostPort:80}</int>",
+			configMapName)
+	}
+	if len(solrCloud.Spec.StorageOptions.BackupRepositories) > 0 && !strings.Contains(solrXml, "<backup>") {
+		return fmt.Errorf("Custom solr.xml in ConfigMap %s must contain a <backup> section defining the backupRepositories of the SolrCloud",
+			configMapName)
+	}
+	if solrCloud.Spec.SolrAddressability.ContextPath != "" && !strings.Contains(solrXml, "${hostContext:") {
+		return fmt.Errorf("Custom solr.xml in ConfigMap %s must contain a placeholder for the 'hostContext' variable to use the contextPath of the SolrCloud, such as <str name=\"hostContext\">${hostContext:solr}</str>",
+			configMapName)
+	}
+	return nil
+}
+
+// ValidateNodePools makes sure that every node pool of the SolrCloud can be given its own StatefulSet
+func ValidateNodePools(solrCloud *solr.SolrCloud) error {
+	poolNames := map[string]bool{}
+	for _, pool := range solrCloud.Spec.NodePools {
+		if poolNames[pool.Name] {
+			return fmt.Errorf("the nodePool name \"%s\" is used by more than one nodePool", pool.Name)
+		}
+		poolNames[pool.Name] = true
+		if pool.Replicas < 0 {
+			return fmt.Errorf("the replicas of nodePool \"%s\" cannot be negative, got %d", pool.Name, pool.Replicas)
+		}
+	}
+	return nil
+}
+
+// ValidateAdditionalConfigMaps makes sure that the additional user-provided ConfigMaps can be mounted into the Solr container.
+func ValidateAdditionalConfigMaps(solrCloud *solr.SolrCloud) error {
+	configMapOptions := solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions
+	if configMapOptions == nil {
+		return nil
+	}
+	mountPaths := map[string]bool{"/var/solr": true, "/var/solr/data": true}
+	for _, additionalConfigMap := range configMapOptions.AdditionalConfigMaps {
+		if additionalConfigMap.Name == "" {
+			return fmt.Errorf("additional ConfigMaps must have a name")
+		}
+		if !path.IsAbs(additionalConfigMap.MountPath) {
+			return fmt.Errorf("the mountPath \"%s\" of additional ConfigMap %s must be an absolute path", additionalConfigMap.MountPath, additionalConfigMap.Name)
+		}
+		mountPath := path.Clean(additionalConfigMap.MountPath)
+		if mountPaths[mountPath] {
+			return fmt.Errorf("the mountPath \"%s\" of additional ConfigMap %s is reserved or used by another additional ConfigMap", additionalConfigMap.MountPath, additionalConfigMap.Name)
+		}
+		mountPaths[mountPath] = true
+	}
+	return nil
+}
+
+// failingContainerReasons are the reasons that a container can be waiting for, which it will not recover from without a change to the pod spec or the image
+var failingContainerReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CrashLoopBackOff":           true,
+	"CreateContainerConfigError": true,
+}
+
+// FailingPodContainer describes the first container of the pod that is failing to start, such as because its image cannot be pulled.
+// An empty string is returned when no container of the pod is failing.
+// The message from the container status is left out, since it changes with every back-off and the description is used in status conditions.
+func FailingPodContainer(pod *corev1.Pod) string {
+	containerStatuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, containerStatus := range containerStatuses {
+		if waiting := containerStatus.State.Waiting; waiting != nil && failingContainerReasons[waiting.Reason] {
+			return fmt.Sprintf("pod %s failing: %s (container %s, image %s)", pod.Name, waiting.Reason, containerStatus.Name, containerStatus.Image)
+		}
+	}
+	return ""
+}
+
+// ValidateSolrCloudImages makes sure that the images used by the SolrCloud are valid.
+func ValidateSolrCloudImages(solrCloud *solr.SolrCloud) error {
+	if err := ValidateContainerImage("solrImage", solrCloud.Spec.SolrImage); err != nil {
+		return err
+	}
+	return ValidateContainerImage("busyBoxImage", solrCloud.Spec.BusyBoxImage)
+}
+
+// ValidateSolrLogXml makes sure that the SolrCloud does not provide a custom log4j2.xml both inline and through a separate ConfigMap.
+func ValidateSolrLogXml(solrCloud *solr.SolrCloud) error {
+	configMapOptions := solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions
+	if solrCloud.Spec.SolrLogXml != "" && configMapOptions != nil && configMapOptions.ProvidedLogXmlConfigMap != "" {
+		return fmt.Errorf("solrLogXml cannot be used alongside the providedLogXmlConfigMap %s, only one custom log4j2.xml can be provided", configMapOptions.ProvidedLogXmlConfigMap)
+	}
+	return nil
+}
+
+// MountedVolumeConfigMapsAndSecrets returns the names of the ConfigMaps and Secrets in the custom volumes that are mounted into the Solr container,
+// excluding the volumes whose changes should not restart the Solr pods.
+func MountedVolumeConfigMapsAndSecrets(solrCloud *solr.SolrCloud) (configMaps []string, secrets []string) {
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		return nil, nil
+	}
+	foundConfigMaps := map[string]bool{}
+	foundSecrets := map[string]bool{}
+	addConfigMap := func(name string) {
+		if name != "" && !foundConfigMaps[name] {
+			foundConfigMaps[name] = true
+			configMaps = append(configMaps, name)
+		}
+	}
+	addSecret := func(name string) {
+		if name != "" && !foundSecrets[name] {
+			foundSecrets[name] = true
+			secrets = append(secrets, name)
+		}
+	}
+	for _, volume := range podOptions.Volumes {
+		if volume.DefaultContainerMount == nil || volume.SkipRestartOnChange {
+			continue
+		}
+		if volume.Source.ConfigMap != nil {
+			addConfigMap(volume.Source.ConfigMap.Name)
+		}
+		if volume.Source.Secret != nil {
+			addSecret(volume.Source.Secret.SecretName)
+		}
+		if volume.Source.Projected != nil {
+			for _, projection := range volume.Source.Projected.Sources {
+				if projection.ConfigMap != nil {
+					addConfigMap(projection.ConfigMap.Name)
+				}
+				if projection.Secret != nil {
+					addSecret(projection.Secret.Name)
+				}
+			}
+		}
+	}
+	return configMaps, secrets
+}
+
+// RequiredEnvFromConfigMapsAndSecrets returns the names of the ConfigMaps and Secrets that the Solr container loads environment variables from.
+// Sources that are marked as optional are not returned, since the Solr pods can start without them.
+func RequiredEnvFromConfigMapsAndSecrets(solrCloud *solr.SolrCloud) (configMaps []string, secrets []string) {
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		return nil, nil
+	}
+	for _, source := range podOptions.EnvFrom {
+		if source.ConfigMapRef != nil && (source.ConfigMapRef.Optional == nil || !*source.ConfigMapRef.Optional) {
+			configMaps = append(configMaps, source.ConfigMapRef.Name)
+		}
+		if source.SecretRef != nil && (source.SecretRef.Optional == nil || !*source.SecretRef.Optional) {
+			secrets = append(secrets, source.SecretRef.Name)
+		}
+	}
+	return configMaps, secrets
+}
+
+// MountedConfigMd5 returns an MD5 of the contents of the given ConfigMaps and Secrets, in order.
+func MountedConfigMd5(configMaps []*corev1.ConfigMap, secrets []*corev1.Secret) string {
+	hash := md5.New()
+	writeData := func(kind string, name string, data map[string][]byte) {
+		hash.Write([]byte(kind + "/" + name + "\n"))
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			hash.Write([]byte(key + "="))
+			hash.Write(data[key])
+			hash.Write([]byte("\n"))
+		}
+	}
+	for _, configMap := range configMaps {
+		data := make(map[string][]byte, len(configMap.Data)+len(configMap.BinaryData))
+		for key, value := range configMap.Data {
+			data[key] = []byte(value)
+		}
+		for key, value := range configMap.BinaryData {
+			data[key] = value
+		}
+		writeData("ConfigMap", configMap.Name, data)
+	}
+	for _, secret := range secrets {
+		writeData("Secret", secret.Name, secret.Data)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// ReservedSolrSystemProperties returns the names of the Java system properties that the operator sets for the Solr pods of the SolrCloud,
+// either directly in SOLR_OPTS or through the env vars that the Solr start script turns into system properties.
+func ReservedSolrSystemProperties(solrCloud *solr.SolrCloud) (properties []string) {
+	properties = []string{"hostPort"}
+
+	if solrCloud.Spec.SolrAddressability.ContextPath != "" {
+		properties = append(properties, "hostContext")
+	}
+
+	zkRef := solrCloud.Spec.ZookeeperRef
+	if zkRef == nil {
+		zkRef = &solr.ZookeeperRef{}
+	}
+
+	if allACL, readOnlyACL := zkRef.GetACLs(); allACL != nil || readOnlyACL != nil {
+		properties = append(properties, "zkACLProvider", "zkCredentialsProvider", "zkDigestUsername", "zkDigestPassword", "zkDigestReadonlyUsername", "zkDigestReadonlyPassword")
+	}
+
+	if zkRef.GetTLS() != nil {
+		properties = append(properties, "zookeeper.client.secure", "zookeeper.clientCnxnSocket",
+			"zookeeper.ssl.keyStore.location", "zookeeper.ssl.keyStore.type", "zookeeper.ssl.keyStore.password",
+			"zookeeper.ssl.trustStore.location", "zookeeper.ssl.trustStore.type", "zookeeper.ssl.trustStore.password")
+	}
+
+	if solrCloud.Spec.SolrTLS != nil {
+		properties = append(properties, "solr.jetty.https.port", "solr.jetty.keystore", "solr.jetty.keystore.password", "solr.jetty.keystore.type",
+			"solr.jetty.truststore", "solr.jetty.truststore.password", "solr.jetty.truststore.type",
+			"solr.jetty.ssl.needClientAuth", "solr.jetty.ssl.wantClientAuth", "solr.ssl.checkPeerName",
+			"javax.net.ssl.keyStore", "javax.net.ssl.keyStorePassword", "javax.net.ssl.keyStoreType",
+			"javax.net.ssl.trustStore", "javax.net.ssl.trustStorePassword", "javax.net.ssl.trustStoreType")
+	}
+
+	if configMapOptions := solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions; solrCloud.Spec.SolrLogXml != "" || (configMapOptions != nil && configMapOptions.ProvidedLogXmlConfigMap != "") {
+		properties = append(properties, "log4j.configurationFile")
+	}
+
+	return properties
+}
+
+// ValidateAdditionalJavaOpts makes sure that the additionalJavaOpts of the SolrCloud do not set any of the system properties that the operator manages.
+func ValidateAdditionalJavaOpts(solrCloud *solr.SolrCloud) error {
+	if len(solrCloud.Spec.AdditionalJavaOpts) == 0 {
+		return nil
+	}
+	reservedProperties := map[string]bool{}
+	for _, property := range ReservedSolrSystemProperties(solrCloud) {
+		reservedProperties[property] = true
+	}
+	for _, javaOpt := range solrCloud.Spec.AdditionalJavaOpts {
+		if !strings.HasPrefix(string(javaOpt), "-D") {
+			continue
+		}
+		property := strings.SplitN(strings.TrimPrefix(string(javaOpt), "-D"), "=", 2)[0]
+		if reservedProperties[property] {
+			return fmt.Errorf("the additional Java option \"%s\" sets the system property \"%s\", which is managed by the Solr Operator", javaOpt, property)
+		}
+	}
+	return nil
+}
+
+// reservedSolrCloudContainerNames are the names of the containers and init containers that the operator adds to Solr pods
+var reservedSolrCloudContainerNames = []string{SolrNodeContainer, "cp-solr-xml", ZookeeperWaitInitContainer, "setup-zk", "gen-pkcs12-keystore"}
+
+// ValidateCustomContainers makes sure that the user-provided sidecar and init containers can be added to the Solr pods.
+// Container names must be unique within a pod, and cannot collide with the containers that the operator manages.
+func ValidateCustomContainers(solrCloud *solr.SolrCloud) error {
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		return nil
+	}
+	containerNames := map[string]bool{}
+	for _, name := range reservedSolrCloudContainerNames {
+		containerNames[name] = true
+	}
+	customContainers := append(append([]corev1.Container{}, podOptions.InitContainers...), podOptions.SidecarContainers...)
+	for _, container := range customContainers {
+		if containerNames[container.Name] {
+			return fmt.Errorf("the container name \"%s\" is reserved or used by another container in the SolrCloud pod", container.Name)
+		}
+		containerNames[container.Name] = true
+	}
+	return nil
+}
+
+// ValidateSolrLifecycleHooks makes sure that the custom lifecycle hooks of the Solr container can be run, alongside the hooks the operator manages
+func ValidateSolrLifecycleHooks(solrCloud *solr.SolrCloud) error {
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if err := ValidateLifecycleHooks(podOptions); err != nil {
+		return err
+	}
+	if podOptions != nil && podOptions.PostStart != nil && podOptions.PostStart.Exec == nil &&
+		(solrCloud.Spec.ZookeeperRef == nil || !solrCloud.Spec.ZookeeperRef.WaitForZookeeper) {
+		return fmt.Errorf("the postStart hook must be an exec handler unless zookeeperRef.waitForZookeeper is enabled, since the Zookeeper chroot is created in the postStart hook otherwise")
+	}
+	return nil
+}
+
+// generateSolrPVC generates a volumeClaimTemplate for the Solr pods from the given template, using the defaultName if the template has no name.
+// The PVCs are labeled with the type of storage they provide, so that the Solr Operator can find them.
+func generateSolrPVC(solrCloud *solr.SolrCloud, template *solr.PersistentVolumeClaimTemplate, defaultName string, storageType string) corev1.PersistentVolumeClaim {
+	pvc := template.DeepCopy()
+
+	// Set the default name of the pvc
+	if pvc.ObjectMeta.Name == "" {
+		pvc.ObjectMeta.Name = defaultName
+	}
+
+	// Set some defaults in the PVC Spec
+	if len(pvc.Spec.AccessModes) == 0 {
+		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{
+			corev1.ReadWriteOnce,
+		}
+	}
+	if pvc.Spec.VolumeMode == nil {
+		temp := corev1.PersistentVolumeFilesystem
+		pvc.Spec.VolumeMode = &temp
+	}
+
+	//  Add internally-used labels.
+	internalLabels := map[string]string{
+		SolrPVCTechnologyLabel: SolrCloudPVCTechnology,
+		SolrPVCStorageLabel:    storageType,
+		SolrPVCInstanceLabel:   solrCloud.Name,
+	}
+	pvc.ObjectMeta.Labels = MergeLabelsOrAnnotations(internalLabels, pvc.ObjectMeta.Labels)
+
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        pvc.ObjectMeta.Name,
+			Labels:      pvc.ObjectMeta.Labels,
+			Annotations: pvc.ObjectMeta.Annotations,
+		},
+		Spec: pvc.Spec,
+	}
+}
+
+// RequestedStorageClassNames returns the names of the StorageClasses that the PVC templates of the SolrCloud request, without duplicates.
+// PVC templates that do not request a StorageClass use the default StorageClass of the cluster, if there is one.
+func RequestedStorageClassNames(solrCloud *solr.SolrCloud) (storageClassNames []string) {
+	var templates []*solr.PersistentVolumeClaimTemplate
+	if solrCloud.UsesPersistentStorage() {
+		templates = append(templates, &solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate)
+	}
+	if logStorage := solrCloud.Spec.StorageOptions.LogStorage; logStorage != nil && logStorage.PersistentVolumeClaimTemplate != nil {
+		templates = append(templates, logStorage.PersistentVolumeClaimTemplate)
+	}
+	for _, template := range templates {
+		if template.Spec.StorageClassName != nil && *template.Spec.StorageClassName != "" && !ContainsString(storageClassNames, *template.Spec.StorageClassName) {
+			storageClassNames = append(storageClassNames, *template.Spec.StorageClassName)
+		}
+	}
+	return storageClassNames
+}
+
+// SolrLogsVolumeName returns the name of the volume that the Solr logs are stored in, when the SolrCloud uses separate log storage.
+func SolrLogsVolumeName(solrCloud *solr.SolrCloud) string {
+	logStorage := solrCloud.Spec.StorageOptions.LogStorage
+	if logStorage != nil && logStorage.PersistentVolumeClaimTemplate != nil && logStorage.PersistentVolumeClaimTemplate.ObjectMeta.Name != "" {
+		return logStorage.PersistentVolumeClaimTemplate.ObjectMeta.Name
+	}
+	return SolrCloudPVCLogStorage
+}
+
+// solrDataVolumeName returns the name of the volume that the Solr data is stored in.
+func solrDataVolumeName(solrCloud *solr.SolrCloud) string {
+	if solrCloud.UsesPersistentStorage() && solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.ObjectMeta.Name != "" {
+		return solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.ObjectMeta.Name
+	}
+	return SolrCloudPVCDataStorage
+}
+
+// ValidateLogStorage makes sure that the separate volume for the Solr logs does not use the name of the Solr data volume.
+func ValidateLogStorage(solrCloud *solr.SolrCloud) error {
+	if solrCloud.Spec.StorageOptions.LogStorage == nil {
+		return nil
+	}
+	if name := SolrLogsVolumeName(solrCloud); name == solrDataVolumeName(solrCloud) {
+		return fmt.Errorf("the logs volume cannot be named \"%s\", since the Solr data volume uses that name", name)
+	}
+	return nil
+}
+
+// reservedSolrCloudVolumeNames are the names of the volumes that the operator can add to Solr pods, besides the data volume
+var reservedSolrCloudVolumeNames = []string{"solr-xml", userProvidedConfigMapVolumeName(LogXmlFile), BackupRestoreVolume, "keystore", "truststore", "pkcs12", "zk-keystore", "zk-truststore"}
+
+// reservedSolrCloudVolumeNamePrefixes are the prefixes of the numbered volumes that the operator can add to Solr pods
+var reservedSolrCloudVolumeNamePrefixes = []string{"additional-configmap-", GCSCredentialsVolumePrefix}
+
+// ValidateCustomVolumes makes sure that the user-provided volumes can be added to the Solr pods.
+// Volume names must be unique within a pod, and cannot collide with the volumes that the operator manages.
+func ValidateCustomVolumes(solrCloud *solr.SolrCloud) error {
+	podOptions := solrCloud.Spec.CustomSolrKubeOptions.PodOptions
+	if podOptions == nil {
+		return nil
+	}
+	volumeNames := map[string]bool{solrDataVolumeName(solrCloud): true}
+	if solrCloud.Spec.StorageOptions.LogStorage != nil {
+		volumeNames[SolrLogsVolumeName(solrCloud)] = true
+	}
+	for _, name := range reservedSolrCloudVolumeNames {
+		volumeNames[name] = true
+	}
+	for _, volume := range podOptions.Volumes {
+		if volumeNames[volume.Name] {
+			return fmt.Errorf("the volume name \"%s\" is reserved or used by another volume in the SolrCloud pod", volume.Name)
+		}
+		for _, prefix := range reservedSolrCloudVolumeNamePrefixes {
+			if strings.HasPrefix(volume.Name, prefix) {
+				return fmt.Errorf("the volume name \"%s\" cannot start with \"%s\", which is reserved for volumes managed by the Solr Operator", volume.Name, prefix)
+			}
+		}
+		volumeNames[volume.Name] = true
+	}
+	return nil
+}
+
 // CreateSolrIngressRules returns all applicable ingress rules for a cloud.
 // solrCloud: SolrCloud instance
 // nodeNames: the names for each of the solr pods
@@ -925,6 +1981,7 @@ func CreateSolrIngressRules(solrCloud *solr.SolrCloud, nodeNames []string, domai
 // solrCloud: SolrCloud instance
 // domainName: string Domain for the ingress rule to use
 func CreateCommonIngressRule(solrCloud *solr.SolrCloud, domainName string) (ingressRule netv1.IngressRule) {
+	// Only route requests under a custom context path to Solr, otherwise the whole host is routed to Solr
 	pathType := netv1.PathTypeImplementationSpecific
 	ingressRule = netv1.IngressRule{
 		Host: solrCloud.ExternalCommonUrl(domainName, false),
@@ -932,6 +1989,7 @@ func CreateCommonIngressRule(solrCloud *solr.SolrCloud, domainName string) (ingr
 			HTTP: &netv1.HTTPIngressRuleValue{
 				Paths: []netv1.HTTPIngressPath{
 					{
+						Path: solrCloud.Spec.SolrAddressability.ContextPath,
 						Backend: netv1.IngressBackend{
 							ServiceName: solrCloud.CommonServiceName(),
 							ServicePort: intstr.FromInt(solrCloud.Spec.SolrAddressability.CommonServicePort),
@@ -957,6 +2015,7 @@ func CreateNodeIngressRule(solrCloud *solr.SolrCloud, nodeName string, domainNam
 			HTTP: &netv1.HTTPIngressRuleValue{
 				Paths: []netv1.HTTPIngressPath{
 					{
+						Path: solrCloud.Spec.SolrAddressability.ContextPath,
 						Backend: netv1.IngressBackend{
 							ServiceName: nodeName,
 							ServicePort: intstr.FromInt(solrCloud.NodePort()),
@@ -970,6 +2029,62 @@ func CreateNodeIngressRule(solrCloud *solr.SolrCloud, nodeName string, domainNam
 	return ingressRule
 }
 
+// mergeCustomEnvVarValue merges the value of the custom env var with the given name in front of the value from the SolrCloud spec.
+// JVM options that are given later take precedence, so the options from the spec win over the same options in the custom env var.
+// The custom env var is removed from the returned env vars, unless its value comes from a source that cannot be merged.
+func mergeCustomEnvVarValue(name string, specValue string, customEnvVars []corev1.EnvVar) (value string, remainingEnvVars []corev1.EnvVar) {
+	values := make([]string, 0, 2)
+	remainingEnvVars = make([]corev1.EnvVar, 0, len(customEnvVars))
+	for _, envVar := range customEnvVars {
+		if envVar.Name == name && envVar.ValueFrom == nil {
+			if envVar.Value != "" {
+				values = append(values, envVar.Value)
+			}
+		} else {
+			remainingEnvVars = append(remainingEnvVars, envVar)
+		}
+	}
+	if specValue != "" {
+		values = append(values, specValue)
+	}
+	return strings.Join(values, " "), remainingEnvVars
+}
+
+// generateZKWaitInitContainer creates an initContainer that blocks until the Zookeeper ensemble responds,
+// and then creates the chRoot if it does not already exist.
+func generateZKWaitInitContainer(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus) corev1.Container {
+	envVars, zkSolrOpt, hasChroot := createZkConnectionEnvVars(solrCloud, solrCloudStatus)
+	if zkSolrOpt != "" {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "SOLR_OPTS",
+			Value: zkSolrOpt,
+		})
+	}
+
+	cmd := "until solr zk ls / -z ${ZK_SERVER} > /dev/null; do echo \"waiting for Zookeeper at ${ZK_SERVER}\"; sleep " + strconv.Itoa(ZookeeperWaitIntervalSeconds) + "; done"
+	if hasChroot {
+		cmd += "; solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}"
+	}
+
+	// The init container must connect to ZK over TLS as well, if it is enabled
+	var volumeMounts []corev1.VolumeMount
+	if zkTLS := solrCloud.Spec.ZookeeperRef.GetTLS(); zkTLS != nil {
+		_, _, volumeMounts = ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+	}
+	return corev1.Container{
+		Name:                     ZookeeperWaitInitContainer,
+		Image:                    solrCloud.Spec.SolrImage.ToImageName(),
+		ImagePullPolicy:          solrCloud.Spec.SolrImage.PullPolicy,
+		TerminationMessagePath:   "/dev/termination-log",
+		TerminationMessagePolicy: "File",
+		Command:                  []string{"sh", "-c", cmd},
+		Env:                      envVars,
+		VolumeMounts:             volumeMounts,
+		Resources:                initContainerResources(solrCloud.Spec.CustomSolrKubeOptions.PodOptions),
+		SecurityContext:          containerSecurityContext(solrCloud.Spec.CustomSolrKubeOptions.PodOptions),
+	}
+}
+
 // TODO: Have this replace the postStart hook for creating the chroot
 func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStatus *solr.SolrCloudStatus, reconcileConfigInfo map[string]string) (bool, corev1.Container) {
 	allSolrOpts := make([]string, 0)
@@ -984,6 +2099,10 @@ func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStat
 		allSolrOpts = append(allSolrOpts, solrCloud.Spec.SolrOpts)
 	}
 
+	for _, javaOpt := range solrCloud.Spec.AdditionalJavaOpts {
+		allSolrOpts = append(allSolrOpts, string(javaOpt))
+	}
+
 	// Add SOLR_OPTS last, so that it can use values from all of the other ENV_VARS
 	if len(allSolrOpts) > 0 {
 		envVars = append(envVars, corev1.EnvVar{
@@ -994,7 +2113,7 @@ func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStat
 
 	cmd := ""
 
-	if solrCloud.Spec.SolrTLS != nil {
+	if solrCloud.Spec.SolrTLS != nil && solrCloud.Spec.SolrTLS.ManagesUrlScheme() {
 		cmd = "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}" +
 			"; /opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd clusterprop -name urlScheme -val https" +
 			"; /opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd get /clusterprops.json;"
@@ -1002,9 +2121,7 @@ func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStat
 
 	if reconcileConfigInfo[SecurityJsonFile] != "" {
 		envVars = append(envVars, corev1.EnvVar{Name: "SECURITY_JSON", ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{Name: solrCloud.SecurityBootstrapSecretName()},
-				Key:                  SecurityJsonFile}}})
+			SecretKeyRef: securityJsonSecretKeySelector(solrCloud)}})
 
 		if cmd == "" {
 			cmd += "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}; "
@@ -1014,6 +2131,11 @@ func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStat
 	}
 
 	if cmd != "" {
+		// The init container must connect to ZK over TLS as well, if it is enabled
+		var volumeMounts []corev1.VolumeMount
+		if zkTLS := solrCloud.Spec.ZookeeperRef.GetTLS(); zkTLS != nil {
+			_, _, volumeMounts = ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+		}
 		return true, corev1.Container{
 			Name:                     "setup-zk",
 			Image:                    solrCloud.Spec.SolrImage.ToImageName(),
@@ -1022,6 +2144,9 @@ func generateZKInteractionInitContainer(solrCloud *solr.SolrCloud, solrCloudStat
 			TerminationMessagePolicy: "File",
 			Command:                  []string{"sh", "-c", cmd},
 			Env:                      envVars,
+			VolumeMounts:             volumeMounts,
+			Resources:                initContainerResources(solrCloud.Spec.CustomSolrKubeOptions.PodOptions),
+			SecurityContext:          containerSecurityContext(solrCloud.Spec.CustomSolrKubeOptions.PodOptions),
 		}
 	}
 
@@ -1067,6 +2192,9 @@ func TLSEnvVars(opts *solr.SolrTLSOptions, createPkcs12InitContainer bool) []cor
 		if opts.TrustStorePasswordSecret != nil {
 			truststorePassFrom = &corev1.EnvVarSource{SecretKeyRef: opts.TrustStorePasswordSecret}
 		}
+	} else if createPkcs12InitContainer {
+		// the initContainer also builds a truststore from the CA cert in the TLS secret
+		truststoreFile = keystorePath + "/" + Pkcs12TruststoreFile
 	}
 
 	envVars := []corev1.EnvVar{
@@ -1185,7 +2313,7 @@ func tlsVolumes(opts *solr.SolrTLSOptions, createPkcs12InitContainer bool) []cor
 	return vols
 }
 
-func generatePkcs12InitContainer(opts *solr.SolrTLSOptions, imageName string, imagePullPolicy corev1.PullPolicy) corev1.Container {
+func generatePkcs12InitContainer(opts *solr.SolrTLSOptions, imageName string, imagePullPolicy corev1.PullPolicy, securityContext *corev1.SecurityContext, resources corev1.ResourceRequirements) corev1.Container {
 	// get the keystore password from the env for generating the keystore using openssl
 	passwordValueFrom := &corev1.EnvVarSource{SecretKeyRef: opts.KeyStorePasswordSecret}
 	envVars := []corev1.EnvVar{
@@ -1195,9 +2323,31 @@ func generatePkcs12InitContainer(opts *solr.SolrTLSOptions, imageName string, im
 		},
 	}
 
-	cmd := "openssl pkcs12 -export -in " + DefaultKeyStorePath + "/" + TLSCertKey + " -in " + DefaultKeyStorePath +
-		"/ca.crt -inkey " + DefaultKeyStorePath + "/tls.key -out " + DefaultKeyStorePath +
-		"/pkcs12/" + Pkcs12KeystoreFile + " -passout pass:${SOLR_SSL_KEY_STORE_PASSWORD}"
+	certFile := DefaultKeyStorePath + "/" + opts.PEMCertKey()
+	keyFile := DefaultKeyStorePath + "/" + opts.PEMPrivateKeyKey()
+	caFile := DefaultKeyStorePath + "/" + opts.PEMCAKey()
+
+	// The CA bundle is optional, since TLS secrets that are not issued by cert-manager do not always include it
+	cmd := "openssl pkcs12 -export -in " + certFile + " $([ -f " + caFile + " ] && echo -certfile " + caFile + ")" +
+		" -inkey " + keyFile + " -out " + DefaultWritableKeyStorePath + "/" + Pkcs12KeystoreFile +
+		" -passout pass:${SOLR_SSL_KEY_STORE_PASSWORD}"
+
+	// Java only trusts certs stored as trusted cert entries, so convert the CA bundle into a separate pkcs12 truststore.
+	// If the TLS secret has no CA bundle, then fall back to using the keystore as the truststore.
+	if opts.TrustStoreSecret == nil {
+		truststoreFile := DefaultWritableKeyStorePath + "/" + Pkcs12TruststoreFile
+		caCertPrefix := DefaultWritableKeyStorePath + "/ca-"
+		// The init container runs again when the pod sandbox is restarted, and the emptyDir still holds the previous truststore,
+		// so always start from scratch. keytool only imports the first cert of a file, so every cert of the bundle is split out
+		// and imported under its own alias (ca-0, ca-1, ...).
+		cmd += " && rm -f " + truststoreFile + " " + caCertPrefix + "*.crt && if [ -f " + caFile + " ]; then " +
+			"awk '/-----BEGIN CERTIFICATE-----/ {f = \"" + caCertPrefix + "\" (n++) \".crt\"} f {print > f} /-----END CERTIFICATE-----/ {close(f); f = \"\"}' " + caFile +
+			" && for caCert in " + caCertPrefix + "*.crt; do " +
+			"keytool -importcert -noprompt -alias $(basename ${caCert} .crt) -file ${caCert}" +
+			" -keystore " + truststoreFile + " -storetype PKCS12 -storepass ${SOLR_SSL_KEY_STORE_PASSWORD} || exit 1; done" +
+			" && rm -f " + caCertPrefix + "*.crt; " +
+			"else cp " + DefaultWritableKeyStorePath + "/" + Pkcs12KeystoreFile + " " + truststoreFile + "; fi"
+	}
 	return corev1.Container{
 		Name:                     "gen-pkcs12-keystore",
 		Image:                    imageName,
@@ -1207,6 +2357,8 @@ func generatePkcs12InitContainer(opts *solr.SolrTLSOptions, imageName string, im
 		Command:                  []string{"sh", "-c", cmd},
 		VolumeMounts:             tlsVolumeMounts(opts, true),
 		Env:                      envVars,
+		Resources:                resources,
+		SecurityContext:          securityContext,
 	}
 }
 
@@ -1229,7 +2381,17 @@ func createZkConnectionEnvVars(solrCloud *solr.SolrCloud, solrCloudStatus *solr.
 
 	// Add ACL information, if given, through Env Vars
 	allACL, readOnlyACL := solrCloud.Spec.ZookeeperRef.GetACLs()
-	if hasACLs, aclEnvs := AddACLsToEnv(allACL, readOnlyACL); hasACLs {
+	hasACLs, aclEnvs := AddACLsToEnv(allACL, readOnlyACL)
+
+	// Add ZK TLS information, if given, through Env Vars
+	if zkTLS := solrCloud.Spec.ZookeeperRef.GetTLS(); zkTLS != nil {
+		tlsEnvVars, _, _ := ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+		envVars = append(envVars, tlsEnvVars...)
+		aclEnvs = AddZkTLSOptsToCredsAndACLs(aclEnvs)
+		hasACLs = true
+	}
+
+	if hasACLs {
 		envVars = append(envVars, aclEnvs...)
 
 		// The $SOLR_ZK_CREDS_AND_ACLS parameter does not get picked up when running solr, it must be added to the SOLR_OPTS.
@@ -1239,8 +2401,13 @@ func createZkConnectionEnvVars(solrCloud *solr.SolrCloud, solrCloudStatus *solr.
 	return envVars, solrOpt, len(zkChroot) > 1
 }
 
+// userProvidedConfigMapVolumeName returns the name of the volume that a file of a user-provided ConfigMap is mounted from, such as "log4j2-xml"
+func userProvidedConfigMapVolumeName(fileKey string) string {
+	return strings.ReplaceAll(fileKey, ".", "-")
+}
+
 func setupVolumeMountForUserProvidedConfigMapEntry(reconcileConfigInfo map[string]string, fileKey string, solrVolumes []corev1.Volume, envVar string) (*corev1.VolumeMount, *corev1.EnvVar, *corev1.Volume) {
-	volName := strings.ReplaceAll(fileKey, ".", "-")
+	volName := userProvidedConfigMapVolumeName(fileKey)
 	mountPath := fmt.Sprintf("/var/solr/%s", reconcileConfigInfo[fileKey])
 	appendedToExisting := false
 	if reconcileConfigInfo[fileKey] == reconcileConfigInfo[SolrXmlFile] {
@@ -1298,12 +2465,22 @@ func ValidateBasicAuthSecret(basicAuthSecret *corev1.Secret) error {
 	return nil
 }
 
+// securityJsonSecretKeySelector returns the secret key that holds the security.json to bootstrap in ZK,
+// either the one provided by the user or the one generated by the operator
+func securityJsonSecretKeySelector(solrCloud *solr.SolrCloud) *corev1.SecretKeySelector {
+	if solrCloud.Spec.SolrSecurity != nil && solrCloud.Spec.SolrSecurity.BootstrapSecurityJson != nil {
+		return solrCloud.Spec.SolrSecurity.BootstrapSecurityJson.DeepCopy()
+	}
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: solrCloud.SecurityBootstrapSecretName()},
+		Key:                  SecurityJsonFile}
+}
+
 func GenerateBasicAuthSecretWithBootstrap(solrCloud *solr.SolrCloud) (*corev1.Secret, *corev1.Secret) {
 
 	securityBootstrapInfo := generateSecurityJson(solrCloud)
 
-	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
-	var annotations map[string]string
+	labels, annotations := withCommonLabelsAndAnnotations(solrCloud, solrCloud.SharedLabelsWith(solrCloud.GetLabels()), nil)
 	basicAuthSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        solrCloud.BasicAuthSecretName(),
@@ -1353,9 +2530,8 @@ func generateSecurityJson(solrCloud *solr.SolrCloud) map[string][]byte {
 		if i > 0 {
 			probeAuthz += ", "
 		}
-		if strings.HasPrefix(p, "/solr") {
-			p = p[len("/solr"):]
-		}
+		// The permission paths of security.json are relative to the context path of Solr
+		p = strings.TrimPrefix(p, solrCloud.SolrContextPath())
 		probeAuthz += fmt.Sprintf("{ \"name\": \"k8s-probe-%d\", \"role\":%s, \"collection\": null, \"path\":\"%s\" }", i, probeRole, p)
 	}
 
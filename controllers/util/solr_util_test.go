@@ -0,0 +1,1698 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// newTestSolrCloud returns the SolrCloud "foo" in the "default" namespace, with the given spec and its defaults set.
+// The SolrCloud connects to the Zookeeper at "zk:2181", unless the spec references another one.
+// The status that the SolrCloud's resources are generated with is returned as well.
+func newTestSolrCloud(spec solr.SolrCloudSpec) (*solr.SolrCloud, *solr.SolrCloudStatus) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       spec,
+	}
+	if solrCloud.Spec.ZookeeperRef == nil {
+		solrCloud.Spec.ZookeeperRef = &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181"},
+		}
+	}
+	solrCloud.WithDefaults()
+	return solrCloud, &solr.SolrCloudStatus{ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo}
+}
+
+func TestExternalDNSServiceAnnotations(t *testing.T) {
+	ttl := int32(60)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:                solr.Ingress,
+					DomainName:            "test.domain.com",
+					AdditionalDomainNames: []string{"other.domain.com"},
+				},
+				PodPort:           3000,
+				CommonServicePort: 4000,
+			},
+		},
+	}
+
+	// No annotations should be added unless they are requested
+	assert.Empty(t, GenerateCommonService(solrCloud).Annotations, "The common service should not have external-dns annotations by default")
+	assert.Empty(t, GenerateNodeService(solrCloud, "foo-solrcloud-0").Annotations, "The node service should not have external-dns annotations by default")
+
+	solrCloud.Spec.SolrAddressability.External.UseExternalDNSAnnotations = true
+	solrCloud.Spec.SolrAddressability.External.ExternalDNSTTL = &ttl
+	commonService := GenerateCommonService(solrCloud)
+	assert.Equal(t, "default-foo-solrcloud.test.domain.com,default-foo-solrcloud.other.domain.com", commonService.Annotations[ExternalDNSHostnameAnnotation], "Wrong external-dns hostname annotation for the common service")
+	assert.Equal(t, "60", commonService.Annotations[ExternalDNSTTLAnnotation], "Wrong external-dns ttl annotation for the common service")
+	nodeService := GenerateNodeService(solrCloud, "foo-solrcloud-0")
+	assert.Equal(t, "default-foo-solrcloud-0.test.domain.com,default-foo-solrcloud-0.other.domain.com", nodeService.Annotations[ExternalDNSHostnameAnnotation], "Wrong external-dns hostname annotation for the node service")
+	assert.Equal(t, "60", nodeService.Annotations[ExternalDNSTTLAnnotation], "Wrong external-dns ttl annotation for the node service")
+
+	// Hidden services should not be given DNS records
+	solrCloud.Spec.SolrAddressability.External.HideCommon = true
+	assert.Empty(t, GenerateCommonService(solrCloud).Annotations, "A hidden common service should not have external-dns annotations")
+
+	// The ExternalDNS method always annotates the common service, but should also use the TTL
+	solrCloud.Spec.SolrAddressability.External.HideCommon = false
+	solrCloud.Spec.SolrAddressability.External.Method = solr.ExternalDNS
+	solrCloud.Spec.SolrAddressability.External.UseExternalDNSAnnotations = false
+	commonService = GenerateCommonService(solrCloud)
+	assert.Equal(t, "default.test.domain.com,default.other.domain.com", commonService.Annotations[ExternalDNSHostnameAnnotation], "Wrong external-dns hostname annotation for the common service")
+	assert.Equal(t, "60", commonService.Annotations[ExternalDNSTTLAnnotation], "Wrong external-dns ttl annotation for the common service")
+
+	// Removing the TTL should remove the annotation from the existing service, but leave user provided annotations alone
+	commonService.Annotations["custom"] = "annotation"
+	solrCloud.Spec.SolrAddressability.External.ExternalDNSTTL = nil
+	assert.True(t, CopyServiceFields(GenerateCommonService(solrCloud), commonService, log), "Removing the external-dns ttl should require an update")
+	assert.NotContains(t, commonService.Annotations, ExternalDNSTTLAnnotation, "The external-dns ttl annotation should be removed")
+	assert.Equal(t, "default.test.domain.com,default.other.domain.com", commonService.Annotations[ExternalDNSHostnameAnnotation], "The external-dns hostname annotation should be kept")
+	assert.Equal(t, "annotation", commonService.Annotations["custom"], "User provided annotations should be kept")
+	assert.False(t, CopyServiceFields(GenerateCommonService(solrCloud), commonService, log), "No update should be required once the annotations are reconciled")
+}
+
+func TestIngressOptions(t *testing.T) {
+	ingressClass := "nginx"
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:     solr.Ingress,
+					DomainName: "test.domain.com",
+				},
+				PodPort:           3000,
+				CommonServicePort: 4000,
+			},
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				IngressOptions: &solr.IngressOptions{
+					Annotations: map[string]string{
+						IngressClassAnnotation:                        "nginx",
+						"nginx.ingress.kubernetes.io/proxy-body-size": "10m",
+					},
+					Labels: map[string]string{"custom": "label"},
+				},
+			},
+		},
+	}
+	nodeNames := []string{"foo-solrcloud-0"}
+
+	ingress := GenerateIngress(solrCloud, nodeNames)
+	assert.Nil(t, ingress.Spec.IngressClassName, "No ingressClassName should be set by default")
+	assert.Equal(t, "nginx", ingress.Annotations[IngressClassAnnotation], "The ingress class annotation should be kept when no ingressClassName is provided")
+	assert.Equal(t, "10m", ingress.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"], "Custom annotations should be added to the ingress")
+	assert.Equal(t, "label", ingress.Labels["custom"], "Custom labels should be added to the ingress")
+
+	// Setting the ingressClassName should replace the deprecated annotation, in both the generated and existing ingress
+	existingIngress := ingress.DeepCopy()
+	solrCloud.Spec.CustomSolrKubeOptions.IngressOptions.IngressClassName = &ingressClass
+	ingress = GenerateIngress(solrCloud, nodeNames)
+	assert.Equal(t, &ingressClass, ingress.Spec.IngressClassName, "The ingressClassName should be set on the ingress")
+	assert.NotContains(t, ingress.Annotations, IngressClassAnnotation, "The ingress class annotation should not be set alongside the ingressClassName")
+	assert.Equal(t, "10m", ingress.Annotations["nginx.ingress.kubernetes.io/proxy-body-size"], "Custom annotations should still be added to the ingress")
+
+	assert.True(t, CopyIngressFields(ingress, existingIngress, log), "Setting the ingressClassName should require an update")
+	assert.Equal(t, &ingressClass, existingIngress.Spec.IngressClassName, "The ingressClassName should be copied to the existing ingress")
+	assert.NotContains(t, existingIngress.Annotations, IngressClassAnnotation, "The ingress class annotation should be removed from the existing ingress")
+	assert.False(t, CopyIngressFields(ingress, existingIngress, log), "No update should be required once the ingress is reconciled")
+}
+
+func TestIngressTLSTermination(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:     solr.Ingress,
+					DomainName: "test.domain.com",
+					IngressTLSTermination: &solr.SolrIngressTLSTermination{
+						Mode:      solr.IngressTLSTerminationEdge,
+						TLSSecret: "ingress-tls",
+						IssuerRef: &solr.CertManagerIssuerReference{
+							Name: "letsencrypt",
+							Kind: solr.CertManagerIssuerKindClusterIssuer,
+						},
+					},
+				},
+				PodPort:           3000,
+				CommonServicePort: 4000,
+			},
+		},
+	}
+	nodeNames := []string{"foo-solrcloud-0", "foo-solrcloud-1"}
+
+	assert.NoError(t, ValidateIngressTLSTermination(solrCloud), "Edge termination should be valid without solrTLS")
+	ingress := GenerateIngress(solrCloud, nodeNames)
+	assert.Equal(t, 1, len(ingress.Spec.TLS), "The ingress should have a single TLS section")
+	assert.Equal(t, "ingress-tls", ingress.Spec.TLS[0].SecretName, "Wrong secret for the ingress TLS")
+	assert.ElementsMatch(t, []string{"default-foo-solrcloud.test.domain.com", "default-foo-solrcloud-0.test.domain.com", "default-foo-solrcloud-1.test.domain.com"}, ingress.Spec.TLS[0].Hosts, "The ingress TLS should cover all exposed hosts")
+	assert.Equal(t, "letsencrypt", ingress.Annotations[CertManagerClusterIssuerAnnotation], "The cert-manager cluster-issuer annotation should be added for a ClusterIssuer")
+	assert.NotContains(t, ingress.Annotations, CertManagerIssuerAnnotation, "The cert-manager issuer annotation should not be added for a ClusterIssuer")
+	assert.NotContains(t, ingress.Annotations, IngressBackendProtocolAnnotation, "Solr should be reached over HTTP with Edge termination")
+
+	// Edge termination conflicts with Solr using TLS
+	solrCloud.Spec.SolrTLS = &solr.SolrTLSOptions{
+		PKCS12Secret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "solr-tls"},
+			Key:                  "keystore.p12",
+		},
+	}
+	assert.Error(t, ValidateIngressTLSTermination(solrCloud), "Edge termination should not be valid with solrTLS")
+
+	solrCloud.Spec.SolrAddressability.External.IngressTLSTermination.Mode = solr.IngressTLSTerminationReencrypt
+	assert.NoError(t, ValidateIngressTLSTermination(solrCloud), "Reencrypt termination should be valid with solrTLS")
+	ingress = GenerateIngress(solrCloud, nodeNames)
+	assert.Equal(t, 1, len(ingress.Spec.TLS), "The ingress TLS termination should replace the solrTLS secret")
+	assert.Equal(t, "ingress-tls", ingress.Spec.TLS[0].SecretName, "Wrong secret for the ingress TLS")
+	assert.Equal(t, "HTTPS", ingress.Annotations[IngressBackendProtocolAnnotation], "Solr should be reached over HTTPS with Reencrypt termination")
+
+	solrCloud.Spec.SolrTLS = nil
+	assert.Error(t, ValidateIngressTLSTermination(solrCloud), "Reencrypt termination should not be valid without solrTLS")
+}
+
+func TestIngressTLSTerminationIssuerKind(t *testing.T) {
+	issuerRef := &solr.CertManagerIssuerReference{
+		Name: "letsencrypt",
+		Kind: solr.CertManagerIssuerKindClusterIssuer,
+	}
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:     solr.Ingress,
+					DomainName: "test.domain.com",
+					IngressTLSTermination: &solr.SolrIngressTLSTermination{
+						Mode:      solr.IngressTLSTerminationEdge,
+						TLSSecret: "ingress-tls",
+						IssuerRef: issuerRef,
+					},
+				},
+			},
+		},
+	}
+	nodeNames := []string{"foo-solrcloud-0"}
+	clusterIssuerIngress := GenerateIngress(solrCloud, nodeNames)
+
+	issuerRef.Namespace = "cert-manager"
+	assert.Error(t, ValidateIngressTLSTermination(solrCloud), "A ClusterIssuer should not be given a namespace")
+
+	issuerRef.Kind = solr.CertManagerIssuerKindIssuer
+	assert.Error(t, ValidateIngressTLSTermination(solrCloud), "An Issuer in a different namespace than the SolrCloud should not be valid")
+	issuerRef.Namespace = "default"
+	assert.NoError(t, ValidateIngressTLSTermination(solrCloud), "An Issuer in the namespace of the SolrCloud should be valid")
+	issuerRef.Namespace = ""
+	assert.NoError(t, ValidateIngressTLSTermination(solrCloud), "An Issuer without a namespace should be valid")
+
+	ingress := GenerateIngress(solrCloud, nodeNames)
+	assert.Equal(t, "letsencrypt", ingress.Annotations[CertManagerIssuerAnnotation], "The cert-manager issuer annotation should be added for an Issuer")
+	assert.NotContains(t, ingress.Annotations, CertManagerClusterIssuerAnnotation, "The cert-manager cluster-issuer annotation should not be added for an Issuer")
+
+	// Switching the issuer kind should remove the annotation of the previous kind
+	assert.True(t, CopyIngressFields(ingress, clusterIssuerIngress, log), "Switching the issuer kind should require an update")
+	assert.Equal(t, "letsencrypt", clusterIssuerIngress.Annotations[CertManagerIssuerAnnotation], "The cert-manager issuer annotation should be added to the existing ingress")
+	assert.NotContains(t, clusterIssuerIngress.Annotations, CertManagerClusterIssuerAnnotation, "The cert-manager cluster-issuer annotation should be removed from the existing ingress")
+
+	issuerRef.Kind = "Certificate"
+	assert.Error(t, ValidateIngressTLSTermination(solrCloud), "Unknown issuer kinds should not be valid")
+	issuerRef.Kind = solr.CertManagerIssuerKindIssuer
+	issuerRef.Name = ""
+	assert.Error(t, ValidateIngressTLSTermination(solrCloud), "An issuer name must be provided")
+}
+
+func TestNeedsPkcs12InitContainer(t *testing.T) {
+	tls := &solr.SolrTLSOptions{
+		PKCS12Secret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "solr-tls"},
+			Key:                  Pkcs12KeystoreFile,
+		},
+	}
+	tlsSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "solr-tls", Namespace: "default"},
+		Data: map[string][]byte{
+			Pkcs12KeystoreFile: []byte("keystore"),
+		},
+	}
+
+	needsInitContainer, err := NeedsPkcs12InitContainer(tls, tlsSecret)
+	assert.NoError(t, err, "A secret with a keystore should be usable")
+	assert.False(t, needsInitContainer, "The keystore should be used directly when it is in the secret")
+	assert.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte("keystore"))), TLSSecretMd5(tls, tlsSecret), "The keystore should be hashed when the secret has no tls.crt")
+
+	tlsSecret.Data[TLSCertKey] = []byte("cert")
+	assert.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte("cert"))), TLSSecretMd5(tls, tlsSecret), "The tls.crt should be hashed when it is in the secret")
+
+	// Only the PEM encoded certificate and key, such as a secret synced from Vault
+	delete(tlsSecret.Data, Pkcs12KeystoreFile)
+	tlsSecret.Data[TLSKeyKey] = []byte("key")
+	needsInitContainer, err = NeedsPkcs12InitContainer(tls, tlsSecret)
+	assert.NoError(t, err, "A secret with a PEM encoded certificate and key should be usable")
+	assert.True(t, needsInitContainer, "The keystore should be generated from the PEM encoded certificate and key")
+
+	delete(tlsSecret.Data, TLSKeyKey)
+	_, err = NeedsPkcs12InitContainer(tls, tlsSecret)
+	assert.Error(t, err, "A secret with only a certificate should not be usable")
+	assert.Contains(t, err.Error(), TLSKeyKey, "The error should name the missing key")
+
+	delete(tlsSecret.Data, TLSCertKey)
+	_, err = NeedsPkcs12InitContainer(tls, tlsSecret)
+	assert.Error(t, err, "An empty secret should not be usable")
+}
+
+func TestPkcs12InitContainerPEMKeys(t *testing.T) {
+	layouts := []struct {
+		name        string
+		pemKeys     *solr.SolrTLSPEMKeys
+		data        []string
+		expectError bool
+		expectCmd   []string
+	}{
+		{
+			name:      "kubernetes.io/tls",
+			data:      []string{"tls.crt", "tls.key", "ca.crt"},
+			expectCmd: []string{"-in /var/solr/tls/tls.crt ", "-certfile /var/solr/tls/ca.crt", "-inkey /var/solr/tls/tls.key ", "-file /var/solr/tls/ca.crt "},
+		},
+		{
+			name:      "Vault PKI",
+			pemKeys:   &solr.SolrTLSPEMKeys{CertKey: "certificate", KeyKey: "private_key", CAKey: "issuing_ca"},
+			data:      []string{"certificate", "private_key", "issuing_ca"},
+			expectCmd: []string{"-in /var/solr/tls/certificate ", "-certfile /var/solr/tls/issuing_ca", "-inkey /var/solr/tls/private_key ", "-file /var/solr/tls/issuing_ca "},
+		},
+		{
+			name:      "Only the private key overridden",
+			pemKeys:   &solr.SolrTLSPEMKeys{KeyKey: "server.key"},
+			data:      []string{"tls.crt", "server.key"},
+			expectCmd: []string{"-in /var/solr/tls/tls.crt ", "-certfile /var/solr/tls/ca.crt", "-inkey /var/solr/tls/server.key "},
+		},
+		{
+			name:        "Overridden keys missing from the secret",
+			pemKeys:     &solr.SolrTLSPEMKeys{CertKey: "server.pem", KeyKey: "server.key"},
+			data:        []string{"tls.crt", "tls.key"},
+			expectError: true,
+		},
+	}
+	for _, layout := range layouts {
+		tls := &solr.SolrTLSOptions{
+			PKCS12Secret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "solr-tls"},
+				Key:                  Pkcs12KeystoreFile,
+			},
+			KeyStorePasswordSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "solr-tls"},
+				Key:                  "password",
+			},
+			PEMKeys: layout.pemKeys,
+		}
+		tlsSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "solr-tls", Namespace: "default"},
+			Data:       map[string][]byte{},
+		}
+		for _, key := range layout.data {
+			tlsSecret.Data[key] = []byte(key)
+		}
+
+		needsInitContainer, err := NeedsPkcs12InitContainer(tls, tlsSecret)
+		if layout.expectError {
+			assert.Error(t, err, "The keystore cannot be generated if the secret does not have the configured keys, for layout: %s", layout.name)
+			continue
+		}
+		assert.NoError(t, err, "The keystore should be generated for layout: %s", layout.name)
+		assert.True(t, needsInitContainer, "The keystore should be generated for layout: %s", layout.name)
+		assert.Equal(t, fmt.Sprintf("%x", md5.Sum([]byte(tls.PEMCertKey()))), TLSSecretMd5(tls, tlsSecret), "The configured certificate should be hashed for layout: %s", layout.name)
+
+		cmd := generatePkcs12InitContainer(tls, "solr:8.9", corev1.PullIfNotPresent, nil, corev1.ResourceRequirements{}).Command[2]
+		for _, expected := range layout.expectCmd {
+			assert.Contains(t, cmd, expected, "Wrong files used to generate the keystore for layout: %s", layout.name)
+		}
+	}
+}
+
+func TestGeneratePodDisruptionBudget(t *testing.T) {
+	replicas := int32(10)
+	maxPodsUnavailable := intstr.FromString("25%")
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			UpdateStrategy: solr.SolrUpdateStrategy{
+				Method: solr.ManagedUpdate,
+				ManagedUpdateOptions: solr.ManagedUpdateOptions{
+					MaxPodsUnavailable: &maxPodsUnavailable,
+				},
+			},
+		},
+	}
+
+	pdb := GeneratePodDisruptionBudget(solrCloud)
+	assert.Equal(t, "foo-solrcloud", pdb.Name, "Wrong name for the PodDisruptionBudget")
+	assert.Equal(t, map[string]string{"solr-cloud": "foo", "technology": solr.SolrTechnologyLabel}, pdb.Spec.Selector.MatchLabels, "The PodDisruptionBudget should select the Solr pods")
+	assert.Equal(t, intstr.FromInt(3), *pdb.Spec.MaxUnavailable, "The maxUnavailable should mirror the maxPodsUnavailable of managed updates")
+
+	// A maxPodsUnavailable of 0 means that all pods can be updated at once, not that no pods can be disrupted
+	maxPodsUnavailable = intstr.FromInt(0)
+	pdb = GeneratePodDisruptionBudget(solrCloud)
+	assert.Equal(t, intstr.FromInt(10), *pdb.Spec.MaxUnavailable, "A maxPodsUnavailable of 0 should allow all pods to be disrupted")
+
+	// Custom options override the managed update settings
+	overrideMaxUnavailable := intstr.FromInt(1)
+	solrCloud.Spec.CustomSolrKubeOptions.PodDisruptionBudgetOptions = &solr.PodDisruptionBudgetOptions{
+		MaxUnavailable: &overrideMaxUnavailable,
+		Annotations:    map[string]string{"custom": "annotation"},
+		Labels:         map[string]string{"custom": "label"},
+	}
+	existingPDB := pdb.DeepCopy()
+	pdb = GeneratePodDisruptionBudget(solrCloud)
+	assert.Equal(t, intstr.FromInt(1), *pdb.Spec.MaxUnavailable, "The custom maxUnavailable should be used")
+	assert.Equal(t, "annotation", pdb.Annotations["custom"], "Custom annotations should be added to the PodDisruptionBudget")
+	assert.Equal(t, "label", pdb.Labels["custom"], "Custom labels should be added to the PodDisruptionBudget")
+
+	assert.True(t, CopyPodDisruptionBudgetFields(pdb, existingPDB, log), "Changing the maxUnavailable should require an update")
+	assert.Equal(t, intstr.FromInt(1), *existingPDB.Spec.MaxUnavailable, "The maxUnavailable should be copied to the existing PodDisruptionBudget")
+	assert.False(t, CopyPodDisruptionBudgetFields(pdb, existingPDB, log), "No update should be required once the PodDisruptionBudget is reconciled")
+}
+
+func TestSolrVersionCounts(t *testing.T) {
+	solrNodes := []solr.SolrNodeStatus{
+		{Name: "foo-solrcloud-0", Version: "8.9"},
+		{Name: "foo-solrcloud-1", Version: "8.11"},
+		{Name: "foo-solrcloud-2", Version: "8.9"},
+		{Name: "foo-solrcloud-3", Version: ""},
+	}
+
+	assert.Equal(t, []solr.SolrVersionCount{
+		{Version: "8.11", Nodes: 1},
+		{Version: "8.9", Nodes: 2},
+	}, SolrVersionCounts(solrNodes), "Each version should be counted once per node, sorted by version, ignoring nodes without a known version")
+
+	assert.Empty(t, SolrVersionCounts(nil), "No versions should be returned when there are no nodes")
+}
+
+func TestValidateCustomContainers(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+	assert.NoError(t, ValidateCustomContainers(solrCloud), "No custom containers should always be valid")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{
+		InitContainers:    []corev1.Container{{Name: "seed-config"}},
+		SidecarContainers: []corev1.Container{{Name: "log-shipper"}},
+	}
+	assert.NoError(t, ValidateCustomContainers(solrCloud), "Uniquely named custom containers should be valid")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers = []corev1.Container{{Name: SolrNodeContainer}}
+	assert.Error(t, ValidateCustomContainers(solrCloud), "A sidecar container cannot use the name of the Solr container")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers = nil
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.InitContainers = []corev1.Container{{Name: "setup-zk"}}
+	assert.Error(t, ValidateCustomContainers(solrCloud), "An init container cannot use the name of an operator managed init container")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.InitContainers = []corev1.Container{{Name: "log-shipper"}}
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers = []corev1.Container{{Name: "log-shipper"}}
+	assert.Error(t, ValidateCustomContainers(solrCloud), "Custom init and sidecar containers cannot share a name")
+}
+
+func TestSolrPorts(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		SolrAddressability: solr.SolrAddressabilityOptions{
+			PodPort:           9000,
+			CommonServicePort: 8000,
+		},
+		CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+			PodOptions: &solr.PodOptions{
+				SidecarContainers: []corev1.Container{{Name: "solr-exporter", Ports: []corev1.ContainerPort{{ContainerPort: 8983}}}},
+			},
+		},
+	})
+	assert.NoError(t, ValidateSolrPodPort(solrCloud), "Solr can listen on a port that is not used by a sidecar")
+
+	solrContainer := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "").Spec.Template.Spec.Containers[0]
+	assert.EqualValues(t, 9000, solrContainer.Ports[0].ContainerPort, "Solr should listen on the podPort")
+	assert.EqualValues(t, 9000, solrContainer.LivenessProbe.HTTPGet.Port.IntValue(), "Solr should be probed on the podPort")
+	assert.EqualValues(t, 9000, solrContainer.ReadinessProbe.HTTPGet.Port.IntValue(), "Solr should be probed on the podPort")
+	assert.Contains(t, solrContainer.Env, corev1.EnvVar{Name: "SOLR_PORT", Value: "9000"}, "Solr should be started on the podPort")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.LivenessProbe = &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/solr/admin/ping"}}}
+	solrContainer = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "").Spec.Template.Spec.Containers[0]
+	assert.EqualValues(t, 9000, solrContainer.LivenessProbe.HTTPGet.Port.IntValue(), "A custom probe without a port should be sent to the podPort")
+	assert.Equal(t, "/solr/admin/ping", solrContainer.LivenessProbe.HTTPGet.Path, "The path of the custom probe should be kept")
+	assert.Equal(t, intstr.IntOrString{}, solrCloud.Spec.CustomSolrKubeOptions.PodOptions.LivenessProbe.HTTPGet.Port, "The custom probe in the spec should not be changed")
+
+	commonService := GenerateCommonService(solrCloud)
+	assert.EqualValues(t, 8000, commonService.Spec.Ports[0].Port, "The common service should listen on the commonServicePort")
+	assert.Equal(t, SolrClientPortName, commonService.Spec.Ports[0].TargetPort.String(), "The common service should target the Solr container port")
+	assert.EqualValues(t, 9000, GenerateHeadlessService(solrCloud).Spec.Ports[0].Port, "The headless service should listen on the podPort")
+	assert.Equal(t, "foo-solrcloud-common.default:8000", solrCloud.InternalCommonUrl(true), "The internal common URL should use the commonServicePort")
+	assert.Equal(t, "foo-solrcloud-0.foo-solrcloud-headless.default:9000", solrCloud.InternalNodeUrl("foo-solrcloud-0", true), "The internal node URL should use the podPort")
+
+	solrCloud.Spec.SolrAddressability.PodPort = 8983
+	assert.Error(t, ValidateSolrPodPort(solrCloud), "Solr cannot listen on a port that is used by a sidecar")
+	solrCloud.Spec.SolrAddressability.PodPort = 0
+	assert.EqualError(t, ValidateSolrPodPort(solrCloud), "port 8983 of the sidecar container \"solr-exporter\" is already used by the container \"solrcloud-node\"", "Solr cannot listen on the default port when it is used by a sidecar")
+
+	solrCloud.Spec.SolrAddressability.PodPort = 9000
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers = append(solrCloud.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers,
+		corev1.Container{Name: "dns", Ports: []corev1.ContainerPort{{ContainerPort: 8983, Protocol: corev1.ProtocolUDP}}})
+	assert.NoError(t, ValidateSolrPodPort(solrCloud), "Sidecars can share a port number over different protocols")
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers = append(solrCloud.Spec.CustomSolrKubeOptions.PodOptions.SidecarContainers,
+		corev1.Container{Name: "metrics", Ports: []corev1.ContainerPort{{ContainerPort: 8983}}})
+	assert.EqualError(t, ValidateSolrPodPort(solrCloud), "port 8983 of the sidecar container \"metrics\" is already used by the container \"solr-exporter\"", "Sidecars cannot listen on the same port")
+}
+
+func TestValidateCustomVolumes(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+	assert.NoError(t, ValidateCustomVolumes(solrCloud), "No custom volumes should always be valid")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{
+		Volumes: []solr.AdditionalVolume{{Name: "synonyms"}, {Name: "cache"}},
+	}
+	assert.NoError(t, ValidateCustomVolumes(solrCloud), "Uniquely named custom volumes should be valid")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.Volumes = []solr.AdditionalVolume{{Name: BackupRestoreVolume}}
+	assert.Error(t, ValidateCustomVolumes(solrCloud), "A custom volume cannot use the name of the backup-restore volume")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.Volumes = []solr.AdditionalVolume{{Name: "data"}}
+	assert.Error(t, ValidateCustomVolumes(solrCloud), "A custom volume cannot use the name of the data volume")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.Volumes = []solr.AdditionalVolume{{Name: "additional-configmap-0"}}
+	assert.Error(t, ValidateCustomVolumes(solrCloud), "A custom volume cannot use the name of an additional ConfigMap volume")
+
+	_, _, logConfigVolume := setupVolumeMountForUserProvidedConfigMapEntry(map[string]string{LogXmlFile: "custom-log-config"}, LogXmlFile, nil, "LOG4J_PROPS")
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.Volumes = []solr.AdditionalVolume{{Name: logConfigVolume.Name}}
+	assert.Error(t, ValidateCustomVolumes(solrCloud), "A custom volume cannot use the name of the volume for a user-provided log4j2.xml")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.Volumes = []solr.AdditionalVolume{{Name: "cache"}, {Name: "cache"}}
+	assert.Error(t, ValidateCustomVolumes(solrCloud), "Custom volumes cannot share a name")
+}
+
+func TestTopologySpreadConstraints(t *testing.T) {
+	customSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"custom": "label"}}
+	constraints := []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+		},
+		{
+			MaxSkew:           2,
+			TopologyKey:       "kubernetes.io/hostname",
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     customSelector,
+		},
+	}
+	selectorLabels := map[string]string{"solr-cloud": "foo", "technology": "solr-cloud"}
+
+	filledConstraints := fillTopologySpreadConstraints(constraints, selectorLabels)
+	if assert.Equal(t, 2, len(filledConstraints), "All topology spread constraints should be kept") {
+		assert.Equal(t, selectorLabels, filledConstraints[0].LabelSelector.MatchLabels, "A constraint without a labelSelector should select the pods of the resource")
+		assert.Equal(t, "topology.kubernetes.io/zone", filledConstraints[0].TopologyKey, "The topologyKey should not be changed")
+		assert.Equal(t, customSelector, filledConstraints[1].LabelSelector, "A user-provided labelSelector should not be changed")
+	}
+	assert.Nil(t, constraints[0].LabelSelector, "The user-provided constraints should not be modified")
+}
+
+func TestPVCNeedsExpansion(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+	}
+
+	needsExpansion, needsResizeRequest := PVCNeedsExpansion(pvc, resource.MustParse("10Gi"))
+	assert.False(t, needsExpansion, "A PVC with the requested size should not need to be expanded")
+	assert.False(t, needsResizeRequest, "A PVC with the requested size should not need a resize request")
+
+	needsExpansion, needsResizeRequest = PVCNeedsExpansion(pvc, resource.MustParse("5Gi"))
+	assert.False(t, needsExpansion, "PVCs should never be shrunk")
+	assert.False(t, needsResizeRequest, "PVCs should never be shrunk")
+
+	needsExpansion, needsResizeRequest = PVCNeedsExpansion(pvc, resource.MustParse("20Gi"))
+	assert.True(t, needsExpansion, "A PVC smaller than the requested size should need to be expanded")
+	assert.True(t, needsResizeRequest, "A PVC that has not been resized yet should need a resize request")
+
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	needsExpansion, needsResizeRequest = PVCNeedsExpansion(pvc, resource.MustParse("20Gi"))
+	assert.True(t, needsExpansion, "A PVC should need to be expanded until its capacity reaches the requested size")
+	assert.False(t, needsResizeRequest, "A PVC that is already being resized should not need another resize request")
+}
+
+func TestZKInteractionInitContainerUsesACLs(t *testing.T) {
+	chroot := "/solr"
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrTLS: &solr.SolrTLSOptions{},
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "zk:2181",
+					ChRoot:                   chroot,
+					AllACL:                   &solr.ZookeeperACL{SecretRef: "zk-secret", UsernameKey: "user", PasswordKey: "pass"},
+				},
+			},
+		},
+	}
+	solrCloudStatus := &solr.SolrCloudStatus{ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo}
+
+	hasZKSetupContainer, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, map[string]string{})
+	assert.True(t, hasZKSetupContainer, "The setup-zk init container is required when TLS is enabled")
+	var credsAndACLs *corev1.EnvVar
+	for i, envVar := range zkSetupContainer.Env {
+		if envVar.Name == "SOLR_ZK_CREDS_AND_ACLS" {
+			credsAndACLs = &zkSetupContainer.Env[i]
+		}
+	}
+	assert.NotNil(t, credsAndACLs, "The setup-zk init container must use the ZK ACLs, so that the znodes it creates are not world writable")
+	if credsAndACLs != nil {
+		assert.Contains(t, credsAndACLs.Value, "-DzkACLProvider=", "The setup-zk init container must set ACLs on the znodes it creates")
+	}
+	assert.Contains(t, zkSetupContainer.Command[2], "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot", "The chroot should only be created if it does not already exist")
+}
+
+func TestZKInteractionInitContainerUrlScheme(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrTLS: &solr.SolrTLSOptions{},
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+		},
+	}
+	solrCloudStatus := &solr.SolrCloudStatus{ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo}
+
+	hasZKSetupContainer, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, map[string]string{})
+	assert.True(t, hasZKSetupContainer, "The setup-zk init container is required when TLS is enabled")
+	assert.Contains(t, zkSetupContainer.Command[2], "-cmd clusterprop -name urlScheme -val https", "The urlScheme cluster property should be set by default when TLS is enabled")
+
+	manageUrlScheme := false
+	solrCloud.Spec.SolrTLS.ManageUrlScheme = &manageUrlScheme
+	hasZKSetupContainer, _ = generateZKInteractionInitContainer(solrCloud, solrCloudStatus, map[string]string{})
+	assert.False(t, hasZKSetupContainer, "The setup-zk init container is not needed when the urlScheme cluster property is managed externally")
+}
+
+func TestSeparateLogStorage(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+		},
+		StorageOptions: solr.SolrDataStorageOptions{
+			PersistentStorage: &solr.SolrPersistentDataStorageOptions{},
+		},
+	})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Len(t, statefulSet.Spec.VolumeClaimTemplates, 1, "Only the data PVC should be used by default")
+	for _, envVar := range statefulSet.Spec.Template.Spec.Containers[0].Env {
+		assert.NotEqual(t, "SOLR_LOGS_DIR", envVar.Name, "The logs directory should not be set by default")
+	}
+
+	sizeLimit := resource.MustParse("1Gi")
+	solrCloud.Spec.StorageOptions.LogStorage = &solr.SolrLogStorageOptions{
+		EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit},
+	}
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Len(t, statefulSet.Spec.VolumeClaimTemplates, 1, "No PVC should be added for logs stored in an emptyDir")
+	var logsVolume *corev1.Volume
+	for i, volume := range statefulSet.Spec.Template.Spec.Volumes {
+		if volume.Name == SolrCloudPVCLogStorage {
+			logsVolume = &statefulSet.Spec.Template.Spec.Volumes[i]
+		}
+	}
+	if assert.NotNil(t, logsVolume, "The logs volume should be added to the pod") {
+		assert.Equal(t, solrCloud.Spec.StorageOptions.LogStorage.EmptyDir, logsVolume.EmptyDir, "The logs volume should use the given emptyDir")
+	}
+	assert.Contains(t, statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: SolrCloudPVCLogStorage, MountPath: SolrLogsDir}, "The logs volume should be mounted at the logs directory")
+	assert.Contains(t, statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "SOLR_LOGS_DIR", Value: SolrLogsDir}, "Solr should write its logs to the logs volume")
+
+	solrCloud.Spec.StorageOptions.LogStorage = &solr.SolrLogStorageOptions{
+		PersistentVolumeClaimTemplate: &solr.PersistentVolumeClaimTemplate{},
+	}
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	if assert.Len(t, statefulSet.Spec.VolumeClaimTemplates, 2, "A separate PVC should be used for the logs") {
+		logsPVC := statefulSet.Spec.VolumeClaimTemplates[1]
+		assert.Equal(t, SolrCloudPVCLogStorage, logsPVC.Name, "Wrong default name for the logs PVC")
+		assert.Equal(t, SolrCloudPVCLogStorage, logsPVC.Labels[SolrPVCStorageLabel], "The logs PVC should be labeled as log storage")
+		assert.Equal(t, SolrCloudPVCDataStorage, statefulSet.Spec.VolumeClaimTemplates[0].Labels[SolrPVCStorageLabel], "The data PVC should be labeled as data storage")
+	}
+	assert.NoError(t, ValidateLogStorage(solrCloud), "The default names of the data and logs volumes should not collide")
+
+	solrCloud.Spec.StorageOptions.LogStorage.PersistentVolumeClaimTemplate.ObjectMeta.Name = SolrCloudPVCDataStorage
+	assert.Error(t, ValidateLogStorage(solrCloud), "The logs volume cannot use the name of the data volume")
+}
+
+func TestRequestedStorageClassNames(t *testing.T) {
+	fast := "fast"
+	slow := "slow"
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+	assert.Empty(t, RequestedStorageClassNames(solrCloud), "Ephemeral storage does not request any StorageClasses")
+
+	solrCloud.Spec.StorageOptions.PersistentStorage = &solr.SolrPersistentDataStorageOptions{}
+	assert.Empty(t, RequestedStorageClassNames(solrCloud), "A PVC template without a storageClassName uses the default StorageClass")
+
+	solrCloud.Spec.StorageOptions.PersistentStorage.PersistentVolumeClaimTemplate.Spec.StorageClassName = &fast
+	solrCloud.Spec.StorageOptions.LogStorage = &solr.SolrLogStorageOptions{
+		PersistentVolumeClaimTemplate: &solr.PersistentVolumeClaimTemplate{Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &fast}},
+	}
+	assert.Equal(t, []string{"fast"}, RequestedStorageClassNames(solrCloud), "Each StorageClass should be returned once")
+
+	solrCloud.Spec.StorageOptions.LogStorage.PersistentVolumeClaimTemplate.Spec.StorageClassName = &slow
+	assert.Equal(t, []string{"fast", "slow"}, RequestedStorageClassNames(solrCloud), "The StorageClass of the logs PVC template should be returned")
+}
+
+func TestWaitForZookeeperInitContainer(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+		},
+	})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	for _, container := range statefulSet.Spec.Template.Spec.InitContainers {
+		assert.NotEqual(t, ZookeeperWaitInitContainer, container.Name, "The pods should not wait for Zookeeper unless it is enabled")
+	}
+	assert.NotNil(t, statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PostStart, "The chroot should be created by the postStart hook when not waiting for Zookeeper")
+
+	solrCloud.Spec.ZookeeperRef.WaitForZookeeper = true
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	initContainers := statefulSet.Spec.Template.Spec.InitContainers
+	assert.Equal(t, 2, len(initContainers), "Wrong number of init containers")
+	assert.Equal(t, "cp-solr-xml", initContainers[0].Name, "The solr.xml should be set up first")
+	assert.Equal(t, ZookeeperWaitInitContainer, initContainers[1].Name, "The pods should wait for Zookeeper before Solr starts")
+	assert.Contains(t, initContainers[1].Command[2], "until solr zk ls / -z ${ZK_SERVER}", "The init container should block until Zookeeper responds")
+	assert.Contains(t, initContainers[1].Command[2], "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}", "The init container should create the chroot after Zookeeper responds")
+	assert.Nil(t, statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PostStart, "The chroot should not be created by the postStart hook when waiting for Zookeeper")
+
+	// A chroot of '/' does not need to be created
+	solrCloudStatus.ZookeeperConnectionInfo.ChRoot = "/"
+	zkWaitContainer := generateZKWaitInitContainer(solrCloud, solrCloudStatus)
+	assert.NotContains(t, zkWaitContainer.Command[2], "mkroot", "The root chroot should not be created")
+}
+
+func TestZKInteractionInitContainerUserProvidedSecurityJson(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrSecurity: &solr.SolrSecurityOptions{
+				AuthenticationType: solr.Basic,
+				BasicAuthSecret:    "operator-creds",
+				BootstrapSecurityJson: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "my-security"},
+					Key:                  "security.json",
+				},
+			},
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			},
+		},
+	}
+	solrCloudStatus := &solr.SolrCloudStatus{ZookeeperConnectionInfo: *solrCloud.Spec.ZookeeperRef.ConnectionInfo}
+	reconcileConfigInfo := map[string]string{SecurityJsonFile: "{\"authentication\":{\"class\":\"solr.BasicAuthPlugin\"}}"}
+
+	hasZKSetupContainer, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, reconcileConfigInfo)
+	assert.True(t, hasZKSetupContainer, "The setup-zk init container is required to bootstrap the security.json")
+	var securityJsonEnvVar *corev1.EnvVar
+	for i, envVar := range zkSetupContainer.Env {
+		if envVar.Name == "SECURITY_JSON" {
+			securityJsonEnvVar = &zkSetupContainer.Env[i]
+		}
+	}
+	assert.NotNil(t, securityJsonEnvVar, "The security.json should be passed to the setup-zk init container")
+	if securityJsonEnvVar != nil {
+		assert.Equal(t, solrCloud.Spec.SolrSecurity.BootstrapSecurityJson, securityJsonEnvVar.ValueFrom.SecretKeyRef, "The user-provided security.json secret should be used")
+	}
+	assert.Contains(t, zkSetupContainer.Command[2], "if [ ${#ZK_SECURITY_JSON} -lt 3 ]", "The security.json should only be bootstrapped when ZK does not have one")
+
+	// Drift is merged by the operator, so that changes made through the Security API are not overwritten when a pod restarts
+	solrCloud.Spec.SolrSecurity.ReconcileSecurityJson = true
+	_, zkSetupContainer = generateZKInteractionInitContainer(solrCloud, solrCloudStatus, reconcileConfigInfo)
+	assert.Contains(t, zkSetupContainer.Command[2], "if [ ${#ZK_SECURITY_JSON} -lt 3 ]", "The security.json should only be bootstrapped when ZK does not have one, even when reconciling it")
+}
+
+func TestMergeCustomEnvVarValue(t *testing.T) {
+	customEnvVars := []corev1.EnvVar{
+		{Name: "SOLR_JAVA_MEM", Value: "-Xms512m -Xmx512m"},
+		{Name: "GC_TUNE", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "gc"}}},
+		{Name: "OTHER", Value: "other"},
+	}
+
+	value, remainingEnvVars := mergeCustomEnvVarValue("SOLR_JAVA_MEM", "-Xms1g -Xmx2g", customEnvVars)
+	assert.Equal(t, "-Xms512m -Xmx512m -Xms1g -Xmx2g", value, "The spec value should come after the custom env var value, so that it takes precedence")
+	assert.Equal(t, customEnvVars[1:], remainingEnvVars, "The merged custom env var should be removed")
+
+	value, remainingEnvVars = mergeCustomEnvVarValue("GC_TUNE", "-XX:+UseG1GC", remainingEnvVars)
+	assert.Equal(t, "-XX:+UseG1GC", value, "Custom env vars using valueFrom cannot be merged")
+	assert.Equal(t, customEnvVars[1:], remainingEnvVars, "Custom env vars using valueFrom should be kept")
+
+	value, remainingEnvVars = mergeCustomEnvVarValue("SOLR_OPTS", "", remainingEnvVars)
+	assert.Empty(t, value, "There is nothing to merge without a spec value or custom env var")
+	assert.Equal(t, customEnvVars[1:], remainingEnvVars, "Other custom env vars should be kept")
+}
+
+func TestValidateAdditionalJavaOpts(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			AdditionalJavaOpts: []solr.JavaOpt{"-DsocketTimeout=300000", "-XX:+AlwaysPreTouch", "-Djavax.net.ssl.keyStore=/my/keystore.p12"},
+		},
+	}
+	assert.NoError(t, ValidateAdditionalJavaOpts(solrCloud), "The TLS system properties are not reserved when TLS is not enabled")
+
+	solrCloud.Spec.SolrTLS = &solr.SolrTLSOptions{
+		PKCS12Secret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls-secret"}, Key: "keystore.p12"},
+	}
+	assert.Error(t, ValidateAdditionalJavaOpts(solrCloud), "The TLS system properties are managed by the operator when TLS is enabled")
+
+	solrCloud.Spec.AdditionalJavaOpts = []solr.JavaOpt{"-DsocketTimeout=300000", "-DhostPort=8080"}
+	assert.Error(t, ValidateAdditionalJavaOpts(solrCloud), "The hostPort system property is always managed by the operator")
+
+	solrCloud.Spec.AdditionalJavaOpts = []solr.JavaOpt{"-DsocketTimeout=300000", "-DhostPortRange=8080"}
+	assert.NoError(t, ValidateAdditionalJavaOpts(solrCloud), "Only exact system property names should be reserved")
+
+	solrCloud.Spec.ZookeeperRef = &solr.ZookeeperRef{
+		ConnectionInfo: &solr.ZookeeperConnectionInfo{
+			InternalConnectionString: "zk:2181",
+			AllACL:                   &solr.ZookeeperACL{SecretRef: "zk-secret", UsernameKey: "user", PasswordKey: "pass"},
+		},
+	}
+	solrCloud.Spec.AdditionalJavaOpts = []solr.JavaOpt{"-DzkDigestUsername=admin"}
+	assert.Error(t, ValidateAdditionalJavaOpts(solrCloud), "The ZK digest system properties are managed by the operator when ZK ACLs are used")
+}
+
+func TestAdditionalJavaOptsWithTLS(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+		},
+		SolrTLS: &solr.SolrTLSOptions{
+			PKCS12Secret:           &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls-secret"}, Key: "keystore.p12"},
+			KeyStorePasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "tls-secret"}, Key: "password"},
+		},
+		SolrOpts:           "-DsocketTimeout=300000",
+		AdditionalJavaOpts: []solr.JavaOpt{"-Dsolr.autoSoftCommit.maxTime=5000", "-XX:+AlwaysPreTouch"},
+	})
+	assert.NoError(t, ValidateAdditionalJavaOpts(solrCloud), "The additional Java options do not set any reserved system properties")
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	var solrOpts string
+	for _, envVar := range statefulSet.Spec.Template.Spec.Containers[0].Env {
+		if envVar.Name == "SOLR_OPTS" {
+			solrOpts = envVar.Value
+		}
+	}
+	assert.Equal(t, "-DhostPort=$(SOLR_NODE_PORT) -DsocketTimeout=300000 -Dsolr.autoSoftCommit.maxTime=5000 -XX:+AlwaysPreTouch", solrOpts, "The additional Java options should be added after the operator managed options and the solrOpts")
+
+	hasZKSetupContainer, zkSetupContainer := generateZKInteractionInitContainer(solrCloud, solrCloudStatus, map[string]string{})
+	assert.True(t, hasZKSetupContainer, "The setup-zk init container is required when TLS is enabled")
+	solrOpts = ""
+	for _, envVar := range zkSetupContainer.Env {
+		if envVar.Name == "SOLR_OPTS" {
+			solrOpts = envVar.Value
+		}
+	}
+	assert.Equal(t, "-DsocketTimeout=300000 -Dsolr.autoSoftCommit.maxTime=5000 -XX:+AlwaysPreTouch", solrOpts, "The additional Java options should be passed to the setup-zk init container")
+}
+
+func TestGenerateHostAliases(t *testing.T) {
+	assert.Nil(t, GenerateHostAliases(map[string]string{}, nil), "No host aliases should be generated when there are no node IPs or custom host aliases")
+
+	hostNameIPs := map[string]string{
+		"default-foo-solrcloud-1.test.domain.com": "10.0.0.2",
+		"default-foo-solrcloud-0.test.domain.com": "10.0.0.1",
+	}
+	customHostAliases := []corev1.HostAlias{
+		{IP: "192.168.0.1", Hostnames: []string{"ldap.internal"}},
+		{IP: "192.168.0.2", Hostnames: []string{"default-foo-solrcloud-0.test.domain.com", "metrics.internal"}},
+		{IP: "192.168.0.3", Hostnames: []string{"default-foo-solrcloud-1.test.domain.com"}},
+	}
+
+	hostAliases := GenerateHostAliases(hostNameIPs, customHostAliases)
+	assert.Equal(t, []corev1.HostAlias{
+		{IP: "10.0.0.1", Hostnames: []string{"default-foo-solrcloud-0.test.domain.com"}},
+		{IP: "10.0.0.2", Hostnames: []string{"default-foo-solrcloud-1.test.domain.com"}},
+		{IP: "192.168.0.1", Hostnames: []string{"ldap.internal"}},
+		{IP: "192.168.0.2", Hostnames: []string{"metrics.internal"}},
+	}, hostAliases, "The operator-managed host aliases should come first, and take precedence over the custom host aliases")
+
+	assert.Equal(t, customHostAliases, GenerateHostAliases(nil, customHostAliases), "The custom host aliases should be used as-is when there are no node IPs")
+}
+
+func TestNodePortServices(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				PodPort:           8983,
+				CommonServicePort: 80,
+				External: &solr.ExternalAddressability{
+					Method:             solr.NodePort,
+					DomainName:         "test.domain.com",
+					UseExternalAddress: true,
+					BaseNodePort:       30100,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.False(t, solrCloud.Spec.SolrAddressability.External.UseExternalAddress, "The external address cannot be advertised with the NodePort method")
+	assert.True(t, solrCloud.UsesIndividualNodeServices(), "The NodePort method should use individual node services")
+
+	nodeService := GenerateNodeService(solrCloud, "foo-solrcloud-2")
+	assert.Equal(t, corev1.ServiceTypeNodePort, nodeService.Spec.Type, "The node service should be a NodePort service")
+	assert.EqualValues(t, 30102, nodeService.Spec.Ports[0].NodePort, "The nodePort should be based on the baseNodePort and the ordinal of the Solr Node")
+	assert.Equal(t, corev1.ServiceTypeNodePort, GenerateCommonService(solrCloud).Spec.Type, "The common service should be a NodePort service")
+
+	// Allocated nodePorts should be kept when no nodePort is requested
+	solrCloud.Spec.SolrAddressability.External.BaseNodePort = 0
+	nodeService.Spec.Ports[0].NodePort = 31234
+	assert.False(t, CopyServiceFields(GenerateNodeService(solrCloud, "foo-solrcloud-2"), nodeService, log), "An allocated nodePort should not require an update")
+	assert.EqualValues(t, 31234, nodeService.Spec.Ports[0].NodePort, "The allocated nodePort should be kept")
+
+	// Hiding the common service should change it back to ClusterIP
+	solrCloud.Spec.SolrAddressability.External.HideCommon = true
+	commonService := GenerateCommonService(solrCloud)
+	commonService.Spec.Type = corev1.ServiceTypeNodePort
+	assert.True(t, CopyServiceFields(GenerateCommonService(solrCloud), commonService, log), "Changing the service type should require an update")
+	assert.Equal(t, corev1.ServiceTypeClusterIP, commonService.Spec.Type, "A hidden common service should be a ClusterIP service")
+
+	node := &corev1.Node{
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: "node-1"},
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		},
+	}
+	assert.Equal(t, "10.0.0.1:31234", ExternalNodePortAddress(node, 31234), "The internal IP of the node should be used when there is no external IP")
+	node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.1"})
+	assert.Equal(t, "203.0.113.1:31234", ExternalNodePortAddress(node, 31234), "The external IP of the node should be preferred")
+}
+
+func TestServiceImmutableFields(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+	solrCloud.WithDefaults()
+
+	// A headless service can be updated in place, as long as it stays headless
+	headless := GenerateHeadlessService(solrCloud)
+	existingHeadless := headless.DeepCopy()
+	existingHeadless.Spec.PublishNotReadyAddresses = false
+	assert.Empty(t, ServiceRecreateReason(headless, existingHeadless), "A headless service should not need to be recreated to stay headless")
+	assert.True(t, CopyServiceFields(headless, existingHeadless, log), "Changing publishNotReadyAddresses should require an update")
+	assert.False(t, CopyServiceFields(GenerateHeadlessService(solrCloud), existingHeadless, log), "No update should be required once the headless service is reconciled")
+
+	// The clusterIP is immutable, so a service with an allocated clusterIP can never become headless in place
+	existingHeadless.Spec.ClusterIP = "10.0.0.5"
+	assert.NotEmpty(t, ServiceRecreateReason(headless, existingHeadless), "A service with a clusterIP must be recreated to become headless")
+	assert.NotEmpty(t, ServiceRecreateReason(existingHeadless, headless), "A headless service must be recreated to get a clusterIP")
+
+	// The type of a service can be changed in place, keeping its allocated clusterIP
+	commonService := GenerateCommonService(solrCloud)
+	existingCommonService := commonService.DeepCopy()
+	existingCommonService.Spec.ClusterIP = "10.0.0.6"
+	existingCommonService.Spec.Type = corev1.ServiceTypeLoadBalancer
+	existingCommonService.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	existingCommonService.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	assert.Empty(t, ServiceRecreateReason(commonService, existingCommonService), "A type change should not require the service to be recreated")
+	assert.True(t, CopyServiceFields(commonService, existingCommonService, log), "Changing the service type should require an update")
+	assert.Equal(t, corev1.ServiceTypeClusterIP, existingCommonService.Spec.Type, "The service type should be updated in place")
+	assert.Equal(t, "10.0.0.6", existingCommonService.Spec.ClusterIP, "The allocated clusterIP should never be overwritten")
+	assert.Empty(t, existingCommonService.Spec.LoadBalancerSourceRanges, "The LoadBalancer fields should be removed when the type changes")
+	assert.Empty(t, existingCommonService.Spec.ExternalTrafficPolicy, "The externalTrafficPolicy should be removed for ClusterIP services")
+	assert.False(t, CopyServiceFields(GenerateCommonService(solrCloud), existingCommonService, log), "No update should be required once the type is reconciled")
+
+	// A requested clusterIP that differs from the allocated one can only be used by recreating the service
+	commonService.Spec.ClusterIP = "10.0.0.7"
+	assert.NotEmpty(t, ServiceRecreateReason(commonService, existingCommonService), "Changing the clusterIP should require the service to be recreated")
+	CopyServiceFields(commonService, existingCommonService, log)
+	assert.Equal(t, "10.0.0.6", existingCommonService.Spec.ClusterIP, "The clusterIP should not be updated in place")
+}
+
+func TestDualStackServices(t *testing.T) {
+	preferDualStack := corev1.IPFamilyPolicyPreferDualStack
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				NodeServiceOptions: &solr.ServiceOptions{
+					IPFamilyPolicy: &preferDualStack,
+					IPFamilies:     []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+				},
+			},
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:             solr.ExternalDNS,
+					DomainName:         "test.domain.com",
+					UseExternalAddress: true,
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+
+	nodeService := GenerateNodeService(solrCloud, "foo-solrcloud-0")
+	assert.Equal(t, &preferDualStack, nodeService.Spec.IPFamilyPolicy, "The ipFamilyPolicy should be set on the node service")
+	assert.Equal(t, []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}, nodeService.Spec.IPFamilies, "The ipFamilies should be set on the node service")
+	assert.Nil(t, GenerateCommonService(solrCloud).Spec.IPFamilyPolicy, "The ipFamilyPolicy should be left to Kubernetes when it is not provided")
+
+	// The IP families defaulted by Kubernetes should not be overwritten when none are requested
+	commonService := GenerateCommonService(solrCloud)
+	existingCommonService := commonService.DeepCopy()
+	singleStack := corev1.IPFamilyPolicySingleStack
+	existingCommonService.Spec.IPFamilyPolicy = &singleStack
+	existingCommonService.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol}
+	assert.False(t, CopyServiceFields(commonService, existingCommonService, log), "The defaulted IP families should not require an update")
+
+	// A secondary IP family can be added in place, but the primary IP family cannot be changed
+	existingNodeService := nodeService.DeepCopy()
+	existingNodeService.Spec.IPFamilyPolicy = &singleStack
+	existingNodeService.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol}
+	assert.Empty(t, ServiceRecreateReason(nodeService, existingNodeService), "Adding a secondary IP family should not require the service to be recreated")
+	assert.True(t, CopyServiceFields(nodeService, existingNodeService, log), "Adding a secondary IP family should require an update")
+	assert.Equal(t, nodeService.Spec.IPFamilies, existingNodeService.Spec.IPFamilies, "The ipFamilies should be updated in place")
+	existingNodeService.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	assert.NotEmpty(t, ServiceRecreateReason(nodeService, existingNodeService), "Changing the primary IP family should require the service to be recreated")
+
+	// The IPv6 clusterIPs of the node services are used as-is in the host aliases
+	hostAliases := GenerateHostAliases(map[string]string{solrCloud.AdvertisedNodeHost("foo-solrcloud-0"): "fd00::5"}, nil)
+	assert.Equal(t, []corev1.HostAlias{{IP: "fd00::5", Hostnames: []string{"default-foo-solrcloud-0.test.domain.com"}}}, hostAliases, "Wrong host alias for an IPv6 clusterIP")
+
+	// IPv6 addresses must be bracketed when they are used in URLs
+	node := &corev1.Node{Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "fd00::1"}}}}
+	assert.Equal(t, "[fd00::1]:31234", ExternalNodePortAddress(node, 31234), "The IPv6 address of the node should be bracketed")
+	assert.Equal(t, "[2001:db8::1]", UrlHost("2001:db8::1"), "An IPv6 load balancer address should be bracketed")
+	assert.Equal(t, "203.0.113.1", UrlHost("203.0.113.1"), "An IPv4 load balancer address should be used as-is")
+	assert.Equal(t, "lb.example.com", UrlHost("lb.example.com"), "A load balancer hostname should be used as-is")
+}
+
+func TestLoadBalancerServices(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				PodPort:           8983,
+				CommonServicePort: 80,
+				External: &solr.ExternalAddressability{
+					Method:             solr.LoadBalancer,
+					DomainName:         "test.domain.com",
+					UseExternalAddress: true,
+				},
+			},
+		},
+	}
+	assert.True(t, solrCloud.UsesIndividualNodeServices(), "The LoadBalancer method should use individual node services")
+	assert.Equal(t, "default-foo-solrcloud-0.test.domain.com", solrCloud.AdvertisedNodeHost("foo-solrcloud-0"), "Wrong advertised host for the LoadBalancer method")
+
+	nodeService := GenerateNodeService(solrCloud, "foo-solrcloud-0")
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, nodeService.Spec.Type, "The node service should be a LoadBalancer service")
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, GenerateCommonService(solrCloud).Spec.Type, "The common service should be a LoadBalancer service")
+
+	solrCloud.Spec.SolrAddressability.External.HideNodes = true
+	assert.False(t, solrCloud.UsesIndividualNodeServices(), "Hidden nodes should not use individual node services")
+
+	assert.Empty(t, LoadBalancerAddress(nodeService), "There is no load balancer address before the load balancer is provisioned")
+	nodeService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.1"}}
+	assert.Equal(t, "203.0.113.1", LoadBalancerAddress(nodeService), "The load balancer IP should be used when there is no hostname")
+	nodeService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "lb.example.com", IP: "203.0.113.1"}}
+	assert.Equal(t, "lb.example.com", LoadBalancerAddress(nodeService), "The load balancer hostname should be preferred")
+}
+
+func TestCommonServiceType(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				CommonServicePort: 80,
+			},
+		},
+	}
+	assert.Equal(t, corev1.ServiceTypeClusterIP, GenerateCommonService(solrCloud).Spec.Type, "The common service should default to ClusterIP")
+
+	solrCloud.Spec.CustomSolrKubeOptions.CommonServiceOptions = &solr.ServiceOptions{Type: corev1.ServiceTypeLoadBalancer}
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, GenerateCommonService(solrCloud).Spec.Type, "The configured type should be used for the common service")
+
+	solrCloud.Spec.SolrAddressability.External = &solr.ExternalAddressability{Method: solr.NodePort, DomainName: "test.domain.com"}
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, CommonServiceType(solrCloud), "The configured type should take precedence over the external addressability method")
+
+	// Changing a LoadBalancer service to ClusterIP should remove the fields that are only valid for LoadBalancers
+	existingService := GenerateCommonService(solrCloud)
+	existingService.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	existingService.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	existingService.Spec.HealthCheckNodePort = 31000
+	existingService.Spec.Ports[0].NodePort = 31001
+	solrCloud.Spec.CustomSolrKubeOptions.CommonServiceOptions.Type = corev1.ServiceTypeClusterIP
+	assert.True(t, CopyServiceFields(GenerateCommonService(solrCloud), existingService, log), "Changing the service type should require an update")
+	assert.Equal(t, corev1.ServiceTypeClusterIP, existingService.Spec.Type, "The service type should be updated")
+	assert.Nil(t, existingService.Spec.LoadBalancerSourceRanges, "The loadBalancerSourceRanges should be removed")
+	assert.Empty(t, existingService.Spec.ExternalTrafficPolicy, "The externalTrafficPolicy should be removed")
+	assert.Zero(t, existingService.Spec.HealthCheckNodePort, "The healthCheckNodePort should be removed")
+	assert.Zero(t, existingService.Spec.Ports[0].NodePort, "The nodePort should be removed")
+}
+
+func TestRestartAnnotation(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+		},
+	})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.NotContains(t, statefulSet.Spec.Template.Annotations, SolrRestartAnnotation, "The pods should not have a restart annotation when the SolrCloud does not")
+
+	solrCloud.Annotations = map[string]string{SolrRestartAnnotation: "1"}
+	restartedStatefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Equal(t, "1", restartedStatefulSet.Spec.Template.Annotations[SolrRestartAnnotation], "The restart annotation of the SolrCloud should be copied to the pods")
+	assert.True(t, CopyStatefulSetFields(restartedStatefulSet, statefulSet, log), "Adding the restart annotation should require an update of the StatefulSet")
+	assert.False(t, CopyStatefulSetFields(GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, ""), statefulSet, log), "An unchanged restart annotation should not require an update of the StatefulSet")
+
+	solrCloud.Annotations[SolrRestartAnnotation] = "2"
+	assert.True(t, CopyStatefulSetFields(GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, ""), statefulSet, log), "Changing the restart annotation should require an update of the StatefulSet")
+	assert.Equal(t, "2", statefulSet.Spec.Template.Annotations[SolrRestartAnnotation], "The new restart annotation should be used for the pods")
+}
+
+func TestCommonLabelsAndAnnotations(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+			CommonLabels:      map[string]string{"team": "search", "technology": "other", "service-type": "other"},
+			CommonAnnotations: map[string]string{"owner": "search-team", SolrZKConnectionStringAnnotation: "other"},
+			CommonServiceOptions: &solr.ServiceOptions{
+				Labels: map[string]string{"team": "search-common"},
+			},
+		},
+	})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{SolrXmlMd5Annotation: "md5"}, false, "")
+	assert.Equal(t, "search", statefulSet.Labels["team"], "The common labels should be added to the StatefulSet")
+	assert.Equal(t, solr.SolrTechnologyLabel, statefulSet.Labels["technology"], "The common labels should not override the labels of the Solr Operator")
+	assert.Equal(t, "search-team", statefulSet.Annotations["owner"], "The common annotations should be added to the StatefulSet")
+	assert.Equal(t, solrCloudStatus.ZkConnectionString(), statefulSet.Annotations[SolrZKConnectionStringAnnotation], "The common annotations should not override the annotations of the Solr Operator")
+	assert.Equal(t, "search", statefulSet.Spec.Template.Labels["team"], "The common labels should be added to the Solr pods")
+	assert.Equal(t, "search-team", statefulSet.Spec.Template.Annotations["owner"], "The common annotations should be added to the Solr pods")
+	assert.Equal(t, "md5", statefulSet.Spec.Template.Annotations[SolrXmlMd5Annotation], "The annotations of the Solr Operator should still be added to the Solr pods")
+	assert.NotContains(t, statefulSet.Spec.Selector.MatchLabels, "team", "The common labels should not be added to the selector of the StatefulSet")
+
+	commonService := GenerateCommonService(solrCloud)
+	assert.Equal(t, "search-common", commonService.Labels["team"], "The labels of the common service options should override the common labels")
+	assert.Equal(t, "common", commonService.Labels["service-type"], "The common labels should not override the labels of the Solr Operator")
+	assert.Equal(t, "search-team", commonService.Annotations["owner"], "The common annotations should be added to the common service")
+
+	assert.Equal(t, "search", GenerateHeadlessService(solrCloud).Labels["team"], "The common labels should be added to the headless service")
+	assert.Equal(t, "search", GenerateNodeService(solrCloud, "foo-solrcloud-0").Labels["team"], "The common labels should be added to the node services")
+	assert.Equal(t, "search-team", GenerateConfigMap(solrCloud).Annotations["owner"], "The common annotations should be added to the ConfigMap")
+	assert.Equal(t, "search", GeneratePodDisruptionBudget(solrCloud).Labels["team"], "The common labels should be added to the PodDisruptionBudget")
+
+	basicAuthSecret, bootstrapSecret := GenerateBasicAuthSecretWithBootstrap(solrCloud)
+	assert.Equal(t, "search", basicAuthSecret.Labels["team"], "The common labels should be added to the basic auth secret")
+	assert.Equal(t, "search-team", bootstrapSecret.Annotations["owner"], "The common annotations should be added to the security bootstrap secret")
+
+	// Updates should add the common labels without removing the labels of the Solr Operator
+	oldService := GenerateCommonService(&solr.SolrCloud{ObjectMeta: solrCloud.ObjectMeta, Spec: solr.SolrCloudSpec{SolrAddressability: solrCloud.Spec.SolrAddressability}})
+	assert.True(t, CopyServiceFields(commonService, oldService, log), "Adding common labels should require an update of the service")
+	assert.Equal(t, "common", oldService.Labels["service-type"], "The labels of the Solr Operator should be kept when updating the service")
+	assert.Equal(t, "search-common", oldService.Labels["team"], "The custom labels should be added when updating the service")
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.EqualValues(t, DefaultSolrTerminationGracePeriodSeconds, *statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, "Wrong default termination grace period")
+	assert.EqualValues(t, DefaultSolrTerminationGracePeriodSeconds, SolrTerminationGracePeriodSeconds(solrCloud), "Wrong default termination grace period")
+	assert.Equal(t, []string{"solr", "stop", "-p", "8983"}, statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command, "Solr should be stopped gracefully by default")
+	assert.Contains(t, statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "SOLR_STOP_WAIT", Value: "55"}, "Solr should be given less time to stop than the termination grace period")
+
+	gracePeriod := int64(300)
+	preStop := &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"sh", "-c", "/scripts/drain.sh && solr stop -p 8983"}}}
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{
+		TerminationGracePeriodSeconds: &gracePeriod,
+		PreStop:                       preStop,
+	}
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.EqualValues(t, gracePeriod, *statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds, "The custom termination grace period should be used")
+	assert.EqualValues(t, gracePeriod, SolrTerminationGracePeriodSeconds(solrCloud), "The custom termination grace period should be used")
+	assert.Equal(t, preStop, statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PreStop, "The custom preStop hook should replace the default one")
+	assert.Contains(t, statefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "SOLR_STOP_WAIT", Value: "295"}, "Solr should be given less time to stop than the termination grace period")
+}
+
+func TestMaxStartupSeconds(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Nil(t, statefulSet.Spec.Template.Spec.Containers[0].StartupProbe, "There should be no startupProbe by default")
+
+	maxStartupSeconds := int32(1805)
+	solrCloud.Spec.MaxStartupSeconds = &maxStartupSeconds
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	startupProbe := statefulSet.Spec.Template.Spec.Containers[0].StartupProbe
+	assert.NotNil(t, startupProbe, "A startupProbe should be added for the maxStartupSeconds")
+	assert.EqualValues(t, DefaultStartupProbeInitialDelaySeconds, startupProbe.InitialDelaySeconds, "The default startupProbe settings should be used")
+	assert.EqualValues(t, DefaultStartupProbePeriodSeconds, startupProbe.PeriodSeconds, "The default startupProbe settings should be used")
+	assert.EqualValues(t, 179, startupProbe.FailureThreshold, "The failureThreshold should allow Solr to start for at least the maxStartupSeconds")
+	assert.Equal(t, statefulSet.Spec.Template.Spec.Containers[0].LivenessProbe.Handler, startupProbe.Handler, "The startupProbe should check the same endpoint as the liveness probe")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{
+		StartupProbe: &corev1.Probe{InitialDelaySeconds: 60, PeriodSeconds: 30},
+	}
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.EqualValues(t, 59, statefulSet.Spec.Template.Spec.Containers[0].StartupProbe.FailureThreshold, "The failureThreshold should be computed from the custom startupProbe settings")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.StartupProbe.FailureThreshold = 10
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.EqualValues(t, 10, statefulSet.Spec.Template.Spec.Containers[0].StartupProbe.FailureThreshold, "The failureThreshold of the custom startupProbe should take precedence")
+
+	maxStartupSeconds = 5
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.StartupProbe.FailureThreshold = 0
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.EqualValues(t, 1, statefulSet.Spec.Template.Spec.Containers[0].StartupProbe.FailureThreshold, "The failureThreshold should be at least 1")
+}
+
+func TestCustomPostStartHook(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+		},
+	})
+
+	postStart := &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"/scripts/warm-caches.sh", "--query", "*:*"}}}
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{PostStart: postStart}
+	assert.NoError(t, ValidateSolrLifecycleHooks(solrCloud), "An exec postStart hook should be allowed alongside the chroot hook")
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	lifecycle := statefulSet.Spec.Template.Spec.Containers[0].Lifecycle
+	assert.Equal(t, []string{"sh", "-c", "(solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} || solr zk mkroot ${ZK_CHROOT} -z ${ZK_SERVER}) && exec \"$@\"", "postStart", "/scripts/warm-caches.sh", "--query", "*:*"}, lifecycle.PostStart.Exec.Command, "The custom postStart hook should be run after the chroot is created")
+	assert.Equal(t, []string{"solr", "stop", "-p", "8983"}, lifecycle.PreStop.Exec.Command, "The default preStop hook should be kept")
+
+	solrCloud.Spec.ZookeeperRef.WaitForZookeeper = true
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Equal(t, postStart, statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PostStart, "The custom postStart hook should be used as-is when the chroot is created by the init container")
+
+	httpPostStart := &corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/register", Port: intstr.FromInt(8080)}}
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.PostStart = httpPostStart
+	assert.NoError(t, ValidateSolrLifecycleHooks(solrCloud), "Any postStart handler should be allowed when the chroot is created by the init container")
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Equal(t, httpPostStart, statefulSet.Spec.Template.Spec.Containers[0].Lifecycle.PostStart, "The custom postStart hook should be used as-is when the chroot is created by the init container")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.PostStart = &corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(8080)}}
+	assert.Error(t, ValidateSolrLifecycleHooks(solrCloud), "A tcpSocket postStart hook should be rejected, even when the chroot is created by the init container")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions.PostStart = httpPostStart
+	solrCloud.Spec.ZookeeperRef.WaitForZookeeper = false
+	assert.Error(t, ValidateSolrLifecycleHooks(solrCloud), "Only exec postStart hooks can be run alongside the chroot hook")
+}
+
+func TestValidateLifecycleHooks(t *testing.T) {
+	assert.NoError(t, ValidateLifecycleHooks(nil), "No pod options should be valid")
+	assert.NoError(t, ValidateLifecycleHooks(&solr.PodOptions{
+		PreStop:   &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"solr", "stop"}}},
+		PostStart: &corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/register", Port: intstr.FromInt(8080)}},
+	}), "Hooks with an exec or httpGet handler should be valid")
+
+	assert.Error(t, ValidateLifecycleHooks(&solr.PodOptions{
+		PostStart: &corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(8080)}},
+	}), "A postStart hook with a tcpSocket handler should be rejected")
+	assert.Error(t, ValidateLifecycleHooks(&solr.PodOptions{
+		PreStop: &corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(8080)}},
+	}), "A preStop hook with a tcpSocket handler should be rejected")
+
+	assert.Error(t, ValidateLifecycleHooks(&solr.PodOptions{
+		PreStop: &corev1.Handler{Exec: &corev1.ExecAction{}},
+	}), "A preStop hook without a command should be rejected")
+	assert.Error(t, ValidateLifecycleHooks(&solr.PodOptions{
+		PostStart: &corev1.Handler{Exec: &corev1.ExecAction{Command: []string{" "}}},
+	}), "A postStart hook with a blank command should be rejected")
+	assert.Error(t, ValidateLifecycleHooks(&solr.PodOptions{
+		PostStart: &corev1.Handler{},
+	}), "A postStart hook without a handler should be rejected")
+}
+
+func TestValidateExternalAddressability(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		Spec: solr.SolrCloudSpec{
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{Method: solr.Ingress, DomainName: "test.domain.com", UseExternalAddress: true},
+			},
+		},
+	}
+	extOpts := solrCloud.Spec.SolrAddressability.External
+	assert.NoError(t, ValidateExternalAddressability(solrCloud), "Valid Ingress addressability should be accepted")
+
+	extOpts.DomainName = ""
+	assert.Error(t, ValidateExternalAddressability(solrCloud), "The Ingress method requires a domainName")
+	extOpts.Method = solr.ExternalDNS
+	assert.Error(t, ValidateExternalAddressability(solrCloud), "The ExternalDNS method requires a domainName")
+	extOpts.Method = solr.LoadBalancer
+	assert.Error(t, ValidateExternalAddressability(solrCloud), "The LoadBalancer method requires a domainName to use the external address")
+	extOpts.UseExternalAddress = false
+	assert.NoError(t, ValidateExternalAddressability(solrCloud), "The LoadBalancer method does not require a domainName when the external address is not used")
+
+	// Combinations that are corrected by the defaulting of the SolrCloud are accepted
+	extOpts.UseExternalAddress = true
+	extOpts.HideNodes = true
+	assert.NoError(t, ValidateExternalAddressability(solrCloud), "useExternalAddress with hideNodes is corrected by the defaults, so no domainName is required")
+
+	extOpts.DomainName = "test.domain.com"
+	extOpts.HideNodes = false
+	extOpts.Method = solr.NodePort
+	assert.NoError(t, ValidateExternalAddressability(solrCloud), "useExternalAddress with the NodePort method is corrected by the defaults")
+	extOpts.UseExternalAddress = false
+	extOpts.BaseNodePort = 30000
+	assert.NoError(t, ValidateExternalAddressability(solrCloud), "A baseNodePort can be used with the NodePort method")
+	extOpts.Method = solr.Ingress
+	assert.NoError(t, ValidateExternalAddressability(solrCloud), "A baseNodePort with another method is corrected by the defaults")
+
+	extOpts.BaseNodePort = 0
+	extOpts.Method = solr.ExternalDNS
+	extOpts.IngressTLSTermination = &solr.SolrIngressTLSTermination{Mode: solr.IngressTLSTerminationEdge, TLSSecret: "tls-secret"}
+	assert.Error(t, ValidateExternalAddressability(solrCloud), "ingressTLSTermination can only be used with the Ingress method")
+}
+
+func TestContextPath(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		SolrAddressability: solr.SolrAddressabilityOptions{
+			External: &solr.ExternalAddressability{
+				Method:     solr.Ingress,
+				DomainName: "test.domain.com",
+			},
+		},
+	})
+	nodeNames := []string{"foo-solrcloud-0"}
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Equal(t, "/solr"+DefaultProbePath, statefulSet.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet.Path, "Solr should be probed under the default context path")
+	assert.NotContains(t, filterEnvVarsByName(statefulSet.Spec.Template.Spec.Containers[0].Env, "SOLR_OPTS")[0].Value, "-DhostContext", "The default context path should not be passed to Solr")
+	assert.Empty(t, GenerateIngress(solrCloud, nodeNames).Spec.Rules[0].HTTP.Paths[0].Path, "The whole host should be routed to Solr by default")
+	assert.NoError(t, ValidateContextPath(solrCloud), "No contextPath should be valid")
+
+	solrCloud.Spec.SolrAddressability.ContextPath = "/search"
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Equal(t, "/search"+DefaultProbePath, statefulSet.Spec.Template.Spec.Containers[0].LivenessProbe.HTTPGet.Path, "Solr should be probed under the custom context path")
+	assert.Contains(t, filterEnvVarsByName(statefulSet.Spec.Template.Spec.Containers[0].Env, "SOLR_OPTS")[0].Value, "-DhostContext=/search", "The custom context path should be passed to Solr")
+	for _, rule := range GenerateIngress(solrCloud, nodeNames).Spec.Rules {
+		assert.Equal(t, "/search", rule.HTTP.Paths[0].Path, "Only the custom context path should be routed to Solr by the Ingress rule for %s", rule.Host)
+	}
+	assert.Contains(t, ReservedSolrSystemProperties(solrCloud), "hostContext", "The context path system property should be managed by the operator")
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{
+		LivenessProbe: &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/search/admin/ping"}}},
+	}
+	assert.Contains(t, string(generateSecurityJson(solrCloud)[SecurityJsonFile]), "\"path\":\"/admin/ping\"", "The probe permissions of security.json should be relative to the context path")
+	assert.NoError(t, ValidateContextPath(solrCloud), "A contextPath starting with a slash should be valid")
+	assert.Error(t, ValidateProvidedSolrXml(solrCloud, "custom-config", "<solr><int name=\"hostPort\">${hostPort:80}</int></solr>"), "A provided solr.xml must contain a hostContext placeholder to use a custom contextPath")
+
+	solrCloud.Spec.SolrAddressability.ContextPath = "search"
+	assert.Error(t, ValidateContextPath(solrCloud), "The contextPath must start with a slash")
+	solrCloud.Spec.SolrAddressability.ContextPath = "/"
+	assert.Error(t, ValidateContextPath(solrCloud), "Solr cannot be served from the root context path")
+	solrCloud.Spec.SolrAddressability.ContextPath = "/search/"
+	assert.Error(t, ValidateContextPath(solrCloud), "The contextPath cannot end with a slash")
+	solrCloud.Spec.SolrAddressability.ContextPath = "/search?q=1"
+	assert.Error(t, ValidateContextPath(solrCloud), "The contextPath must be a plain URL path")
+}
+
+func TestValidateSolrTLSAndProvidedSolrXml(t *testing.T) {
+	solrCloud := &solr.SolrCloud{}
+	assert.NoError(t, ValidateSolrTLS(solrCloud), "A SolrCloud without TLS should be accepted")
+
+	solrCloud.Spec.SolrTLS = &solr.SolrTLSOptions{
+		KeyStorePasswordSecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "pkcs12-password"}, Key: "password"},
+	}
+	assert.Error(t, ValidateSolrTLS(solrCloud), "TLS requires a pkcs12Secret")
+	solrCloud.Spec.SolrTLS.PKCS12Secret = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "pkcs12"}, Key: "keystore.p12"}
+	assert.NoError(t, ValidateSolrTLS(solrCloud), "TLS with a keystore and password should be accepted")
+	solrCloud.Spec.SolrTLS.ClientAuth = solr.Need
+	assert.Error(t, ValidateSolrTLS(solrCloud), "Requiring client certs requires a clientCertSecret for the Solr Operator")
+	solrCloud.Spec.SolrTLS.ClientCertSecret = "operator-client-cert"
+	assert.NoError(t, ValidateSolrTLS(solrCloud), "Requiring client certs with a clientCertSecret should be accepted")
+	solrCloud.Spec.SolrTLS.KeyStorePasswordSecret = nil
+	assert.Error(t, ValidateSolrTLS(solrCloud), "TLS requires a keyStorePasswordSecret")
+
+	assert.Error(t, ValidateProvidedSolrXml(solrCloud, "custom-config", "<solr></solr>"), "A provided solr.xml must contain a hostPort placeholder")
+	assert.NoError(t, ValidateProvidedSolrXml(solrCloud, "custom-config", "<solr><int name=\"hostPort\">${hostPort:80}</int></solr>"), "A provided solr.xml with a hostPort placeholder should be accepted")
+}
+
+func TestNodePoolStatefulSet(t *testing.T) {
+	replicas := int32(2)
+	storageCapacity := resource.MustParse("500Gi")
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		Replicas: &replicas,
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+		},
+		StorageOptions: solr.SolrDataStorageOptions{
+			PersistentStorage: &solr.SolrPersistentDataStorageOptions{
+				PersistentVolumeClaimTemplate: solr.PersistentVolumeClaimTemplate{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")}},
+					},
+				},
+			},
+		},
+		NodePools: []solr.SolrNodePool{
+			{
+				Name:            "large",
+				Replicas:        3,
+				SolrJavaMem:     "-Xms8g -Xmx8g",
+				Resources:       corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")}},
+				NodeSelector:    map[string]string{"instance-type": "large"},
+				StorageCapacity: &storageCapacity,
+			},
+		},
+	})
+
+	assert.EqualValues(t, 5, solrCloud.TotalReplicas(), "The node pool replicas should be included in the total replicas")
+	assert.Equal(t, []string{"foo-solrcloud-0", "foo-solrcloud-1", "foo-solrcloud-large-0", "foo-solrcloud-large-1", "foo-solrcloud-large-2"}, solrCloud.GetAllSolrNodeNames(), "Wrong Solr node names with a node pool")
+	assert.Equal(t, "large", solrCloud.NodePoolOfNode("foo-solrcloud-large-2"), "Wrong node pool for a node of the pool")
+	assert.Equal(t, "", solrCloud.NodePoolOfNode("foo-solrcloud-1"), "Nodes of the SolrCloud's StatefulSet are not part of a node pool")
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	poolStatefulSet := GenerateNodePoolStatefulSet(solrCloud, solrCloud.Spec.NodePools[0], solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+
+	assert.Equal(t, "foo-solrcloud-large", poolStatefulSet.Name, "Wrong name for the node pool StatefulSet")
+	assert.EqualValues(t, 3, *poolStatefulSet.Spec.Replicas, "Wrong replicas for the node pool StatefulSet")
+	assert.Equal(t, statefulSet.Spec.ServiceName, poolStatefulSet.Spec.ServiceName, "The node pool should use the headless service of the SolrCloud")
+	assert.Equal(t, "large", poolStatefulSet.Spec.Selector.MatchLabels[SolrNodePoolLabel], "The node pool StatefulSet should only select the pods of its pool")
+	assert.Equal(t, "large", poolStatefulSet.Spec.Template.Labels[SolrNodePoolLabel], "The node pool pods should be labeled with their pool")
+	assert.NotContains(t, statefulSet.Spec.Template.Labels, SolrNodePoolLabel, "The pods of the SolrCloud's StatefulSet should not be labeled with a node pool")
+	for key, value := range statefulSet.Spec.Selector.MatchLabels {
+		assert.Equal(t, value, poolStatefulSet.Spec.Template.Labels[key], "The node pool pods should be selected by the services of the SolrCloud")
+	}
+
+	assert.Equal(t, resource.MustParse("16Gi"), poolStatefulSet.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceMemory], "The node pool resources should be used")
+	assert.NotContains(t, statefulSet.Spec.Template.Spec.Containers[0].Resources.Requests, corev1.ResourceMemory, "The node pool resources should not be used by the SolrCloud's StatefulSet")
+	assert.Equal(t, map[string]string{"instance-type": "large"}, poolStatefulSet.Spec.Template.Spec.NodeSelector, "The node pool nodeSelector should be used")
+	assert.Contains(t, poolStatefulSet.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "SOLR_JAVA_MEM", Value: "-Xms8g -Xmx8g"}, "The node pool heap should be used")
+	assert.Equal(t, storageCapacity, poolStatefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage], "The node pool storage capacity should be used")
+	assert.Equal(t, resource.MustParse("100Gi"), statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage], "The node pool storage capacity should not be used by the SolrCloud's StatefulSet")
+
+	poolPVC := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-foo-solrcloud-large-2", Labels: map[string]string{SolrNodePoolLabel: "large"}}}
+	assert.False(t, IsSolrCloudPVCOrphan(solrCloud, poolPVC), "A PVC of a node pool should use the replicas of the pool")
+	assert.True(t, IsSolrCloudPVCOrphan(solrCloud, corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-foo-solrcloud-2"}}), "A PVC of the SolrCloud's StatefulSet should use the replicas of the SolrCloud")
+	solrCloud.Spec.NodePools = nil
+	assert.True(t, IsSolrCloudPVCOrphan(solrCloud, poolPVC), "The PVCs of a removed node pool should be orphaned")
+}
+
+func TestAdditionalConfigMaps(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				ConfigMapOptions: &solr.ConfigMapOptions{
+					ProvidedConfigMap: "solr-xml",
+					AdditionalConfigMaps: []solr.AdditionalConfigMap{
+						{Name: "config-a", MountPath: "/opt/config-a"},
+						{Name: "solr-xml", MountPath: "/opt/config-b"},
+					},
+				},
+			},
+		},
+	}
+	assert.NoError(t, ValidateAdditionalConfigMaps(solrCloud), "Additional ConfigMaps with unique absolute mount paths are valid")
+	assert.Equal(t, []string{"solr-xml", "config-a"}, solrCloud.ProvidedConfigMapNames(), "Each provided ConfigMap should be listed once")
+
+	solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.AdditionalConfigMaps[1].MountPath = "/opt/config-a/"
+	assert.Error(t, ValidateAdditionalConfigMaps(solrCloud), "Additional ConfigMaps cannot share a mount path")
+	solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.AdditionalConfigMaps[1].MountPath = "opt/config-b"
+	assert.Error(t, ValidateAdditionalConfigMaps(solrCloud), "Additional ConfigMaps must use absolute mount paths")
+	solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions.AdditionalConfigMaps[1].MountPath = "/var/solr/data"
+	assert.Error(t, ValidateAdditionalConfigMaps(solrCloud), "Additional ConfigMaps cannot be mounted over the Solr data")
+}
+
+func TestMountedConfigMapsAndSecrets(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					Volumes: []solr.AdditionalVolume{
+						{
+							Name:                  "config",
+							Source:                corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config-a"}}},
+							DefaultContainerMount: &corev1.VolumeMount{MountPath: "/opt/config"},
+						},
+						{
+							Name:                  "credentials",
+							Source:                corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "secret-a"}},
+							DefaultContainerMount: &corev1.VolumeMount{MountPath: "/opt/credentials"},
+						},
+						{
+							Name: "projected",
+							Source: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+								{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "config-b"}}},
+								{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-a"}}},
+							}}},
+							DefaultContainerMount: &corev1.VolumeMount{MountPath: "/opt/projected"},
+						},
+						{
+							Name:   "sidecar-only",
+							Source: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "sidecar-config"}}},
+						},
+						{
+							Name:                  "hot-reloaded",
+							Source:                corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "reloaded-config"}}},
+							DefaultContainerMount: &corev1.VolumeMount{MountPath: "/opt/reloaded"},
+							SkipRestartOnChange:   true,
+						},
+					},
+				},
+			},
+		},
+	}
+	configMaps, secrets := MountedVolumeConfigMapsAndSecrets(solrCloud)
+	assert.Equal(t, []string{"config-a", "config-b"}, configMaps, "Only ConfigMaps mounted into the Solr container, without skipRestartOnChange, should be tracked")
+	assert.Equal(t, []string{"secret-a"}, secrets, "Each Secret mounted into the Solr container should be tracked once")
+
+	configMapA := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config-a"}, Data: map[string]string{"a": "1", "b": "2"}}
+	configMapB := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config-b"}, BinaryData: map[string][]byte{"c": []byte("3")}}
+	secretA := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret-a"}, Data: map[string][]byte{"password": []byte("pass")}}
+	configMd5 := MountedConfigMd5([]*corev1.ConfigMap{configMapA, configMapB}, []*corev1.Secret{secretA})
+	assert.Equal(t, configMd5, MountedConfigMd5([]*corev1.ConfigMap{configMapA.DeepCopy(), configMapB.DeepCopy()}, []*corev1.Secret{secretA.DeepCopy()}), "The MD5 should not change when the contents are the same")
+	configMapB.BinaryData["c"] = []byte("4")
+	newConfigMd5 := MountedConfigMd5([]*corev1.ConfigMap{configMapA, configMapB}, []*corev1.Secret{secretA})
+	assert.NotEqual(t, configMd5, newConfigMd5, "The MD5 should change when the contents of a ConfigMap change")
+	secretA.Data["password"] = []byte("newPass")
+	assert.NotEqual(t, newConfigMd5, MountedConfigMd5([]*corev1.ConfigMap{configMapA, configMapB}, []*corev1.Secret{secretA}), "The MD5 should change when the contents of a Secret change")
+}
+
+func TestRequiredEnvFromConfigMapsAndSecrets(t *testing.T) {
+	optional := true
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+				PodOptions: &solr.PodOptions{
+					EnvFrom: []corev1.EnvFromSource{
+						{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config-a"}}},
+						{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-a"}}},
+						{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config-b"}, Optional: &optional}},
+						{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-b"}, Optional: &optional}},
+					},
+				},
+			},
+		},
+	}
+	configMaps, secrets := RequiredEnvFromConfigMapsAndSecrets(solrCloud)
+	assert.Equal(t, []string{"config-a"}, configMaps, "Only the ConfigMaps that are not optional are required")
+	assert.Equal(t, []string{"secret-a"}, secrets, "Only the Secrets that are not optional are required")
+
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = nil
+	configMaps, secrets = RequiredEnvFromConfigMapsAndSecrets(solrCloud)
+	assert.Empty(t, configMaps, "No ConfigMaps are required without podOptions")
+	assert.Empty(t, secrets, "No Secrets are required without podOptions")
+}
+
+func TestInlineLogXml(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrLogXml: "<Configuration/>",
+		},
+	}
+	assert.NoError(t, ValidateSolrLogXml(solrCloud), "An inline log4j2.xml is valid on its own")
+	assert.Equal(t, "<Configuration/>", GenerateConfigMap(solrCloud).Data[LogXmlFile], "The inline log4j2.xml should be stored in the generated ConfigMap")
+	assert.Contains(t, ReservedSolrSystemProperties(solrCloud), "log4j.configurationFile", "The log4j configuration file is managed by the operator when a custom log4j2.xml is provided")
+
+	solrCloud.Spec.CustomSolrKubeOptions.ConfigMapOptions = &solr.ConfigMapOptions{ProvidedLogXmlConfigMap: "log-xml"}
+	assert.Error(t, ValidateSolrLogXml(solrCloud), "An inline log4j2.xml cannot be used alongside a providedLogXmlConfigMap")
+
+	solrCloud.Spec.SolrLogXml = ""
+	_, hasLogXml := GenerateConfigMap(solrCloud).Data[LogXmlFile]
+	assert.False(t, hasLogXml, "The generated ConfigMap should not contain a log4j2.xml unless one is given inline")
+}
+
+func TestImagePullSecretsAndPolicy(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/"},
+		},
+		SolrImage:    &solr.ContainerImage{Repository: "registry.example.com/solr", PullPolicy: corev1.PullIfNotPresent, ImagePullSecret: "solr-registry"},
+		BusyBoxImage: &solr.ContainerImage{Repository: "mirror.example.com/busybox", ImagePullSecret: "mirror-registry"},
+		CustomSolrKubeOptions: solr.CustomSolrKubeOptions{
+			PodOptions: &solr.PodOptions{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "shared-registry"}}},
+		},
+	})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "shared-registry"}, {Name: "solr-registry"}, {Name: "mirror-registry"}}, statefulSet.Spec.Template.Spec.ImagePullSecrets, "The pull secrets of the pod options, Solr image and BusyBox image should all be used")
+	assert.Equal(t, corev1.PullIfNotPresent, statefulSet.Spec.Template.Spec.Containers[0].ImagePullPolicy, "The Solr container should use the pull policy of the Solr image")
+
+	solrCloud.Spec.BusyBoxImage.ImagePullSecret = "solr-registry"
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "shared-registry"}, {Name: "solr-registry"}}, statefulSet.Spec.Template.Spec.ImagePullSecrets, "A pull secret shared by the Solr and BusyBox images should only be listed once")
+
+	solrCloud.Spec.SolrImage.PullPolicy = corev1.PullAlways
+	assert.True(t, CopyStatefulSetFields(GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, ""), statefulSet, log), "Changing the pull policy should require an update of the StatefulSet")
+	assert.Equal(t, corev1.PullAlways, statefulSet.Spec.Template.Spec.Containers[0].ImagePullPolicy, "The new pull policy should be copied to the StatefulSet")
+}
+
+func TestFailingPodContainer(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-solrcloud-0"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{
+				{Name: "cp-solr-xml", Image: "library/busybox:1.28.0-glibc", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: SolrNodeContainer, Image: "library/solr:8.8.0", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+			},
+		},
+	}
+	assert.Empty(t, FailingPodContainer(pod), "A container that is still being created is not failing")
+
+	pod.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image \"library/solr:8.8.0\""}
+	assert.Equal(t, "pod foo-solrcloud-0 failing: ImagePullBackOff (container solrcloud-node, image library/solr:8.8.0)", FailingPodContainer(pod), "Wrong description of a container whose image cannot be pulled")
+
+	pod.Status.InitContainerStatuses[0].State = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	assert.Equal(t, "pod foo-solrcloud-0 failing: CrashLoopBackOff (container cp-solr-xml, image library/busybox:1.28.0-glibc)", FailingPodContainer(pod), "A failing init container should be reported first")
+}
+
+func TestValidateContainerImages(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			SolrImage:    &solr.ContainerImage{Repository: "registry.example.com:5000/solr"},
+			BusyBoxImage: &solr.ContainerImage{Repository: "mirror.example.com/library/busybox", Tag: "1.28.0-glibc"},
+		},
+	}
+	assert.NoError(t, ValidateSolrCloudImages(solrCloud), "Images with a registry port and without a tag are valid")
+	solrCloud.WithDefaults()
+	assert.NoError(t, ValidateSolrCloudImages(solrCloud), "The default images are valid")
+	assert.Equal(t, solr.DefaultSolrVersion, solr.ImageVersion(solrCloud.Spec.SolrImage.ToImageName()), "The version of an image with a registry port should be its tag")
+
+	solrCloud.Spec.BusyBoxImage.Tag = "1.28:glibc"
+	assert.Error(t, ValidateSolrCloudImages(solrCloud), "Image tags cannot contain a colon")
+	solrCloud.Spec.BusyBoxImage.Tag = "1.28.0-glibc"
+	solrCloud.Spec.SolrImage.Repository = "registry.example.com:5000/solr:8.11"
+	assert.Error(t, ValidateSolrCloudImages(solrCloud), "The tag of an image cannot be given in its repository")
+	assert.Error(t, ValidateContainerImage("image", &solr.ContainerImage{Repository: "solr", Tag: ".8"}), "Image tags cannot start with a period")
+}
+
+func TestInitContainerResources(t *testing.T) {
+	solrCloud, solrCloudStatus := newTestSolrCloud(solr.SolrCloudSpec{
+		ZookeeperRef: &solr.ZookeeperRef{
+			ConnectionInfo:   &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/solr"},
+			WaitForZookeeper: true,
+		},
+	})
+
+	statefulSet := GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	for _, container := range statefulSet.Spec.Template.Spec.InitContainers {
+		assert.Equal(t, DefaultInitContainerResources(), container.Resources, "The %s init container should be given the default resources", container.Name)
+	}
+
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	solrCloud.Spec.CustomSolrKubeOptions.PodOptions = &solr.PodOptions{InitContainerResources: resources}
+	statefulSet = GenerateStatefulSet(solrCloud, solrCloudStatus, map[string]string{}, map[string]string{}, false, "")
+	for _, container := range statefulSet.Spec.Template.Spec.InitContainers {
+		assert.Equal(t, *resources, container.Resources, "The %s init container should be given the provided resources", container.Name)
+	}
+
+	pkcs12Container := generatePkcs12InitContainer(&solr.SolrTLSOptions{}, "solr:8.9", corev1.PullIfNotPresent, nil, initContainerResources(solrCloud.Spec.CustomSolrKubeOptions.PodOptions))
+	assert.Equal(t, *resources, pkcs12Container.Resources, "The pkcs12 init container should be given the provided resources")
+}
+
+func TestTLSHostnames(t *testing.T) {
+	replicas := int32(2)
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			Replicas: &replicas,
+			SolrAddressability: solr.SolrAddressabilityOptions{
+				External: &solr.ExternalAddressability{
+					Method:     solr.Ingress,
+					DomainName: "k8s.solr.cloud",
+				},
+			},
+			SolrTLS: &solr.SolrTLSOptions{AdditionalDNSNames: []string{"solr.example.com"}},
+		},
+	}
+	solrCloud.WithDefaults()
+	assert.Equal(t, []string{
+		"foo-solrcloud-common.default",
+		"default-foo-solrcloud.k8s.solr.cloud",
+		"foo-solrcloud-0.default",
+		"default-foo-solrcloud-0.k8s.solr.cloud",
+		"foo-solrcloud-1.default",
+		"default-foo-solrcloud-1.k8s.solr.cloud",
+		"solr.example.com",
+	}, solrCloud.TLSDNSNames(), "Wrong DNS names required for the TLS certificate")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"*.default", "foo-solrcloud-common.default", "*.k8s.solr.cloud"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := ParseTLSCertificate(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}))
+	assert.NoError(t, err, "The PEM encoded certificate should be parsed")
+	assert.Equal(t, []string{"solr.example.com"}, MissingTLSDNSNames(cert, solrCloud.TLSDNSNames()), "Only the additional DNS name is not covered by the wildcard certificate")
+
+	_, err = ParseTLSCertificate([]byte("mock tls.crt"))
+	assert.Error(t, err, "A tls.crt that is not PEM encoded cannot be parsed")
+}
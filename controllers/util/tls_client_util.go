@@ -0,0 +1,146 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+)
+
+const (
+	TLSCACertKey = "ca.crt"
+)
+
+// The mTLS http client of each SolrCloud, along with the resourceVersion of the client cert secret that it was built from
+type mTLSClientForSecret struct {
+	secretVersion string
+	client        *http.Client
+}
+
+var mTLSClients = map[string]mTLSClientForSecret{}
+var mTLSClientsLock sync.Mutex
+
+// BuildMTLSHttpClient creates an http client that presents the client certificate found in the given kubernetes.io/tls secret.
+// If the secret also contains a CA cert, it is used to verify the certificate of the Solr server.
+// Otherwise the Solr server is verified the same way the operator-wide http client does, as configured through the -tls-* flags.
+func BuildMTLSHttpClient(clientCertSecret *corev1.Secret) (*http.Client, error) {
+	certBytes, hasCert := clientCertSecret.Data[TLSCertKey]
+	keyBytes, hasKey := clientCertSecret.Data[TLSKeyKey]
+	if !hasCert || !hasKey {
+		return nil, fmt.Errorf("client cert secret %s must contain both the %s and %s keys", clientCertSecret.Name, TLSCertKey, TLSKeyKey)
+	}
+
+	clientCert, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := solr_api.DefaultTLSConfig()
+	tlsConfig.Certificates = []tls.Certificate{clientCert}
+	if caCertBytes, hasCA := clientCertSecret.Data[TLSCACertKey]; hasCA {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCertBytes) {
+			return nil, fmt.Errorf("unable to parse the %s in client cert secret %s", TLSCACertKey, clientCertSecret.Name)
+		}
+		tlsConfig.RootCAs = caCertPool
+		tlsConfig.InsecureSkipVerify = false
+	}
+
+	mTLSTransport := http.DefaultTransport.(*http.Transport).Clone()
+	mTLSTransport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: mTLSTransport}, nil
+}
+
+// MTLSHttpClientForCloud returns the http client that presents the client certificate in the given secret when calling
+// the Solr API for the given cloud. The http client is cached per SolrCloud and only rebuilt when the secret has changed,
+// such as when the certificate is renewed. If the secret cannot be used, the previous client is kept.
+func MTLSHttpClientForCloud(cloud *solr.SolrCloud, clientCertSecret *corev1.Secret) (*http.Client, error) {
+	cloudKey := cloud.Namespace + "/" + cloud.Name
+
+	mTLSClientsLock.Lock()
+	defer mTLSClientsLock.Unlock()
+
+	if cached, hasClient := mTLSClients[cloudKey]; hasClient && cached.secretVersion == clientCertSecret.ResourceVersion {
+		return cached.client, nil
+	}
+
+	httpClient, err := BuildMTLSHttpClient(clientCertSecret)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Built mTLS http client for SolrCloud", "namespace", cloud.Namespace, "cloud", cloud.Name, "secret", clientCertSecret.Name, "secretVersion", clientCertSecret.ResourceVersion)
+	mTLSClients[cloudKey] = mTLSClientForSecret{secretVersion: clientCertSecret.ResourceVersion, client: httpClient}
+
+	return httpClient, nil
+}
+
+// ResolveMTLSHttpClients makes the Solr API calls for every SolrCloud with a solrTLS.clientCertSecret present that client certificate.
+// The secret is read with the given reader whenever a cloud is called, so every controller uses the client certificate,
+// even before the SolrCloud has been reconciled, such as right after the operator restarts or while the cloud is paused.
+func ResolveMTLSHttpClients(reader client.Reader) {
+	solr_api.SetHttpClientResolver(func(cloud *solr.SolrCloud) (*http.Client, error) {
+		if cloud.Spec.SolrTLS == nil || cloud.Spec.SolrTLS.ClientCertSecret == "" {
+			return nil, nil
+		}
+		clientCertSecret := &corev1.Secret{}
+		if err := reader.Get(context.TODO(), types.NamespacedName{Name: cloud.Spec.SolrTLS.ClientCertSecret, Namespace: cloud.Namespace}, clientCertSecret); err != nil {
+			return nil, err
+		}
+		return MTLSHttpClientForCloud(cloud, clientCertSecret)
+	})
+}
+
+// RemoveMTLSHttpClientForCloud forgets the mTLS http client of the given cloud.
+// This must be called once the cloud no longer provides a client cert secret, or is deleted.
+func RemoveMTLSHttpClientForCloud(cloud *solr.SolrCloud) {
+	mTLSClientsLock.Lock()
+	defer mTLSClientsLock.Unlock()
+
+	delete(mTLSClients, cloud.Namespace+"/"+cloud.Name)
+}
+
+// ParseTLSCertificate parses the first certificate of the PEM encoded certificate chain, such as the "tls.crt" of a TLS secret
+func ParseTLSCertificate(certPem []byte) (*x509.Certificate, error) {
+	for block, rest := pem.Decode(certPem); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+	return nil, fmt.Errorf("no PEM encoded certificate found")
+}
+
+// MissingTLSDNSNames returns the DNS names that the given certificate is not valid for, taking wildcard names into account
+func MissingTLSDNSNames(cert *x509.Certificate, dnsNames []string) (missing []string) {
+	for _, dnsName := range dnsNames {
+		if cert.VerifyHostname(dnsName) != nil {
+			missing = append(missing, dnsName)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,143 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	solr "github.com/apache/solr-operator/api/v1beta1"
+	"github.com/apache/solr-operator/controllers/util/solr_api"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"math/big"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+	"time"
+)
+
+func TestMTLSHttpClientForCloud(t *testing.T) {
+	cloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+	clientCertSecret := generateTestClientCertSecret(t)
+
+	mTLSClient, err := MTLSHttpClientForCloud(cloud, clientCertSecret)
+	assert.NoError(t, err, "The client cert secret should be usable")
+
+	cachedClient, err := MTLSHttpClientForCloud(cloud, clientCertSecret)
+	assert.NoError(t, err)
+	assert.Same(t, mTLSClient, cachedClient, "The http client should not be rebuilt while the client cert secret is unchanged")
+
+	clientCertSecret.ResourceVersion = "2"
+	reloadedClient, err := MTLSHttpClientForCloud(cloud, clientCertSecret)
+	assert.NoError(t, err)
+	assert.NotSame(t, mTLSClient, reloadedClient, "The http client should be rebuilt when the client cert secret changes")
+
+	// A secret that cannot be used must not replace the working client
+	brokenSecret := clientCertSecret.DeepCopy()
+	brokenSecret.ResourceVersion = "3"
+	delete(brokenSecret.Data, TLSKeyKey)
+	_, err = MTLSHttpClientForCloud(cloud, brokenSecret)
+	assert.Error(t, err, "A client cert secret without a private key cannot be used")
+	cachedClient, err = MTLSHttpClientForCloud(cloud, clientCertSecret)
+	assert.NoError(t, err)
+	assert.Same(t, reloadedClient, cachedClient, "The previous http client should be kept when the client cert secret is invalid")
+
+	RemoveMTLSHttpClientForCloud(cloud)
+	mTLSClientsLock.Lock()
+	_, hasClient := mTLSClients[cloud.Namespace+"/"+cloud.Name]
+	mTLSClientsLock.Unlock()
+	assert.False(t, hasClient, "The mTLS http client of the SolrCloud should be forgotten")
+}
+
+func TestResolveMTLSHttpClients(t *testing.T) {
+	clientCertSecret := generateTestClientCertSecret(t)
+	cloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:       solr.SolrCloudSpec{SolrTLS: &solr.SolrTLSOptions{ClientCertSecret: clientCertSecret.Name}},
+	}
+	otherCloud := &solr.SolrCloud{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default"}}
+	missingSecretCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "baz", Namespace: "default"},
+		Spec:       solr.SolrCloudSpec{SolrTLS: &solr.SolrTLSOptions{ClientCertSecret: "missing"}},
+	}
+	defaultClient, err := solr_api.HttpClientForCloud(otherCloud)
+	assert.NoError(t, err)
+
+	// The fake client assigns the resourceVersion itself
+	clientCertSecret.ResourceVersion = ""
+	ResolveMTLSHttpClients(fake.NewFakeClient(clientCertSecret))
+	defer solr_api.SetHttpClientResolver(nil)
+	defer RemoveMTLSHttpClientForCloud(cloud)
+
+	mTLSClient, err := solr_api.HttpClientForCloud(cloud)
+	assert.NoError(t, err, "The client cert secret of the SolrCloud should be resolved")
+	assert.NotSame(t, defaultClient, mTLSClient, "The SolrCloud should use its own mTLS http client, without being reconciled first")
+	resolvedClient, err := solr_api.HttpClientForCloud(cloud)
+	assert.NoError(t, err)
+	assert.Same(t, mTLSClient, resolvedClient, "The resolved http client should be cached")
+
+	resolvedClient, err = solr_api.HttpClientForCloud(otherCloud)
+	assert.NoError(t, err)
+	assert.Same(t, defaultClient, resolvedClient, "SolrClouds without a client cert secret should keep using the operator-wide http client")
+
+	_, err = solr_api.HttpClientForCloud(missingSecretCloud)
+	assert.Error(t, err, "Solr should not be called without the client cert, while the client cert secret is missing")
+}
+
+func TestBuildMTLSHttpClientVerification(t *testing.T) {
+	clientCertSecret := generateTestClientCertSecret(t)
+
+	httpClient, err := BuildMTLSHttpClient(clientCertSecret)
+	assert.NoError(t, err)
+	tlsConfig := httpClient.Transport.(*http.Transport).TLSClientConfig
+	assert.Equal(t, solr_api.DefaultTLSConfig().InsecureSkipVerify, tlsConfig.InsecureSkipVerify, "Without a ca.crt, the Solr server should be verified the same way as by the operator-wide http client")
+	assert.Len(t, tlsConfig.Certificates, 1, "The client cert should be presented")
+
+	clientCertSecret.Data[TLSCACertKey] = clientCertSecret.Data[TLSCertKey]
+	httpClient, err = BuildMTLSHttpClient(clientCertSecret)
+	assert.NoError(t, err)
+	tlsConfig = httpClient.Transport.(*http.Transport).TLSClientConfig
+	assert.False(t, tlsConfig.InsecureSkipVerify, "The Solr server should be verified with the ca.crt of the client cert secret")
+	assert.NotNil(t, tlsConfig.RootCAs, "The ca.crt of the client cert secret should be trusted")
+}
+
+// generateTestClientCertSecret returns a kubernetes.io/tls secret holding a self-signed client certificate
+func generateTestClientCertSecret(t *testing.T) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "client-cert", Namespace: "default", ResourceVersion: "1"},
+		Data: map[string][]byte{
+			TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}),
+			TLSKeyKey:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		},
+	}
+}
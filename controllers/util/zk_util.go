@@ -18,15 +18,24 @@
 package util
 
 import (
+	"fmt"
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/go-logr/logr"
 	zk "github.com/pravega/zookeeper-operator/pkg/apis/zookeeper/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sort"
 	"strings"
 )
 
+const (
+	ZookeeperCleanupContainer = "zk-cleanup"
+	SecurityJsonContainer     = "security-json"
+	ZookeeperJobRetries       = 3
+)
+
 var log = logf.Log.WithName("controller")
 
 // GenerateZookeeperCluster returns a new ZookeeperCluster pointer generated for the SolrCloud instance
@@ -309,13 +318,216 @@ func AddACLsToEnv(allACL *solr.ZookeeperACL, readOnlyACL *solr.ZookeeperACL) (ha
 					},
 				},
 			})
-		zkDigests = append(zkDigests, "-DzkDigestReadonlyUsername=$(ZK_READ_ACL_USERNAME)", "-DzkDigestReadonlyPassword=$(ZK_READ_ACL_PASSWORD)")
+		if allACL != nil {
+			zkDigests = append(zkDigests, "-DzkDigestReadonlyUsername=$(ZK_READ_ACL_USERNAME)", "-DzkDigestReadonlyPassword=$(ZK_READ_ACL_PASSWORD)")
+		} else {
+			// Without admin credentials, Solr can only authenticate with the read-only credentials.
+			// The ACL provider cannot be used, since Solr would create znodes that it is not able to modify itself.
+			zkDigests = append(zkDigests, "-DzkDigestUsername=$(ZK_READ_ACL_USERNAME)", "-DzkDigestPassword=$(ZK_READ_ACL_PASSWORD)")
+		}
+	}
+	credsAndACLs := "-DzkCredentialsProvider=org.apache.solr.common.cloud.VMParamsSingleSetCredentialsDigestZkCredentialsProvider " + strings.Join(zkDigests, " ")
+	if allACL != nil {
+		credsAndACLs = "-DzkACLProvider=org.apache.solr.common.cloud.VMParamsAllAndReadonlyDigestZkACLProvider " + credsAndACLs
 	}
 	envVars = append(envVars,
 		corev1.EnvVar{
 			Name:  "SOLR_ZK_CREDS_AND_ACLS",
-			Value: "-DzkACLProvider=org.apache.solr.common.cloud.VMParamsAllAndReadonlyDigestZkACLProvider -DzkCredentialsProvider=org.apache.solr.common.cloud.VMParamsSingleSetCredentialsDigestZkCredentialsProvider " + strings.Join(zkDigests, " "),
+			Value: credsAndACLs,
 		})
 
 	return true, envVars
 }
+
+const (
+	ZkTLSTrustStorePath = "/var/solr/zk-tls/truststore"
+	ZkTLSKeyStorePath   = "/var/solr/zk-tls/keystore"
+)
+
+// ZookeeperTLSEnvVarsAndVolumes creates the environment variables, volumes and volumeMounts needed to connect to ZK over TLS.
+// The ZK client system properties are provided through the $SOLR_ZK_TLS_OPTS env var.
+func ZookeeperTLSEnvVarsAndVolumes(zkTLS *solr.ZookeeperTLSOptions) (envVars []corev1.EnvVar, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) {
+	f := false
+	defaultMode := int32(0664)
+	tlsOpts := []string{
+		"-Dzookeeper.client.secure=true",
+		"-Dzookeeper.clientCnxnSocket=org.apache.zookeeper.ClientCnxnSocketNetty",
+	}
+
+	addStore := func(storeType string, volumeName string, mountPath string, storeSecret *corev1.SecretKeySelector, passwordSecret *corev1.SecretKeySelector, passwordEnvVar string) {
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  storeSecret.Name,
+					Items:       []corev1.KeyToPath{{Key: storeSecret.Key, Path: storeSecret.Key}},
+					DefaultMode: &defaultMode,
+					Optional:    &f,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: volumeName, MountPath: mountPath, ReadOnly: true})
+		tlsOpts = append(tlsOpts,
+			"-Dzookeeper.ssl."+storeType+".location="+mountPath+"/"+storeSecret.Key,
+			"-Dzookeeper.ssl."+storeType+".type=PKCS12")
+		if passwordSecret != nil {
+			envVars = append(envVars, corev1.EnvVar{
+				Name:      passwordEnvVar,
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: passwordSecret},
+			})
+			tlsOpts = append(tlsOpts, "-Dzookeeper.ssl."+storeType+".password=$("+passwordEnvVar+")")
+		}
+	}
+
+	addStore("trustStore", "zk-truststore", ZkTLSTrustStorePath, &zkTLS.TrustStoreSecret, zkTLS.TrustStorePasswordSecret, "ZK_SSL_TRUST_STORE_PASSWORD")
+	if zkTLS.KeyStoreSecret != nil {
+		addStore("keyStore", "zk-keystore", ZkTLSKeyStorePath, zkTLS.KeyStoreSecret, zkTLS.KeyStorePasswordSecret, "ZK_SSL_KEY_STORE_PASSWORD")
+	}
+
+	envVars = append(envVars, corev1.EnvVar{
+		Name:  "SOLR_ZK_TLS_OPTS",
+		Value: strings.Join(tlsOpts, " "),
+	})
+	return envVars, volumes, volumeMounts
+}
+
+// AddZkTLSOptsToCredsAndACLs adds the ZK TLS options to the $SOLR_ZK_CREDS_AND_ACLS env var, creating it if no ACLs are used.
+// The Solr ZK tools, such as "solr zk" and zkcli.sh, pick up this env var, so they will connect to ZK over TLS as well.
+func AddZkTLSOptsToCredsAndACLs(aclEnvVars []corev1.EnvVar) []corev1.EnvVar {
+	for i := range aclEnvVars {
+		if aclEnvVars[i].Name == "SOLR_ZK_CREDS_AND_ACLS" {
+			aclEnvVars[i].Value += " $(SOLR_ZK_TLS_OPTS)"
+			return aclEnvVars
+		}
+	}
+	return append(aclEnvVars, corev1.EnvVar{
+		Name:  "SOLR_ZK_CREDS_AND_ACLS",
+		Value: "$(SOLR_ZK_TLS_OPTS)",
+	})
+}
+
+// ValidateZookeeperCleanup makes sure that the Zookeeper data of the SolrCloud can be removed when it is deleted.
+// Only a chroot can be removed, never the root of the Zookeeper ensemble.
+func ValidateZookeeperCleanup(solrCloud *solr.SolrCloud) error {
+	zkRef := solrCloud.Spec.ZookeeperRef
+	if zkRef == nil || !zkRef.CleanupZookeeperData {
+		return nil
+	}
+	chroot := ""
+	if zkRef.ConnectionInfo != nil {
+		chroot = zkRef.ConnectionInfo.ChRoot
+	} else if zkRef.ProvidedZookeeper != nil {
+		chroot = zkRef.ProvidedZookeeper.ChRoot
+	}
+	if strings.Trim(chroot, "/") == "" {
+		return fmt.Errorf("zookeeperRef.cleanupZookeeperData requires a chroot, the root of the Zookeeper ensemble cannot be removed")
+	}
+	return nil
+}
+
+// SharesZookeeperChroot returns whether a SolrCloud using the other Zookeeper connection would lose data,
+// if the chroot of the given Zookeeper connection was removed.
+// This is the case when both use the same Zookeeper ensemble, and the other chroot is the same as, or within, the removed chroot.
+func SharesZookeeperChroot(removed solr.ZookeeperConnectionInfo, other solr.ZookeeperConnectionInfo) bool {
+	if normalizedZkServers(removed.InternalConnectionString) != normalizedZkServers(other.InternalConnectionString) {
+		return false
+	}
+	removedChroot := strings.TrimSuffix(removed.ChRoot, "/")
+	otherChroot := strings.TrimSuffix(other.ChRoot, "/")
+	return otherChroot == removedChroot || strings.HasPrefix(otherChroot, removedChroot+"/")
+}
+
+// normalizedZkServers returns the servers of a Zookeeper connection string in a consistent order
+func normalizedZkServers(connectionString string) string {
+	servers := strings.Split(connectionString, ",")
+	for i := range servers {
+		servers[i] = strings.TrimSpace(servers[i])
+	}
+	sort.Strings(servers)
+	return strings.Join(servers, ",")
+}
+
+// GenerateZookeeperCleanupJob returns a new Job that removes the chroot of the deleted SolrCloud, and everything within it, from Zookeeper.
+// The Job connects to Zookeeper with the connection information in the status of the SolrCloud, the same way that the Solr pods do.
+// A chroot that does not exist is not an error, however the Job fails if Zookeeper cannot be reached.
+func GenerateZookeeperCleanupJob(solrCloud *solr.SolrCloud) *batchv1.Job {
+	cmd := "solr zk ls / -z ${ZK_SERVER} > /dev/null || exit 1; " +
+		"if solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} > /dev/null; then solr zk rm -r ${ZK_CHROOT} -z ${ZK_SERVER}; else echo \"The chroot ${ZK_CHROOT} does not exist\"; fi"
+
+	return generateZookeeperJob(solrCloud, solrCloud.ZookeeperCleanupJobName(), solr.ZookeeperCleanupTechnologyLabel, ZookeeperCleanupContainer, cmd, nil)
+}
+
+// GenerateSecurityJsonJob returns a new Job that writes the security.json in the SecurityJsonSecretName secret to the chroot of the SolrCloud in Zookeeper.
+// The Job uses the ZK ACLs of the SolrCloud, the same way that the setup-zk initContainer does when bootstrapping the security.json.
+// The security.json in Zookeeper is replaced as a whole, unless it is already the same.
+// Changes made through the Solr Security API after the merged security.json was built are therefore overwritten.
+func GenerateSecurityJsonJob(solrCloud *solr.SolrCloud) *batchv1.Job {
+	securityJsonEnvVar := corev1.EnvVar{Name: "SECURITY_JSON", ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: solrCloud.SecurityJsonSecretName()},
+			Key:                  SecurityJsonFile}}}
+	cmd := "solr zk ls ${ZK_CHROOT} -z ${ZK_SERVER} > /dev/null || exit 1; " +
+		"ZK_SECURITY_JSON=$(/opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd get /security.json); " +
+		"if [ \"${ZK_SECURITY_JSON}\" = \"${SECURITY_JSON}\" ]; then echo \"The security.json in ZK is already up to date\"; exit 0; fi; " +
+		"printf '%s' \"${SECURITY_JSON}\" > /tmp/security.json; /opt/solr/server/scripts/cloud-scripts/zkcli.sh -zkhost ${ZK_HOST} -cmd putfile /security.json /tmp/security.json"
+
+	return generateZookeeperJob(solrCloud, solrCloud.SecurityJsonJobName(), solr.SecurityJsonTechnologyLabel, SecurityJsonContainer, cmd, []corev1.EnvVar{securityJsonEnvVar})
+}
+
+// generateZookeeperJob returns a new Job that runs the given command against the Zookeeper chroot of the SolrCloud
+func generateZookeeperJob(solrCloud *solr.SolrCloud, name string, technologyLabel string, containerName string, cmd string, additionalEnvVars []corev1.EnvVar) *batchv1.Job {
+	labels := solrCloud.SharedLabelsWith(solrCloud.GetLabels())
+	labels["technology"] = technologyLabel
+
+	envVars, zkSolrOpt, _ := createZkConnectionEnvVars(solrCloud, &solrCloud.Status)
+	if zkSolrOpt != "" {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  "SOLR_OPTS",
+			Value: zkSolrOpt,
+		})
+	}
+	envVars = append(envVars, additionalEnvVars...)
+
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if zkTLS := solrCloud.Spec.ZookeeperRef.GetTLS(); zkTLS != nil {
+		_, volumes, volumeMounts = ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+	}
+
+	backoffLimit := int32(ZookeeperJobRetries)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: solrCloud.GetNamespace(),
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Volumes: volumes,
+					Containers: []corev1.Container{
+						{
+							Name:            containerName,
+							Image:           solrCloud.Spec.SolrImage.ToImageName(),
+							ImagePullPolicy: solrCloud.Spec.SolrImage.PullPolicy,
+							Command:         []string{"sh", "-c", cmd},
+							Env:             envVars,
+							VolumeMounts:    volumeMounts,
+							Resources:       initContainerResources(solrCloud.Spec.CustomSolrKubeOptions.PodOptions),
+							SecurityContext: containerSecurityContext(solrCloud.Spec.CustomSolrKubeOptions.PodOptions),
+						},
+					},
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+	if solrCloud.Spec.SolrImage.ImagePullSecret != "" {
+		job.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: solrCloud.Spec.SolrImage.ImagePullSecret}}
+	}
+	return job
+}
@@ -21,7 +21,9 @@ import (
 	solr "github.com/apache/solr-operator/api/v1beta1"
 	zkv1beta1 "github.com/pravega/zookeeper-operator/pkg/apis/zookeeper/v1beta1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
 	"testing"
 )
 
@@ -57,3 +59,170 @@ func TestDefaultStorageOptions(t *testing.T) {
 	assert.Equal(t, "ephemeral", zkCluster.Spec.StorageType, "By default when Solr is using ephemeral storage, zk should as well. Wrong storageType")
 	assert.Nil(t, zkCluster.Spec.Persistence, "By default when Solr is using ephemeral storage, zk should as well. Therefore 'persistence' should be nil")
 }
+
+func TestAddACLsToEnv(t *testing.T) {
+	allACL := &solr.ZookeeperACL{SecretRef: "zk-secret", UsernameKey: "user", PasswordKey: "pass"}
+	readOnlyACL := &solr.ZookeeperACL{SecretRef: "zk-read-secret", UsernameKey: "read-user", PasswordKey: "read-pass"}
+
+	hasACLs, envVars := AddACLsToEnv(nil, nil)
+	assert.False(t, hasACLs, "No ACLs should be used when none are provided")
+	assert.Empty(t, envVars, "No env vars should be added when no ACLs are provided")
+
+	hasACLs, envVars = AddACLsToEnv(allACL, readOnlyACL)
+	assert.True(t, hasACLs, "ACLs should be used when they are provided")
+	assert.Equal(t, 5, len(envVars), "Wrong number of env vars for admin and read-only ACLs")
+	assert.Equal(t, "-DzkACLProvider=org.apache.solr.common.cloud.VMParamsAllAndReadonlyDigestZkACLProvider -DzkCredentialsProvider=org.apache.solr.common.cloud.VMParamsSingleSetCredentialsDigestZkCredentialsProvider -DzkDigestUsername=$(ZK_ALL_ACL_USERNAME) -DzkDigestPassword=$(ZK_ALL_ACL_PASSWORD) -DzkDigestReadonlyUsername=$(ZK_READ_ACL_USERNAME) -DzkDigestReadonlyPassword=$(ZK_READ_ACL_PASSWORD)", envVars[4].Value, "Wrong ZK creds and ACLs for admin and read-only ACLs")
+
+	hasACLs, envVars = AddACLsToEnv(nil, readOnlyACL)
+	assert.True(t, hasACLs, "ACLs should be used when only read-only ACLs are provided")
+	assert.Equal(t, 3, len(envVars), "Wrong number of env vars for read-only ACLs")
+	assert.Equal(t, "zk-read-secret", envVars[0].ValueFrom.SecretKeyRef.Name, "The read-only username should come from the read-only secret")
+	assert.Equal(t, "-DzkCredentialsProvider=org.apache.solr.common.cloud.VMParamsSingleSetCredentialsDigestZkCredentialsProvider -DzkDigestUsername=$(ZK_READ_ACL_USERNAME) -DzkDigestPassword=$(ZK_READ_ACL_PASSWORD)", envVars[2].Value, "Only the read-only credentials, and no ACL provider, should be used when no admin ACL is provided")
+}
+
+func TestZookeeperTLSEnvVarsAndVolumes(t *testing.T) {
+	zkTLS := &solr.ZookeeperTLSOptions{
+		TrustStoreSecret: corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "zk-tls"},
+			Key:                  "truststore.p12",
+		},
+		TrustStorePasswordSecret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "zk-tls"},
+			Key:                  "password",
+		},
+	}
+
+	envVars, volumes, volumeMounts := ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+	assert.Equal(t, 2, len(envVars), "Wrong number of env vars for a ZK TrustStore")
+	assert.Equal(t, "ZK_SSL_TRUST_STORE_PASSWORD", envVars[0].Name, "The TrustStore password should be provided through an env var")
+	assert.Equal(t, "SOLR_ZK_TLS_OPTS", envVars[1].Name, "The ZK TLS options should be provided through an env var")
+	assert.Equal(t, "-Dzookeeper.client.secure=true -Dzookeeper.clientCnxnSocket=org.apache.zookeeper.ClientCnxnSocketNetty -Dzookeeper.ssl.trustStore.location=/var/solr/zk-tls/truststore/truststore.p12 -Dzookeeper.ssl.trustStore.type=PKCS12 -Dzookeeper.ssl.trustStore.password=$(ZK_SSL_TRUST_STORE_PASSWORD)", envVars[1].Value, "Wrong ZK TLS options for a ZK TrustStore")
+	assert.Equal(t, 1, len(volumes), "Only the TrustStore should be mounted when no KeyStore is provided")
+	assert.Equal(t, "zk-tls", volumes[0].Secret.SecretName, "The TrustStore volume should reference the TrustStore secret")
+	assert.Equal(t, 1, len(volumeMounts), "Only the TrustStore should be mounted when no KeyStore is provided")
+	assert.Equal(t, ZkTLSTrustStorePath, volumeMounts[0].MountPath, "Wrong mount path for the ZK TrustStore")
+
+	zkTLS.KeyStoreSecret = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "zk-client-tls"},
+		Key:                  "keystore.p12",
+	}
+	envVars, volumes, volumeMounts = ZookeeperTLSEnvVarsAndVolumes(zkTLS)
+	assert.Equal(t, 2, len(envVars), "A KeyStore without a password should not add a password env var")
+	assert.Contains(t, envVars[1].Value, "-Dzookeeper.ssl.keyStore.location=/var/solr/zk-tls/keystore/keystore.p12", "The ZK TLS options should reference the KeyStore")
+	assert.NotContains(t, envVars[1].Value, "-Dzookeeper.ssl.keyStore.password", "No KeyStore password should be set when none is provided")
+	assert.Equal(t, 2, len(volumes), "The TrustStore and KeyStore should be mounted")
+	assert.Equal(t, 2, len(volumeMounts), "The TrustStore and KeyStore should be mounted")
+
+	credsAndACLs := AddZkTLSOptsToCredsAndACLs(nil)
+	assert.Equal(t, []corev1.EnvVar{{Name: "SOLR_ZK_CREDS_AND_ACLS", Value: "$(SOLR_ZK_TLS_OPTS)"}}, credsAndACLs, "The ZK TLS options should be added to the ZK creds, even when no ACLs are used")
+
+	_, aclEnvVars := AddACLsToEnv(&solr.ZookeeperACL{SecretRef: "zk-secret", UsernameKey: "user", PasswordKey: "pass"}, nil)
+	credsAndACLs = AddZkTLSOptsToCredsAndACLs(aclEnvVars)
+	assert.Equal(t, len(aclEnvVars), len(credsAndACLs), "No additional env var should be created when ACLs are used")
+	assert.True(t, strings.HasSuffix(credsAndACLs[len(credsAndACLs)-1].Value, " $(SOLR_ZK_TLS_OPTS)"), "The ZK TLS options should be appended to the ZK creds and ACLs")
+}
+
+func TestValidateZookeeperCleanup(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{InternalConnectionString: "zk:2181", ChRoot: "/"},
+			},
+		},
+	}
+	assert.NoError(t, ValidateZookeeperCleanup(solrCloud), "A SolrCloud that does not clean up Zookeeper is valid")
+
+	solrCloud.Spec.ZookeeperRef.CleanupZookeeperData = true
+	assert.Error(t, ValidateZookeeperCleanup(solrCloud), "The root of the Zookeeper ensemble cannot be removed")
+
+	solrCloud.Spec.ZookeeperRef.ConnectionInfo.ChRoot = "/foo"
+	assert.NoError(t, ValidateZookeeperCleanup(solrCloud), "A chroot can be removed")
+
+	solrCloud.Spec.ZookeeperRef.ConnectionInfo = nil
+	solrCloud.Spec.ZookeeperRef.ProvidedZookeeper = &solr.ZookeeperSpec{}
+	assert.Error(t, ValidateZookeeperCleanup(solrCloud), "A provided Zookeeper without a chroot cannot be cleaned up")
+}
+
+func TestSharesZookeeperChroot(t *testing.T) {
+	removed := solr.ZookeeperConnectionInfo{InternalConnectionString: "zk-0:2181,zk-1:2181", ChRoot: "/foo"}
+
+	assert.True(t, SharesZookeeperChroot(removed, solr.ZookeeperConnectionInfo{InternalConnectionString: "zk-1:2181,zk-0:2181", ChRoot: "/foo"}), "The same chroot on the same ensemble is shared, regardless of the order of the servers")
+	assert.True(t, SharesZookeeperChroot(removed, solr.ZookeeperConnectionInfo{InternalConnectionString: "zk-0:2181,zk-1:2181", ChRoot: "/foo/bar"}), "A chroot within the removed chroot is shared")
+	assert.False(t, SharesZookeeperChroot(removed, solr.ZookeeperConnectionInfo{InternalConnectionString: "zk-0:2181,zk-1:2181", ChRoot: "/foobar"}), "A chroot with the removed chroot as a name prefix is not shared")
+	assert.False(t, SharesZookeeperChroot(removed, solr.ZookeeperConnectionInfo{InternalConnectionString: "zk-0:2181,zk-1:2181", ChRoot: "/"}), "The data of a SolrCloud using the root is not within the removed chroot")
+	assert.False(t, SharesZookeeperChroot(removed, solr.ZookeeperConnectionInfo{InternalConnectionString: "other-zk:2181", ChRoot: "/foo"}), "The same chroot on another ensemble is not shared")
+}
+
+func TestGenerateZookeeperCleanupJob(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "zk:2181",
+					ChRoot:                   "/foo",
+					AllACL:                   &solr.ZookeeperACL{SecretRef: "zk-secret", UsernameKey: "user", PasswordKey: "pass"},
+				},
+				CleanupZookeeperData: true,
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloud.Status.ZookeeperConnectionInfo = *solrCloud.Spec.ZookeeperRef.ConnectionInfo
+
+	job := GenerateZookeeperCleanupJob(solrCloud)
+	assert.Equal(t, "foo-solrcloud-zk-cleanup", job.Name, "Wrong name for the Zookeeper cleanup Job")
+	assert.Equal(t, solr.ZookeeperCleanupTechnologyLabel, job.Spec.Template.Labels["technology"], "The Job pods must not match the selectors of the Solr pods")
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy, "Wrong restartPolicy for the Zookeeper cleanup Job")
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, solrCloud.Spec.SolrImage.ToImageName(), container.Image, "The Job should use the Solr image")
+	assert.Contains(t, container.Command[2], "solr zk rm -r ${ZK_CHROOT} -z ${ZK_SERVER}", "The Job should remove the chroot")
+	envVars := map[string]string{}
+	for _, envVar := range container.Env {
+		envVars[envVar.Name] = envVar.Value
+	}
+	assert.Equal(t, "/foo", envVars["ZK_CHROOT"], "The chroot should be taken from the status of the SolrCloud")
+	assert.Equal(t, "zk:2181", envVars["ZK_SERVER"], "The Zookeeper server should be taken from the status of the SolrCloud")
+	assert.Equal(t, "$(SOLR_ZK_CREDS_AND_ACLS)", envVars["SOLR_OPTS"], "The Job should connect to Zookeeper with the ACLs of the SolrCloud")
+}
+
+func TestGenerateSecurityJsonJob(t *testing.T) {
+	solrCloud := &solr.SolrCloud{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: solr.SolrCloudSpec{
+			ZookeeperRef: &solr.ZookeeperRef{
+				ConnectionInfo: &solr.ZookeeperConnectionInfo{
+					InternalConnectionString: "zk:2181",
+					ChRoot:                   "/foo",
+					AllACL:                   &solr.ZookeeperACL{SecretRef: "zk-secret", UsernameKey: "user", PasswordKey: "pass"},
+				},
+			},
+		},
+	}
+	solrCloud.WithDefaults()
+	solrCloud.Status.ZookeeperConnectionInfo = *solrCloud.Spec.ZookeeperRef.ConnectionInfo
+
+	job := GenerateSecurityJsonJob(solrCloud)
+	assert.Equal(t, "foo-solrcloud-security-json", job.Name, "Wrong name for the security.json Job")
+	assert.Equal(t, solr.SecurityJsonTechnologyLabel, job.Spec.Template.Labels["technology"], "The Job pods must not match the selectors of the Solr pods")
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, container.Command[2], "-cmd putfile /security.json /tmp/security.json", "The Job should write the security.json to Zookeeper")
+	assert.Contains(t, container.Command[2], "printf '%s' \"${SECURITY_JSON}\" > /tmp/security.json", "The security.json should be written to the file as-is")
+	assert.Contains(t, container.Command[2], "if [ \"${ZK_SECURITY_JSON}\" = \"${SECURITY_JSON}\" ]", "The Job should not write a security.json that is already in Zookeeper")
+	var securityJsonEnvVar *corev1.EnvVar
+	envVars := map[string]string{}
+	for i, envVar := range container.Env {
+		envVars[envVar.Name] = envVar.Value
+		if envVar.Name == "SECURITY_JSON" {
+			securityJsonEnvVar = &container.Env[i]
+		}
+	}
+	assert.Equal(t, "$(SOLR_ZK_CREDS_AND_ACLS)", envVars["SOLR_OPTS"], "The Job should write the security.json with the ACLs of the SolrCloud")
+	if assert.NotNil(t, securityJsonEnvVar, "The merged security.json should be passed to the Job") {
+		assert.Equal(t, solrCloud.SecurityJsonSecretName(), securityJsonEnvVar.ValueFrom.SecretKeyRef.Name, "The merged security.json should be read from its secret")
+		assert.Equal(t, SecurityJsonFile, securityJsonEnvVar.ValueFrom.SecretKeyRef.Key, "The merged security.json should be read from its secret")
+	}
+}
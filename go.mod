@@ -11,6 +11,7 @@ require (
 	github.com/kr/pretty v0.2.1 // indirect
 	github.com/onsi/gomega v1.10.1
 	github.com/pravega/zookeeper-operator v0.2.9
+	github.com/prometheus/client_golang v1.7.1
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
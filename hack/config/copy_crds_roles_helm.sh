@@ -32,6 +32,8 @@ HELM_DIRECTORY="${HELM_DIRECTORY:-helm}"
   printf "\n"
   cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrbackups.yaml"
   cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrclouds.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrcollectionaliases.yaml"
+  cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrcollections.yaml"
   cat "${CONFIG_DIRECTORY}/crd/bases/solr.apache.org_solrprometheusexporters.yaml"
 } > "${HELM_DIRECTORY}/solr-operator/crds/crds.yaml"
 
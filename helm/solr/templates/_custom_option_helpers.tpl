@@ -55,10 +55,18 @@ tolerations:
 nodeSelector:
   {{- toYaml .Values.podOptions.nodeSelector | nindent 2 }}
 {{ end }}
+{{- if .Values.podOptions.topologySpreadConstraints -}}
+topologySpreadConstraints:
+  {{- toYaml .Values.podOptions.topologySpreadConstraints | nindent 2 }}
+{{ end }}
 {{- if .Values.podOptions.podSecurityContext -}}
 podSecurityContext:
   {{- toYaml .Values.podOptions.podSecurityContext | nindent 2 }}
 {{ end }}
+{{- if .Values.podOptions.containerSecurityContext -}}
+containerSecurityContext:
+  {{- toYaml .Values.podOptions.containerSecurityContext | nindent 2 }}
+{{ end }}
 {{- if (or .Values.podOptions.imagePullSecrets .Values.global.imagePullSecrets) -}}
 imagePullSecrets:
   {{- toYaml (append .Values.podOptions.imagePullSecrets .Values.global.imagePullSecrets) | nindent 2 }}
@@ -121,6 +129,29 @@ labels:
 annotations:
   {{- toYaml .Values.ingressOptions.annotations | nindent 2 }}
 {{ end }}
+{{- if .Values.ingressOptions.ingressClassName -}}
+ingressClassName: {{ .Values.ingressOptions.ingressClassName | quote }}
+{{ end }}
+{{- end -}}
+
+{{/*
+The values within PodDisruptionBudget Options for a SolrCloud
+*/}}
+{{- define "solr.custom-kube-options.pod-disruption-budget.filler" -}}
+{{- if .Values.podDisruptionBudgetOptions.labels -}}
+labels:
+  {{- toYaml .Values.podDisruptionBudgetOptions.labels | nindent 2 }}
+{{ end }}
+{{- if .Values.podDisruptionBudgetOptions.annotations -}}
+annotations:
+  {{- toYaml .Values.podDisruptionBudgetOptions.annotations | nindent 2 }}
+{{ end }}
+{{- if .Values.podDisruptionBudgetOptions.disabled -}}
+disabled: true
+{{ end }}
+{{- if .Values.podDisruptionBudgetOptions.maxUnavailable -}}
+maxUnavailable: {{ .Values.podDisruptionBudgetOptions.maxUnavailable | toJson }}
+{{ end }}
 {{- end -}}
 
 {{/*
@@ -228,6 +259,12 @@ ingressOptions:
   {{- . | nindent 2 -}}
 {{ end }}
 {{ end }}
+{{- with (include "solr.custom-kube-options.pod-disruption-budget.filler" .) -}}
+{{- if . -}}
+podDisruptionBudgetOptions:
+  {{- . | nindent 2 -}}
+{{ end }}
+{{ end }}
 {{- end -}}
 
 {{/*
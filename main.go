@@ -18,12 +18,14 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"flag"
 	"fmt"
 	solrv1beta1 "github.com/apache/solr-operator/api/v1beta1"
 	"github.com/apache/solr-operator/controllers"
+	"github.com/apache/solr-operator/controllers/util"
 	"github.com/apache/solr-operator/controllers/util/solr_api"
 	"github.com/apache/solr-operator/version"
 	"io/ioutil"
@@ -31,12 +33,16 @@ import (
 	"os"
 	"runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"strings"
 
 	zkv1beta1 "github.com/pravega/zookeeper-operator/pkg/apis"
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	// +kubebuilder:scaffold:imports
@@ -60,6 +66,15 @@ var (
 	// External Operator dependencies
 	useZookeeperCRD bool
 
+	// Admission webhooks
+	enableWebhooks bool
+
+	// Reconcile intervals
+	requeueIntervals controllers.RequeueIntervals
+
+	// Reconcile concurrency
+	maxConcurrentReconciles int
+
 	// mTLS information
 	clientSkipVerify  bool
 	clientCertPath    string
@@ -75,6 +90,12 @@ func init() {
 
 	// +kubebuilder:scaffold:scheme
 	flag.BoolVar(&useZookeeperCRD, "zk-operator", true, "The operator will not use the zk operator & crd when this flag is set to false.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the defaulting and validating admission webhooks for SolrClouds. Requires a serving certificate in /tmp/k8s-webhook-server/serving-certs.")
+	flag.DurationVar(&requeueIntervals.Poll, "requeue-poll-interval", controllers.DefaultRequeueIntervals.Poll, "How often to check on operations that are expected to finish soon, such as moving replicas before a scale down. Also the first wait when backing off on transient conditions.")
+	flag.DurationVar(&requeueIntervals.Retry, "requeue-retry-interval", controllers.DefaultRequeueIntervals.Retry, "How long to wait before retrying blocked actions, such as managed updates waiting on replicas to recover.")
+	flag.DurationVar(&requeueIntervals.MissingDependency, "requeue-missing-dependency-interval", controllers.DefaultRequeueIntervals.MissingDependency, "How often to check for user-provided resources that do not exist yet, such as a providedConfigMap or a StorageClass.")
+	flag.DurationVar(&requeueIntervals.MaxBackoff, "requeue-max-backoff", controllers.DefaultRequeueIntervals.MaxBackoff, "The longest wait when backing off on transient conditions, such as Zookeeper or the TLS secrets becoming available.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "The maximum number of SolrClouds that are reconciled in parallel.")
 	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "The comma-separated list of namespaces to watch. If an empty string (default) is provided, the operator will watch the entire Kubernetes cluster.")
 
 	flag.BoolVar(&clientSkipVerify, "tls-skip-verify-server", true, "Controls whether a client verifies the server's certificate chain and host name. If true (insecure), TLS accepts any certificate presented by the server and any host name in that certificate.")
@@ -121,6 +142,7 @@ func main() {
 	// For further information see the kubernetes documentation about
 	// Using [RBAC Authorization](https://kubernetes.io/docs/reference/access-authn-authz/rbac/).
 	var managerWatchCache cache.NewCacheFunc
+	var managerClient manager.NewClientFunc
 	if watchNamespaces != "" {
 		setupLog.Info(fmt.Sprintf("Managing for Namespaces: %s", watchNamespaces))
 		ns := strings.Split(watchNamespaces, ",")
@@ -128,9 +150,13 @@ func main() {
 			ns[i] = strings.TrimSpace(ns[i])
 		}
 		managerWatchCache = cache.MultiNamespacedCacheBuilder(ns)
+		// The namespaced caches cannot hold cluster-scoped resources, such as Nodes and StorageClasses, so they are read from the API Server instead.
+		// Only these cluster-scoped resources need a ClusterRole, everything else can be restricted to Roles in the watched namespaces.
+		managerClient = newNamespacedCacheClient(ns)
 	} else {
 		setupLog.Info("Managing for the entire cluster.")
 		managerWatchCache = (cache.NewCacheFunc)(nil)
+		managerClient = (manager.NewClientFunc)(nil)
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -139,21 +165,31 @@ func main() {
 		LeaderElection:     enableLeaderElection,
 		Port:               9443,
 		NewCache:           managerWatchCache,
+		NewClient:          managerClient,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	controllers.UseZkCRD(useZookeeperCRD)
+	if maxConcurrentReconciles < 1 {
+		setupLog.Error(fmt.Errorf("must be at least 1, got %d", maxConcurrentReconciles), "invalid -max-concurrent-reconciles")
+		os.Exit(1)
+	}
 
 	if err = initMTLSConfig(); err != nil {
 		os.Exit(1)
 	}
+	util.ResolveMTLSHttpClients(mgr.GetClient())
 
 	if err = (&controllers.SolrCloudReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Client:                  mgr.GetClient(),
+		Log:                     ctrl.Log.WithName("controllers").WithName("SolrCloud"),
+		Recorder:                mgr.GetEventRecorderFor("solrcloud-controller"),
+		UseZkCRD:                useZookeeperCRD,
+		UseDefaultingWebhook:    enableWebhooks,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RequeueIntervals:        requeueIntervals,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SolrCloud")
 		os.Exit(1)
@@ -165,13 +201,38 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "SolrBackup")
 		os.Exit(1)
 	}
-	if err = (&controllers.SolrPrometheusExporterReconciler{
+	if err = (&controllers.SolrCollectionReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("SolrCollection"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SolrCollection")
+		os.Exit(1)
+	}
+	if err = (&controllers.SolrCollectionAliasReconciler{
 		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("SolrPrometheusExporter"),
+		Log:    ctrl.Log.WithName("controllers").WithName("SolrCollectionAlias"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SolrCollectionAlias")
+		os.Exit(1)
+	}
+	if err = (&controllers.SolrPrometheusExporterReconciler{
+		Client:               mgr.GetClient(),
+		Log:                  ctrl.Log.WithName("controllers").WithName("SolrPrometheusExporter"),
+		UseServiceMonitorCRD: serviceMonitorCRDInstalled(mgr.GetConfig()),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SolrPrometheusExporter")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&solrv1beta1.SolrCloud{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SolrCloud")
+			os.Exit(1)
+		}
+		if err = (&controllers.SolrCloudValidator{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SolrCloud")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
@@ -181,6 +242,57 @@ func main() {
 	}
 }
 
+// newNamespacedCacheClient returns the function that creates the client used by the manager when only the given namespaces are watched.
+// It is the same as the default manager client, except that objects that the cache does not hold are read from the API Server.
+func newNamespacedCacheClient(namespaces []string) manager.NewClientFunc {
+	watchedNamespaces := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		watchedNamespaces[namespace] = true
+	}
+	return func(watchCache cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
+		c, err := client.New(config, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return &client.DelegatingClient{
+			Reader: &namespacedCacheReader{
+				CacheReader:  watchCache,
+				ClientReader: c,
+				Namespaces:   watchedNamespaces,
+			},
+			Writer:       c,
+			StatusClient: c,
+		}, nil
+	}
+}
+
+// namespacedCacheReader reads objects in the watched namespaces from the cache, and all other objects from the API Server.
+// Objects that are gotten without a namespace are cluster-scoped, since the operator always gets namespaced objects within the namespace of their SolrCloud.
+// Lists without a namespace are read from the cache, which holds the objects of all watched namespaces, since the operator only lists namespaced objects.
+type namespacedCacheReader struct {
+	CacheReader  client.Reader
+	ClientReader client.Reader
+
+	// Namespaces are the namespaces whose objects are held by the cache
+	Namespaces map[string]bool
+}
+
+func (r *namespacedCacheReader) Get(ctx context.Context, key client.ObjectKey, obj k8sRuntime.Object) error {
+	if !r.Namespaces[key.Namespace] {
+		return r.ClientReader.Get(ctx, key, obj)
+	}
+	return r.CacheReader.Get(ctx, key, obj)
+}
+
+func (r *namespacedCacheReader) List(ctx context.Context, list k8sRuntime.Object, opts ...client.ListOption) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	if listOpts.Namespace != "" && !r.Namespaces[listOpts.Namespace] {
+		return r.ClientReader.List(ctx, list, opts...)
+	}
+	return r.CacheReader.List(ctx, list, opts...)
+}
+
 func initMTLSConfig() error {
 	if clientCertPath != "" {
 		setupLog.Info("mTLS config", "clientSkipVerify", clientSkipVerify, "clientCertPath", clientCertPath,
@@ -201,7 +313,7 @@ func initMTLSConfig() error {
 			if caCertBytes, err := ioutil.ReadFile(caCertPath); err == nil {
 				caCertPool := x509.NewCertPool()
 				caCertPool.AppendCertsFromPEM(caCertBytes)
-				mTLSTransport.TLSClientConfig.ClientCAs = caCertPool
+				mTLSTransport.TLSClientConfig.RootCAs = caCertPool
 				setupLog.Info("Configured the custom CA pem for the mTLS transport", "path", caCertPath)
 			} else {
 				setupLog.Error(err, "Cannot read provided CA pem for mTLS transport", "path", caCertPath)
@@ -214,3 +326,23 @@ func initMTLSConfig() error {
 
 	return nil
 }
+
+// serviceMonitorCRDInstalled checks whether the Prometheus Operator's ServiceMonitor CRD is installed in the Kubernetes cluster
+func serviceMonitorCRDInstalled(config *rest.Config) bool {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		setupLog.Error(err, "Cannot create a discovery client to look for the ServiceMonitor CRD")
+		return false
+	}
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(util.ServiceMonitorGVK.GroupVersion().String())
+	if err != nil {
+		setupLog.Info("The ServiceMonitor CRD is not installed, so no ServiceMonitors will be generated for SolrPrometheusExporters")
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == util.ServiceMonitorGVK.Kind {
+			return true
+		}
+	}
+	return false
+}
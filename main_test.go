@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+// recordingReader records the objects that are read through it
+type recordingReader struct {
+	gets  []client.ObjectKey
+	lists int
+}
+
+func (r *recordingReader) Get(ctx context.Context, key client.ObjectKey, obj k8sRuntime.Object) error {
+	r.gets = append(r.gets, key)
+	return nil
+}
+
+func (r *recordingReader) List(ctx context.Context, list k8sRuntime.Object, opts ...client.ListOption) error {
+	r.lists += 1
+	return nil
+}
+
+func TestNamespacedCacheReader(t *testing.T) {
+	cacheReader := &recordingReader{}
+	apiServerReader := &recordingReader{}
+	reader := &namespacedCacheReader{CacheReader: cacheReader, ClientReader: apiServerReader, Namespaces: map[string]bool{"default": true, "solr": true}}
+
+	assert.NoError(t, reader.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "foo-solrcloud-configmap"}, &corev1.ConfigMap{}))
+	assert.Equal(t, []client.ObjectKey{{Namespace: "default", Name: "foo-solrcloud-configmap"}}, cacheReader.gets, "Objects in a watched namespace should be read from the cache")
+	assert.Empty(t, apiServerReader.gets, "Objects in a watched namespace should not be read from the API Server")
+
+	assert.NoError(t, reader.Get(context.TODO(), client.ObjectKey{Name: "fast"}, &storagev1.StorageClass{}))
+	assert.Equal(t, []client.ObjectKey{{Name: "fast"}}, apiServerReader.gets, "Cluster-scoped objects should be read from the API Server")
+	assert.Len(t, cacheReader.gets, 1, "Cluster-scoped objects should not be read from the cache")
+
+	assert.NoError(t, reader.Get(context.TODO(), client.ObjectKey{Namespace: "other", Name: "zk-secret"}, &corev1.Secret{}))
+	assert.Equal(t, []client.ObjectKey{{Name: "fast"}, {Namespace: "other", Name: "zk-secret"}}, apiServerReader.gets, "Objects outside of the watched namespaces should be read from the API Server")
+	assert.Len(t, cacheReader.gets, 1, "Objects outside of the watched namespaces should not be read from the cache")
+
+	assert.NoError(t, reader.List(context.TODO(), &corev1.PodList{}, client.InNamespace("solr")))
+	assert.Equal(t, 1, cacheReader.lists, "Lists in a watched namespace should be read from the cache")
+	assert.Equal(t, 0, apiServerReader.lists, "Lists in a watched namespace should not be read from the API Server")
+
+	assert.NoError(t, reader.List(context.TODO(), &corev1.PodList{}))
+	assert.Equal(t, 2, cacheReader.lists, "Lists across all namespaces should be read from the cache, which holds every watched namespace")
+	assert.Equal(t, 0, apiServerReader.lists, "Lists across all namespaces should not be read from the API Server")
+
+	assert.NoError(t, reader.List(context.TODO(), &corev1.PodList{}, client.InNamespace("other")))
+	assert.Equal(t, 1, apiServerReader.lists, "Lists outside of the watched namespaces should be read from the API Server")
+	assert.Equal(t, 2, cacheReader.lists, "Lists outside of the watched namespaces should not be read from the cache")
+}